@@ -0,0 +1,75 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/cliphist/cliphist.go
+// Summary: Picker listing recent clipboard entries so one can be pasted.
+// Usage: The desktop opens it as a floating panel from control mode "p",
+//   hands it the clipboard history and listens on "clipboard.select"
+//   (payload: the entry ID) and "clipboard.close".
+
+package cliphist
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/apps/internal/listpicker"
+	"github.com/framegrace/texelation/texel"
+	"github.com/mattn/go-runewidth"
+)
+
+var _ texel.ClipboardHistorySetter = (*Picker)(nil)
+
+// Picker is the clipboard history list.
+type Picker struct {
+	*listpicker.Picker
+	now func() time.Time
+}
+
+// New creates an empty picker.
+func New() *Picker {
+	return &Picker{
+		Picker: listpicker.New(listpicker.Config{
+			App:     "clipboard-history",
+			Title:   "Clipboard",
+			Header:  "Clipboard history",
+			Empty:   "Nothing copied yet",
+			Control: "clipboard",
+		}),
+		now: time.Now,
+	}
+}
+
+// SetClipboardHistory implements texel.ClipboardHistorySetter.
+func (p *Picker) SetClipboardHistory(entries []texel.ClipboardEntry) {
+	now := p.now()
+	items := make([]listpicker.Item, len(entries))
+	for i, e := range entries {
+		source := e.Source
+		if source == "" {
+			source = "client"
+		}
+		items[i] = listpicker.Item{
+			Text:  preview(e),
+			Meta:  fmt.Sprintf("%s · %s", runewidth.Truncate(source, 16, "…"), listpicker.FormatAge(now.Sub(e.CopiedAt))),
+			Value: e.ID,
+		}
+	}
+	p.SetItems(items)
+}
+
+// preview renders an entry on one line: the first line of text with a
+// count of the rest, or the type and size for non-text data.
+func preview(e texel.ClipboardEntry) string {
+	if !strings.HasPrefix(e.Mime, "text/") {
+		return fmt.Sprintf("[%s, %d bytes]", e.Mime, len(e.Data))
+	}
+	text := strings.ReplaceAll(string(e.Data), "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	first := strings.Join(strings.Fields(lines[0]), " ")
+	if len(lines) > 1 {
+		return fmt.Sprintf("%s (+%d lines)", first, len(lines)-1)
+	}
+	return first
+}
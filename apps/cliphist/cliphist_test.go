@@ -0,0 +1,47 @@
+package cliphist
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPickerListsAndPastes(t *testing.T) {
+	now := time.Unix(10000, 0)
+	p := New()
+	p.now = func() time.Time { return now }
+	p.Resize(60, 6)
+	p.SetClipboardHistory([]texel.ClipboardEntry{
+		{ID: 7, Mime: "text/plain", Data: []byte("make   test\nmake lint\n"), Source: "shell", CopiedAt: now.Add(-2 * time.Minute)},
+		{ID: 3, Mime: "image/png", Data: make([]byte, 12), CopiedAt: now.Add(-5 * time.Hour)},
+	})
+
+	var lines []string
+	for _, row := range p.Render() {
+		var sb strings.Builder
+		for _, c := range row {
+			sb.WriteRune(c.Ch)
+		}
+		lines = append(lines, sb.String())
+	}
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{"make test (+1 lines)", "shell · 2m ago", "[image/png, 12 bytes]", "client · 5h ago"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("render missing %q:\n%s", want, screen)
+		}
+	}
+
+	var picked interface{}
+	p.RegisterControl("clipboard.select", "", func(payload interface{}) error {
+		picked = payload
+		return nil
+	})
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if picked != uint64(3) {
+		t.Fatalf("picked %v, want second entry", picked)
+	}
+}
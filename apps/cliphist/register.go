@@ -0,0 +1,26 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/cliphist/register.go
+// Summary: Registers the clipboard history picker with the Texelation registry.
+
+package cliphist
+
+import "github.com/framegrace/texelation/registry"
+
+func init() {
+	registry.RegisterBuiltInProvider(func(_ *registry.Registry) (*registry.Manifest, registry.AppFactory) {
+		return &registry.Manifest{
+			Name:        "clipboard-history",
+			DisplayName: "Clipboard",
+			Description: "Paste an earlier clipboard entry",
+			Icon:        "📋",
+			Category:    "system",
+			ThemeSchema: registry.ThemeSchema{
+				"ui": {"bg.surface", "text.primary", "text.secondary", "text.inverse", "accent"},
+			},
+		}, func() interface{} {
+			return New()
+		}
+	})
+}
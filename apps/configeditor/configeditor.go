@@ -411,6 +411,44 @@ func (e *ConfigEditor) buildSystemSections(target *configTarget) *widgets.TabPan
 		panel.AddTab("Screensaver", e.buildSectionPane(target, target.values, "screensaver", ssValues, false, applySystem))
 	}
 
+	// Filter monitor by defaults.
+	monValues := sectionValues(target.values, "monitor")
+	if defaults := config.SystemDefaults(); defaults != nil {
+		if defMon := defaults.Section("monitor"); defMon != nil {
+			filtered := make(map[string]interface{})
+			for key, defVal := range defMon {
+				if userVal, ok := monValues[key]; ok {
+					filtered[key] = userVal
+				} else {
+					filtered[key] = defVal
+				}
+			}
+			monValues = filtered
+		}
+	}
+	if len(monValues) > 0 {
+		panel.AddTab("Monitor", e.buildSectionPane(target, target.values, "monitor", monValues, false, applySystem))
+	}
+
+	// Filter clipboard by defaults.
+	clipValues := sectionValues(target.values, "clipboard")
+	if defaults := config.SystemDefaults(); defaults != nil {
+		if defClip := defaults.Section("clipboard"); defClip != nil {
+			filtered := make(map[string]interface{})
+			for key, defVal := range defClip {
+				if userVal, ok := clipValues[key]; ok {
+					filtered[key] = userVal
+				} else {
+					filtered[key] = defVal
+				}
+			}
+			clipValues = filtered
+		}
+	}
+	if len(clipValues) > 0 {
+		panel.AddTab("Clipboard", e.buildSectionPane(target, target.values, "clipboard", clipValues, false, applySystem))
+	}
+
 	themePane := e.buildGroupedThemePane(target, target.themeValues, systemThemeSections, true)
 	panel.AddTab("Theme", themePane)
 
@@ -19,12 +19,9 @@ import (
 	"github.com/framegrace/texelui/widgets"
 )
 
-// keybindingConfig is the JSON structure of keybindings.json.
-type keybindingConfig struct {
-	Preset      string              `json:"preset"`
-	ExtraPreset string              `json:"extraPreset"`
-	Actions     map[string][]string `json:"actions"`
-}
+// keybindingConfig is the JSON structure of keybindings.json. The editor
+// only changes actions; chords and key tables are kept as loaded.
+type keybindingConfig = keybind.Config
 
 // modifierOptions for the combo box. <Control Mode> is dynamic.
 var modifierLabels = []string{"(none)", "<Ctrl>", "<Alt>", "<Shift>", "<Control Mode>"}
@@ -33,7 +30,7 @@ var modifierLabels = []string{"(none)", "<Ctrl>", "<Alt>", "<Shift>", "<Control
 func buildKeybindingsTab(onApply func()) core.Widget {
 	// Load current keybinding config
 	kbCfg := loadKeybindingConfig()
-	registry := keybind.NewRegistry(kbCfg.Preset, kbCfg.ExtraPreset, kbCfg.Actions)
+	registry := keybind.NewRegistryFromConfig(*kbCfg)
 	if kbCfg.Actions == nil {
 		kbCfg.Actions = make(map[string][]string)
 	}
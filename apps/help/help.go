@@ -86,6 +86,12 @@ func formatKeys(r *keybind.Registry, action keybind.Action, fallback string) str
 	return s
 }
 
+// paletteKeys labels an action that is unbound by default and runs from
+// the command palette.
+func paletteKeys(r *keybind.Registry) string {
+	return formatKeys(r, keybind.ControlToggle, "Ctrl+A") + " " + formatKeys(r, keybind.ControlPalette, ":")
+}
+
 func allSections(r *keybind.Registry) []helpSection {
 	return []helpSection{
 		{
@@ -117,6 +123,10 @@ func allSections(r *keybind.Registry) []helpSection {
 				{formatKeys(r, keybind.TermScrollbar, "F7"), "Toggle scrollbar"},
 				{formatKeys(r, keybind.TermTransformer, "F8"), "Toggle transformers"},
 				{formatKeys(r, keybind.TermScreenshot, "Ctrl+P"), "Save pane screenshot"},
+				{formatKeys(r, keybind.TermTableView, paletteKeys(r)), "Table viewer (sort, filter, copy)"},
+				{formatKeys(r, keybind.TermFold, paletteKeys(r)), "Fold/unfold JSON or YAML output"},
+				{formatKeys(r, keybind.TermCollapse, paletteKeys(r)), "Collapse/expand command output"},
+				{formatKeys(r, keybind.TermExport, paletteKeys(r)), "Export command output (HTML/ANSI/text)"},
 				{formatKeys(r, keybind.TermScrollPgUp, "Alt+PgUp") + "/" + formatKeys(r, keybind.TermScrollPgDn, "Alt+PgDn"), "Scroll history (page)"},
 				{"Mouse wheel", "Scroll history"},
 				{"Drag mouse", "Select & copy text"},
@@ -135,6 +145,21 @@ func controlSection(r *keybind.Registry) helpSection {
 			{formatKeys(r, keybind.ControlClose, "x"), "Close active pane"},
 			{formatKeys(r, keybind.ControlSwap, "w"), "Swap panes (then Arrow)"},
 			{formatKeys(r, keybind.ControlZoom, "z"), "Toggle zoom"},
+			{formatKeys(r, keybind.ControlStack, "s"), "Stack split into tabs / unstack"},
+			{formatKeys(r, keybind.ControlStackPrev, "[") + "/" + formatKeys(r, keybind.ControlStackNext, "]"), "Previous/next tab"},
+			{formatKeys(r, keybind.ControlLayout, "Space"), "Cycle layouts (even, main, tiled)"},
+			{formatKeys(r, keybind.ControlEqualize, "="), "Equalise pane sizes"},
+			{formatKeys(r, keybind.ControlSendPane, "m"), "Send pane to workspace (then 1-9)"},
+			{formatKeys(r, keybind.ControlJoinPane, "J"), "Join pane from workspace (then 1-9)"},
+			{formatKeys(r, keybind.ControlBreakPane, "!"), "Break pane out to new workspace"},
+			{formatKeys(r, keybind.ControlUndoClose, "u"), "Reopen last closed pane"},
+			{formatKeys(r, keybind.ControlReopen, "U"), "Pick a closed pane to reopen"},
+			{formatKeys(r, keybind.ControlSnapshots, "S"), "Restore an earlier session layout"},
+			{formatKeys(r, keybind.ControlLock, "L"), "Lock the screen"},
+			{formatKeys(r, keybind.ControlClipboard, "p"), "Clipboard history (Enter pastes)"},
+			{formatKeys(r, keybind.ControlPalette, ":"), "Command palette (type to filter, Enter runs)"},
+			{formatKeys(r, keybind.ControlOSC52Allow, "y") + "/" + formatKeys(r, keybind.ControlOSC52Deny, "n"), "Allow/deny a terminal clipboard request"},
+			{formatKeys(r, keybind.ControlOSC52AllowPane, "Y") + "/" + formatKeys(r, keybind.ControlOSC52DenyPane, "N"), "Always allow/never allow it for that pane"},
 			{formatKeys(r, keybind.ControlRenameTab, "t"), "Rename workspace"},
 		{formatKeys(r, keybind.ControlNewTab, "T"), "New workspace (type name, Enter)"},
 			{formatKeys(r, keybind.ControlCloseTab, "X"), "Close workspace (y/n confirm)"},
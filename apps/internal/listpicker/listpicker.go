@@ -0,0 +1,310 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/internal/listpicker/listpicker.go
+// Summary: Shared list widget for the desktop's picker overlays.
+// Usage: A picker app embeds *Picker, turns its data into Items and calls
+//   SetItems. Up/Down move the selection, Enter triggers
+//   "<Control>.select" with the selected item's Value and Esc triggers
+//   "<Control>.close". With Config.Score set, typing filters the list and
+//   the header becomes the query line. The desktop opens pickers with
+//   launchPickerOverlay.
+
+package listpicker
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelation/internal/theming"
+	"github.com/framegrace/texelation/texel"
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+var _ texelcore.ControlBusProvider = (*Picker)(nil)
+
+// Config describes a picker.
+type Config struct {
+	App     string // registry name; picks the theme
+	Title   string // panel title, returned by GetTitle
+	Header  string // first line of the panel, unless filtering
+	Empty   string // shown when there is nothing to pick
+	Control string // control bus prefix of the select and close events
+
+	// Score ranks an item's text against a typed query, -1 for no match.
+	// Nil disables filtering.
+	Score func(text, query string) int
+}
+
+// Item is one row of the list.
+type Item struct {
+	Text   string      // left-aligned, truncated to fit
+	Note   string      // dim, after Text when there is room; filtered on
+	Meta   string      // dim, right-aligned
+	Detail []string    // dim lines shown under the item while it is selected
+	Value  interface{} // payload of "<Control>.select"
+}
+
+// Picker is a selectable list shown in a floating panel.
+type Picker struct {
+	cfg Config
+
+	mu            sync.Mutex
+	width, height int
+	items         []Item
+	query         []rune
+	matches       []Item
+	selected      int
+	controlBus    texelcore.ControlBus
+	refreshChan   chan<- bool
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+// New creates an empty picker.
+func New(cfg Config) *Picker {
+	return &Picker{
+		cfg:        cfg,
+		controlBus: texelcore.NewControlBus(),
+		stop:       make(chan struct{}),
+	}
+}
+
+// SetItems replaces the list and selects its first match.
+func (p *Picker) SetItems(items []Item) {
+	p.mu.Lock()
+	p.items = items
+	p.filterLocked()
+	p.mu.Unlock()
+	p.requestRefresh()
+}
+
+// filterLocked rebuilds matches for the current query, best first. Ties
+// keep the given order. Caller holds mu.
+func (p *Picker) filterLocked() {
+	p.selected = 0
+	query := string(p.query)
+	if p.cfg.Score == nil || strings.TrimSpace(query) == "" {
+		p.matches = p.items
+		return
+	}
+	type scored struct {
+		item  Item
+		score int
+	}
+	var hits []scored
+	for _, it := range p.items {
+		if s := p.cfg.Score(it.Text+" "+it.Note, query); s >= 0 {
+			hits = append(hits, scored{it, s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	p.matches = make([]Item, len(hits))
+	for i, h := range hits {
+		p.matches[i] = h.item
+	}
+}
+
+// RegisterControl implements texelcore.ControlBusProvider.
+func (p *Picker) RegisterControl(id, description string, handler func(payload interface{}) error) error {
+	return p.controlBus.Register(id, description, texel.ControlHandler(handler))
+}
+
+func (p *Picker) Run() error {
+	<-p.stop
+	return nil
+}
+
+func (p *Picker) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *Picker) Resize(cols, rows int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.width, p.height = cols, rows
+}
+
+func (p *Picker) GetTitle() string {
+	return p.cfg.Title
+}
+
+func (p *Picker) SetRefreshNotifier(refreshChan chan<- bool) {
+	p.refreshChan = refreshChan
+}
+
+func (p *Picker) HandleKey(ev *tcell.EventKey) {
+	p.mu.Lock()
+	switch ev.Key() {
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		if p.selected > 0 {
+			p.selected--
+		}
+	case tcell.KeyDown, tcell.KeyCtrlN:
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if p.cfg.Score != nil && len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filterLocked()
+		}
+	case tcell.KeyCtrlU:
+		if p.cfg.Score != nil {
+			p.query = p.query[:0]
+			p.filterLocked()
+		}
+	case tcell.KeyRune:
+		if p.cfg.Score != nil {
+			p.query = append(p.query, ev.Rune())
+			p.filterLocked()
+		}
+	case tcell.KeyEnter:
+		if p.selected < len(p.matches) {
+			value := p.matches[p.selected].Value
+			p.mu.Unlock()
+			p.trigger(p.cfg.Control+".select", value)
+			return
+		}
+	case tcell.KeyEsc:
+		p.mu.Unlock()
+		p.trigger(p.cfg.Control+".close", nil)
+		return
+	}
+	p.mu.Unlock()
+	p.requestRefresh()
+}
+
+func (p *Picker) trigger(id string, payload interface{}) {
+	if err := p.controlBus.Trigger(id, payload); err != nil {
+		log.Printf("%s: failed to trigger %s: %v", p.cfg.Title, id, err)
+	}
+}
+
+func (p *Picker) requestRefresh() {
+	if p.refreshChan == nil {
+		return
+	}
+	select {
+	case p.refreshChan <- true:
+	default:
+	}
+}
+
+func (p *Picker) Render() [][]texelcore.Cell {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.width <= 0 || p.height <= 0 {
+		return [][]texelcore.Cell{}
+	}
+
+	tm := theming.ForApp(p.cfg.App)
+	base := tcell.StyleDefault.Background(tm.GetSemanticColor("bg.surface")).Foreground(tm.GetSemanticColor("text.primary"))
+	dim := base.Foreground(tm.GetSemanticColor("text.secondary"))
+	sel := base.Background(tm.GetSemanticColor("accent")).Foreground(tm.GetSemanticColor("text.inverse"))
+
+	buf := make([][]texelcore.Cell, p.height)
+	for y := range buf {
+		buf[y] = make([]texelcore.Cell, p.width)
+		for x := range buf[y] {
+			buf[y][x] = texelcore.Cell{Ch: ' ', Style: base}
+		}
+	}
+	put := func(x, y int, s string, st tcell.Style) int {
+		for _, r := range s {
+			w := runewidth.RuneWidth(r)
+			if x+w > p.width {
+				break
+			}
+			buf[y][x] = texelcore.Cell{Ch: r, Style: st}
+			x += w
+		}
+		return x
+	}
+
+	if p.cfg.Score != nil {
+		x := put(1, 0, "> ", base.Bold(true))
+		x = put(x, 0, string(p.query), base)
+		put(x, 0, "▏", dim)
+	} else {
+		put(1, 0, p.cfg.Header, base.Bold(true))
+	}
+	if len(p.matches) == 0 {
+		if p.height > 2 {
+			put(1, 2, p.cfg.Empty, dim)
+		}
+		return buf
+	}
+
+	// Keep the selected item and its detail lines on screen below the
+	// header.
+	type row struct {
+		item   int
+		detail string
+	}
+	var rows []row
+	selStart, selEnd := 0, 0
+	for i, item := range p.matches {
+		if i == p.selected {
+			selStart = len(rows)
+		}
+		rows = append(rows, row{item: i})
+		if i == p.selected {
+			for _, d := range item.Detail {
+				rows = append(rows, row{item: i, detail: d})
+			}
+			selEnd = len(rows)
+		}
+	}
+	visible := p.height - 2
+	first := 0
+	if selEnd > visible {
+		first = min(selEnd-visible, selStart)
+	}
+	for i := first; i < len(rows) && i-first < visible; i++ {
+		y := i - first + 2
+		r := rows[i]
+		if r.detail != "" {
+			put(1, y, runewidth.Truncate(r.detail, p.width-2, "…"), dim)
+			continue
+		}
+		item := p.matches[r.item]
+		st, metaSt := base, dim
+		if r.item == p.selected {
+			st, metaSt = sel, sel
+			for x := range buf[y] {
+				buf[y][x].Style = sel
+			}
+		}
+		metaX := p.width - 1
+		if item.Meta != "" {
+			metaX = max(p.width-runewidth.StringWidth(item.Meta)-1, 1)
+			put(metaX, y, item.Meta, metaSt)
+		}
+		x := put(1, y, runewidth.Truncate(item.Text, metaX-2, "…"), st)
+		if rest := metaX - x - 3; rest > 0 && item.Note != "" {
+			put(x+2, y, runewidth.Truncate(item.Note, rest, "…"), metaSt)
+		}
+	}
+	return buf
+}
+
+// FormatAge renders d as a short "n units ago" string.
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
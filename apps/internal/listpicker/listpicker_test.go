@@ -0,0 +1,60 @@
+package listpicker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPickerScrollsSelectsAndCloses(t *testing.T) {
+	p := New(Config{App: "test", Title: "Test", Header: "Pick one", Empty: "Nothing here", Control: "test"})
+	p.Resize(30, 4)
+
+	screen := func() string {
+		var lines []string
+		for _, row := range p.Render() {
+			var sb strings.Builder
+			for _, c := range row {
+				sb.WriteRune(c.Ch)
+			}
+			lines = append(lines, sb.String())
+		}
+		return strings.Join(lines, "\n")
+	}
+	if got := screen(); !strings.Contains(got, "Pick one") || !strings.Contains(got, "Nothing here") {
+		t.Fatalf("empty picker:\n%s", got)
+	}
+
+	p.SetItems([]Item{
+		{Text: "alpha", Meta: "1", Value: 1},
+		{Text: "beta", Meta: "2", Value: 2},
+		{Text: "gamma", Meta: "3", Value: 3},
+	})
+	var picked interface{}
+	closed := false
+	p.RegisterControl("test.select", "", func(payload interface{}) error {
+		picked = payload
+		return nil
+	})
+	p.RegisterControl("test.close", "", func(interface{}) error {
+		closed = true
+		return nil
+	})
+
+	// Two rows fit below the header; moving to the third scrolls.
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	if got := screen(); strings.Contains(got, "alpha") || !strings.Contains(got, "gamma") {
+		t.Fatalf("selection scrolled out of view:\n%s", got)
+	}
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if picked != 3 {
+		t.Fatalf("picked %v, want 3", picked)
+	}
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone))
+	if !closed {
+		t.Fatal("Esc did not close the picker")
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/monitor/monitor.go
+// Summary: Live performance monitor graphing server diagnostics samples.
+// Usage: Launch "monitor" from the launcher; the pane subscribes to
+//   texel.EventDiagnostics and redraws on every sample.
+// Notes: Sparklines scale to the largest value currently on screen, so a
+//   single spike stays visible until it scrolls out of the history.
+
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelation/internal/theming"
+	"github.com/framegrace/texelation/texel"
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// historyLen is how many samples are kept; wider panes show more of them.
+const historyLen = 240
+
+const (
+	labelWidth = 12
+	valueWidth = 10
+)
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// metric is one graphed series derived from the sample history.
+type metric struct {
+	label  string
+	value  func(prev, cur texel.DiagnosticsPayload) float64
+	format func(v float64) string
+}
+
+var metrics = []metric{
+	{"publish", func(_, c texel.DiagnosticsPayload) float64 { return float64(c.PublishLatency) }, formatDuration},
+	{"flush", func(_, c texel.DiagnosticsPayload) float64 { return float64(c.FlushLatency) }, formatDuration},
+	{"diff queue", func(_, c texel.DiagnosticsPayload) float64 { return float64(c.DiffQueueDepth) }, formatCount},
+	{"dropped/s", func(p, c texel.DiagnosticsPayload) float64 {
+		return perSecond(p.DroppedDiffs, c.DroppedDiffs, c.Interval)
+	}, formatCount},
+	{"merged/s", func(p, c texel.DiagnosticsPayload) float64 {
+		return perSecond(p.CoalescedDiffs, c.CoalescedDiffs, c.Interval)
+	}, formatCount},
+	{"index queue", func(_, c texel.DiagnosticsPayload) float64 { return float64(c.IndexBacklog) }, formatCount},
+	{"goroutines", func(_, c texel.DiagnosticsPayload) float64 { return float64(c.Goroutines) }, formatCount},
+}
+
+type monitorApp struct {
+	mu            sync.RWMutex
+	width, height int
+	history       []texel.DiagnosticsPayload
+	refreshChan   chan<- bool
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewMonitorApp creates the performance monitor.
+func NewMonitorApp() texelcore.App {
+	return &monitorApp{stop: make(chan struct{})}
+}
+
+func (a *monitorApp) Run() error {
+	<-a.stop
+	return nil
+}
+
+func (a *monitorApp) Stop() {
+	a.stopOnce.Do(func() { close(a.stop) })
+}
+
+func (a *monitorApp) Resize(cols, rows int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.width, a.height = cols, rows
+}
+
+func (a *monitorApp) GetTitle() string {
+	return "Monitor"
+}
+
+func (a *monitorApp) HandleKey(ev *tcell.EventKey) {}
+
+func (a *monitorApp) SetRefreshNotifier(refreshChan chan<- bool) {
+	a.refreshChan = refreshChan
+}
+
+// OnEvent records diagnostics samples broadcast by the server.
+func (a *monitorApp) OnEvent(event texel.Event) {
+	if event.Type != texel.EventDiagnostics {
+		return
+	}
+	sample, ok := event.Payload.(texel.DiagnosticsPayload)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	if len(a.history) == historyLen {
+		copy(a.history, a.history[1:])
+		a.history = a.history[:historyLen-1]
+	}
+	a.history = append(a.history, sample)
+	a.mu.Unlock()
+	if a.refreshChan != nil {
+		select {
+		case a.refreshChan <- true:
+		default:
+		}
+	}
+}
+
+func (a *monitorApp) Render() [][]texelcore.Cell {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.width <= 0 || a.height <= 0 {
+		return [][]texelcore.Cell{}
+	}
+
+	tm := theming.ForApp("monitor")
+	bg := tm.GetColor("desktop", "default_bg", tcell.ColorReset).TrueColor()
+	base := tcell.StyleDefault.Background(bg).Foreground(tm.GetSemanticColor("text.primary"))
+	dim := base.Foreground(tm.GetSemanticColor("text.secondary"))
+	title := base.Foreground(tm.GetSemanticColor("text.active")).Bold(true)
+	spark := base.Foreground(tm.GetSemanticColor("accent.primary"))
+
+	buf := make([][]texelcore.Cell, a.height)
+	for y := range buf {
+		buf[y] = make([]texelcore.Cell, a.width)
+		for x := range buf[y] {
+			buf[y][x] = texelcore.Cell{Ch: ' ', Style: base}
+		}
+	}
+	put := func(x, y int, s string, st tcell.Style) int {
+		if y < 0 || y >= a.height {
+			return x
+		}
+		for _, r := range s {
+			w := runewidth.RuneWidth(r)
+			if x+w > a.width {
+				break
+			}
+			buf[y][x] = texelcore.Cell{Ch: r, Style: st}
+			x += w
+		}
+		return x
+	}
+
+	put(1, 0, "Performance Monitor", title)
+	if len(a.history) == 0 {
+		put(1, 2, "Waiting for diagnostics samples…", dim)
+		return buf
+	}
+	latest := a.history[len(a.history)-1]
+	put(22, 0, fmt.Sprintf("every %s · %s", latest.Interval, latest.At.Format("15:04:05")), dim)
+
+	graphWidth := max(a.width-labelWidth-valueWidth-3, 0)
+	y := 2
+	for _, m := range metrics {
+		if y >= a.height {
+			return buf
+		}
+		values := a.series(m, graphWidth)
+		put(1, y, m.label, dim)
+		put(1+labelWidth, y, sparkline(values), spark)
+		var cur float64
+		if len(values) > 0 {
+			cur = values[len(values)-1]
+		}
+		v := m.format(cur)
+		put(a.width-1-runewidth.StringWidth(v), y, v, base)
+		y++
+	}
+
+	y++
+	put(1, y, "pane render (slowest first)", title)
+	y++
+	for _, p := range latest.Panes {
+		if y >= a.height {
+			break
+		}
+		name := p.Title
+		if name == "" {
+			name = fmt.Sprintf("%x", p.ID[:4])
+		}
+		put(1, y, runewidth.Truncate(name, max(a.width-valueWidth-3, 0), "…"), base)
+		v := formatDuration(float64(p.RenderTime))
+		put(a.width-1-runewidth.StringWidth(v), y, v, dim)
+		y++
+	}
+	return buf
+}
+
+// series returns up to n of the newest values of m, oldest first.
+func (a *monitorApp) series(m metric, n int) []float64 {
+	start := max(len(a.history)-n, 0)
+	out := make([]float64, 0, len(a.history)-start)
+	for i := start; i < len(a.history); i++ {
+		var prev texel.DiagnosticsPayload
+		if i > 0 {
+			prev = a.history[i-1]
+		} else {
+			prev = a.history[i]
+		}
+		out = append(out, m.value(prev, a.history[i]))
+	}
+	return out
+}
+
+// sparkline renders values as block glyphs scaled to their maximum.
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(v / peak * float64(len(sparkLevels)-1))
+		}
+		out[i] = sparkLevels[min(max(level, 0), len(sparkLevels)-1)]
+	}
+	return string(out)
+}
+
+func perSecond(prev, cur uint64, interval time.Duration) float64 {
+	if cur < prev || interval <= 0 {
+		return 0
+	}
+	return float64(cur-prev) / interval.Seconds()
+}
+
+func formatDuration(v float64) string {
+	d := time.Duration(v)
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%dµs", d/time.Microsecond)
+	}
+}
+
+func formatCount(v float64) string {
+	if v >= 10000 {
+		return fmt.Sprintf("%.1fk", v/1000)
+	}
+	if v != float64(int64(v)) {
+		return fmt.Sprintf("%.1f", v)
+	}
+	return fmt.Sprintf("%d", int64(v))
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+)
+
+func TestSparklineScalesToPeak(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 2, 4}); got != "▁▂▄█" {
+		t.Fatalf("sparkline = %q", got)
+	}
+	if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Fatalf("flat sparkline = %q", got)
+	}
+}
+
+func TestMonitorGraphsSamples(t *testing.T) {
+	app := NewMonitorApp().(*monitorApp)
+	app.Resize(60, 16)
+	refresh := make(chan bool, 1)
+	app.SetRefreshNotifier(refresh)
+
+	for i := 0; i < historyLen+5; i++ {
+		app.OnEvent(texel.Event{Type: texel.EventDiagnostics, Payload: texel.DiagnosticsPayload{
+			At:             time.Unix(int64(i), 0),
+			Interval:       time.Second,
+			PublishLatency: time.Duration(i) * time.Millisecond,
+			DroppedDiffs:   uint64(2 * i),
+			Goroutines:     10,
+			Panes:          []texel.PaneDiagnostics{{Title: "htop", RenderTime: 3 * time.Millisecond}},
+		}})
+	}
+	if len(app.history) != historyLen {
+		t.Fatalf("history = %d, want %d", len(app.history), historyLen)
+	}
+	select {
+	case <-refresh:
+	default:
+		t.Fatal("sample should request a redraw")
+	}
+
+	buf := app.Render()
+	text := make([]string, len(buf))
+	for y, row := range buf {
+		var sb strings.Builder
+		for _, c := range row {
+			sb.WriteRune(c.Ch)
+		}
+		text[y] = sb.String()
+	}
+	screen := strings.Join(text, "\n")
+	for _, want := range []string{"publish", "█", "244.0ms", "dropped/s", "2", "htop", "3.0ms"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("render missing %q:\n%s", want, screen)
+		}
+	}
+}
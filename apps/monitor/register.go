@@ -0,0 +1,27 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/monitor/register.go
+// Summary: Registers the monitor app with the Texelation registry.
+
+package monitor
+
+import "github.com/framegrace/texelation/registry"
+
+func init() {
+	registry.RegisterBuiltInProvider(func(_ *registry.Registry) (*registry.Manifest, registry.AppFactory) {
+		return &registry.Manifest{
+			Name:        "monitor",
+			DisplayName: "Monitor",
+			Description: "Live server performance graphs",
+			Icon:        "📈",
+			Category:    "system",
+			ThemeSchema: registry.ThemeSchema{
+				"desktop": {"default_bg"},
+				"ui":      {"text.primary", "text.secondary", "text.active", "accent.primary"},
+			},
+		}, func() interface{} {
+			return NewMonitorApp()
+		}
+	})
+}
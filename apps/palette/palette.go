@@ -0,0 +1,75 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/palette/palette.go
+// Summary: Fuzzy-searchable list of everything the desktop can run.
+// Usage: The desktop opens it as a floating panel from control mode ":",
+//   hands it the entries and listens on "palette.select" (payload: the
+//   chosen texel.PaletteEntry) and "palette.close". Typing filters, Up/Down
+//   move, Enter runs.
+
+package palette
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/framegrace/texelation/apps/internal/listpicker"
+	"github.com/framegrace/texelation/texel"
+)
+
+var _ texel.PaletteEntriesSetter = (*Palette)(nil)
+
+// Palette is the command palette overlay.
+type Palette struct {
+	*listpicker.Picker
+}
+
+// New creates an empty palette.
+func New() *Palette {
+	return &Palette{listpicker.New(listpicker.Config{
+		App:     "palette",
+		Title:   "Commands",
+		Empty:   "No matching commands",
+		Control: "palette",
+		Score:   score,
+	})}
+}
+
+// SetPaletteEntries implements texel.PaletteEntriesSetter.
+func (p *Palette) SetPaletteEntries(entries []texel.PaletteEntry) {
+	items := make([]listpicker.Item, len(entries))
+	for i, e := range entries {
+		items[i] = listpicker.Item{Text: e.Title, Note: e.Category, Meta: e.Keys, Value: e}
+	}
+	p.SetItems(items)
+}
+
+// score ranks text against query, ignoring case and spaces in the query.
+// It is -1 unless every query rune appears in text in order; otherwise
+// matches at word starts and runs of adjacent matches score higher.
+func score(text, query string) int {
+	t := []rune(strings.ToLower(text))
+	total, ti, last := 0, 0, -2
+	for _, q := range strings.ToLower(query) {
+		if unicode.IsSpace(q) {
+			continue
+		}
+		for ti < len(t) && t[ti] != q {
+			ti++
+		}
+		if ti == len(t) {
+			return -1
+		}
+		total++
+		if ti == last+1 {
+			total += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			total += 3
+		}
+		last = ti
+		ti++
+	}
+	return total
+}
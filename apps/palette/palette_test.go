@@ -0,0 +1,58 @@
+package palette
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/framegrace/texelation/texel"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPaletteFiltersAndRuns(t *testing.T) {
+	p := New()
+	p.Resize(60, 8)
+	p.SetPaletteEntries([]texel.PaletteEntry{
+		{Title: "Split pane vertically", Category: "Control", Keys: "Ctrl+A |"},
+		{Title: "Toggle history search", Category: "Terminal", Keys: "F3"},
+		{Title: "Launch Terminal", Category: "App"},
+		{Title: "vim", Category: "Pane · workspace 2"},
+	})
+
+	for _, r := range "srch" {
+		p.HandleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	var lines []string
+	for _, row := range p.Render() {
+		var sb strings.Builder
+		for _, c := range row {
+			sb.WriteRune(c.Ch)
+		}
+		lines = append(lines, sb.String())
+	}
+	screen := strings.Join(lines, "\n")
+	if !strings.Contains(screen, "Toggle history search") || !strings.Contains(screen, "F3") {
+		t.Fatalf("expected search entry with its key:\n%s", screen)
+	}
+	if strings.Contains(screen, "Split pane") {
+		t.Fatalf("non-matching entry still listed:\n%s", screen)
+	}
+
+	var picked interface{}
+	p.RegisterControl("palette.select", "", func(payload interface{}) error {
+		picked = payload
+		return nil
+	})
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if e, ok := picked.(texel.PaletteEntry); !ok || e.Title != "Toggle history search" {
+		t.Fatalf("picked %v, want the search entry", picked)
+	}
+}
+
+func TestScorePrefersWordStarts(t *testing.T) {
+	if score("Toggle pane zoom", "xyz") != -1 {
+		t.Fatal("expected no match")
+	}
+	if score("Toggle pane zoom", "pz") <= score("Toggle pane zoom", "ne") {
+		t.Fatal("expected word-start matches to rank higher")
+	}
+}
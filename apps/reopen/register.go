@@ -0,0 +1,26 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/reopen/register.go
+// Summary: Registers the reopen picker with the Texelation registry.
+
+package reopen
+
+import "github.com/framegrace/texelation/registry"
+
+func init() {
+	registry.RegisterBuiltInProvider(func(_ *registry.Registry) (*registry.Manifest, registry.AppFactory) {
+		return &registry.Manifest{
+			Name:        "reopen",
+			DisplayName: "Reopen",
+			Description: "Reopen a recently closed pane",
+			Icon:        "↩",
+			Category:    "system",
+			ThemeSchema: registry.ThemeSchema{
+				"ui": {"bg.surface", "text.primary", "text.secondary", "text.inverse", "accent"},
+			},
+		}, func() interface{} {
+			return New()
+		}
+	})
+}
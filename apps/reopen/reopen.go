@@ -0,0 +1,54 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/reopen/reopen.go
+// Summary: Picker listing recently closed panes so one can be reopened.
+// Usage: The desktop opens it as a floating panel from control mode "U",
+//   hands it the undo-close stack and listens on "reopen.select" (payload:
+//   the pane ID) and "reopen.close".
+
+package reopen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/framegrace/texelation/apps/internal/listpicker"
+	"github.com/framegrace/texelation/texel"
+)
+
+var _ texel.ClosedPaneListSetter = (*Picker)(nil)
+
+// Picker is the reopen-closed-pane list.
+type Picker struct {
+	*listpicker.Picker
+	now func() time.Time
+}
+
+// New creates an empty picker.
+func New() *Picker {
+	return &Picker{
+		Picker: listpicker.New(listpicker.Config{
+			App:     "reopen",
+			Title:   "Reopen",
+			Header:  "Reopen closed pane",
+			Empty:   "No closed panes",
+			Control: "reopen",
+		}),
+		now: time.Now,
+	}
+}
+
+// SetClosedPanes implements texel.ClosedPaneListSetter.
+func (p *Picker) SetClosedPanes(entries []texel.ClosedPaneInfo) {
+	now := p.now()
+	items := make([]listpicker.Item, len(entries))
+	for i, e := range entries {
+		items[i] = listpicker.Item{
+			Text:  e.Title,
+			Meta:  fmt.Sprintf("ws %d · %s", e.WorkspaceID, listpicker.FormatAge(now.Sub(e.ClosedAt))),
+			Value: e.ID,
+		}
+	}
+	p.SetItems(items)
+}
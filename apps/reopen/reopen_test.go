@@ -0,0 +1,47 @@
+package reopen
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPickerListsAndSelects(t *testing.T) {
+	now := time.Unix(10000, 0)
+	p := New()
+	p.now = func() time.Time { return now }
+	p.Resize(50, 6)
+	p.SetClosedPanes([]texel.ClosedPaneInfo{
+		{ID: [16]byte{1}, Title: "vim", WorkspaceID: 1, ClosedAt: now.Add(-5 * time.Minute)},
+		{ID: [16]byte{2}, Title: "htop", WorkspaceID: 2, ClosedAt: now.Add(-3 * time.Hour)},
+	})
+
+	var lines []string
+	for _, row := range p.Render() {
+		var sb strings.Builder
+		for _, c := range row {
+			sb.WriteRune(c.Ch)
+		}
+		lines = append(lines, sb.String())
+	}
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{"vim", "ws 1 · 5m ago", "htop", "ws 2 · 3h ago"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("render missing %q:\n%s", want, screen)
+		}
+	}
+
+	var picked interface{}
+	p.RegisterControl("reopen.select", "", func(payload interface{}) error {
+		picked = payload
+		return nil
+	})
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if picked != [16]byte{2} {
+		t.Fatalf("picked %v, want second entry", picked)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/snapshots/register.go
+// Summary: Registers the snapshot picker with the Texelation registry.
+
+package snapshots
+
+import "github.com/framegrace/texelation/registry"
+
+func init() {
+	registry.RegisterBuiltInProvider(func(_ *registry.Registry) (*registry.Manifest, registry.AppFactory) {
+		return &registry.Manifest{
+			Name:        "snapshots",
+			DisplayName: "Snapshots",
+			Description: "Restore an earlier session layout",
+			Icon:        "⟲",
+			Category:    "system",
+			ThemeSchema: registry.ThemeSchema{
+				"ui": {"bg.surface", "text.primary", "text.secondary", "text.inverse", "accent"},
+			},
+		}, func() interface{} {
+			return New()
+		}
+	})
+}
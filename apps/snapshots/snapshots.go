@@ -0,0 +1,69 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/snapshots/snapshots.go
+// Summary: Picker listing saved generations of the session layout so an
+//   earlier one can be restored.
+// Usage: The desktop opens it as a floating panel from control mode "S",
+//   hands it the generations and listens on "snapshots.select" (payload:
+//   the generation ID) and "snapshots.close".
+
+package snapshots
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/framegrace/texelation/apps/internal/listpicker"
+	"github.com/framegrace/texelation/texel"
+)
+
+var _ texel.SnapshotListSetter = (*Picker)(nil)
+
+// Picker is the snapshot generation list. The selected generation is
+// expanded to show its workspaces and pane titles.
+type Picker struct {
+	*listpicker.Picker
+}
+
+// New creates an empty picker.
+func New() *Picker {
+	return &Picker{listpicker.New(listpicker.Config{
+		App:     "snapshots",
+		Title:   "Snapshots",
+		Header:  "Restore session layout",
+		Empty:   "No saved snapshots",
+		Control: "snapshots",
+	})}
+}
+
+// SetSnapshots implements texel.SnapshotListSetter.
+func (p *Picker) SetSnapshots(generations []texel.SnapshotGeneration) {
+	items := make([]listpicker.Item, len(generations))
+	for i, g := range generations {
+		panes := 0
+		var detail []string
+		for _, ws := range g.Workspaces {
+			panes += len(ws.PaneTitles)
+			name := ws.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", ws.ID)
+			}
+			detail = append(detail, "  "+name+": "+strings.Join(ws.PaneTitles, ", "))
+		}
+		items[i] = listpicker.Item{
+			Text:   g.SavedAt.Local().Format("Mon Jan 2 15:04:05"),
+			Meta:   fmt.Sprintf("%s · %s", plural(len(g.Workspaces), "workspace"), plural(panes, "pane")),
+			Detail: detail,
+			Value:  g.ID,
+		}
+	}
+	p.SetItems(items)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,60 @@
+package snapshots
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPickerListsAndSelects(t *testing.T) {
+	saved := time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local)
+	p := New()
+	p.Resize(60, 8)
+	p.SetSnapshots([]texel.SnapshotGeneration{
+		{ID: "20260304-103000", SavedAt: saved, Workspaces: []texel.SnapshotWorkspace{
+			{ID: 1, Name: "main", PaneTitles: []string{"vim", "htop"}},
+		}},
+		{ID: "20260303-090000", SavedAt: saved.Add(-25 * time.Hour), Workspaces: []texel.SnapshotWorkspace{
+			{ID: 1, PaneTitles: []string{"bash"}},
+			{ID: 2, Name: "logs", PaneTitles: []string{"tail"}},
+		}},
+	})
+
+	screen := func() string {
+		var lines []string
+		for _, row := range p.Render() {
+			var sb strings.Builder
+			for _, c := range row {
+				sb.WriteRune(c.Ch)
+			}
+			lines = append(lines, sb.String())
+		}
+		return strings.Join(lines, "\n")
+	}
+	got := screen()
+	for _, want := range []string{"Wed Mar 4 10:30:00", "1 workspace · 2 panes", "main: vim, htop", "2 workspaces · 2 panes"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("render missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "logs: tail") {
+		t.Fatalf("unselected generation should not be expanded:\n%s", got)
+	}
+
+	var picked interface{}
+	p.RegisterControl("snapshots.select", "", func(payload interface{}) error {
+		picked = payload
+		return nil
+	})
+	p.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	if got := screen(); !strings.Contains(got, "logs: tail") || strings.Contains(got, "main: vim") {
+		t.Fatalf("selection should expand the second generation only:\n%s", got)
+	}
+	p.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if picked != "20260303-090000" {
+		t.Fatalf("picked %v, want second generation", picked)
+	}
+}
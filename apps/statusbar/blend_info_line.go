@@ -3,7 +3,7 @@
 //
 // File: apps/statusbar/blend_info_line.go
 // Summary: Custom widget for the status bar's second row — gradient blend line
-// with overlaid text (mode icon, title, fps, clock) and toast notifications.
+// with overlaid text (mode icon, title, segments, clock) and toast notifications.
 
 package statusbar
 
@@ -39,6 +39,8 @@ type BlendInfoLine struct {
 	title         string
 	date          string
 	clock         string
+	leftSegs      []Segment
+	rightSegs     []Segment
 
 	// Toast state
 	toastMessage  string
@@ -107,6 +109,16 @@ func (bil *BlendInfoLine) SetTitle(title string) {
 	bil.invalidate()
 }
 
+// SetSegments sets the configured segments shown after the title and
+// before the clock.
+func (bil *BlendInfoLine) SetSegments(left, right []Segment) {
+	bil.mu.Lock()
+	bil.leftSegs = left
+	bil.rightSegs = right
+	bil.mu.Unlock()
+	bil.invalidate()
+}
+
 // SetClock sets the date and clock strings for the right-side display.
 func (bil *BlendInfoLine) SetClock(date, t string) {
 	bil.mu.Lock()
@@ -169,6 +181,8 @@ func (bil *BlendInfoLine) Draw(painter *core.Painter) {
 	title := bil.title
 	date := bil.date
 	clock := bil.clock
+	leftSegs := bil.leftSegs
+	rightSegs := bil.rightSegs
 	toastMsg := bil.toastMessage
 	toastSev := bil.toastSeverity
 	bil.mu.Unlock()
@@ -243,13 +257,33 @@ func (bil *BlendInfoLine) Draw(painter *core.Painter) {
 	}
 	leftStr := " " + modeIcon + title + " "
 
-	// --- Right side (date + clock with icons) ---
+	// segmentStyle resolves a segment's theme colour, falling back to def.
+	segmentStyle := func(seg Segment, def tcell.Color) color.DynamicStyle {
+		fg := def
+		if seg.Color != "" {
+			if c := tm.GetSemanticColor(seg.Color); c != tcell.ColorDefault {
+				fg = c
+			}
+		}
+		return color.DynamicStyle{FG: color.Solid(fg), BG: color.Solid(tcell.ColorDefault)}
+	}
+
+	// --- Right side (segments, then date + clock with icons) ---
 	calendarIcon := " \U000F00ED " // nf-md-calendar
 	clockIcon := " \U000F0954 "    // nf-md-clock-outline
 	rightStr := calendarIcon + date + clockIcon + clock + " "
 	rightWidth := utf8.RuneCountInString(rightStr)
+	segsWidth := 0
+	for _, seg := range rightSegs {
+		segsWidth += utf8.RuneCountInString(seg.Text) + 2
+	}
+	if rightWidth+segsWidth > w/2 {
+		rightSegs = nil // the clock wins when space is short
+		segsWidth = 0
+	}
+	rightWidth += segsWidth
 
-	// Draw left text (stop before right-side zone).
+	// Draw left text and segments (stop before right-side zone).
 	col := x
 	limit := x + w
 	if rightWidth < w {
@@ -262,6 +296,16 @@ func (bil *BlendInfoLine) Draw(painter *core.Painter) {
 		painter.SetDynamicCellKeepBG(col, y, r, darkDS)
 		col++
 	}
+	for _, seg := range leftSegs {
+		ds := segmentStyle(seg, darkFG)
+		for _, r := range " " + seg.Text + " " {
+			if col >= limit {
+				break
+			}
+			painter.SetDynamicCellKeepBG(col, y, r, ds)
+			col++
+		}
+	}
 
 	// Draw toast centered, overlaying the middle area between left and right.
 	if toastActive {
@@ -289,6 +333,13 @@ func (bil *BlendInfoLine) Draw(painter *core.Painter) {
 	accentDS := color.DynamicStyle{FG: color.Solid(resolvedAccent), BG: color.Solid(tcell.ColorDefault)}
 	if rightWidth <= w {
 		col = x + w - rightWidth
+		for _, seg := range rightSegs {
+			ds := segmentStyle(seg, resolvedAccent)
+			for _, r := range " " + seg.Text + " " {
+				painter.SetDynamicCellKeepBG(col, y, r, ds)
+				col++
+			}
+		}
 		for _, r := range rightStr {
 			if col >= x+w {
 				break
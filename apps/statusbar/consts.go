@@ -15,4 +15,6 @@ const (
 	rightLineTabSeparator = '\uE0B9'
 	keyboardIcon          = " \uF11C "
 	ctrlIcon              = " \uF085 "
+	cwdIcon               = "\uF07C " // nf-fa-folder_open
+	gitIcon               = "\uE0A0 " // powerline branch
 )
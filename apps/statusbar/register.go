@@ -18,6 +18,9 @@ func init() {
 				Type:        registry.AppTypeBuiltIn,
 				Icon:        "bar",
 				Category:    "system",
+				ThemeSchema: registry.ThemeSchema{
+					"ui": {"accent.primary", "bg.base", "text.inverse", "action.success", "action.warning", "action.danger"},
+				},
 			}, func() interface{} {
 				return New()
 			}
@@ -0,0 +1,402 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/statusbar/segments.go
+// Summary: Configurable info line segments: cwd, git, command status and
+// shell snippets.
+// Usage: The "statusbar" section of the statusbar app config lists the
+//   segments for each side ("left": "cwd git", "right": "status"); custom
+//   segments are defined under "statusbar.segments" by name:
+//     {"load": {"command": "cut -d' ' -f1 /proc/loadavg",
+//               "interval_seconds": 5, "timeout_ms": 500,
+//               "color": "text.secondary"}}
+// Notes: cwd and command status come from the focused pane's shell
+//   integration (OSC 7 and OSC 133). git and custom segments run in the
+//   background, in that cwd, with a timeout; their output is cached per cwd
+//   and refreshed on their interval, so a slow command never stalls the bar.
+
+package statusbar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/framegrace/texelation/config"
+	"github.com/framegrace/texelation/texel"
+)
+
+const (
+	defaultGitInterval     = 5 * time.Second
+	defaultCommandInterval = 10 * time.Second
+	defaultCommandTimeout  = time.Second
+	maxCwdWidth            = 32
+)
+
+// Segment is one piece of text on the info line.
+type Segment struct {
+	Text string
+	// Color is a semantic theme colour ("action.success"); empty uses the
+	// side's default.
+	Color string
+}
+
+// commandSegment is a user-defined shell snippet.
+type commandSegment struct {
+	command  string
+	interval time.Duration
+	timeout  time.Duration
+	color    string
+}
+
+// segmentConfig is the parsed segment configuration.
+type segmentConfig struct {
+	left, right []string
+	gitInterval time.Duration
+	gitTimeout  time.Duration
+	commands    map[string]commandSegment
+}
+
+// loadSegmentConfig reads the segment layout from the statusbar app config.
+func loadSegmentConfig(cfg config.Config) segmentConfig {
+	sc := segmentConfig{
+		left:        strings.Fields(cfg.GetString("statusbar", "left", "cwd git")),
+		right:       strings.Fields(cfg.GetString("statusbar", "right", "status")),
+		gitInterval: secondsOr(cfg.GetInt("statusbar", "git_interval_seconds", 0), defaultGitInterval),
+		gitTimeout:  millisOr(cfg.GetInt("statusbar", "git_timeout_ms", 0), defaultCommandTimeout),
+		commands:    make(map[string]commandSegment),
+	}
+	for name, raw := range cfg.Section("statusbar.segments") {
+		var def map[string]interface{}
+		switch v := raw.(type) {
+		case map[string]interface{}:
+			def = v
+		case config.Section:
+			def = v
+		default:
+			log.Printf("StatusBar: segment %q is not an object", name)
+			continue
+		}
+		seg := config.Config{"s": def}
+		command := seg.GetString("s", "command", "")
+		if command == "" {
+			log.Printf("StatusBar: segment %q has no command", name)
+			continue
+		}
+		sc.commands[name] = commandSegment{
+			command:  command,
+			interval: secondsOr(seg.GetInt("s", "interval_seconds", 0), defaultCommandInterval),
+			timeout:  millisOr(seg.GetInt("s", "timeout_ms", 0), defaultCommandTimeout),
+			color:    seg.GetString("s", "color", ""),
+		}
+	}
+	return sc
+}
+
+func secondsOr(n int, def time.Duration) time.Duration {
+	if n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return def
+}
+
+func millisOr(n int, def time.Duration) time.Duration {
+	if n > 0 {
+		return time.Duration(n) * time.Millisecond
+	}
+	return def
+}
+
+// runFunc runs a command in dir and returns its standard output.
+type runFunc func(ctx context.Context, dir, name string, args ...string) (string, error)
+
+// execWaitDelay bounds how long execRun waits for the output pipe to close
+// after the command is killed.
+const execWaitDelay = 100 * time.Millisecond
+
+// execRun runs the command in its own process group and kills the whole
+// group when ctx ends, so a snippet like "sleep 5 | cat" cannot keep the
+// output pipe open past its timeout.
+func execRun(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = execWaitDelay
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		cmd.Dir = dir
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// cachedOutput is the last result of a background segment for one cwd.
+type cachedOutput struct {
+	text     string
+	fetched  time.Time
+	inFlight bool
+}
+
+// segmentSet renders the configured segments and keeps the background ones
+// fresh. onChange is called, without locks held, when the text may differ.
+type segmentSet struct {
+	mu       sync.Mutex
+	cfg      segmentConfig
+	shell    texel.ShellStatus
+	cache    map[string]*cachedOutput
+	run      runFunc
+	onChange func()
+	home     string
+}
+
+func newSegmentSet(cfg segmentConfig, onChange func()) *segmentSet {
+	home, _ := os.UserHomeDir()
+	return &segmentSet{
+		cfg:      cfg,
+		cache:    make(map[string]*cachedOutput),
+		run:      execRun,
+		onChange: onChange,
+		home:     home,
+	}
+}
+
+// SetShell records the focused pane's shell status. A new cwd or a command
+// that just ended refreshes git at once, since either may change it.
+func (s *segmentSet) SetShell(status texel.ShellStatus) {
+	s.mu.Lock()
+	prev := s.shell
+	s.shell = status
+	if status.Cwd != prev.Cwd || (prev.Running && !status.Running) {
+		if c := s.cache["git\x00"+status.Cwd]; c != nil {
+			c.fetched = time.Time{}
+		}
+	}
+	s.mu.Unlock()
+	s.Tick(time.Now())
+	if status != prev {
+		s.changed()
+	}
+}
+
+// Tick starts fetches that are due. A running command's elapsed time also
+// changes every tick.
+func (s *segmentSet) Tick(now time.Time) {
+	s.mu.Lock()
+	cwd := s.shell.Cwd
+	running := s.shell.Running
+	for _, name := range s.names() {
+		switch name {
+		case "git":
+			if cwd != "" {
+				s.fetchLocked("git\x00"+cwd, now, s.cfg.gitInterval, s.cfg.gitTimeout, cwd, s.gitStatus)
+			}
+		default:
+			cs, ok := s.cfg.commands[name]
+			if !ok {
+				continue
+			}
+			s.fetchLocked(name+"\x00"+cwd, now, cs.interval, cs.timeout, cwd, func(ctx context.Context, dir string) (string, error) {
+				out, err := s.run(ctx, dir, "sh", "-c", cs.command)
+				return firstLine(out), err
+			})
+		}
+	}
+	s.mu.Unlock()
+	if running && s.shows("status") {
+		s.changed()
+	}
+}
+
+func (s *segmentSet) names() []string {
+	return append(append([]string(nil), s.cfg.left...), s.cfg.right...)
+}
+
+func (s *segmentSet) shows(name string) bool {
+	for _, n := range s.names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchLocked refreshes key in the background when its cached value is
+// older than interval. Caller holds s.mu.
+func (s *segmentSet) fetchLocked(key string, now time.Time, interval, timeout time.Duration, dir string, fetch func(ctx context.Context, dir string) (string, error)) {
+	c := s.cache[key]
+	if c == nil {
+		c = &cachedOutput{}
+		s.cache[key] = c
+	}
+	if c.inFlight || now.Sub(c.fetched) < interval {
+		return
+	}
+	c.inFlight = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		text, err := fetch(ctx, dir)
+		cancel()
+		if err != nil {
+			text = ""
+		}
+		s.mu.Lock()
+		changed := text != c.text
+		c.text = text
+		c.fetched = time.Now()
+		c.inFlight = false
+		s.mu.Unlock()
+		if changed {
+			s.changed()
+		}
+	}()
+}
+
+// gitStatus returns the branch of dir's repository, with "*" when the
+// work tree is dirty, or an error outside a repository. It never takes
+// index.lock, so it cannot race the user's own git commands, and it does
+// not start the repository's fsmonitor hook.
+func (s *segmentSet) gitStatus(ctx context.Context, dir string) (string, error) {
+	out, err := s.run(ctx, dir, "git", "--no-optional-locks", "-c", "core.fsmonitor=false",
+		"-C", dir, "status", "--porcelain=v1", "--branch")
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	branch := parseGitBranch(lines[0])
+	if branch == "" {
+		return "", fmt.Errorf("no branch in %q", lines[0])
+	}
+	if len(lines) > 1 {
+		branch += "*"
+	}
+	return branch, nil
+}
+
+// parseGitBranch extracts the branch from a porcelain "## ..." header.
+func parseGitBranch(header string) string {
+	header, ok := strings.CutPrefix(header, "## ")
+	if !ok {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(header, "No commits yet on "); ok {
+		return rest
+	}
+	if strings.HasPrefix(header, "HEAD (no branch)") {
+		return "HEAD"
+	}
+	branch, _, _ := strings.Cut(header, "...")
+	branch, _, _ = strings.Cut(branch, " ")
+	return branch
+}
+
+func (s *segmentSet) changed() {
+	if s.onChange != nil {
+		s.onChange()
+	}
+}
+
+// Render returns the current left and right segments; empty ones are
+// left out.
+func (s *segmentSet) Render(now time.Time) (left, right []Segment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renderLocked(s.cfg.left, now), s.renderLocked(s.cfg.right, now)
+}
+
+func (s *segmentSet) renderLocked(names []string, now time.Time) []Segment {
+	var out []Segment
+	for _, name := range names {
+		var seg Segment
+		switch name {
+		case "cwd":
+			if s.shell.Cwd != "" {
+				seg = Segment{Text: cwdIcon + shortenPath(s.shell.Cwd, s.home, maxCwdWidth)}
+			}
+		case "git":
+			if c := s.cache["git\x00"+s.shell.Cwd]; c != nil && c.text != "" {
+				seg = Segment{Text: gitIcon + c.text, Color: "action.success"}
+				if strings.HasSuffix(c.text, "*") {
+					seg.Color = "action.warning"
+				}
+			}
+		case "status":
+			seg = commandStatus(s.shell, now)
+		default:
+			if cs, ok := s.cfg.commands[name]; ok {
+				if c := s.cache[name+"\x00"+s.shell.Cwd]; c != nil && c.text != "" {
+					seg = Segment{Text: c.text, Color: cs.color}
+				}
+			}
+		}
+		if seg.Text != "" {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+// commandStatus shows the running command's elapsed time, or the last
+// command's exit code and duration.
+func commandStatus(sh texel.ShellStatus, now time.Time) Segment {
+	switch {
+	case sh.Running && !sh.Started.IsZero():
+		return Segment{Text: "⏵ " + formatDuration(now.Sub(sh.Started)), Color: "accent.primary"}
+	case sh.Finished && sh.ExitCode == 0:
+		return Segment{Text: "✓ " + formatDuration(sh.Duration), Color: "action.success"}
+	case sh.Finished:
+		return Segment{Text: fmt.Sprintf("✗ %d %s", sh.ExitCode, formatDuration(sh.Duration)), Color: "action.danger"}
+	}
+	return Segment{}
+}
+
+// formatDuration renders d compactly: 850ms, 2.3s, 1m05s, 2h03m.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// shortenPath abbreviates home to "~" and keeps the last directories when
+// the path is longer than max runes.
+func shortenPath(path, home string, max int) string {
+	if home != "" && (path == home || strings.HasPrefix(path, home+"/")) {
+		path = "~" + path[len(home):]
+	}
+	if len([]rune(path)) <= max {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		short := "…/" + filepath.Join(parts[i:]...)
+		if len([]rune(short)) <= max {
+			return short
+		}
+	}
+	r := []rune(parts[len(parts)-1])
+	if len(r) > max-1 {
+		r = r[len(r)-max+1:]
+	}
+	return "…" + string(r)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,186 @@
+package statusbar
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/config"
+	"github.com/framegrace/texelation/texel"
+)
+
+func TestParseGitBranch(t *testing.T) {
+	tests := map[string]string{
+		"## main...origin/main [ahead 1]": "main",
+		"## feature/x":                    "feature/x",
+		"## No commits yet on trunk":      "trunk",
+		"## HEAD (no branch)":             "HEAD",
+		"fatal: not a git repository":     "",
+	}
+	for header, want := range tests {
+		if got := parseGitBranch(header); got != want {
+			t.Errorf("parseGitBranch(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestGitStatusTakesNoLocksAndSkipsFsmonitor(t *testing.T) {
+	set := newSegmentSet(segmentConfig{}, nil)
+	var got []string
+	set.run = func(ctx context.Context, dir, name string, args ...string) (string, error) {
+		got = args
+		return "## main\n", nil
+	}
+	if branch, err := set.gitStatus(context.Background(), "/repo"); err != nil || branch != "main" {
+		t.Fatalf("gitStatus = %q, %v", branch, err)
+	}
+	if !slices.Contains(got, "--no-optional-locks") || !slices.Contains(got, "core.fsmonitor=false") {
+		t.Errorf("git args = %q, want --no-optional-locks and core.fsmonitor=false", got)
+	}
+}
+
+func TestShortenPathAndDuration(t *testing.T) {
+	if got := shortenPath("/home/u/src/app", "/home/u", 32); got != "~/src/app" {
+		t.Errorf("home path = %q", got)
+	}
+	if got := shortenPath("/srv/very/long/path/to/some/project", "", 16); got != "…/some/project" {
+		t.Errorf("long path = %q", got)
+	}
+	durations := map[time.Duration]string{
+		850 * time.Millisecond:                      "850ms",
+		2300 * time.Millisecond:                     "2.3s",
+		65 * time.Second:                            "1m05s",
+		2*time.Hour + 3*time.Minute + 9*time.Second: "2h03m",
+	}
+	for d, want := range durations {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestLoadSegmentConfig(t *testing.T) {
+	sc := loadSegmentConfig(config.Config{
+		"statusbar": map[string]interface{}{"left": "cwd load", "right": ""},
+		"statusbar.segments": map[string]interface{}{
+			"load":   map[string]interface{}{"command": "uptime", "interval_seconds": float64(3), "color": "text.muted"},
+			"broken": map[string]interface{}{"interval_seconds": float64(3)},
+		},
+	})
+	if len(sc.left) != 2 || len(sc.right) != 0 {
+		t.Fatalf("sides = %v / %v", sc.left, sc.right)
+	}
+	load, ok := sc.commands["load"]
+	if !ok || load.interval != 3*time.Second || load.timeout != defaultCommandTimeout || load.color != "text.muted" {
+		t.Fatalf("load segment = %+v", load)
+	}
+	if _, ok := sc.commands["broken"]; ok {
+		t.Error("segment without a command should be skipped")
+	}
+}
+
+func TestSegmentSetRendersShellGitAndCommands(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	changed := make(chan struct{}, 16)
+	set := newSegmentSet(segmentConfig{
+		left:        []string{"cwd", "git"},
+		right:       []string{"load", "status"},
+		gitInterval: time.Hour,
+		gitTimeout:  time.Second,
+		commands:    map[string]commandSegment{"load": {command: "uptime", interval: time.Hour, timeout: 50 * time.Millisecond}},
+	}, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	set.home = "/home/u"
+	dirty := false
+	set.run = func(ctx context.Context, dir, name string, args ...string) (string, error) {
+		mu.Lock()
+		calls = append(calls, dir+" "+name)
+		isDirty := dirty
+		mu.Unlock()
+		switch {
+		case name == "git" && dir == "/tmp":
+			return "", errors.New("not a repository")
+		case name == "git" && isDirty:
+			return "## main...origin/main\n M file.go\n", nil
+		case name == "git":
+			return "## main...origin/main\n", nil
+		}
+		if dir == "/tmp" {
+			<-ctx.Done() // a hung command is cut off by its timeout
+			return "", ctx.Err()
+		}
+		return "0.42\n", nil
+	}
+	waitFor := func(cond func(left, right []Segment) bool) (left, right []Segment) {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for {
+			left, right = set.Render(time.Now())
+			if cond(left, right) {
+				return left, right
+			}
+			select {
+			case <-changed:
+			case <-time.After(10 * time.Millisecond):
+			case <-deadline:
+				t.Fatalf("segments never settled: %+v / %+v", left, right)
+			}
+		}
+	}
+
+	set.SetShell(texel.ShellStatus{Cwd: "/home/u/src"})
+	left, right := waitFor(func(l, r []Segment) bool { return len(l) == 2 && len(r) == 1 })
+	if left[0].Text != cwdIcon+"~/src" || left[1].Text != gitIcon+"main" || left[1].Color != "action.success" {
+		t.Fatalf("left = %+v", left)
+	}
+	if right[0].Text != "0.42" {
+		t.Fatalf("right = %+v", right)
+	}
+
+	// A finished command refreshes git even inside its interval.
+	mu.Lock()
+	dirty = true
+	mu.Unlock()
+	start := time.Now().Add(-2 * time.Second)
+	set.SetShell(texel.ShellStatus{Cwd: "/home/u/src", Command: "make", Running: true, Started: start})
+	if _, right := set.Render(start.Add(3 * time.Second)); right[len(right)-1].Text != "⏵ 3.0s" {
+		t.Fatalf("running status = %+v", right)
+	}
+	set.SetShell(texel.ShellStatus{Cwd: "/home/u/src", Command: "make", Started: start, Finished: true, ExitCode: 2, Duration: 2 * time.Second})
+	left, right = waitFor(func(l, r []Segment) bool { return len(l) == 2 && l[1].Text == gitIcon+"main*" })
+	if left[1].Color != "action.warning" {
+		t.Fatalf("dirty git colour = %q", left[1].Color)
+	}
+	if st := right[len(right)-1]; st.Text != "✗ 2 2.0s" || st.Color != "action.danger" {
+		t.Fatalf("failed status = %+v", st)
+	}
+
+	// Outside a repository git disappears; a command that hangs times out
+	// and is left out rather than blocking.
+	set.SetShell(texel.ShellStatus{Cwd: "/tmp"})
+	waitFor(func(l, r []Segment) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(l) == 1 && len(r) == 0 && len(calls) == 5
+	})
+}
+
+func TestExecRunKillsProcessGroupOnTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := execRun(ctx, "", "/bin/sh", "-c", "sleep 5 | cat"); err == nil {
+		t.Fatal("expected the timed-out command to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("execRun returned after %v; the pipeline outlived its timeout", elapsed)
+	}
+}
@@ -9,9 +9,11 @@ package statusbar
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/framegrace/texelation/config"
 	"github.com/framegrace/texelation/texel"
 	"github.com/framegrace/texelui/adapter"
 	dyncolor "github.com/framegrace/texelui/color"
@@ -28,6 +30,7 @@ type StatusBarApp struct {
 	ui        *core.UIManager
 	tabBar    *primitives.TabBar
 	blendLine *BlendInfoLine
+	segments  *segmentSet
 
 	mu         sync.RWMutex
 	workspaces []texel.WorkspaceInfo
@@ -86,6 +89,7 @@ func New() *StatusBarApp {
 		activeID:   1,
 		stopClock:  make(chan struct{}),
 	}
+	sb.segments = newSegmentSet(loadSegmentConfig(config.App("statusbar")), sb.updateSegments)
 
 	// Wire tab change -> workspace switch.
 	tabBar.OnChange = func(idx int) {
@@ -218,11 +222,16 @@ func (sb *StatusBarApp) OnEvent(event texel.Event) {
 	case texel.EventActivePaneChanged:
 		if p, ok := event.Payload.(texel.ActivePaneChangedPayload); ok {
 			sb.blendLine.SetTitle(p.ActiveTitle)
+			sb.segments.SetShell(p.Shell)
 		}
 	case texel.EventPerformanceUpdate:
 		// Intentionally ignored — FPS display caused 60fps buffer deltas
 		// to the client, starving keyboard input. Will be reimplemented
 		// client-side if needed.
+	case texel.EventPaneMoved:
+		if p, ok := event.Payload.(texel.PaneMovedPayload); ok {
+			sb.flashTab(p.To)
+		}
 	case texel.EventToast:
 		if p, ok := event.Payload.(texel.ToastPayload); ok {
 			sb.blendLine.ShowToast(p.Message, p.Severity, p.Duration)
@@ -286,6 +295,7 @@ func (sb *StatusBarApp) handleWorkspacesChanged(p texel.WorkspacesChangedPayload
 		if label == "" {
 			label = fmt.Sprintf("%d", ws.ID)
 		}
+		label += alertBadge(ws.Alert)
 		tabs = append(tabs, primitives.TabItem{
 			Label: label,
 			Color: dyncolor.Solid(darkenColor(ws.Color, 0.5)),
@@ -309,6 +319,61 @@ func (sb *StatusBarApp) handleWorkspacesChanged(p texel.WorkspacesChangedPayload
 	sb.refresh()
 }
 
+// alertBadge marks a tab whose workspace has monitor alerts, using tmux's
+// window flags: '#' for activity and '~' for silence.
+func alertBadge(alert texel.PaneAlert) string {
+	badge := ""
+	if alert&texel.AlertActivity != 0 {
+		badge += "#"
+	}
+	if alert&texel.AlertSilence != 0 {
+		badge += "~"
+	}
+	if badge != "" {
+		badge = " " + badge
+	}
+	return badge
+}
+
+// tabFlashDuration is how long a tab pulses after a pane moves into it.
+const tabFlashDuration = 1500 * time.Millisecond
+
+// flashTab pulses the tab of workspace wsID so the destination of a moved
+// pane is visible, then settles back to its normal colour.
+func (sb *StatusBarApp) flashTab(wsID int) {
+	sb.mu.Lock()
+	idx := slices.Index(sb.tabOrder, wsID)
+	color, ok := sb.workspaceColor(wsID)
+	if idx < 0 || idx >= len(sb.tabBar.Tabs) || !ok {
+		sb.mu.Unlock()
+		return
+	}
+	sb.tabBar.Tabs[idx].Color = dyncolor.Pulse(color, 0.5, 1.0, 8)
+	sb.mu.Unlock()
+	sb.refresh()
+
+	time.AfterFunc(tabFlashDuration, func() {
+		sb.mu.Lock()
+		idx := slices.Index(sb.tabOrder, wsID)
+		color, ok := sb.workspaceColor(wsID)
+		if idx >= 0 && idx < len(sb.tabBar.Tabs) && ok {
+			sb.tabBar.Tabs[idx].Color = dyncolor.Solid(darkenColor(color, 0.5))
+		}
+		sb.mu.Unlock()
+		sb.refresh()
+	})
+}
+
+// workspaceColor returns the accent colour of workspace id. Callers hold sb.mu.
+func (sb *StatusBarApp) workspaceColor(id int) (tcell.Color, bool) {
+	for _, ws := range sb.workspaces {
+		if ws.ID == id {
+			return ws.Color, true
+		}
+	}
+	return 0, false
+}
+
 // handleWorkspaceSwitched updates the active tab.
 func (sb *StatusBarApp) handleWorkspaceSwitched(p texel.WorkspaceSwitchedPayload) {
 	sb.mu.Lock()
@@ -402,11 +467,19 @@ func (sb *StatusBarApp) clockLoop() {
 			return
 		case t := <-ticker.C:
 			sb.blendLine.SetClock(t.Format("Mon 02 Jan"), t.Format("15:04:05"))
+			sb.segments.Tick(t)
 			sb.refresh()
 		}
 	}
 }
 
+// updateSegments redraws the configured segments; the segment set calls it
+// when their text may have changed.
+func (sb *StatusBarApp) updateSegments() {
+	sb.blendLine.SetSegments(sb.segments.Render(time.Now()))
+	sb.refresh()
+}
+
 // --- TabModeHandler interface ---
 
 // StartNewTab inserts a new tab after the current one and opens the editor.
@@ -96,3 +96,27 @@ func TestStatusBar_Title(t *testing.T) {
 		t.Errorf("expected title %q, got %q", "Status Bar", title)
 	}
 }
+
+func TestStatusBar_AlertBadges(t *testing.T) {
+	sb := New()
+	sb.Resize(80, 2)
+
+	sb.OnEvent(texel.Event{
+		Type: texel.EventWorkspacesChanged,
+		Payload: texel.WorkspacesChangedPayload{
+			Workspaces: []texel.WorkspaceInfo{
+				{ID: 1, Name: "main", Color: tcell.ColorGreen},
+				{ID: 2, Name: "build", Color: tcell.ColorBlue, Alert: texel.AlertActivity},
+				{ID: 3, Name: "tail", Color: tcell.ColorRed, Alert: texel.AlertActivity | texel.AlertSilence},
+			},
+			ActiveID: 1,
+		},
+	})
+
+	want := []string{"main", "build #", "tail #~"}
+	for i, tab := range sb.tabBar.Tabs {
+		if tab.Label != want[i] {
+			t.Fatalf("tab %d label = %q, want %q", i, tab.Label, want[i])
+		}
+	}
+}
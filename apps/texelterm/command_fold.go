@@ -0,0 +1,194 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/command_fold.go
+// Summary: Collapsible command output. A finished command's output can be
+// collapsed into one summary line showing the command, line count, exit
+// status and duration.
+// Usage: The OSC 133 handlers record each command's output range; the
+//   texelterm.collapse action (unbound by default; run it from the
+//   command palette) collapses or expands the newest finished command in
+//   view, and clicking a summary line expands it.
+//   Search hits inside a collapsed block expand it (see
+//   VTerm.ScrollToGlobalLine).
+// Notes: Collapsing is a projection in the sparse ViewWindow. The
+//   scrollback keeps every line, so search, copy and persistence still
+//   see the full output. Finished commands are also appended to the
+//   pane's command log so `texelterm export --command` can find them.
+
+package texelterm
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxCommandBlocks bounds how many finished commands can be collapsed.
+const maxCommandBlocks = 256
+
+// commandFolds records command output ranges. It is only touched under
+// a.mu; the OSC 133 handlers run during Parse, which holds it.
+type commandFolds struct {
+	blocks  []parser.CommandRecord // finished commands, oldest first
+	running *parser.CommandRecord  // the running command, if any
+	logPath string                 // command log, empty without persistence
+}
+
+// beginCommandBlockLocked records the start of a command's output. Caller
+// holds a.mu.
+func (a *TexelTerm) beginCommandBlockLocked(cmd string) {
+	a.commands.running = &parser.CommandRecord{
+		Command: cmd,
+		Prompt:  a.vterm.PromptStartGlobalLine,
+		Start:   a.vterm.CommandStartGlobalLine,
+		Started: time.Now(),
+	}
+}
+
+// endCommandBlockLocked records a finished command's output range. The
+// output ends on the line before the cursor, or on the cursor's line when
+// the last line had no trailing newline. Caller holds a.mu.
+func (a *TexelTerm) endCommandBlockLocked(exitCode int) {
+	running := a.commands.running
+	a.commands.running = nil
+	if running == nil || running.Start < 0 || a.vterm == nil || a.vterm.InAltScreen() {
+		return
+	}
+	b := *running
+	gi, col := a.vterm.CursorGlobalIdx()
+	b.End = gi - 1
+	if col > 0 {
+		b.End = gi
+	}
+	if b.End < b.Start {
+		return // no output
+	}
+	b.ExitCode = exitCode
+	b.Duration = time.Since(b.Started)
+	a.commands.blocks = append(a.commands.blocks, b)
+	if n := len(a.commands.blocks); n > maxCommandBlocks {
+		a.commands.blocks = append(a.commands.blocks[:0:0], a.commands.blocks[n-maxCommandBlocks:]...)
+	}
+	if a.commands.logPath != "" {
+		if err := parser.AppendCommandRecord(a.commands.logPath, b); err != nil {
+			log.Printf("[TEXELTERM] Failed to log command: %v", err)
+		}
+	}
+}
+
+// commandSummary renders the line shown in place of b's output.
+func commandSummary(b parser.CommandRecord) []parser.Cell {
+	lines := b.End - b.Start + 1
+	noun := "lines"
+	if lines == 1 {
+		noun = "line"
+	}
+	var cells []parser.Cell
+	add := func(s string, fg parser.Color, attr parser.Attribute) {
+		for _, r := range s {
+			cells = append(cells, parser.Cell{Rune: r, FG: fg, BG: parser.DefaultBG, Attr: attr})
+		}
+	}
+	add("▶ ", parser.DefaultFG, parser.AttrBold)
+	add(fmt.Sprintf("%s  %d %s · ", b.Command, lines, noun), parser.DefaultFG, parser.AttrDim)
+	exit := fmt.Sprintf("exit %d", b.ExitCode)
+	if b.ExitCode != 0 {
+		add(exit, parser.Color{Mode: parser.ColorModeStandard, Value: 1}, 0)
+	} else {
+		add(exit, parser.DefaultFG, parser.AttrDim)
+	}
+	add(" · "+formatCommandDuration(b.Duration), parser.DefaultFG, parser.AttrDim)
+	return cells
+}
+
+// formatCommandDuration formats d for a summary line.
+func formatCommandDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// toggleCommandInView collapses the newest finished command in view, or
+// expands it if it is already collapsed.
+func (a *TexelTerm) toggleCommandInView() {
+	a.mu.Lock()
+	b, ok := a.commandBlockInViewLocked()
+	if ok {
+		if _, _, collapsed := a.vterm.CollapsedAt(b.Start); collapsed {
+			a.vterm.ExpandLines(b.Start)
+		} else {
+			a.vterm.CollapseLines(b.Start, b.End, commandSummary(b))
+		}
+	}
+	a.mu.Unlock()
+	if !ok {
+		if a.statusBar != nil {
+			a.statusBar.ShowError("No finished command in view")
+		}
+		return
+	}
+	a.requestRefresh()
+}
+
+// commandBlockInViewLocked finds the newest finished command whose output
+// overlaps the rendered rows, falling back to the last finished command.
+// Caller holds a.mu.
+func (a *TexelTerm) commandBlockInViewLocked() (parser.CommandRecord, bool) {
+	blocks := a.commands.blocks
+	if a.vterm == nil || a.vterm.InAltScreen() || len(blocks) == 0 {
+		return parser.CommandRecord{}, false
+	}
+	lo, hi := int64(-1), int64(-1)
+	for _, gi := range a.lastRowGlobalIdx {
+		if gi < 0 {
+			continue
+		}
+		if lo < 0 || gi < lo {
+			lo = gi
+		}
+		hi = max(hi, gi)
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if b := blocks[i]; lo >= 0 && b.End >= lo && b.Start <= hi {
+			return b, true
+		}
+	}
+	return blocks[len(blocks)-1], true
+}
+
+// handleCommandSummaryClick expands the collapsed command whose summary
+// line gets a left-button press, and reports whether it did.
+func (a *TexelTerm) handleCommandSummaryClick(y int, press bool, mods tcell.ModMask) bool {
+	if !press || mods != tcell.ModNone {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.vterm == nil || a.vterm.InAltScreen() || y < 0 {
+		return false
+	}
+	if y >= len(a.lastRowGlobalIdx) {
+		return false
+	}
+	gi := a.lastRowGlobalIdx[y]
+	start, end, ok := a.vterm.CollapsedAt(gi)
+	if !ok || start != gi {
+		return false
+	}
+	// JSON/YAML folds collapse the same way; leave those to fold_view.go.
+	for _, b := range a.commands.blocks {
+		if b.Start == start && b.End == end {
+			return a.vterm.ExpandLines(gi)
+		}
+	}
+	return false
+}
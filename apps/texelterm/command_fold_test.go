@@ -0,0 +1,72 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/command_fold_test.go
+// Summary: Exercises collapsing command output into a summary line.
+
+package texelterm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func renderedRows(a *TexelTerm) []string {
+	var rows []string
+	for _, cells := range a.Render() {
+		var b strings.Builder
+		for _, c := range cells {
+			b.WriteRune(max(c.Ch, ' '))
+		}
+		rows = append(rows, strings.TrimRight(b.String(), " "))
+	}
+	return rows
+}
+
+func TestCommandOutputCollapse(t *testing.T) {
+	tt := NewTestTerm(40, 8)
+	a := tt.term
+	a.vterm.OnCommandStart = a.beginCommandBlockLocked
+	a.vterm.OnCommandEnd = a.endCommandBlockLocked
+
+	tt.Write([]byte("$ seq 5\r\n\x1b]133;C;seq 5\x071\r\n2\r\n3\r\n4\r\n5\r\n\x1b]133;D;1\x07$ "))
+	if len(a.commands.blocks) != 1 {
+		t.Fatalf("blocks = %+v", a.commands.blocks)
+	}
+	b := a.commands.blocks[0]
+	if b.End-b.Start != 4 || b.ExitCode != 1 {
+		t.Fatalf("block = %+v", b)
+	}
+
+	renderedRows(a)
+	a.toggleCommandInView()
+	rows := renderedRows(a)
+	y := -1
+	for i, row := range rows {
+		if strings.HasPrefix(row, "▶ seq 5  5 lines · exit 1 · ") {
+			y = i
+		}
+	}
+	if y != 1 || rows[0] != "$ seq 5" || rows[2] != "$" {
+		t.Fatalf("collapsed rows = %q", rows)
+	}
+
+	// Clicking the summary expands the output again.
+	a.HandleMouse(tcell.NewEventMouse(3, y, tcell.Button1, tcell.ModNone))
+	a.HandleMouse(tcell.NewEventMouse(3, y, tcell.ButtonNone, tcell.ModNone))
+	if rows = renderedRows(a); rows[1] != "1" || rows[5] != "5" {
+		t.Fatalf("expanded rows = %q", rows)
+	}
+
+	// A search hit inside collapsed output expands it.
+	a.toggleCommandInView()
+	renderedRows(a)
+	if !a.vterm.ScrollToGlobalLine(b.Start + 2) {
+		t.Fatal("ScrollToGlobalLine failed")
+	}
+	if _, _, ok := a.vterm.CollapsedAt(b.Start); ok {
+		t.Error("scrolling to a hidden line should expand its block")
+	}
+}
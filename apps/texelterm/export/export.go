@@ -0,0 +1,274 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package export writes scrollback lines out of the terminal: as a
+// standalone HTML page styled from the pane's palette, as ANSI-escaped
+// text for `less -R`, or as plain text.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatANSI Format = "ansi"
+	FormatText Format = "text"
+)
+
+// ParseFormat parses a format name; "txt" is accepted for text.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatHTML, FormatANSI, FormatText:
+		return f, nil
+	case "txt":
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want html, ansi or text)", s)
+}
+
+// Ext returns the file extension for f.
+func (f Format) Ext() string {
+	switch f {
+	case FormatHTML:
+		return "html"
+	case FormatANSI:
+		return "ans"
+	default:
+		return "txt"
+	}
+}
+
+// Palette resolves indexed colors like the terminal does: slots 0-255
+// are the indexed colors, 256 the default foreground and 257 the default
+// background.
+type Palette [258]tcell.Color
+
+// Write writes lines to w in format f. Nil lines are gaps in the history
+// and come out empty. The palette is only used for HTML.
+func Write(w io.Writer, lines []*parser.LogicalLine, f Format, p *Palette) error {
+	bw := bufio.NewWriter(w)
+	switch f {
+	case FormatHTML:
+		writeHTML(bw, lines, p)
+	case FormatANSI:
+		for _, l := range lines {
+			writeANSILine(bw, lineCells(l))
+		}
+	default:
+		for _, l := range lines {
+			var sb strings.Builder
+			for _, c := range lineCells(l) {
+				sb.WriteRune(c.Rune)
+			}
+			bw.WriteString(strings.TrimRight(sb.String(), " "))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// lineCells returns the cells of l that start a character, with empty
+// cells as spaces. Trailing blank cells with no background are dropped.
+func lineCells(l *parser.LogicalLine) []parser.Cell {
+	if l == nil {
+		return nil
+	}
+	cells := make([]parser.Cell, 0, len(l.Cells))
+	for i, c := range l.Cells {
+		if i > 0 && l.Cells[i-1].Wide {
+			continue // second half of a wide character
+		}
+		if c.Rune == 0 {
+			c.Rune = ' '
+		}
+		cells = append(cells, c)
+	}
+	for n := len(cells); n > 0; n-- {
+		c := cells[n-1]
+		if c.Rune != ' ' || c.BG.Mode != parser.ColorModeDefault || c.Attr&(parser.AttrReverse|parser.AttrUnderline) != 0 {
+			return cells[:n]
+		}
+	}
+	return nil
+}
+
+// style is the part of a cell that affects how it is drawn.
+type style struct {
+	fg, bg parser.Color
+	attr   parser.Attribute
+}
+
+func cellStyle(c parser.Cell) style {
+	return style{c.FG, c.BG, c.Attr}
+}
+
+// runs splits cells into maximal runs of one style.
+func runs(cells []parser.Cell, fn func(s style, text string)) {
+	for i := 0; i < len(cells); {
+		s := cellStyle(cells[i])
+		var sb strings.Builder
+		j := i
+		for ; j < len(cells) && cellStyle(cells[j]) == s; j++ {
+			sb.WriteRune(cells[j].Rune)
+		}
+		fn(s, sb.String())
+		i = j
+	}
+}
+
+// writeANSILine writes one line with SGR sequences, resetting at its end.
+func writeANSILine(w *bufio.Writer, cells []parser.Cell) {
+	styled := false
+	runs(cells, func(s style, text string) {
+		if params := sgrParams(s); params != "" {
+			w.WriteString("\x1b[0;" + params + "m")
+			styled = true
+		} else if styled {
+			w.WriteString("\x1b[0m")
+			styled = false
+		}
+		w.WriteString(text)
+	})
+	if styled {
+		w.WriteString("\x1b[0m")
+	}
+	w.WriteByte('\n')
+}
+
+// sgrParams returns the SGR parameters selecting s, empty for the default
+// style.
+func sgrParams(s style) string {
+	var ps []string
+	for _, a := range []struct {
+		attr parser.Attribute
+		code string
+	}{
+		{parser.AttrBold, "1"},
+		{parser.AttrDim, "2"},
+		{parser.AttrItalic, "3"},
+		{parser.AttrUnderline, "4"},
+		{parser.AttrReverse, "7"},
+	} {
+		if s.attr&a.attr != 0 {
+			ps = append(ps, a.code)
+		}
+	}
+	if p := sgrColor(s.fg, 30, 90, 38); p != "" {
+		ps = append(ps, p)
+	}
+	if p := sgrColor(s.bg, 40, 100, 48); p != "" {
+		ps = append(ps, p)
+	}
+	return strings.Join(ps, ";")
+}
+
+// sgrColor returns the SGR parameters for c: base+n for the first eight
+// colors, bright+n-8 for the next eight, and the extended form otherwise.
+func sgrColor(c parser.Color, base, bright, extended int) string {
+	switch c.Mode {
+	case parser.ColorModeStandard:
+		if c.Value < 8 {
+			return strconv.Itoa(base + int(c.Value))
+		}
+		return strconv.Itoa(bright + int(c.Value) - 8)
+	case parser.ColorMode256:
+		return fmt.Sprintf("%d;5;%d", extended, c.Value)
+	case parser.ColorModeRGB:
+		return fmt.Sprintf("%d;2;%d;%d;%d", extended, c.R, c.G, c.B)
+	}
+	return ""
+}
+
+// writeHTML writes a standalone page holding lines in a <pre> block.
+func writeHTML(w *bufio.Writer, lines []*parser.LogicalLine, p *Palette) {
+	w.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>texelterm export</title>\n</head>\n<body style=\"margin:0\">\n")
+	w.WriteString(`<pre style="margin:0;padding:1em;font-family:monospace`)
+	if c := cssColor(p[257]); c != "" {
+		w.WriteString(";background-color:" + c)
+	}
+	if c := cssColor(p[256]); c != "" {
+		w.WriteString(";color:" + c)
+	}
+	w.WriteString(`">`)
+	for _, l := range lines {
+		runs(lineCells(l), func(s style, text string) {
+			text = html.EscapeString(text)
+			if css := p.css(s); css != "" {
+				text = `<span style="` + css + `">` + text + `</span>`
+			}
+			w.WriteString(text)
+		})
+		w.WriteByte('\n')
+	}
+	w.WriteString("</pre>\n</body>\n</html>\n")
+}
+
+// css returns the inline style drawing s, empty for the default style.
+// Dim and reverse are resolved to colors the way the terminal draws them.
+func (p *Palette) css(s style) string {
+	fg, bg := p.color(s.fg, 256), p.color(s.bg, 257)
+	if s.attr&parser.AttrDim != 0 && fg != tcell.ColorDefault {
+		r, g, b := fg.RGB()
+		fg = tcell.NewRGBColor(r*6/10, g*6/10, b*6/10)
+	}
+	if s.attr&parser.AttrReverse != 0 {
+		fg, bg = bg, fg
+	}
+	var decls []string
+	if fg != p[256] || s.attr&parser.AttrReverse != 0 {
+		if c := cssColor(fg); c != "" {
+			decls = append(decls, "color:"+c)
+		}
+	}
+	if bg != p[257] || s.attr&parser.AttrReverse != 0 {
+		if c := cssColor(bg); c != "" {
+			decls = append(decls, "background-color:"+c)
+		}
+	}
+	if s.attr&parser.AttrBold != 0 {
+		decls = append(decls, "font-weight:bold")
+	}
+	if s.attr&parser.AttrItalic != 0 {
+		decls = append(decls, "font-style:italic")
+	}
+	if s.attr&parser.AttrUnderline != 0 {
+		decls = append(decls, "text-decoration:underline")
+	}
+	return strings.Join(decls, ";")
+}
+
+// color resolves c against the palette; def is the slot of the default.
+func (p *Palette) color(c parser.Color, def int) tcell.Color {
+	switch c.Mode {
+	case parser.ColorModeStandard, parser.ColorMode256:
+		return p[c.Value]
+	case parser.ColorModeRGB:
+		return tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+	default:
+		return p[def]
+	}
+}
+
+// cssColor formats c as #rrggbb, or "" if it has no concrete value.
+func cssColor(c tcell.Color) string {
+	if !c.Valid() {
+		return ""
+	}
+	r, g, b := c.RGB()
+	if r < 0 {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
@@ -0,0 +1,144 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/gdamore/tcell/v2"
+)
+
+func line(parts ...any) *parser.LogicalLine {
+	var cells []parser.Cell
+	style := parser.Cell{FG: parser.DefaultFG, BG: parser.DefaultBG}
+	for _, p := range parts {
+		switch p := p.(type) {
+		case parser.Cell:
+			style = p
+		case string:
+			for _, r := range p {
+				c := style
+				c.Rune = r
+				cells = append(cells, c)
+			}
+		}
+	}
+	return parser.NewLogicalLineFromCells(cells)
+}
+
+var red = parser.Color{Mode: parser.ColorModeStandard, Value: 1}
+
+func export(t *testing.T, f Format, lines ...*parser.LogicalLine) string {
+	t.Helper()
+	var p Palette
+	p[1] = tcell.NewRGBColor(0xff, 0, 0)
+	p[256] = tcell.NewRGBColor(0xee, 0xee, 0xee)
+	p[257] = tcell.NewRGBColor(0x11, 0x11, 0x11)
+	var sb strings.Builder
+	if err := Write(&sb, lines, f, &p); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestWriteText(t *testing.T) {
+	wide := parser.NewLogicalLineFromCells([]parser.Cell{{Rune: '世', Wide: true}, {}, {Rune: 'x'}, {Rune: ' '}})
+	got := export(t, FormatText, line("a <b>  "), nil, wide)
+	if want := "a <b>\n\n世x\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+}
+
+func TestWriteANSI(t *testing.T) {
+	bold := parser.Cell{FG: red, BG: parser.DefaultBG, Attr: parser.AttrBold}
+	plain := parser.Cell{FG: parser.DefaultFG, BG: parser.DefaultBG}
+	rgb := parser.Cell{FG: parser.DefaultFG, BG: parser.Color{Mode: parser.ColorModeRGB, R: 1, G: 2, B: 3}}
+	got := export(t, FormatANSI, line(bold, "err", plain, " ok"), line(rgb, "bg"))
+	want := "\x1b[0;1;31merr\x1b[0m ok\n\x1b[0;48;2;1;2;3mbg\x1b[0m\n"
+	if got != want {
+		t.Errorf("ansi = %q, want %q", got, want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	errStyle := parser.Cell{FG: red, BG: parser.DefaultBG, Attr: parser.AttrBold}
+	inverse := parser.Cell{FG: parser.DefaultFG, BG: parser.DefaultBG, Attr: parser.AttrReverse}
+	got := export(t, FormatHTML, line("if a<b & c", errStyle, " fail"), line(inverse, "sel"))
+	for _, want := range []string{
+		"background-color:#111111;color:#eeeeee\">",
+		"if a&lt;b &amp; c<span style=\"color:#ff0000;font-weight:bold\"> fail</span>\n",
+		"<span style=\"color:#111111;background-color:#eeeeee\">sel</span>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("html lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+// fakeSource is a history of n lines written one minute apart from t0.
+type fakeSource struct {
+	n  int64
+	t0 time.Time
+}
+
+func (s fakeSource) LineCount() int64 { return s.n }
+
+func (s fakeSource) ReadLineRange(start, end int64) ([]*parser.LogicalLine, error) {
+	return make([]*parser.LogicalLine, end-start), nil
+}
+
+func (s fakeSource) FindLineAt(t time.Time) int64 {
+	if t.Before(s.t0) {
+		return -1
+	}
+	return min(int64(t.Sub(s.t0)/time.Minute), s.n-1)
+}
+
+func TestResolveBounds(t *testing.T) {
+	t0 := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	src := fakeSource{n: 100, t0: t0}
+	cmds := []parser.CommandRecord{
+		{Command: "make", Prompt: 10, Start: 11, End: 40},
+		{Command: "ls", Prompt: -1, Start: 50, End: 52},
+	}
+	bound := func(s string) Bound {
+		b, err := ParseBound(s, t0)
+		if err != nil {
+			t.Fatalf("ParseBound(%q): %v", s, err)
+		}
+		return b
+	}
+	for _, tc := range []struct {
+		from, to   string
+		start, end int64
+	}{
+		{"", "", 0, 100},
+		{"5", "line:9", 5, 10},
+		{"line:-10", "", 90, 100},
+		{"command:0", "command:0", 10, 41},
+		{"command:-1", "command:-1", 50, 53},
+		{"time:09:30", "time:09:32", 30, 33},
+		{"time:2026-03-04T09:10:00Z", "time:09:10:30", 10, 11},
+	} {
+		start, end, err := Resolve(src, cmds, bound(tc.from), bound(tc.to))
+		if err != nil || start != tc.start || end != tc.end {
+			t.Errorf("Resolve(%q, %q) = %d, %d, %v; want %d, %d", tc.from, tc.to, start, end, err, tc.start, tc.end)
+		}
+	}
+
+	if _, _, err := Resolve(src, cmds, bound("command:5"), Bound{}); err == nil {
+		t.Error("a missing command should be an error")
+	}
+	if _, _, err := Resolve(src, cmds, bound("50"), bound("10")); err == nil {
+		t.Error("an empty range should be an error")
+	}
+	for _, bad := range []string{"line:x", "time:noon", "page:1"} {
+		if _, err := ParseBound(bad, t0); err == nil {
+			t.Errorf("ParseBound(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+// Source is the history an export reads from; parser.HistoryReader
+// implements it.
+type Source interface {
+	LineCount() int64
+	ReadLineRange(start, end int64) ([]*parser.LogicalLine, error)
+	FindLineAt(t time.Time) int64
+}
+
+type boundKind int
+
+const (
+	boundNone boundKind = iota
+	boundLine
+	boundTime
+	boundCommand
+)
+
+// Bound is one end of an export range: a global line index, a time, or
+// a command from the pane's command log. The zero Bound is open.
+type Bound struct {
+	kind boundKind
+	n    int64 // line index or command number; negative counts from the end
+	t    time.Time
+}
+
+// ParseBound parses "line:N" (or just "N"), "time:T" or "command:N". A
+// negative N counts back from the end, so "command:-1" is the last
+// command. T is RFC 3339 or a clock time ("15:04", "15:04:05") on the
+// day of now. An empty string is an open bound. Both ends of a range
+// are inclusive: "--to line:9" exports line 9.
+func ParseBound(s string, now time.Time) (Bound, error) {
+	if s == "" {
+		return Bound{}, nil
+	}
+	kind, val, ok := strings.Cut(s, ":")
+	if !ok {
+		kind, val = "line", s
+	}
+	switch kind {
+	case "line", "command":
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return Bound{}, fmt.Errorf("bad %s number in %q", kind, s)
+		}
+		if kind == "line" {
+			return Bound{kind: boundLine, n: n}, nil
+		}
+		return Bound{kind: boundCommand, n: n}, nil
+	case "time":
+		if t, err := time.Parse(time.RFC3339, val); err == nil {
+			return Bound{kind: boundTime, t: t}, nil
+		}
+		for _, layout := range []string{"15:04:05", "15:04"} {
+			if t, err := time.ParseInLocation(layout, val, now.Location()); err == nil {
+				y, m, d := now.Date()
+				return Bound{kind: boundTime, t: time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location())}, nil
+			}
+		}
+		return Bound{}, fmt.Errorf("bad time in %q (want RFC 3339 or HH:MM[:SS])", s)
+	}
+	return Bound{}, fmt.Errorf("bad bound %q (want line:N, time:T or command:N)", s)
+}
+
+// CommandBound returns the bound selecting command n, as "command:n" does.
+func CommandBound(n int64) Bound {
+	return Bound{kind: boundCommand, n: n}
+}
+
+// Resolve turns from and to into the global line range [start, end) of
+// src. A command bound starts at the command's prompt and ends after its
+// last line of output; cmds is the pane's command log, oldest first.
+func Resolve(src Source, cmds []parser.CommandRecord, from, to Bound) (start, end int64, err error) {
+	count := src.LineCount()
+	if start, err = resolve(src, cmds, from, count, false); err != nil {
+		return 0, 0, err
+	}
+	if end, err = resolve(src, cmds, to, count, true); err != nil {
+		return 0, 0, err
+	}
+	start, end = max(start, 0), min(end, count)
+	if end <= start {
+		return 0, 0, fmt.Errorf("empty range: lines %d to %d", start, end)
+	}
+	return start, end, nil
+}
+
+// resolve returns the first line selected by b, or with isEnd the line
+// after the last one.
+func resolve(src Source, cmds []parser.CommandRecord, b Bound, count int64, isEnd bool) (int64, error) {
+	switch b.kind {
+	case boundLine:
+		gi := b.n
+		if gi < 0 {
+			gi += count
+		}
+		if isEnd {
+			gi++
+		}
+		return gi, nil
+	case boundTime:
+		if isEnd {
+			return src.FindLineAt(b.t) + 1, nil
+		}
+		return src.FindLineAt(b.t.Add(-time.Nanosecond)) + 1, nil
+	case boundCommand:
+		i := b.n
+		if i < 0 {
+			i += int64(len(cmds))
+		}
+		if i < 0 || i >= int64(len(cmds)) {
+			return 0, fmt.Errorf("no command %d (the log has %d)", b.n, len(cmds))
+		}
+		c := cmds[i]
+		if isEnd {
+			return c.End + 1, nil
+		}
+		if c.Prompt >= 0 && c.Prompt <= c.Start {
+			return c.Prompt, nil
+		}
+		return c.Start, nil
+	}
+	if isEnd {
+		return count, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,101 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/export_action.go
+// Summary: The texelterm.export action (unbound by default): saves the
+// newest finished command in view to a file, or the visible lines when no
+// command has been seen (no shell integration).
+// Usage: Bound through termActions. The format comes from the "format"
+//   option of the texelterm.export section (html, ansi or text); files go
+//   to ~/.texelation/exports. Whole histories are exported with the
+//   `texelterm export` command instead.
+
+package texelterm
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/export"
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+// DefaultPalette returns the palette a new pane starts with, for styling
+// exports made outside a running pane.
+func DefaultPalette() export.Palette {
+	return export.Palette(newDefaultPalette())
+}
+
+// exportInView writes the newest finished command in view, prompt
+// included, or else the rendered lines.
+func (a *TexelTerm) exportInView() {
+	format, err := export.ParseFormat(a.paneConfig().GetString("texelterm.export", "format", "html"))
+	if err != nil {
+		a.showExportError(err)
+		return
+	}
+
+	a.mu.Lock()
+	start, end := int64(-1), int64(-1)
+	if b, ok := a.commandBlockInViewLocked(); ok {
+		start, end = b.Start, b.End+1
+		if b.Prompt >= 0 && b.Prompt <= b.Start {
+			start = b.Prompt
+		}
+	} else if a.vterm != nil && !a.vterm.InAltScreen() {
+		for _, gi := range a.lastRowGlobalIdx {
+			if gi < 0 {
+				continue
+			}
+			if start < 0 || gi < start {
+				start = gi
+			}
+			end = max(end, gi+1)
+		}
+	}
+	var lines []*parser.LogicalLine
+	for gi := start; gi >= 0 && gi < end; gi++ {
+		lines = append(lines, a.vterm.GetLogicalLine(gi))
+	}
+	palette := export.Palette(a.colorPalette)
+	a.mu.Unlock()
+
+	if len(lines) == 0 {
+		a.showExportError(fmt.Errorf("nothing to export"))
+		return
+	}
+	home, _ := os.UserHomeDir()
+	dir := filepath.Join(home, ".texelation", "exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		a.showExportError(err)
+		return
+	}
+	name := filepath.Join(dir, fmt.Sprintf("export-%s.%s", time.Now().Format("2006-01-02_15-04-05"), format.Ext()))
+	f, err := os.Create(name)
+	if err != nil {
+		a.showExportError(err)
+		return
+	}
+	err = export.Write(f, lines, format, &palette)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		a.showExportError(err)
+		return
+	}
+	log.Printf("[EXPORT] Saved %d lines to %s", len(lines), name)
+	if a.statusBar != nil {
+		a.statusBar.ShowSuccess(fmt.Sprintf("Exported %d lines to %s", len(lines), name))
+	}
+}
+
+func (a *TexelTerm) showExportError(err error) {
+	log.Printf("[EXPORT] %v", err)
+	if a.statusBar != nil {
+		a.statusBar.ShowError("Export: " + err.Error())
+	}
+}
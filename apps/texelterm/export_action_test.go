@@ -0,0 +1,46 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/export_action_test.go
+// Summary: Exercises exporting the command in view to a file.
+
+package texelterm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportCommandInView(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tt := NewTestTerm(40, 8)
+	a := tt.term
+	a.vterm.OnCommandStart = a.beginCommandBlockLocked
+	a.vterm.OnCommandEnd = a.endCommandBlockLocked
+
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07ls\r\n\x1b]133;C;ls\x07old\r\n\x1b]133;D;0\x07"))
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07seq 2\r\n\x1b]133;C;seq 2\x071\r\n\x1b[31m2\x1b[m\r\n\x1b]133;D;0\x07\x1b]133;A\x07$ "))
+	renderedRows(a)
+	a.exportInView()
+
+	files, _ := filepath.Glob(filepath.Join(home, ".texelation", "exports", "export-*.html"))
+	if len(files) != 1 {
+		t.Fatalf("exports = %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"$ seq 2\n1\n<span style=\"color:", ">2</span>\n</pre>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("export lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "old") {
+		t.Errorf("export includes an older command:\n%s", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package extfilter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+// decodeANSI turns a filter's reply into cells. SGR sequences set colors
+// and attributes the way the terminal does; other escape sequences and
+// control characters are dropped, and tabs expand to the next multiple
+// of eight columns.
+func decodeANSI(s string) []parser.Cell {
+	cells := make([]parser.Cell, 0, len(s))
+	fg, bg, attr := parser.DefaultFG, parser.DefaultBG, parser.Attribute(0)
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == 0x1b:
+			i = skipEscape(rs, i, func(params string) {
+				fg, bg, attr = applySGR(params, fg, bg, attr)
+			})
+		case r == '\t':
+			for n := 8 - len(cells)%8; n > 0; n-- {
+				cells = append(cells, parser.Cell{Rune: ' ', FG: fg, BG: bg, Attr: attr})
+			}
+		case r < 0x20 || r == 0x7f:
+		default:
+			cells = append(cells, parser.Cell{Rune: r, FG: fg, BG: bg, Attr: attr})
+		}
+	}
+	return cells
+}
+
+// skipEscape consumes the escape sequence starting at rs[i] and returns
+// the index of its last rune. sgr is called with the parameters of a CSI
+// sequence ending in 'm'.
+func skipEscape(rs []rune, i int, sgr func(params string)) int {
+	if i+1 >= len(rs) {
+		return i
+	}
+	switch rs[i+1] {
+	case '[': // CSI: parameters, intermediates, final byte
+		j := i + 2
+		for j < len(rs) && (rs[j] < 0x40 || rs[j] > 0x7e) {
+			j++
+		}
+		if j < len(rs) && rs[j] == 'm' {
+			sgr(string(rs[i+2 : j]))
+		}
+		return min(j, len(rs)-1)
+	case ']': // OSC: up to BEL or ST
+		for j := i + 2; j < len(rs); j++ {
+			if rs[j] == 0x07 {
+				return j
+			}
+			if rs[j] == 0x1b && j+1 < len(rs) && rs[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(rs) - 1
+	default:
+		return i + 1
+	}
+}
+
+// applySGR applies one SGR parameter string, as in "1;38;5;208", to the
+// current style.
+func applySGR(params string, fg, bg parser.Color, attr parser.Attribute) (parser.Color, parser.Color, parser.Attribute) {
+	var ps []int
+	for _, f := range strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' }) {
+		n, _ := strconv.Atoi(f)
+		ps = append(ps, n)
+	}
+	if len(ps) == 0 {
+		ps = []int{0}
+	}
+	for i := 0; i < len(ps); i++ {
+		switch p := ps[i]; {
+		case p == 0:
+			fg, bg, attr = parser.DefaultFG, parser.DefaultBG, 0
+		case p == 1:
+			attr |= parser.AttrBold
+		case p == 2:
+			attr |= parser.AttrDim
+		case p == 3:
+			attr |= parser.AttrItalic
+		case p == 4:
+			attr |= parser.AttrUnderline
+		case p == 7:
+			attr |= parser.AttrReverse
+		case p == 22:
+			attr &^= parser.AttrBold | parser.AttrDim
+		case p == 23:
+			attr &^= parser.AttrItalic
+		case p == 24:
+			attr &^= parser.AttrUnderline
+		case p == 27:
+			attr &^= parser.AttrReverse
+		case p >= 30 && p <= 37:
+			fg = parser.Color{Mode: parser.ColorModeStandard, Value: uint8(p - 30)}
+		case p == 39:
+			fg = parser.DefaultFG
+		case p >= 40 && p <= 47:
+			bg = parser.Color{Mode: parser.ColorModeStandard, Value: uint8(p - 40)}
+		case p == 49:
+			bg = parser.DefaultBG
+		case p >= 90 && p <= 97:
+			fg = parser.Color{Mode: parser.ColorModeStandard, Value: uint8(p - 90 + 8)}
+		case p >= 100 && p <= 107:
+			bg = parser.Color{Mode: parser.ColorModeStandard, Value: uint8(p - 100 + 8)}
+		case p == 38 || p == 48:
+			c, n := extendedColor(ps[i+1:])
+			if n == 0 {
+				continue
+			}
+			if p == 38 {
+				fg = c
+			} else {
+				bg = c
+			}
+			i += n
+		}
+	}
+	return fg, bg, attr
+}
+
+// extendedColor reads "5;n" or "2;r;g;b" and returns the color and how
+// many parameters it used, or 0 if they are malformed.
+func extendedColor(ps []int) (parser.Color, int) {
+	switch {
+	case len(ps) >= 2 && ps[0] == 5:
+		if ps[1] < 8 {
+			return parser.Color{Mode: parser.ColorModeStandard, Value: uint8(ps[1])}, 2
+		}
+		return parser.Color{Mode: parser.ColorMode256, Value: uint8(ps[1])}, 2
+	case len(ps) >= 4 && ps[0] == 2:
+		return parser.Color{Mode: parser.ColorModeRGB, R: uint8(ps[1]), G: uint8(ps[2]), B: uint8(ps[3])}, 4
+	}
+	return parser.Color{}, 0
+}
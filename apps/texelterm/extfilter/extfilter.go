@@ -0,0 +1,453 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// Package extfilter is an inline output transformer for texelterm that
+// pipes command output through long-lived external filter processes
+// (log prettifiers, wrappers around jq or bat, and the like) and shows
+// their styled replies in place of the original lines. Filters are
+// chosen per command with a glob and configured in the
+// "transformers.extfilter" section. It self-registers via init() into
+// the transformer registry.
+//
+// A filter speaks one of two protocols over stdin/stdout:
+//
+//   - "lines": each output line is written as plain text followed by a
+//     newline, and exactly one reply line is read back.
+//   - "json": one JSON object per line. The filter receives
+//     {"type":"start","command":"..."} when a command's output begins,
+//     {"type":"line","id":N,"text":"..."} for each line and {"type":"end"}
+//     when the prompt returns, and answers each line with
+//     {"id":N,"text":"..."}. A reply without "text" keeps the original.
+//
+// Replies may carry SGR escape sequences for colors and attributes. The
+// parser never waits for them: lines are queued for the filter as they
+// are committed, and each reply is applied later, from the goroutine
+// reading the filter, through the terminal's overlay callback.
+//
+// A reply that takes longer than the filter's timeout, or a filter whose
+// replies have taken more than its budget in total during one command,
+// bypasses the filter for the rest of that command. A filter that exits
+// is restarted at the next command, up to maxRestarts times.
+package extfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/apps/texelterm/transformer"
+	"github.com/framegrace/texelation/apps/texelterm/txfmt"
+)
+
+func init() {
+	transformer.Register("extfilter", func(cfg transformer.Config) (transformer.Transformer, error) {
+		filters, err := ParseFilters(cfg["filters"])
+		if err != nil {
+			return nil, err
+		}
+		return New(filters), nil
+	})
+}
+
+// Compile-time interface verification.
+var (
+	_ transformer.Transformer    = (*Transformer)(nil)
+	_ transformer.AsyncOverlayer = (*Transformer)(nil)
+	_ transformer.Closer         = (*Transformer)(nil)
+)
+
+const (
+	defaultTimeout = 100 * time.Millisecond
+	defaultBudget  = 5 * time.Second
+
+	// maxRestarts is how many times a failing filter is restarted before
+	// it is disabled for the rest of the session.
+	maxRestarts = 3
+)
+
+// Protocol is how texelterm talks to a filter.
+type Protocol string
+
+const (
+	ProtocolLines Protocol = "lines"
+	ProtocolJSON  Protocol = "json"
+)
+
+// Filter is one configured filter.
+type Filter struct {
+	Name     string
+	Command  string // glob matched against the command line or its name; "" matches all
+	Run      string // shell command line starting the filter
+	Protocol Protocol
+	Timeout  time.Duration // per-line wait for a reply
+	Budget   time.Duration // time the filter may spend replying per command
+}
+
+// ParseFilters reads filters from config: a list of objects with "id"
+// (the filter's name), "enabled", "command", "run", "protocol",
+// "timeout_ms" and "budget_ms". Disabled filters are skipped.
+func ParseFilters(raw interface{}) ([]Filter, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("extfilter: filters must be a list, got %T", raw)
+	}
+	var filters []Filter
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("extfilter: filter %d is not an object", i+1)
+		}
+		if enabled, ok := m["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		str := func(key string) string {
+			s, _ := m[key].(string)
+			return strings.TrimSpace(s)
+		}
+		f := Filter{
+			Name:     str("id"),
+			Command:  str("command"),
+			Run:      str("run"),
+			Protocol: Protocol(str("protocol")),
+			Timeout:  defaultTimeout,
+			Budget:   defaultBudget,
+		}
+		if f.Name == "" {
+			f.Name = fmt.Sprintf("filter %d", i+1)
+		}
+		if f.Run == "" {
+			return nil, fmt.Errorf("extfilter: %s has nothing to run", f.Name)
+		}
+		switch f.Protocol {
+		case "":
+			f.Protocol = ProtocolLines
+		case ProtocolLines, ProtocolJSON:
+		default:
+			return nil, fmt.Errorf("extfilter: %s: unknown protocol %q", f.Name, f.Protocol)
+		}
+		if ms, ok := m["timeout_ms"].(float64); ok && ms > 0 {
+			f.Timeout = time.Duration(ms) * time.Millisecond
+		}
+		if ms, ok := m["budget_ms"].(float64); ok && ms > 0 {
+			f.Budget = time.Duration(ms) * time.Millisecond
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// filter is a configured filter and its process.
+type filter struct {
+	Filter
+	proc     *process
+	pending  []pending     // lines sent to proc and not yet answered, oldest first
+	wake     chan struct{} // tells proc's watcher that pending has grown
+	restarts int
+	disabled bool // failed too often
+}
+
+// pending is a line waiting for the filter's reply.
+type pending struct {
+	id      int64 // json protocol line id
+	lineIdx int64
+	command int64 // the command the line belongs to
+	sent    time.Time
+}
+
+// Transformer is the extfilter transformer. The parser goroutine and the
+// goroutines watching filter replies share its state under mu.
+type Transformer struct {
+	mu                  sync.Mutex
+	filters             []*filter
+	overlay             func(lineIdx int64, cells []parser.Cell)
+	hasShellIntegration bool
+	wasCommand          bool
+	command             string
+	commands            int64 // commands started, to tell stale replies apart
+
+	active    *filter       // filter for the running command, if any
+	begun     bool          // the active filter has been told the command started
+	bypass    bool          // the active filter failed or overran during this command
+	seq       int64         // id of the last line sent, for the json protocol
+	spent     time.Duration // time the active filter spent replying this command
+	lastReply time.Time
+}
+
+// New creates a Transformer. A command's output goes through the first
+// filter whose glob matches it.
+func New(filters []Filter) *Transformer {
+	t := &Transformer{}
+	for _, f := range filters {
+		t.filters = append(t.filters, &filter{Filter: f})
+	}
+	return t
+}
+
+// SetAsyncOverlayFunc implements transformer.AsyncOverlayer. Replies are
+// dropped until it is set.
+func (t *Transformer) SetAsyncOverlayFunc(fn func(lineIdx int64, cells []parser.Cell)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.overlay = fn
+}
+
+// NotifyPromptStart records that shell integration is active and ends
+// the current command's block.
+func (t *Transformer) NotifyPromptStart() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hasShellIntegration = true
+	t.endCommandLocked()
+}
+
+// NotifyCommandStart picks the filter for cmd's output.
+func (t *Transformer) NotifyCommandStart(cmd string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endCommandLocked()
+	t.command = cmd
+	t.commands++
+	t.spent = 0
+	for _, f := range t.filters {
+		if !f.disabled && transformer.MatchCommand(f.Command, cmd) {
+			t.active = f
+			return
+		}
+	}
+}
+
+// HandleLine queues a command output line for the active filter; its
+// reply becomes the line's overlay when it arrives. Filters only run on
+// output that shell integration marks as belonging to a command.
+func (t *Transformer) HandleLine(lineIdx int64, line *parser.LogicalLine, isCommand bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.hasShellIntegration {
+		return
+	}
+	if t.wasCommand && !isCommand {
+		t.endCommandLocked()
+	}
+	t.wasCommand = isCommand
+	if !isCommand || t.active == nil || t.bypass {
+		return
+	}
+
+	plain, _ := txfmt.BuildPlainTextMap(line.Cells)
+	text := strings.TrimRight(string(plain), " ")
+	if err := t.sendLocked(lineIdx, text); err != nil {
+		log.Printf("[EXTFILTER] %s: %v; passing %q through unfiltered", t.active.Name, err, t.command)
+		t.failLocked(t.active, err)
+	}
+}
+
+// sendLocked queues one line for the active filter. Caller holds mu.
+func (t *Transformer) sendLocked(lineIdx int64, text string) error {
+	f := t.active
+	if err := t.beginLocked(); err != nil {
+		return err
+	}
+	msg := []byte(text + "\n")
+	if f.Protocol == ProtocolJSON {
+		t.seq++
+		msg = encode(map[string]interface{}{"type": "line", "id": t.seq, "text": text})
+	}
+	if err := f.proc.send(msg); err != nil {
+		return err
+	}
+	f.pending = append(f.pending, pending{id: t.seq, lineIdx: lineIdx, command: t.commands, sent: time.Now()})
+	if len(f.pending) == 1 {
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// beginLocked starts the active filter if needed and, for the json
+// protocol, tells it which command's output follows. Caller holds mu.
+func (t *Transformer) beginLocked() error {
+	f := t.active
+	if f.proc == nil {
+		p, err := startProcess(f.Run)
+		if err != nil {
+			return err
+		}
+		f.proc = p
+		f.pending = nil
+		f.wake = make(chan struct{}, 1)
+		go t.watch(f, p, f.wake)
+	}
+	if t.begun {
+		return nil
+	}
+	t.begun = true
+	if f.Protocol == ProtocolJSON {
+		return f.proc.send(encode(map[string]interface{}{"type": "start", "command": t.command}))
+	}
+	return nil
+}
+
+// watch applies p's replies until p exits, and gives up on lines that
+// wait longer than the filter's timeout.
+func (t *Transformer) watch(f *filter, p *process, wake <-chan struct{}) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		t.mu.Lock()
+		wait := time.Hour
+		if f.proc == p && len(f.pending) > 0 {
+			wait = max(time.Until(f.pending[0].sent.Add(f.Timeout)), 0)
+		}
+		t.mu.Unlock()
+		timer.Reset(wait)
+
+		select {
+		case s := <-p.out:
+			t.reply(f, p, s)
+		case <-wake:
+		case <-timer.C:
+			t.mu.Lock()
+			if f.proc == p && len(f.pending) > 0 && time.Since(f.pending[0].sent) >= f.Timeout {
+				log.Printf("[EXTFILTER] %s: %v; passing %q through unfiltered", f.Name, errTimeout, t.command)
+				t.failLocked(f, errTimeout)
+			}
+			t.mu.Unlock()
+		case <-p.done:
+			// Replies written just before exiting still count.
+			for drained := false; !drained; {
+				select {
+				case s := <-p.out:
+					t.reply(f, p, s)
+				default:
+					drained = true
+				}
+			}
+			t.mu.Lock()
+			if f.proc == p {
+				t.failLocked(f, errExited)
+			}
+			t.mu.Unlock()
+			return
+		}
+	}
+}
+
+// reply matches one reply line from p to the line it answers and shows
+// it as that line's overlay.
+func (t *Transformer) reply(f *filter, p *process, s string) {
+	t.mu.Lock()
+	if f.proc != p || len(f.pending) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	i, text, keep := 0, s, false
+	if f.Protocol == ProtocolJSON {
+		var msg struct {
+			ID   int64   `json:"id"`
+			Text *string `json:"text"`
+		}
+		// Late replies to lines already given up on, and anything that is
+		// not a reply, are skipped.
+		i = -1
+		if json.Unmarshal([]byte(s), &msg) == nil {
+			for j, pl := range f.pending {
+				if pl.id == msg.ID {
+					i = j
+					break
+				}
+			}
+		}
+		if i < 0 {
+			t.mu.Unlock()
+			return
+		}
+		if msg.Text == nil {
+			keep = true
+		} else {
+			text = *msg.Text
+		}
+	}
+	pl := f.pending[i]
+	f.pending = append(f.pending[:i], f.pending[i+1:]...)
+	if t.active == f && pl.command == t.commands {
+		now := time.Now()
+		start := pl.sent
+		if t.lastReply.After(start) {
+			start = t.lastReply
+		}
+		t.spent += now.Sub(start)
+		t.lastReply = now
+		if f.Budget > 0 && t.spent > f.Budget && !t.bypass {
+			log.Printf("[EXTFILTER] %s: over its %v budget; passing the rest of %q through unfiltered", f.Name, f.Budget, t.command)
+			t.bypass = true
+		}
+	}
+	overlay := t.overlay
+	t.mu.Unlock()
+
+	if !keep && overlay != nil {
+		overlay(pl.lineIdx, decodeANSI(text))
+	}
+}
+
+// failLocked bypasses f if it is filtering the running command. A filter
+// that exited, or whose line replies may now be out of step, is stopped
+// so the next command starts a fresh one. Caller holds mu.
+func (t *Transformer) failLocked(f *filter, err error) {
+	if t.active == f {
+		t.bypass = true
+	}
+	if f.proc == nil {
+		return
+	}
+	f.pending = nil
+	if err == errTimeout && f.Protocol == ProtocolJSON {
+		return // late replies are recognised by id
+	}
+	f.proc.stop()
+	f.proc = nil
+	f.restarts++
+	if f.restarts > maxRestarts {
+		f.disabled = true
+		log.Printf("[EXTFILTER] %s: failed %d times, disabled", f.Name, f.restarts)
+	}
+}
+
+// endCommandLocked closes the current command's block. Replies to its
+// lines still in flight are applied when they arrive. Caller holds mu.
+func (t *Transformer) endCommandLocked() {
+	if f := t.active; f != nil && t.begun && f.proc != nil && f.Protocol == ProtocolJSON {
+		_ = f.proc.send(encode(map[string]interface{}{"type": "end"}))
+	}
+	t.active = nil
+	t.begun = false
+	t.bypass = false
+	t.command = ""
+}
+
+// Close stops all filter processes.
+func (t *Transformer) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range t.filters {
+		if f.proc != nil {
+			f.proc.stop()
+			f.proc = nil
+			f.pending = nil
+		}
+	}
+}
+
+func encode(msg map[string]interface{}) []byte {
+	b, _ := json.Marshal(msg)
+	return append(b, '\n')
+}
@@ -0,0 +1,262 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package extfilter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+func makeLine(s string) *parser.LogicalLine {
+	cells := make([]parser.Cell, len([]rune(s)))
+	for i, r := range []rune(s) {
+		cells[i] = parser.Cell{Rune: r, FG: parser.DefaultFG, BG: parser.DefaultBG}
+	}
+	return &parser.LogicalLine{Cells: cells}
+}
+
+func overlayText(l *parser.LogicalLine) string {
+	var b strings.Builder
+	for _, c := range l.Overlay {
+		b.WriteRune(c.Rune)
+	}
+	return b.String()
+}
+
+// run feeds one command and its output through tr and returns the
+// lines, with each reply applied to its line as an overlay. It waits for
+// want replies and then for the filter to stop waiting on any others.
+func run(t *testing.T, tr *Transformer, want int, cmd string, output ...string) []*parser.LogicalLine {
+	t.Helper()
+	var lines []*parser.LogicalLine
+	for _, s := range output {
+		lines = append(lines, makeLine(s))
+	}
+	got := make(chan struct{}, len(output))
+	tr.SetAsyncOverlayFunc(func(lineIdx int64, cells []parser.Cell) {
+		lines[lineIdx-1].Overlay = cells
+		got <- struct{}{}
+	})
+
+	tr.NotifyPromptStart()
+	tr.HandleLine(0, makeLine("$ "+cmd), false)
+	tr.NotifyCommandStart(cmd)
+	for i, l := range lines {
+		tr.HandleLine(int64(i+1), l, true)
+	}
+	tr.NotifyPromptStart()
+
+	for i := 0; i < want; i++ {
+		select {
+		case <-got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: got %d replies, want %d", cmd, i, want)
+		}
+	}
+	waitIdle(t, tr)
+	return lines
+}
+
+// waitIdle waits until no filter of tr has lines awaiting a reply.
+func waitIdle(t *testing.T, tr *Transformer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tr.mu.Lock()
+		idle := true
+		for _, f := range tr.filters {
+			idle = idle && len(f.pending) == 0
+		}
+		tr.mu.Unlock()
+		if idle {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("filter still has lines in flight")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDecodeANSI(t *testing.T) {
+	cells := decodeANSI("a\x1b[1;38;5;208mb\x1b[0m\x1b]8;;x\x07\tc\x1b[48;2;1;2;3mdd")
+	var text strings.Builder
+	for _, c := range cells {
+		text.WriteRune(c.Rune)
+	}
+	if text.String() != "ab      cdd" {
+		t.Fatalf("text = %q", text.String())
+	}
+	if c := cells[1]; c.Attr != parser.AttrBold || c.FG.Mode != parser.ColorMode256 || c.FG.Value != 208 {
+		t.Errorf("b = %+v", c)
+	}
+	if c := cells[8]; c.Attr != 0 || c.FG != parser.DefaultFG {
+		t.Errorf("c = %+v", c)
+	}
+	if c := cells[9]; c.BG.Mode != parser.ColorModeRGB || c.BG.B != 3 {
+		t.Errorf("d = %+v", c)
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	filters, err := ParseFilters([]interface{}{
+		map[string]interface{}{"id": "logs", "command": "kubectl", "run": "prettylog", "timeout_ms": float64(250), "budget_ms": float64(900)},
+		map[string]interface{}{"id": "off", "enabled": false},
+		map[string]interface{}{"id": "j", "run": "cat", "protocol": "json"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 2 || filters[0].Protocol != ProtocolLines || filters[0].Timeout != 250*time.Millisecond || filters[0].Budget != 900*time.Millisecond ||
+		filters[1].Protocol != ProtocolJSON || filters[1].Budget != defaultBudget {
+		t.Fatalf("filters = %+v", filters)
+	}
+	for _, bad := range []map[string]interface{}{
+		{"id": "x"},
+		{"id": "x", "run": "cat", "protocol": "xml"},
+	} {
+		if _, err := ParseFilters([]interface{}{bad}); err == nil {
+			t.Errorf("ParseFilters(%v) should fail", bad)
+		}
+	}
+}
+
+func TestLinesFilter(t *testing.T) {
+	tr := New([]Filter{{
+		Name:     "upper",
+		Command:  "make",
+		Run:      `while IFS= read -r l; do printf '\033[1m%s\033[0m!\n' "$l"; done`,
+		Protocol: ProtocolLines,
+		Timeout:  2 * time.Second,
+	}})
+	defer tr.Close()
+
+	lines := run(t, tr, 2, "make all", "building", "done")
+	for i, want := range []string{"building!", "done!"} {
+		if got := overlayText(lines[i]); got != want {
+			t.Errorf("line %d overlay = %q, want %q", i, got, want)
+		}
+	}
+	if lines[0].Overlay[0].Attr != parser.AttrBold || lines[0].Overlay[8].Attr != 0 {
+		t.Error("SGR styling lost")
+	}
+	if lines[0].Cells[0].Rune != 'b' || len(lines[0].Cells) != len("building") {
+		t.Error("original cells must be kept")
+	}
+
+	lines = run(t, tr, 0, "ls", "a")
+	if lines[0].Overlay != nil {
+		t.Error("commands not matching the glob should be left alone")
+	}
+}
+
+func TestJSONFilter(t *testing.T) {
+	// sed echoes each message back, so "line" messages read as replies
+	// with the (possibly colored) original text, while start and end
+	// messages are ignored.
+	tr := New([]Filter{{
+		Name:     "echo",
+		Run:      `sed -u 's/ERROR/\\u001b[31mERROR\\u001b[0m/'`,
+		Protocol: ProtocolJSON,
+		Timeout:  2 * time.Second,
+	}})
+	defer tr.Close()
+
+	lines := run(t, tr, 2, "app", "ok", "ERROR x")
+	if got := overlayText(lines[0]); got != "ok" {
+		t.Errorf("overlay = %q", got)
+	}
+	if got := overlayText(lines[1]); got != "ERROR x" {
+		t.Fatalf("overlay = %q", got)
+	}
+	if c := lines[1].Overlay[0]; c.FG.Mode != parser.ColorModeStandard || c.FG.Value != 1 {
+		t.Errorf("E = %+v", c)
+	}
+}
+
+func TestFilterTimeoutAndCrash(t *testing.T) {
+	tr := New([]Filter{{Name: "slow", Run: "sleep 30", Protocol: ProtocolLines, Timeout: 50 * time.Millisecond}})
+	defer tr.Close()
+	start := time.Now()
+	lines := run(t, tr, 0, "x", "a", "b", "c")
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("a stuck filter held up output for %v", d)
+	}
+	for _, l := range lines {
+		if l.Overlay != nil {
+			t.Error("lines should pass through unfiltered")
+		}
+	}
+
+	// A filter that exits after one line is restarted for each command
+	// until it has failed too often.
+	tr = New([]Filter{{Name: "once", Run: "head -n 1", Protocol: ProtocolLines, Timeout: 2 * time.Second}})
+	defer tr.Close()
+	for i := 0; i <= maxRestarts; i++ {
+		lines = run(t, tr, 1, "x", "a", "b", "c")
+		if overlayText(lines[0]) != "a" {
+			t.Fatalf("command %d: first line not filtered", i)
+		}
+	}
+	if !tr.filters[0].disabled {
+		t.Fatal("a filter that keeps failing should be disabled")
+	}
+	if lines = run(t, tr, 0, "x", "a"); lines[0].Overlay != nil {
+		t.Error("a disabled filter should not run")
+	}
+}
+
+func TestFilterNeverBlocksTheParser(t *testing.T) {
+	tr := New([]Filter{{Name: "slow", Run: `while IFS= read -r l; do sleep 0.2; echo "$l"; done`, Protocol: ProtocolLines, Timeout: 5 * time.Second}})
+	defer tr.Close()
+	tr.SetAsyncOverlayFunc(func(int64, []parser.Cell) {})
+	tr.NotifyPromptStart()
+	tr.NotifyCommandStart("x")
+	start := time.Now()
+	for i := int64(1); i <= 10; i++ {
+		tr.HandleLine(i, makeLine("line"), true)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("HandleLine waited %v for a slow filter", d)
+	}
+}
+
+func TestFilterBudget(t *testing.T) {
+	tr := New([]Filter{{
+		Name:     "slow",
+		Run:      `while IFS= read -r l; do sleep 0.1; echo "$l"; done`,
+		Protocol: ProtocolLines,
+		Timeout:  5 * time.Second,
+		Budget:   50 * time.Millisecond,
+	}})
+	defer tr.Close()
+	got := make(chan int64, 4)
+	tr.SetAsyncOverlayFunc(func(lineIdx int64, cells []parser.Cell) { got <- lineIdx })
+
+	// The first reply takes twice the budget, so the filter is bypassed
+	// for the rest of the command.
+	tr.NotifyPromptStart()
+	tr.NotifyCommandStart("x")
+	tr.HandleLine(1, makeLine("a"), true)
+	select {
+	case <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply to the first line")
+	}
+	tr.HandleLine(2, makeLine("b"), true)
+	waitIdle(t, tr)
+	select {
+	case idx := <-got:
+		t.Fatalf("line %d filtered after the budget ran out", idx)
+	default:
+	}
+
+	// The next command gets a fresh budget.
+	if lines := run(t, tr, 1, "y", "c"); overlayText(lines[0]) != "c" {
+		t.Fatal("budget not reset for the next command")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package extfilter
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+const (
+	// queueSize bounds the lines waiting to be written to a filter and
+	// the replies waiting to be read, so a stalled filter costs memory
+	// only up to this point.
+	queueSize = 256
+
+	// maxReplyBytes is the longest reply line accepted from a filter.
+	maxReplyBytes = 1 << 20
+)
+
+var (
+	errTimeout = errors.New("filter timed out")
+	errExited  = errors.New("filter exited")
+	errBusy    = errors.New("filter input queue full")
+)
+
+// process is a running filter. Writes and reads happen on their own
+// goroutines so a filter that stops reading or writing never blocks the
+// caller.
+type process struct {
+	cmd  *exec.Cmd
+	in   chan []byte   // lines to write, drained by the writer goroutine
+	out  chan string   // reply lines from the reader goroutine
+	done chan struct{} // closed once the process has exited
+
+	mu      sync.Mutex
+	stopped bool // guarded by mu; in is closed
+}
+
+// startProcess runs the filter through /bin/sh so its command line may use
+// pipes and quoting, in its own process group so stopping it also stops
+// anything it spawned. Its stderr is discarded.
+func startProcess(run string) (*process, error) {
+	cmd := exec.Command("/bin/sh", "-c", run)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{
+		cmd:  cmd,
+		in:   make(chan []byte, queueSize),
+		out:  make(chan string, queueSize),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		for b := range p.in {
+			if _, err := stdin.Write(b); err != nil {
+				break
+			}
+		}
+		stdin.Close()
+	}()
+
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		sc.Buffer(make([]byte, 0, 4096), maxReplyBytes)
+		// The transformer's watcher reads until done is closed, so this
+		// never blocks for long, and dropping a reply here would put
+		// the lines protocol out of step.
+		for sc.Scan() {
+			p.out <- sc.Text()
+		}
+		_ = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// send queues b for the filter without blocking.
+func (p *process) send(b []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped || p.exited() {
+		return errExited
+	}
+	select {
+	case p.in <- b:
+		return nil
+	default:
+		return errBusy
+	}
+}
+
+// exited reports whether the process has exited.
+func (p *process) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop closes the filter's input and kills it.
+func (p *process) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	close(p.in)
+	_ = syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,285 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/fold_view.go
+// Summary: Collapsible JSON/YAML output. Collapsed folds are drawn as one
+// summary line ("{…} 42 keys") in place of the lines they hide.
+// Usage: Click a fold's opening bracket, key or summary to toggle it; the
+//   texelterm.fold action (unbound by default; run it from the command
+//   palette) collapses or expands the newest structured block in view.
+//   Blocks txfmt recorded are used as is; other visible output is run
+//   through txfmt.DetectFolds on demand.
+// Notes: Fold state lives in the txfmt fold tree and is shown through the
+//   sparse ViewWindow's collapses, the same projection command output
+//   collapsing uses, so wrapped lines, scrolling and selections behave
+//   alike for both. The scrollback keeps every line.
+
+package texelterm
+
+import (
+	"strings"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/apps/texelterm/txfmt"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// maxFoldScan is how many lines around a click or the viewport are
+	// read when looking for a structured block on demand.
+	maxFoldScan = 10000
+
+	// maxFoldBlocks bounds how many on-demand blocks (and their fold
+	// state) are remembered.
+	maxFoldBlocks = 64
+)
+
+// foldView holds the fold blocks found on demand and which blocks' fold
+// state the view already shows. It is only touched under a.mu.
+type foldView struct {
+	blocks []*txfmt.FoldBlock // oldest first
+	shown  map[*txfmt.FoldBlock]bool
+}
+
+// remember keeps b and returns it, or the block already remembered for
+// the same range so its fold state carries over.
+func (fv *foldView) remember(b *txfmt.FoldBlock) *txfmt.FoldBlock {
+	for _, old := range fv.blocks {
+		if old.Start == b.Start && old.End == b.End {
+			return old
+		}
+	}
+	fv.blocks = append(fv.blocks, b)
+	if over := len(fv.blocks) - maxFoldBlocks; over > 0 {
+		fv.blocks = append(fv.blocks[:0], fv.blocks[over:]...)
+	}
+	return b
+}
+
+// foldBlocksLocked returns the blocks txfmt recorded followed by those
+// found on demand. Caller holds a.mu.
+func (a *TexelTerm) foldBlocksLocked() []*txfmt.FoldBlock {
+	var blocks []*txfmt.FoldBlock
+	if a.pipeline != nil {
+		for _, t := range a.pipeline.Transformers() {
+			if f, ok := t.(*txfmt.Formatter); ok {
+				blocks = append(blocks, f.FoldBlocks()...)
+			}
+		}
+	}
+	return append(blocks, a.folds.blocks...)
+}
+
+// detectFoldsLocked runs fold detection over lines from..to of the
+// scrollback and remembers what it finds. Caller holds a.mu.
+func (a *TexelTerm) detectFoldsLocked(from, to int64) []*txfmt.FoldBlock {
+	from = max(from, 0)
+	to = min(to, a.vterm.ContentEnd())
+	if to < from {
+		return nil
+	}
+	idx := make([]int64, 0, to-from+1)
+	lines := make([]string, 0, to-from+1)
+	for gi := from; gi <= to; gi++ {
+		idx = append(idx, gi)
+		var text string
+		if ll := a.vterm.GetLogicalLine(gi); ll != nil {
+			text = foldLineText(ll.Cells)
+		}
+		lines = append(lines, text)
+	}
+	blocks := txfmt.DetectFolds(idx, lines)
+	for i, b := range blocks {
+		blocks[i] = a.folds.remember(b)
+	}
+	return blocks
+}
+
+// foldLineText returns a line's text with cell columns preserved.
+func foldLineText(cells []parser.Cell) string {
+	var b strings.Builder
+	for _, c := range cells {
+		if c.Rune == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// mayOpenFold reports whether a line could be a fold header, to avoid
+// scanning the scrollback for clicks on ordinary text.
+func mayOpenFold(text string) bool {
+	trim := strings.TrimSpace(text)
+	return strings.HasSuffix(trim, "{") || strings.HasSuffix(trim, "[") ||
+		strings.HasSuffix(trim, ":") || strings.HasPrefix(trim, "- ")
+}
+
+// foldHeaderAtLocked returns the fold whose header is drawn on row y, and
+// its block, detecting the block on demand. Caller holds a.mu.
+func (a *TexelTerm) foldHeaderAtLocked(y int) (*txfmt.FoldBlock, *txfmt.Fold) {
+	if a.vterm == nil || a.vterm.InAltScreen() || y < 0 || y >= len(a.lastRowGlobalIdx) {
+		return nil, nil
+	}
+	gi := a.lastRowGlobalIdx[y]
+	if gi < 0 || y > 0 && a.lastRowGlobalIdx[y-1] == gi {
+		return nil, nil
+	}
+	for _, b := range a.foldBlocksLocked() {
+		if b.Contains(gi) {
+			if f := b.FoldAt(gi); f != nil {
+				return b, f
+			}
+		}
+	}
+	ll := a.vterm.GetLogicalLine(gi)
+	if ll == nil || !mayOpenFold(foldLineText(ll.Cells)) {
+		return nil, nil
+	}
+	for _, b := range a.detectFoldsLocked(gi-maxFoldScan, gi+maxFoldScan) {
+		if b.Contains(gi) {
+			if f := b.FoldAt(gi); f != nil {
+				return b, f
+			}
+		}
+	}
+	return nil, nil
+}
+
+// handleFoldClick toggles the fold under a left-button press on its
+// bracket, key or summary, and reports whether it did.
+func (a *TexelTerm) handleFoldClick(x, y int, press bool, mods tcell.ModMask) bool {
+	if !press || mods != tcell.ModNone {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b, f := a.foldHeaderAtLocked(y)
+	if f == nil || x < f.Col-2 {
+		return false
+	}
+	f.Collapsed = !f.Collapsed
+	a.showFoldBlockLocked(b)
+	return true
+}
+
+// toggleFoldsInView collapses the newest structured block in view, or
+// expands it when something in it is already collapsed.
+func (a *TexelTerm) toggleFoldsInView() {
+	a.mu.Lock()
+	b := a.foldBlockInViewLocked()
+	if b != nil {
+		b.SetCollapsed(!b.AnyCollapsed())
+		a.showFoldBlockLocked(b)
+	}
+	a.mu.Unlock()
+	if b == nil {
+		if a.statusBar != nil {
+			a.statusBar.ShowError("No JSON or YAML in view")
+		}
+		return
+	}
+	a.requestRefresh()
+}
+
+// foldBlockInViewLocked finds the newest fold block overlapping the
+// rendered rows. Caller holds a.mu.
+func (a *TexelTerm) foldBlockInViewLocked() *txfmt.FoldBlock {
+	if a.vterm == nil || a.vterm.InAltScreen() {
+		return nil
+	}
+	lo, hi := int64(-1), int64(-1)
+	for _, gi := range a.lastRowGlobalIdx {
+		if gi < 0 {
+			continue
+		}
+		if lo < 0 || gi < lo {
+			lo = gi
+		}
+		hi = max(hi, gi)
+	}
+	if lo < 0 {
+		return nil
+	}
+	// A collapsed last row stands for the lines it hides.
+	if _, end, ok := a.vterm.CollapsedAt(hi); ok {
+		hi = end
+	}
+
+	var best *txfmt.FoldBlock
+	pick := func(blocks []*txfmt.FoldBlock) {
+		for _, b := range blocks {
+			if b.End >= lo && b.Start <= hi && (best == nil || b.Start > best.Start) {
+				best = b
+			}
+		}
+	}
+	pick(a.foldBlocksLocked())
+	if best == nil {
+		pick(a.detectFoldsLocked(lo-maxFoldScan, hi))
+	}
+	return best
+}
+
+// showFoldBlocksLocked hands the fold state of blocks txfmt recorded since
+// the last call to the view, so blocks that start out collapsed show
+// collapsed. Caller holds a.mu.
+func (a *TexelTerm) showFoldBlocksLocked() {
+	if a.vterm == nil || a.vterm.InAltScreen() {
+		return
+	}
+	blocks := a.foldBlocksLocked()
+	shown := make(map[*txfmt.FoldBlock]bool, len(blocks))
+	for _, b := range blocks {
+		if !a.folds.shown[b] {
+			a.showFoldBlockLocked(b)
+		}
+		shown[b] = true
+	}
+	a.folds.shown = shown
+}
+
+// showFoldBlockLocked makes the view's collapses match b's fold tree:
+// each collapsed fold that is not inside another collapsed fold hides its
+// lines behind its summary. Caller holds a.mu.
+func (a *TexelTerm) showFoldBlockLocked(b *txfmt.FoldBlock) {
+	var walk func(folds []*txfmt.Fold)
+	walk = func(folds []*txfmt.Fold) {
+		for _, f := range folds {
+			if start, end, ok := a.vterm.CollapsedAt(f.Start); ok && start == f.Start && end == f.End {
+				a.vterm.ExpandLines(f.Start)
+			}
+			if f.Collapsed {
+				// Replaces any collapse of a nested fold.
+				a.vterm.CollapseLines(f.Start, f.End, a.foldSummaryLocked(f))
+				continue
+			}
+			walk(f.Children)
+		}
+	}
+	walk(b.Folds)
+}
+
+// foldSummaryLocked draws a collapsed fold's header: the line up to the
+// fold's column, then the bold marker and dim count. Caller holds a.mu.
+func (a *TexelTerm) foldSummaryLocked(f *txfmt.Fold) []parser.Cell {
+	var cells []parser.Cell
+	if ll := a.vterm.GetLogicalLine(f.Start); ll != nil {
+		cells = append(cells, ll.Cells[:min(f.Col, len(ll.Cells))]...)
+	}
+	for i := range cells {
+		if cells[i].Rune == 0 {
+			cells[i].Rune = ' '
+		}
+		cells[i].Wrapped = false
+	}
+	for i, r := range []rune(f.Summary()) {
+		attr := parser.AttrDim
+		if i < 3 {
+			attr = parser.AttrBold
+		}
+		cells = append(cells, parser.Cell{Rune: r, FG: parser.DefaultFG, BG: parser.DefaultBG, Attr: attr})
+	}
+	return cells
+}
@@ -0,0 +1,120 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/fold_view_test.go
+// Summary: Exercises collapsing structured output, toggling folds by click,
+// copying across a collapsed fold and folds next to wrapped lines.
+
+package texelterm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestFoldsCollapseToggleAndCopy(t *testing.T) {
+	tt := NewTestTerm(40, 12)
+	a := tt.term
+	// Enough output above to fill the screen, as in a working session.
+	tt.Write([]byte(strings.Repeat("filler\r\n", 20) + "$ cat pod.json\r\n{\r\n  \"kind\": \"Pod\",\r\n  \"spec\": {\r\n    \"a\": 1,\r\n    \"b\": 2\r\n  }\r\n}\r\n$ "))
+	screen := func() []string {
+		var out []string
+		for _, row := range a.Render() {
+			var b strings.Builder
+			for _, c := range row {
+				b.WriteRune(max(c.Ch, ' '))
+			}
+			out = append(out, strings.TrimRight(b.String(), " "))
+		}
+		return out
+	}
+	find := func(rows []string, s string) int {
+		for y, r := range rows {
+			if strings.Contains(r, s) {
+				return y
+			}
+		}
+		return -1
+	}
+	screen()
+
+	a.toggleFoldsInView()
+	rows := screen()
+	if find(rows, "{…} 2 keys") < 0 || find(rows, `"spec"`) >= 0 {
+		t.Fatalf("root not collapsed:\n%s", strings.Join(rows, "\n"))
+	}
+	if y := find(rows, "$ cat pod.json"); y < 0 || !strings.HasPrefix(rows[y+2], "$") {
+		t.Fatalf("prompt should follow the summary:\n%s", strings.Join(rows, "\n"))
+	}
+
+	// A selection from the command line across the summary copies the
+	// unfolded text.
+	y := find(rows, "{…}")
+	startLine, _, _, ok := a.vterm.ViewportToContent(y-1, 0)
+	endLine, _, _, ok2 := a.vterm.ViewportToContent(y+1, 0)
+	if !ok || !ok2 {
+		t.Fatal("rows around the summary not mapped")
+	}
+	text := a.vterm.GetContentText(startLine, 0, endLine, 0)
+	if !strings.Contains(text, `"b": 2`) || !strings.Contains(text, "}") {
+		t.Fatalf("copied %q", text)
+	}
+
+	// Clicking the summary expands the root; clicking "spec"'s bracket
+	// then collapses just that object.
+	click := func(x, y int) {
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone))
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone))
+	}
+	click(1, y)
+	rows = screen()
+	spec := find(rows, `"spec": {`)
+	if spec < 0 {
+		t.Fatalf("root not expanded:\n%s", strings.Join(rows, "\n"))
+	}
+	click(strings.Index(rows[spec], "{"), spec)
+	rows = screen()
+	if find(rows, `"spec": {…} 2 keys`) < 0 || find(rows, `"a": 1`) >= 0 || find(rows, `"kind"`) < 0 {
+		t.Fatalf("spec not collapsed:\n%s", strings.Join(rows, "\n"))
+	}
+}
+
+func TestFoldsKeepWrappedLinesWhole(t *testing.T) {
+	tt := NewTestTerm(20, 10)
+	a := tt.term
+	long := `"name": "a-value-that-wraps-twice-over",`
+	tt.Write([]byte("$ cat x.json\r\n{\r\n  " + long + "\r\n  \"spec\": {\r\n    \"a\": 1\r\n  }\r\n}\r\n$ "))
+	screen := func() string {
+		var out []string
+		for _, row := range a.Render() {
+			var b strings.Builder
+			for _, c := range row {
+				b.WriteRune(max(c.Ch, ' '))
+			}
+			out = append(out, strings.TrimRight(b.String(), " "))
+		}
+		return strings.Join(out, "\n")
+	}
+	screen()
+	y := -1
+	for i, gi := range a.lastRowGlobalIdx {
+		if ll := a.vterm.GetLogicalLine(gi); gi >= 0 && ll != nil && strings.Contains(foldLineText(ll.Cells), `"spec"`) {
+			y = i
+			break
+		}
+	}
+	if y < 0 {
+		t.Fatalf("spec line not rendered:\n%s", screen())
+	}
+	a.HandleMouse(tcell.NewEventMouse(10, y, tcell.Button1, tcell.ModNone))
+	a.HandleMouse(tcell.NewEventMouse(10, y, tcell.ButtonNone, tcell.ModNone))
+	got := screen()
+	if !strings.Contains(got, "{…} 1 key") || strings.Contains(got, `"a": 1`) {
+		t.Fatalf("spec not collapsed:\n%s", got)
+	}
+	if !strings.Contains(strings.ReplaceAll(got, "\n", ""), long) {
+		t.Fatalf("wrapped line above the fold lost its continuation rows:\n%s", got)
+	}
+}
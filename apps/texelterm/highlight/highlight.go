@@ -0,0 +1,333 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// Package highlight is an inline output transformer for texelterm that
+// styles text matching user-defined rules: a regex, an optional command
+// glob, colors and attributes, and an optional action run by Ctrl+click
+// on a match. Rules come from the "transformers.highlight" config section.
+// It self-registers via init() into the transformer registry.
+package highlight
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/apps/texelterm/transformer"
+	"github.com/framegrace/texelation/apps/texelterm/txfmt"
+)
+
+func init() {
+	transformer.Register("highlight", func(cfg transformer.Config) (transformer.Transformer, error) {
+		rules, err := ParseRules(cfg["rules"])
+		if err != nil {
+			return nil, err
+		}
+		return New(rules), nil
+	})
+}
+
+// Compile-time interface verification.
+var _ transformer.Transformer = (*Highlighter)(nil)
+
+// maxCommandSpans bounds how many command output ranges are remembered
+// for resolving Ctrl+click on older lines.
+const maxCommandSpans = 256
+
+// Action is what Ctrl+click on a match does.
+type Action string
+
+const (
+	ActionNone Action = ""
+	ActionOpen Action = "open" // open the rule's URL template
+	ActionCopy Action = "copy" // copy the matched text
+)
+
+// Rule is one highlight rule.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Command string // glob matched against the command line or its name; "" matches all
+	FG, BG  *parser.Color
+	Attr    parser.Attribute
+	Action  Action
+	URL     string // for ActionOpen; {match} and {1}..{9} are replaced
+}
+
+// MatchesCommand reports whether the rule applies to output of cmd. A
+// rule with a command glob never applies when the command is unknown.
+func (r *Rule) MatchesCommand(cmd string) bool {
+	return transformer.MatchCommand(r.Command, cmd)
+}
+
+func (r *Rule) style(c *parser.Cell) {
+	if r.FG != nil {
+		c.FG = *r.FG
+	}
+	if r.BG != nil {
+		c.BG = *r.BG
+	}
+	c.Attr |= r.Attr
+}
+
+// ParseRules reads rules from config: a list of objects with "id" (the
+// rule's name), "enabled", "pattern", "command", "fg", "bg", "attrs"
+// (comma separated), "action" and "url". Disabled rules are skipped.
+func ParseRules(raw interface{}) ([]*Rule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("highlight: rules must be a list, got %T", raw)
+	}
+	var rules []*Rule
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("highlight: rule %d is not an object", i+1)
+		}
+		if enabled, ok := m["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		str := func(key string) string {
+			s, _ := m[key].(string)
+			return strings.TrimSpace(s)
+		}
+		r := &Rule{Name: str("id"), Command: str("command"), Action: Action(str("action")), URL: str("url")}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		pattern := str("pattern")
+		if pattern == "" {
+			return nil, fmt.Errorf("highlight: %s has no pattern", r.Name)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("highlight: %s: %w", r.Name, err)
+		}
+		r.Pattern = re
+		if r.FG, err = parseColor(str("fg")); err != nil {
+			return nil, fmt.Errorf("highlight: %s: fg: %w", r.Name, err)
+		}
+		if r.BG, err = parseColor(str("bg")); err != nil {
+			return nil, fmt.Errorf("highlight: %s: bg: %w", r.Name, err)
+		}
+		if r.Attr, err = parseAttrs(str("attrs")); err != nil {
+			return nil, fmt.Errorf("highlight: %s: %w", r.Name, err)
+		}
+		switch r.Action {
+		case ActionNone, ActionCopy:
+		case ActionOpen:
+			if r.URL == "" {
+				return nil, fmt.Errorf("highlight: %s: open action needs a url", r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("highlight: %s: unknown action %q", r.Name, r.Action)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// parseColor reads "#rrggbb", a color name (optionally "bright_"), or a
+// 256-color palette index. An empty string means "leave as is".
+func parseColor(s string) (*parser.Color, error) {
+	s = strings.ToLower(s)
+	if s == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "#") && len(s) == 7 {
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad color %q", s)
+		}
+		return &parser.Color{Mode: parser.ColorModeRGB, R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return &parser.Color{Mode: parser.ColorMode256, Value: uint8(n)}, nil
+	}
+	name, bright := strings.CutPrefix(s, "bright_")
+	for i, c := range colorNames {
+		if c != name {
+			continue
+		}
+		if bright {
+			return &parser.Color{Mode: parser.ColorMode256, Value: uint8(i + 8)}, nil
+		}
+		return &parser.Color{Mode: parser.ColorModeStandard, Value: uint8(i)}, nil
+	}
+	return nil, fmt.Errorf("bad color %q", s)
+}
+
+// parseAttrs reads a comma-separated list like "bold,underline".
+func parseAttrs(s string) (parser.Attribute, error) {
+	var attr parser.Attribute
+	for _, name := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case "bold":
+			attr |= parser.AttrBold
+		case "underline":
+			attr |= parser.AttrUnderline
+		case "reverse":
+			attr |= parser.AttrReverse
+		case "dim":
+			attr |= parser.AttrDim
+		case "italic":
+			attr |= parser.AttrItalic
+		default:
+			return 0, fmt.Errorf("unknown attribute %q", name)
+		}
+	}
+	return attr, nil
+}
+
+// commandSpan records which command produced a range of lines.
+type commandSpan struct {
+	start, end int64
+	command    string
+}
+
+// Highlighter is the highlight transformer.
+type Highlighter struct {
+	rules               []*Rule
+	wasCommand          bool
+	hasShellIntegration bool
+	command             string
+
+	mu    sync.Mutex
+	spans []commandSpan // guarded by mu, oldest first
+}
+
+// New creates a Highlighter applying rules in order; later rules win
+// where matches overlap.
+func New(rules []*Rule) *Highlighter {
+	return &Highlighter{rules: rules}
+}
+
+// Rules returns the configured rules.
+func (h *Highlighter) Rules() []*Rule { return h.rules }
+
+// NotifyPromptStart records that shell integration is active.
+func (h *Highlighter) NotifyPromptStart() {
+	h.hasShellIntegration = true
+}
+
+// NotifyCommandStart records the command whose output follows.
+func (h *Highlighter) NotifyCommandStart(cmd string) {
+	h.command = cmd
+}
+
+// HandleLine styles matches in a command output line.
+func (h *Highlighter) HandleLine(lineIdx int64, line *parser.LogicalLine, isCommand bool) {
+	effectiveIsCommand := isCommand || !h.hasShellIntegration
+	if h.wasCommand && !effectiveIsCommand {
+		h.command = ""
+	}
+	h.wasCommand = effectiveIsCommand
+	if !effectiveIsCommand || len(line.Cells) == 0 {
+		return
+	}
+	h.recordLine(lineIdx)
+
+	var cells []parser.Cell
+	plain, textToCell := txfmt.BuildPlainTextMap(line.Cells)
+	text := string(plain)
+	for _, r := range h.rules {
+		if !r.MatchesCommand(h.command) || !r.Pattern.MatchString(text) {
+			continue
+		}
+		if cells == nil {
+			if line.Overlay == nil {
+				line.Overlay = make([]parser.Cell, len(line.Cells))
+				copy(line.Overlay, line.Cells)
+				line.OverlayWidth = len(line.Cells)
+			}
+			cells = line.Overlay
+			// An earlier transformer may have reshaped the overlay; match
+			// against what is shown.
+			plain, textToCell = txfmt.BuildPlainTextMap(cells)
+			text = string(plain)
+		}
+		txfmt.ApplyRegexFunc(cells, text, textToCell, r.Pattern, r.style)
+	}
+}
+
+// recordLine extends the current command's span to lineIdx.
+func (h *Highlighter) recordLine(lineIdx int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n := len(h.spans); n > 0 && h.spans[n-1].command == h.command && h.spans[n-1].end < lineIdx {
+		h.spans[n-1].end = lineIdx
+		return
+	}
+	if len(h.spans) == maxCommandSpans {
+		h.spans = append(h.spans[:0], h.spans[1:]...)
+	}
+	h.spans = append(h.spans, commandSpan{lineIdx, lineIdx, h.command})
+}
+
+// CommandAt returns the command that produced lineIdx, or "" if unknown.
+func (h *Highlighter) CommandAt(lineIdx int64) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.spans) - 1; i >= 0; i-- {
+		if s := h.spans[i]; lineIdx >= s.start && lineIdx <= s.end {
+			return s.command
+		}
+	}
+	return ""
+}
+
+// Match is a rule match under the pointer.
+type Match struct {
+	Rule   *Rule
+	Text   string
+	Groups []string // submatches, Groups[0] is the whole match
+}
+
+// URL expands the rule's URL template for this match.
+func (m *Match) URL() string {
+	out := strings.ReplaceAll(m.Rule.URL, "{match}", url.PathEscape(m.Text))
+	for i := 1; i < len(m.Groups) && i <= 9; i++ {
+		out = strings.ReplaceAll(out, "{"+strconv.Itoa(i)+"}", url.PathEscape(m.Groups[i]))
+	}
+	return out
+}
+
+// ActionAt finds a match with an action covering column col of line
+// lineIdx, whose text is given with one rune per cell. The last matching
+// rule wins, as it does for styling.
+func (h *Highlighter) ActionAt(lineIdx int64, text string, col int) (*Match, bool) {
+	cmd := h.CommandAt(lineIdx)
+	var found *Match
+	for _, r := range h.rules {
+		if r.Action == ActionNone || !r.MatchesCommand(cmd) {
+			continue
+		}
+		for _, loc := range r.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start := len([]rune(text[:loc[0]]))
+			end := start + len([]rune(text[loc[0]:loc[1]]))
+			if col < start || col >= end {
+				continue
+			}
+			m := &Match{Rule: r, Text: text[loc[0]:loc[1]]}
+			for g := 0; g < len(loc); g += 2 {
+				if loc[g] < 0 {
+					m.Groups = append(m.Groups, "")
+				} else {
+					m.Groups = append(m.Groups, text[loc[g]:loc[g+1]])
+				}
+			}
+			found = m
+		}
+	}
+	return found, found != nil
+}
@@ -0,0 +1,149 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package highlight
+
+import (
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/apps/texelterm/transformer"
+)
+
+func makeLine(s string) *parser.LogicalLine {
+	cells := make([]parser.Cell, len([]rune(s)))
+	for i, r := range []rune(s) {
+		cells[i] = parser.Cell{Rune: r, FG: parser.DefaultFG, BG: parser.DefaultBG}
+	}
+	return &parser.LogicalLine{Cells: cells}
+}
+
+func mustRules(t *testing.T, raw ...map[string]interface{}) []*Rule {
+	t.Helper()
+	list := make([]interface{}, len(raw))
+	for i, r := range raw {
+		list[i] = r
+	}
+	rules, err := ParseRules(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestParseRules(t *testing.T) {
+	rules := mustRules(t,
+		map[string]interface{}{"id": "err", "pattern": `ERROR`, "fg": "#ff0000", "bg": "bright_black", "attrs": "bold, underline"},
+		map[string]interface{}{"id": "off", "enabled": false, "pattern": `(`},
+		map[string]interface{}{"id": "jira", "pattern": `[A-Z]+-\d+`, "fg": "cyan", "action": "open", "url": "https://jira/{match}"},
+	)
+	if len(rules) != 2 {
+		t.Fatalf("rules = %d, want 2 (disabled rules are skipped)", len(rules))
+	}
+	r := rules[0]
+	if r.FG == nil || r.FG.Mode != parser.ColorModeRGB || r.FG.R != 0xff || r.FG.G != 0 {
+		t.Errorf("fg = %+v", r.FG)
+	}
+	if r.BG == nil || r.BG.Mode != parser.ColorMode256 || r.BG.Value != 8 {
+		t.Errorf("bg = %+v", r.BG)
+	}
+	if r.Attr != parser.AttrBold|parser.AttrUnderline {
+		t.Errorf("attrs = %v", r.Attr)
+	}
+	if rules[1].FG.Mode != parser.ColorModeStandard || rules[1].FG.Value != 6 || rules[1].Action != ActionOpen {
+		t.Errorf("jira = %+v", rules[1])
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"id": "x"},
+		{"id": "x", "pattern": `(`},
+		{"id": "x", "pattern": "a", "fg": "puce"},
+		{"id": "x", "pattern": "a", "attrs": "blink"},
+		{"id": "x", "pattern": "a", "action": "open"},
+		{"id": "x", "pattern": "a", "action": "launch"},
+	} {
+		if _, err := ParseRules([]interface{}{bad}); err == nil {
+			t.Errorf("ParseRules(%v) should fail", bad)
+		}
+	}
+}
+
+func TestHighlightStylesCommandOutput(t *testing.T) {
+	h := New(mustRules(t,
+		map[string]interface{}{"id": "err", "pattern": `ERROR`, "fg": "red", "attrs": "bold"},
+		map[string]interface{}{"id": "go", "pattern": `FAIL`, "command": "go", "bg": "yellow"},
+	))
+	h.NotifyPromptStart()
+
+	prompt := makeLine("$ ERROR")
+	h.HandleLine(0, prompt, false)
+	if prompt.Overlay != nil {
+		t.Error("prompt lines should not be highlighted")
+	}
+
+	h.NotifyCommandStart("make test")
+	line := makeLine("FAIL: ERROR here")
+	h.HandleLine(1, line, true)
+	if line.Overlay == nil {
+		t.Fatal("no overlay for a matching line")
+	}
+	if c := line.Overlay[6]; c.FG.Mode != parser.ColorModeStandard || c.FG.Value != 1 || c.Attr&parser.AttrBold == 0 {
+		t.Errorf("ERROR cell = %+v", c)
+	}
+	if c := line.Overlay[0]; c.BG != parser.DefaultBG {
+		t.Error("the go rule should not apply to make's output")
+	}
+	if line.Cells[6].FG != parser.DefaultFG {
+		t.Error("the original cells must be left alone")
+	}
+
+	h.HandleLine(2, makeLine("$ "), false)
+	h.NotifyCommandStart("/usr/local/go/bin/go test ./...")
+	line = makeLine("FAIL x")
+	h.HandleLine(3, line, true)
+	if line.Overlay == nil || line.Overlay[0].BG.Value != 3 {
+		t.Errorf("the go rule should match on the command's base name: %+v", line.Overlay)
+	}
+	if line.Overlay[5].BG != parser.DefaultBG {
+		t.Error("only the match should be styled")
+	}
+}
+
+func TestHighlightActionAt(t *testing.T) {
+	h := New(mustRules(t,
+		map[string]interface{}{"id": "jira", "pattern": `([A-Z]+)-(\d+)`, "action": "open", "url": "https://t/{1}/{2}?q={match}"},
+		map[string]interface{}{"id": "sha", "pattern": `\b[0-9a-f]{7}\b`, "command": "git*", "action": "copy"},
+		map[string]interface{}{"id": "plain", "pattern": `done`},
+	))
+	h.NotifyPromptStart()
+	h.NotifyCommandStart("git log")
+	h.HandleLine(5, makeLine("abc1234 fix ABC-42 done"), true)
+
+	text := "abc1234 fix ABC-42 done"
+	if m, ok := h.ActionAt(5, text, 2); !ok || m.Rule.Name != "sha" || m.Text != "abc1234" {
+		t.Errorf("sha match = %+v", m)
+	}
+	m, ok := h.ActionAt(5, text, 15)
+	if !ok || m.Rule.Name != "jira" {
+		t.Fatalf("jira match = %+v", m)
+	}
+	if got := m.URL(); got != "https://t/ABC/42?q=ABC-42" {
+		t.Errorf("url = %q", got)
+	}
+	if _, ok := h.ActionAt(5, text, 20); ok {
+		t.Error("rules without an action should not match")
+	}
+	if _, ok := h.ActionAt(99, text, 2); ok {
+		t.Error("command-specific rules need a known command")
+	}
+}
+
+func TestHighlightRegistered(t *testing.T) {
+	factory, ok := transformer.Lookup("highlight")
+	if !ok {
+		t.Fatal("highlight should be registered via init()")
+	}
+	if _, err := factory(transformer.Config{"rules": []interface{}{map[string]interface{}{"id": "x"}}}); err == nil {
+		t.Error("a rule without a pattern should fail the factory")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/highlight_click.go
+// Summary: Runs the action of a highlight rule when its match is
+// Ctrl+clicked: open a URL built from the match, or copy the match.
+// Usage: Rules are configured in the "transformers.highlight" section;
+//   see apps/texelterm/highlight.
+
+package texelterm
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/framegrace/texelation/apps/texelterm/highlight"
+	"github.com/gdamore/tcell/v2"
+)
+
+// openURL hands a URL to the desktop's opener. Tests replace it.
+var openURL = func(url string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	path, err := exec.LookPath(opener)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, url)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// handleHighlightClick runs the action of the highlight match under a
+// Ctrl+left press and reports whether there was one.
+func (a *TexelTerm) handleHighlightClick(x, y int, press bool, mods tcell.ModMask) bool {
+	if !press || mods != tcell.ModCtrl {
+		return false
+	}
+	a.mu.Lock()
+	m := a.highlightMatchAtLocked(x, y)
+	a.mu.Unlock()
+	if m == nil {
+		return false
+	}
+
+	switch m.Rule.Action {
+	case highlight.ActionOpen:
+		url := m.URL()
+		if err := openURL(url); err != nil {
+			if a.statusBar != nil {
+				a.statusBar.ShowError(fmt.Sprintf("Open %s: %v", url, err))
+			}
+			return true
+		}
+		if a.statusBar != nil {
+			a.statusBar.ShowSuccess("Opened " + url)
+		}
+	case highlight.ActionCopy:
+		a.SetClipboard("text/plain", []byte(m.Text))
+		if a.statusBar != nil {
+			a.statusBar.ShowSuccess(fmt.Sprintf("Copied %q", m.Text))
+		}
+	}
+	return true
+}
+
+// highlightMatchAtLocked returns the highlight match with an action under
+// the rendered cell x, y, if any. Caller holds a.mu.
+func (a *TexelTerm) highlightMatchAtLocked(x, y int) *highlight.Match {
+	if a.pipeline == nil || a.vterm == nil || a.vterm.InAltScreen() {
+		return nil
+	}
+	var hls []*highlight.Highlighter
+	for _, t := range a.pipeline.Transformers() {
+		if h, ok := t.(*highlight.Highlighter); ok {
+			hls = append(hls, h)
+		}
+	}
+	if len(hls) == 0 {
+		return nil
+	}
+
+	grid := NewVTermGridAdapter(a.vterm)
+	line, col, _, ok := grid.ViewportToContent(y, x)
+	if !ok || line < 0 {
+		return nil
+	}
+	ll := a.vterm.GetLogicalLine(line)
+	if ll == nil {
+		return nil
+	}
+	text := foldLineText(ll.Cells)
+	for _, h := range hls {
+		if m, ok := h.ActionAt(line, text, col); ok {
+			return m
+		}
+	}
+	return nil
+}
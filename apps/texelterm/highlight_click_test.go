@@ -0,0 +1,95 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/highlight_click_test.go
+// Summary: Exercises Ctrl+click actions on highlight rule matches.
+
+package texelterm
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/transformer"
+	"github.com/framegrace/texelation/config"
+	"github.com/gdamore/tcell/v2"
+)
+
+type recordingClipboard struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (c *recordingClipboard) SetClipboard(_ string, data []byte) {
+	c.mu.Lock()
+	c.data = append([]byte(nil), data...)
+	c.mu.Unlock()
+}
+
+func (c *recordingClipboard) GetClipboard() (string, []byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return "text/plain", c.data, c.data != nil
+}
+
+func TestHighlightCtrlClickActions(t *testing.T) {
+	tt := NewTestTerm(40, 6)
+	a := tt.term
+	a.pipeline = transformer.BuildPipeline(config.Config{
+		"transformers": map[string]interface{}{
+			"pipeline": []interface{}{map[string]interface{}{"id": "highlight"}},
+		},
+		"transformers.highlight": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{"id": "ticket", "pattern": `#(\d+)`, "action": "open", "url": "https://issues/{1}"},
+				map[string]interface{}{"id": "sha", "pattern": `\b[0-9a-f]{7}\b`, "action": "copy"},
+			},
+		},
+	})
+	if a.pipeline == nil {
+		t.Fatal("no pipeline")
+	}
+	clip := &recordingClipboard{}
+	a.SetClipboardService(clip)
+	var opened []string
+	defer func(orig func(string) error) { openURL = orig }(openURL)
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	tt.Write([]byte("$ git log\r\nabc1234 fix #42\r\n$ "))
+	var row string
+	y := -1
+	for i, cells := range a.Render() {
+		var b strings.Builder
+		for _, c := range cells {
+			b.WriteRune(max(c.Ch, ' '))
+		}
+		if strings.Contains(b.String(), "abc1234") {
+			row, y = b.String(), i
+		}
+	}
+	if y < 0 {
+		t.Fatal("output row not rendered")
+	}
+	click := func(x int, mods tcell.ModMask) {
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.Button1, mods))
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.ButtonNone, mods))
+	}
+
+	click(strings.Index(row, "#42")+1, tcell.ModCtrl)
+	if len(opened) != 1 || opened[0] != "https://issues/42" {
+		t.Fatalf("opened %v", opened)
+	}
+	click(2, tcell.ModCtrl)
+	if _, data, _ := clip.GetClipboard(); string(data) != "abc1234" {
+		t.Fatalf("clipboard = %q", data)
+	}
+	click(strings.Index(row, "fix"), tcell.ModCtrl)
+	click(strings.Index(row, "#42"), tcell.ModNone)
+	if len(opened) != 1 {
+		t.Errorf("only a Ctrl+click on a match should act: %v", opened)
+	}
+}
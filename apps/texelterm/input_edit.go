@@ -0,0 +1,277 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/input_edit.go
+// Summary: Mouse editing of the shell's command line. A click inside the
+// input moves the shell cursor there, a double-click selects a word of
+// the input, and Backspace or Delete removes a selected part of it.
+// Usage: HandleMouse calls noteInputClick on every event; HandleKey calls
+//   deleteInputSelection before sending a key to the shell. Enabled by
+//   the "click_to_move_cursor" option of the texelterm.selection section.
+// Notes: The input region comes from OSC 133;B, so this needs shell
+//   integration. Edits are sent as the same arrow and backspace keys a
+//   user would type, counted in characters rather than cells so wide
+//   characters and wrapped lines move the cursor correctly.
+
+package texelterm
+
+import (
+	"bytes"
+	"log"
+	"sort"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxInputRows bounds how many wrapped rows of input are read.
+const maxInputRows = 500
+
+// inputPos is a cell in the scrollback.
+type inputPos struct {
+	line int64
+	col  int
+}
+
+func (p inputPos) before(q inputPos) bool {
+	return p.line < q.line || (p.line == q.line && p.col < q.col)
+}
+
+// inputLayout maps the shell's editable input to characters.
+type inputLayout struct {
+	start  inputPos
+	last   int64      // last row of the input
+	chars  []inputPos // first cell of each character, in order
+	cursor int        // index of the character under the shell cursor
+}
+
+// inputLayoutOf reads the input region of v. ok is false unless the shell
+// is waiting for input and the view is at the live edge. Input spanning
+// several rows is only understood when the rows wrap into each other.
+func inputLayoutOf(v *parser.VTerm) (*inputLayout, bool) {
+	if v == nil || v.InAltScreen() || !v.InputActive || v.CommandActive ||
+		v.InputStartGlobalLine < 0 || v.ScrollOffset() != 0 {
+		return nil, false
+	}
+	l := &inputLayout{start: inputPos{v.InputStartGlobalLine, v.InputStartCol}}
+	curLine, curCol := v.CursorGlobalIdx()
+	cursor := inputPos{curLine, curCol}
+	if cursor.before(l.start) {
+		return nil, false
+	}
+	for gi := l.start.line; ; gi++ {
+		if gi-l.start.line >= maxInputRows {
+			return nil, false
+		}
+		var cells []parser.Cell
+		if ll := v.GetLogicalLine(gi); ll != nil {
+			cells = ll.Cells
+		}
+		end := len(cells)
+		wrapped := end > 0 && cells[end-1].Wrapped
+		if !wrapped && gi < curLine {
+			return nil, false // multi-line input
+		}
+		col := 0
+		if gi == l.start.line {
+			col = l.start.col
+		}
+		if !wrapped {
+			for end > col && isBlankCell(cells[end-1]) {
+				end--
+			}
+			if gi == curLine && curCol > end {
+				end = curCol // typed trailing spaces
+			}
+		}
+		for ; col < end; col++ {
+			if col > 0 && col <= len(cells) && cells[col-1].Wide {
+				continue // second half of a wide character
+			}
+			l.chars = append(l.chars, inputPos{gi, col})
+		}
+		l.last = gi
+		if !wrapped {
+			break
+		}
+	}
+	l.cursor = l.index(cursor)
+	return l, true
+}
+
+func isBlankCell(c parser.Cell) bool {
+	return c.Rune == 0 || c.Rune == ' '
+}
+
+// index returns how many input characters start before p, i.e. the
+// character index the shell cursor has when it sits on p.
+func (l *inputLayout) index(p inputPos) int {
+	return sort.Search(len(l.chars), func(i int) bool { return !l.chars[i].before(p) })
+}
+
+// contains reports whether p lies on the input's rows at or after its
+// start.
+func (l *inputLayout) contains(p inputPos) bool {
+	return !p.before(l.start) && p.line <= l.last
+}
+
+// pos returns the cell of character i; i == len(chars) is just past the
+// last character.
+func (l *inputLayout) pos(i int) inputPos {
+	if i < len(l.chars) {
+		return l.chars[i]
+	}
+	if len(l.chars) == 0 {
+		return l.start
+	}
+	p := l.chars[len(l.chars)-1]
+	return inputPos{p.line, p.col + 1}
+}
+
+// wordAt returns the character range [lo, hi) of the word around
+// character i, using the same word characters as selection.
+func (l *inputLayout) wordAt(v *parser.VTerm, i int) (lo, hi int, ok bool) {
+	runes := make([]rune, len(l.chars))
+	var line int64 = -1
+	var cells []parser.Cell
+	for j, p := range l.chars {
+		if p.line != line {
+			line, cells = p.line, nil
+			if ll := v.GetLogicalLine(line); ll != nil {
+				cells = ll.Cells
+			}
+		}
+		if p.col < len(cells) {
+			runes[j] = cells[p.col].Rune
+		}
+	}
+	if i < 0 || i >= len(runes) || !isWordChar(runes[i]) {
+		return 0, 0, false
+	}
+	lo, hi = i, i+1
+	for lo > 0 && isWordChar(runes[lo-1]) {
+		lo--
+	}
+	for hi < len(runes) && isWordChar(runes[hi]) {
+		hi++
+	}
+	return lo, hi, true
+}
+
+// cursorMoveKeys returns the arrow keys that move the shell cursor by
+// delta characters.
+func cursorMoveKeys(delta int, appMode bool) []byte {
+	key := If(appMode, "\x1bOC", "\x1b[C")
+	if delta < 0 {
+		key = If(appMode, "\x1bOD", "\x1b[D")
+		delta = -delta
+	}
+	return bytes.Repeat([]byte(key), delta)
+}
+
+// clickToMoveEnabled reports whether clicks may move the shell cursor.
+func (a *TexelTerm) clickToMoveEnabled() bool {
+	return a.paneConfig().GetBool("texelterm.selection", "click_to_move_cursor", true)
+}
+
+// noteInputClick tracks left-button presses and moves the shell cursor
+// when a press and release land on the same cell of the input. It never
+// consumes the event, so the click still clears or starts a selection.
+func (a *TexelTerm) noteInputClick(x, y int, press, release bool, mods tcell.ModMask) {
+	if press {
+		a.inputClick = inputClick{x: x, y: y, pending: mods == tcell.ModNone}
+		return
+	}
+	if !release {
+		return
+	}
+	c := a.inputClick
+	a.inputClick = inputClick{}
+	if !c.pending || c.x != x || c.y != y || a.pty == nil || !a.clickToMoveEnabled() {
+		return
+	}
+
+	a.mu.Lock()
+	var keys []byte
+	if l, ok := inputLayoutOf(a.vterm); ok {
+		grid := NewVTermGridAdapter(a.vterm)
+		if line, col, _, ok := grid.ViewportToContent(y, x); ok && l.contains(inputPos{line, col}) {
+			keys = cursorMoveKeys(l.index(inputPos{line, col})-l.cursor, a.vterm.AppCursorKeys())
+		}
+	}
+	a.mu.Unlock()
+	a.writeInputKeys(keys)
+}
+
+// inputWordAt is the mouse coordinator's WordSelector: inside the input
+// a word may wrap across rows and never reaches into the prompt. It runs
+// under the coordinator's lock, so like the coordinator's other VTerm
+// reads it does not take a.mu.
+func (a *TexelTerm) inputWordAt(line int64, offset int) (int64, int, int64, int, bool) {
+	l, ok := inputLayoutOf(a.vterm)
+	if !ok || !l.contains(inputPos{line, offset}) {
+		return 0, 0, 0, 0, false
+	}
+	lo, hi, ok := l.wordAt(a.vterm, l.index(inputPos{line, offset + 1})-1)
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	start, end := l.pos(lo), l.pos(hi-1)
+	return start.line, start.col, end.line, end.col + 1, true
+}
+
+// deleteInputSelection handles Backspace and Delete while a selection
+// inside the input is shown: the cursor moves to the end of the selection
+// and one backspace per selected character is sent. A selection that
+// starts in the prompt is trimmed to the input. Reports whether it
+// handled the key.
+func (a *TexelTerm) deleteInputSelection(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+	default:
+		return false
+	}
+	if ev.Modifiers() != tcell.ModNone || a.pty == nil || a.mouseCoordinator == nil ||
+		!a.mouseCoordinator.IsSelectionRendered() || !a.clickToMoveEnabled() {
+		return false
+	}
+	startLine, startOff, endLine, endOff, ok := a.mouseCoordinator.SelectionRange()
+	if !ok {
+		return false
+	}
+
+	a.mu.Lock()
+	var keys []byte
+	if l, ok := inputLayoutOf(a.vterm); ok && l.contains(inputPos{endLine, endOff}) {
+		lo := 0
+		if s := (inputPos{startLine, startOff}); !s.before(l.start) {
+			lo = l.index(s)
+		}
+		if hi := l.index(inputPos{endLine, endOff}); hi > lo {
+			keys = append(cursorMoveKeys(hi-l.cursor, a.vterm.AppCursorKeys()), bytes.Repeat([]byte{0x7f}, hi-lo)...)
+		}
+	}
+	a.mu.Unlock()
+	if keys == nil {
+		return false
+	}
+	a.mouseCoordinator.ClearSelection()
+	a.writeInputKeys(keys)
+	return true
+}
+
+// writeInputKeys sends synthesised keys to the shell.
+func (a *TexelTerm) writeInputKeys(keys []byte) {
+	if len(keys) == 0 {
+		return
+	}
+	if _, err := a.pty.Write(keys); err != nil {
+		log.Printf("[TEXELTERM] Failed to write to PTY: %v", err)
+	}
+}
+
+// inputClick is a left-button press that may turn into a click-to-move.
+type inputClick struct {
+	x, y    int
+	pending bool
+}
@@ -0,0 +1,136 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/input_edit_test.go
+// Summary: Exercises click-to-move and mouse editing on the prompt line.
+
+package texelterm
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// capturePty points a's pty at a pipe; the returned func closes it and
+// returns what was written.
+func capturePty(t *testing.T, a *TexelTerm) func() string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.pty = w
+	return func() string {
+		w.Close()
+		b, _ := io.ReadAll(r)
+		r.Close()
+		a.pty = nil
+		return string(b)
+	}
+}
+
+func TestInputLayoutWrapsAndCountsCharacters(t *testing.T) {
+	tt := NewTestTerm(20, 6)
+	a := tt.term
+	// The 20-character command wraps after "wor".
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07echo hello big world"))
+	l, ok := inputLayoutOf(a.vterm)
+	if !ok {
+		t.Fatal("no input layout")
+	}
+	if len(l.chars) != 20 || l.cursor != 20 || l.last != l.start.line+1 {
+		t.Fatalf("layout = %+v", l)
+	}
+	if got := l.index(inputPos{l.start.line + 1, 1}); got != 19 {
+		t.Errorf("index on wrapped row = %d, want 19", got)
+	}
+
+	line, off, endLine, endOff, ok := a.inputWordAt(l.start.line, 18)
+	if !ok || line != l.start.line || off != 17 || endLine != line+1 || endOff != 2 {
+		t.Errorf("word = (%d,%d)-(%d,%d) %v", line, off, endLine, endOff, ok)
+	}
+	if _, _, _, _, ok := a.inputWordAt(l.start.line, 0); ok {
+		t.Error("the prompt is not part of the input")
+	}
+
+	tt = NewTestTerm(20, 6)
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07世界x"))
+	if l, ok = inputLayoutOf(tt.term.vterm); !ok || len(l.chars) != 3 || l.cursor != 3 {
+		t.Fatalf("wide layout = %+v", l)
+	}
+
+	tt.Write([]byte("\r\n\x1b]133;C\x07"))
+	if _, ok := inputLayoutOf(tt.term.vterm); ok {
+		t.Error("no input layout while a command runs")
+	}
+}
+
+func TestClickMovesShellCursor(t *testing.T) {
+	tt := NewTestTerm(20, 6)
+	a := tt.term
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07echo hello big world"))
+	y := int(a.vterm.InputStartGlobalLine)
+	click := func(x, y int) {
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone))
+		a.HandleMouse(tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone))
+	}
+
+	done := capturePty(t, a)
+	click(7, y)   // the "h" of hello
+	click(1, y+1) // the "d" of world, on the wrapped row
+	click(1, y)   // on the prompt: ignored
+	got := done()
+	if want := strings.Repeat("\x1b[D", 15) + "\x1b[D"; got != want {
+		t.Errorf("keys = %q, want %q", got, want)
+	}
+
+	// A drag is a selection, not a click.
+	done = capturePty(t, a)
+	a.HandleMouse(tcell.NewEventMouse(7, y, tcell.Button1, tcell.ModNone))
+	a.HandleMouse(tcell.NewEventMouse(9, y, tcell.Button1, tcell.ModNone))
+	a.HandleMouse(tcell.NewEventMouse(9, y, tcell.ButtonNone, tcell.ModNone))
+	if got := done(); got != "" {
+		t.Errorf("drag sent %q", got)
+	}
+}
+
+func TestDeleteInputSelection(t *testing.T) {
+	tt := NewTestTerm(20, 6)
+	a := tt.term
+	a.mouseCoordinator = NewMouseCoordinator(NewVTermAdapter(a.vterm),
+		NewVTermGridAdapter(a.vterm), a, AutoScrollConfig{})
+	a.mouseCoordinator.SetSize(20, 6)
+	a.mouseCoordinator.SetWordSelector(a.inputWordAt)
+	tt.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07echo hello big world"))
+	y := int(a.vterm.InputStartGlobalLine)
+
+	// Double-click "hello".
+	for i := 0; i < 2; i++ {
+		a.HandleMouse(tcell.NewEventMouse(8, y, tcell.Button1, tcell.ModNone))
+		a.HandleMouse(tcell.NewEventMouse(8, y, tcell.ButtonNone, tcell.ModNone))
+	}
+	if _, off, _, end, ok := a.mouseCoordinator.SelectionRange(); !ok || off != 7 || end != 12 {
+		t.Fatalf("selection = %d-%d %v", off, end, ok)
+	}
+
+	done := capturePty(t, a)
+	a.HandleKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	got := done()
+	if want := strings.Repeat("\x1b[D", 10) + strings.Repeat("\x7f", 5); got != want {
+		t.Errorf("keys = %q, want %q", got, want)
+	}
+	if a.mouseCoordinator.IsSelectionRendered() {
+		t.Error("the selection should be cleared")
+	}
+
+	// Without a selection Backspace is an ordinary key.
+	done = capturePty(t, a)
+	a.HandleKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if got := done(); got != "\x7f" {
+		t.Errorf("keys = %q", got)
+	}
+}
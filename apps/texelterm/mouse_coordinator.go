@@ -225,6 +225,23 @@ func (m *MouseCoordinator) HandleMouse(ev *tcell.EventMouse) bool {
 	return false
 }
 
+// SetWordSelector installs a WordSelector for double-click selection.
+func (m *MouseCoordinator) SetWordSelector(fn WordSelector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selectionMachine.SetWordSelector(fn)
+}
+
+// ClearSelection removes the current selection, including a finished one
+// that is still displayed.
+func (m *MouseCoordinator) ClearSelection() {
+	m.mu.Lock()
+	m.selectionMachine.Cancel()
+	m.autoScroll.Stop()
+	m.mu.Unlock()
+	m.markDirty()
+}
+
 // IsSelectionActive returns true if a selection is in progress.
 func (m *MouseCoordinator) IsSelectionActive() bool {
 	m.mu.Lock()
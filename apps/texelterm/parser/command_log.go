@@ -0,0 +1,108 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/parser/command_log.go
+// Summary: Persistent log of finished shell commands (OSC 133 blocks).
+// Usage: The terminal appends a CommandRecord when a command finishes;
+//   tools read them back with ReadCommandRecords to scope work to one
+//   command's output.
+// Notes: Records live next to the WAL as JSON lines. The file is kept
+//   under maxCommandLogSize by dropping its older half.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxCommandLogSize bounds the command log file.
+const maxCommandLogSize = 1 << 20
+
+// CommandRecord describes one finished command.
+type CommandRecord struct {
+	Command  string        `json:"command"`
+	Prompt   int64         `json:"prompt"` // global line of the prompt, -1 if unknown
+	Start    int64         `json:"start"`  // first line of output
+	End      int64         `json:"end"`    // last line of output, inclusive
+	ExitCode int           `json:"exit_code"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CommandLogPath returns the command log of terminalID under baseDir.
+func CommandLogPath(baseDir, terminalID string) string {
+	return filepath.Join(baseDir, "terminals", terminalID, "commands.jsonl")
+}
+
+// AppendCommandRecord appends rec to the log at path, creating it if
+// needed.
+func AppendCommandRecord(path string, rec CommandRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(data)) > maxCommandLogSize {
+		if err := trimCommandLog(path); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// trimCommandLog rewrites the log at path keeping its newer half.
+func trimCommandLog(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data = data[len(data)/2:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadCommandRecords reads the log at path, oldest first. A missing log
+// yields no records; malformed lines are skipped.
+func ReadCommandRecords(path string) ([]CommandRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var recs []CommandRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, maxCommandLogSize)
+	for sc.Scan() {
+		var rec CommandRecord
+		if json.Unmarshal(sc.Bytes(), &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, sc.Err()
+}
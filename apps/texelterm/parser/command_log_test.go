@@ -0,0 +1,45 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandLog_AppendReadAndTrim(t *testing.T) {
+	path := CommandLogPath(t.TempDir(), "cmds")
+	if recs, err := ReadCommandRecords(path); err != nil || recs != nil {
+		t.Fatalf("missing log = %v, %v", recs, err)
+	}
+	if filepath.Base(path) != "commands.jsonl" {
+		t.Errorf("path = %s", path)
+	}
+
+	long := strings.Repeat("x", 4096)
+	n := 2 * maxCommandLogSize / len(long)
+	for i := 0; i < n; i++ {
+		rec := CommandRecord{Command: long, Prompt: int64(i), Start: int64(i) + 1, End: int64(i) + 5, ExitCode: i % 3}
+		if err := AppendCommandRecord(path, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := ReadCommandRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) == 0 || len(recs) >= n {
+		t.Fatalf("kept %d of %d records, want the log trimmed", len(recs), n)
+	}
+	last := recs[len(recs)-1]
+	if last.Prompt != int64(n-1) || last.End != int64(n+4) || last.ExitCode != (n-1)%3 {
+		t.Errorf("last record: prompt %d, end %d, exit %d", last.Prompt, last.End, last.ExitCode)
+	}
+	for i := 1; i < len(recs); i++ {
+		if recs[i].Prompt != recs[i-1].Prompt+1 {
+			t.Fatalf("records out of order at %d: %d after %d", i, recs[i].Prompt, recs[i-1].Prompt)
+		}
+	}
+}
@@ -0,0 +1,551 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/parser/history_fsck.go
+// Summary: Integrity checker and repair tool for a terminal's persisted
+// history: page files, the WAL and the search index.
+// Usage: CheckHistory(baseDir, terminalID, opts), from `texelterm fsck`.
+// Notes: Checks, in order:
+//   - every page file decodes, its header matches its name and size, and
+//     its line offsets are in order;
+//   - no global line index is stored in two pages;
+//   - the page index can be rebuilt (what OpenPageStore does);
+//   - every WAL entry's checksum;
+//   - line timestamps are set, not in the future, and grow with the index;
+//   - the search index is intact and covers every non-blank line.
+//   Repair moves unreadable pages aside (*.corrupt), rewrites mismatched
+//   headers, drops the older copy of duplicated lines, truncates a torn
+//   WAL tail and rebuilds the search index from the history. Repair
+//   refuses to run while a terminal has the history open (history_lock.go).
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// FsckSeverity ranks a problem found by CheckHistory.
+type FsckSeverity int
+
+const (
+	FsckWarning FsckSeverity = iota // degraded but readable
+	FsckError                       // data is unreadable or inconsistent
+)
+
+func (s FsckSeverity) String() string {
+	if s == FsckError {
+		return "error"
+	}
+	return "warning"
+}
+
+// FsckIssue is one problem found by CheckHistory.
+type FsckIssue struct {
+	Severity FsckSeverity
+	Where    string // "page 00000003", "wal", "timestamps", "search index"
+	Message  string
+	Repaired bool
+}
+
+// FsckOptions configures CheckHistory.
+type FsckOptions struct {
+	Repair    bool      // fix what can be fixed
+	IndexPath string    // search index database; empty skips the index
+	Now       time.Time // reference for future timestamps; zero means now
+}
+
+// FsckReport is the result of checking one terminal's history.
+type FsckReport struct {
+	TerminalID   string
+	Pages        int   // readable page files
+	Lines        int64 // stored lines, WAL included
+	WALEntries   int
+	IndexedLines int64
+	Issues       []FsckIssue
+}
+
+// Healthy reports whether no error is left unrepaired.
+func (r *FsckReport) Healthy() bool {
+	for _, is := range r.Issues {
+		if is.Severity == FsckError && !is.Repaired {
+			return false
+		}
+	}
+	return true
+}
+
+// add records an issue and returns its index.
+func (r *FsckReport) add(sev FsckSeverity, where string, repaired bool, format string, args ...any) int {
+	r.Issues = append(r.Issues, FsckIssue{Severity: sev, Where: where, Message: fmt.Sprintf(format, args...), Repaired: repaired})
+	return len(r.Issues) - 1
+}
+
+// CheckHistory checks the history of terminalID under baseDir (the
+// pane's .hist3 path) and, with opts.Repair, fixes what it can. The
+// error is only for failures of the checker itself, and ErrHistoryInUse
+// when a repair is asked for while a terminal has the history open.
+func CheckHistory(baseDir, terminalID string, opts FsckOptions) (*FsckReport, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Repair {
+		lock, err := lockHistory(DefaultWALConfig(baseDir, terminalID).WALDir, syscall.LOCK_EX)
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Close()
+	}
+	r := &FsckReport{TerminalID: terminalID}
+	cfg := DefaultPageStoreConfig(baseDir, terminalID)
+	pagesDir := filepath.Join(baseDir, "terminals", terminalID, "pages")
+
+	if err := fsckPages(r, pagesDir, opts); err != nil {
+		return nil, err
+	}
+	if err := fsckDuplicates(r, cfg, pagesDir, opts); err != nil {
+		return nil, err
+	}
+	if _, err := OpenPageStore(cfg); err != nil {
+		r.add(FsckError, "pages", false, "the page index cannot be rebuilt: %v", err)
+		return r, nil
+	}
+	fsckWAL(r, filepath.Join(DefaultWALConfig(baseDir, terminalID).WALDir, "wal.log"), opts)
+
+	h, err := OpenHistoryReader(baseDir, terminalID)
+	if err != nil {
+		return r, nil // nothing stored
+	}
+	if err := fsckTimestamps(r, h, opts.Now); err != nil {
+		return nil, err
+	}
+	if opts.IndexPath != "" {
+		if err := fsckSearchIndex(r, h, opts); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// fsckPages validates each page file on its own.
+func fsckPages(r *FsckReport, pagesDir string, opts FsckOptions) error {
+	entries, err := os.ReadDir(pagesDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(pagesDir, name)
+		if strings.HasSuffix(name, ".tmp") {
+			repaired := opts.Repair && os.Remove(path) == nil
+			r.add(FsckWarning, name, repaired, "leftover temporary file from an interrupted write")
+			continue
+		}
+		idStr, ok := strings.CutSuffix(name, ".page")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			r.add(FsckWarning, name, false, "not a page file name; ignored")
+			continue
+		}
+		where := "page " + idStr
+		p, err := readPageChecked(path)
+		if err != nil {
+			repaired := opts.Repair && os.Rename(path, path+".corrupt") == nil
+			r.add(FsckError, where, repaired, "unreadable: %v", err)
+			continue
+		}
+		if named := p.Header.PageID; named != id {
+			p.Header.PageID = id
+			repaired := opts.Repair && (&PageStore{}).writePageToDisk(p, path) == nil
+			r.add(FsckError, where, repaired, "header names page %d", named)
+		}
+		r.Pages++
+	}
+	return nil
+}
+
+// readPageChecked decodes a page file, rejecting anything Page.ReadFrom
+// would misread: a size that disagrees with the header or line offsets
+// out of order.
+func readPageChecked(path string) (*Page, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Page{}
+	rd := bytes.NewReader(data)
+	if _, err := p.readHeader(rd); err != nil {
+		return nil, err
+	}
+	want := PageHeaderSize + int64(p.Header.LineCount)*LineIndexSize + int64(p.Header.UncompressedSize)
+	if int64(len(data)) != want {
+		return nil, fmt.Errorf("file is %d bytes, its header implies %d", len(data), want)
+	}
+	if p.Header.LineCount > 0 && p.Header.UncompressedSize == 0 {
+		return nil, fmt.Errorf("%d lines but no line data", p.Header.LineCount)
+	}
+	if _, err := p.readIndex(rd); err != nil {
+		return nil, err
+	}
+	var prev uint32
+	for i, e := range p.Index {
+		if e.Offset < prev || e.Offset > p.Header.UncompressedSize {
+			return nil, fmt.Errorf("line %d has offset %d out of order", i, e.Offset)
+		}
+		prev = e.Offset
+	}
+	if _, err := p.readLineData(rd); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// pageSpan is the global line range [first, end) a page file holds.
+type pageSpan struct {
+	id         uint64
+	first, end int64
+}
+
+// pageSpans reads the headers of the readable pages, sorted by first line.
+func pageSpans(pagesDir string) ([]pageSpan, error) {
+	entries, err := os.ReadDir(pagesDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var spans []pageSpan
+	for _, e := range entries {
+		idStr, ok := strings.CutSuffix(e.Name(), ".page")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		p, err := (&PageStore{}).readPageHeader(filepath.Join(pagesDir, e.Name()))
+		if err != nil {
+			continue // reported by fsckPages
+		}
+		first := int64(p.Header.FirstGlobalIdx)
+		spans = append(spans, pageSpan{id, first, first + int64(p.Header.LineCount)})
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].first != spans[j].first {
+			return spans[i].first < spans[j].first
+		}
+		return spans[i].id < spans[j].id
+	})
+	return spans, nil
+}
+
+// duplicate is a line range stored in two pages. keep is the page with
+// the higher ID, which was written later.
+type duplicate struct {
+	drop, keep uint64
+	lo, hi     int64 // inclusive
+}
+
+// duplicates lists the overlaps between spans, which are sorted by first
+// line.
+func duplicates(spans []pageSpan) []duplicate {
+	var out []duplicate
+	for i, a := range spans {
+		for _, b := range spans[i+1:] {
+			if b.first >= a.end {
+				break
+			}
+			d := duplicate{drop: a.id, keep: b.id, lo: b.first, hi: min(a.end, b.end) - 1}
+			if a.id > b.id {
+				d.drop, d.keep = b.id, a.id
+			}
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// fsckDuplicates reports global line indexes held by more than one page;
+// the page index would hold both and lookups would pick one arbitrarily.
+// Repair drops the older copy.
+func fsckDuplicates(r *FsckReport, cfg PageStoreConfig, pagesDir string, opts FsckOptions) error {
+	spans, err := pageSpans(pagesDir)
+	if err != nil {
+		return err
+	}
+	if !opts.Repair {
+		for _, d := range duplicates(spans) {
+			r.add(FsckError, fmt.Sprintf("page %08d", d.drop), false, "lines %d-%d are also stored in page %08d", d.lo, d.hi, d.keep)
+		}
+		return nil
+	}
+	// Each repair rewrites one page, possibly splitting it, so the
+	// headers are read again after every fix.
+	for range len(spans) * len(spans) {
+		dups := duplicates(spans)
+		if len(dups) == 0 {
+			return nil
+		}
+		d := dups[0]
+		where := fmt.Sprintf("page %08d", d.drop)
+		ps, err := OpenPageStore(cfg)
+		if err == nil {
+			ps.mu.Lock()
+			err = ps.rewritePageForDelete(d.drop, d.lo, d.hi)
+			ps.mu.Unlock()
+		}
+		if err != nil {
+			r.add(FsckError, where, false, "lines %d-%d are also stored in page %08d; cannot repair: %v", d.lo, d.hi, d.keep, err)
+			return nil
+		}
+		r.add(FsckError, where, true, "lines %d-%d are also stored in page %08d; kept that newer copy", d.lo, d.hi, d.keep)
+		if spans, err = pageSpans(pagesDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fsckWAL verifies the WAL header and every entry's checksum. A torn or
+// corrupt tail is truncated on repair, as recovery would do, but without
+// replaying anything into the pages.
+func fsckWAL(r *FsckReport, walPath string, opts FsckOptions) {
+	f, err := os.Open(walPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		r.add(FsckError, "wal", false, "cannot open: %v", err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		r.add(FsckError, "wal", false, "cannot stat: %v", err)
+		return
+	}
+
+	var w WriteAheadLog
+	if err := w.readHeader(f); err != nil {
+		f.Close()
+		repaired := opts.Repair && os.Rename(walPath, walPath+".corrupt") == nil
+		r.add(FsckError, "wal", repaired, "%v; lines not yet checkpointed are lost", err)
+		return
+	}
+	rd := bufio.NewReader(f)
+	pos := int64(WALHeaderSize)
+	for {
+		_, n, err := w.readEntry(rd)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			f.Close()
+			repaired := opts.Repair && os.Truncate(walPath, pos) == nil
+			r.add(FsckError, "wal", repaired, "entry at offset %d is corrupt (%v); %d bytes from there on are unreadable", pos, err, info.Size()-pos)
+			return
+		}
+		pos += int64(n)
+		r.WALEntries++
+	}
+}
+
+// fsckTimestamps counts stored lines and checks their timestamps. Lines
+// that are older than the line before them are normal after in-place
+// edits, but make time-based navigation less precise, so they are only
+// warned about.
+func fsckTimestamps(r *FsckReport, h *HistoryReader, now time.Time) error {
+	var zero, future, backwards int64
+	var prev time.Time
+	err := h.Scan(func(gi int64, line *LogicalLine, ts time.Time) error {
+		r.Lines++
+		switch {
+		case ts.IsZero() || ts.UnixNano() == 0:
+			zero++
+			return nil
+		case ts.After(now.Add(time.Minute)):
+			future++
+		case ts.Before(prev):
+			backwards++
+		}
+		prev = ts
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if zero > 0 {
+		r.add(FsckWarning, "timestamps", false, "%d lines have no timestamp", zero)
+	}
+	if future > 0 {
+		r.add(FsckWarning, "timestamps", false, "%d lines are dated in the future", future)
+	}
+	if backwards > 0 {
+		r.add(FsckWarning, "timestamps", false, "%d lines are older than the line before them; time-based navigation may land slightly off", backwards)
+	}
+	return nil
+}
+
+// fsckSearchIndex checks the search index database and compares it with
+// the history. Repair rebuilds it from the history.
+func fsckSearchIndex(r *FsckReport, h *HistoryReader, opts FsckOptions) error {
+	var issues []int
+	rebuild, fresh := false, false
+	if _, err := os.Stat(opts.IndexPath); errors.Is(err, os.ErrNotExist) {
+		issues = append(issues, r.add(FsckWarning, "search index", false, "missing; history search finds nothing"))
+		rebuild, fresh = true, true
+	} else if ok, err := fsckIndexRows(r, h, opts.IndexPath, &issues); err != nil {
+		issues = append(issues, r.add(FsckError, "search index", false, "unreadable: %v", err))
+		rebuild, fresh = true, true
+	} else {
+		rebuild = !ok
+	}
+	if !rebuild || !opts.Repair {
+		return nil
+	}
+	n, err := reindexSearch(opts.IndexPath, h, fresh)
+	if err != nil {
+		r.add(FsckError, "search index", false, "rebuild failed: %v", err)
+		return nil
+	}
+	r.IndexedLines = n
+	for _, i := range issues {
+		r.Issues[i].Repaired = true
+	}
+	return nil
+}
+
+// fsckIndexRows compares the index rows with the history's non-blank
+// lines. ok is false when the index needs rebuilding.
+func fsckIndexRows(r *FsckReport, h *HistoryReader, dbPath string, issues *[]int) (ok bool, err error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	var integrity string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return false, err
+	}
+	if integrity != "ok" {
+		return false, errors.New(integrity)
+	}
+	ok = true
+	var version int
+	if db.QueryRow("SELECT version FROM schema_version LIMIT 1").Scan(&version) == nil && version != searchIndexSchemaVersion {
+		r.add(FsckWarning, "search index", false, "schema version %d, current is %d; it is migrated when the pane opens", version, searchIndexSchemaVersion)
+	}
+
+	rows, err := db.Query("SELECT id FROM lines")
+	if err != nil {
+		return false, err
+	}
+	indexed := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return false, err
+		}
+		indexed[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	r.IndexedLines = int64(len(indexed))
+
+	var text, missing int64
+	err = h.Scan(func(gi int64, line *LogicalLine, _ time.Time) error {
+		if ExtractTextFromLine(line) == "" {
+			return nil
+		}
+		text++
+		if indexed[gi] {
+			delete(indexed, gi)
+		} else {
+			missing++
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if missing > 0 {
+		*issues = append(*issues, r.add(FsckWarning, "search index", false,
+			"%d of %d lines are not indexed (%.0f%% coverage)", missing, text, 100*float64(text-missing)/float64(text)))
+		ok = false
+	}
+	if stale := len(indexed); stale > 0 {
+		*issues = append(*issues, r.add(FsckWarning, "search index", false, "%d indexed lines are no longer in the history", stale))
+		ok = false
+	}
+	return ok, nil
+}
+
+// reindexSearch replaces the rows of the search index at dbPath with the
+// history's non-blank lines and returns how many it wrote. fresh deletes
+// the database first.
+func reindexSearch(dbPath string, h *HistoryReader, fresh bool) (int64, error) {
+	if fresh {
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return 0, err
+			}
+		}
+	}
+	si, err := NewSearchIndex(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer si.Close()
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	tx, err := si.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM lines"); err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare("INSERT INTO lines (id, timestamp, is_command, content) VALUES (?, ?, 0, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	var n int64
+	err = h.Scan(func(gi int64, line *LogicalLine, ts time.Time) error {
+		text := ExtractTextFromLine(line)
+		if text == "" {
+			return nil
+		}
+		n++
+		_, err := stmt.Exec(gi, ts.UnixNano(), text)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
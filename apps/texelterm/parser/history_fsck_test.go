@@ -0,0 +1,184 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCheckHistory_ReportsAndRepairs(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := DefaultWALConfig(tmpDir, "fsck")
+	config.CheckpointInterval = 0
+	wal, err := OpenWriteAheadLog(config)
+	if err != nil {
+		t.Fatalf("OpenWriteAheadLog: %v", err)
+	}
+	defer wal.Close()
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	text := []string{"zero", "one", "two", "three", "four", "five", "six", "seven"}
+	for i, s := range text {
+		if err := wal.Append(int64(i), NewLogicalLineFromCells(makeCells(s)), base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if i == 5 {
+			if err := wal.Checkpoint(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := wal.SyncWAL(); err != nil {
+		t.Fatal(err)
+	}
+
+	indexPath := filepath.Join(tmpDir, "fsck.index.db")
+	idx, err := NewSearchIndex(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Close()
+	opts := FsckOptions{IndexPath: indexPath, Now: base.Add(time.Hour)}
+
+	report, err := CheckHistory(tmpDir, "fsck", opts)
+	if err != nil {
+		t.Fatalf("CheckHistory: %v", err)
+	}
+	if report.Lines != 8 || report.WALEntries == 0 {
+		t.Fatalf("Lines = %d, WALEntries = %d; want 8 lines and some WAL entries", report.Lines, report.WALEntries)
+	}
+	if !report.Healthy() || len(report.Issues) != 1 || report.Issues[0].Where != "search index" {
+		t.Fatalf("clean history: issues = %+v, want only the unindexed lines", report.Issues)
+	}
+
+	// Checking an open history is fine; repairing it is not.
+	if _, err := CheckHistory(tmpDir, "fsck", FsckOptions{Repair: true}); !errors.Is(err, ErrHistoryInUse) {
+		t.Fatalf("repair of an open history: err = %v, want ErrHistoryInUse", err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copy a page under a new ID (a duplicate with a stale header), leave
+	// a temporary file behind and tear the WAL's tail.
+	pagesDir := filepath.Join(tmpDir, "terminals", "fsck", "pages")
+	entries, err := os.ReadDir(pagesDir)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("no pages written: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(pagesDir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pagesDir, "00000099.page"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pagesDir, "00000100.page.tmp"), data[:10], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	walPath := filepath.Join(config.WALDir, "wal.log")
+	f, err := os.OpenFile(walPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("not a wal entry, just some trailing garbage"))
+	f.Close()
+
+	report, err = CheckHistory(tmpDir, "fsck", opts)
+	if err != nil {
+		t.Fatalf("CheckHistory: %v", err)
+	}
+	if report.Healthy() {
+		t.Fatalf("damaged history reported healthy: %+v", report.Issues)
+	}
+	var sawHeader, sawDuplicate, sawWAL bool
+	for _, is := range report.Issues {
+		if is.Repaired {
+			t.Errorf("issue repaired without Repair: %+v", is)
+		}
+		sawHeader = sawHeader || is.Where == "page 00000099" && strings.Contains(is.Message, "header")
+		sawDuplicate = sawDuplicate || strings.Contains(is.Message, "also stored in page")
+		sawWAL = sawWAL || is.Where == "wal" && is.Severity == FsckError
+	}
+	if !sawHeader || !sawDuplicate || !sawWAL {
+		t.Fatalf("issues = %+v; want a stale header, a duplicate and a corrupt WAL tail", report.Issues)
+	}
+
+	opts.Repair = true
+	report, err = CheckHistory(tmpDir, "fsck", opts)
+	if err != nil {
+		t.Fatalf("CheckHistory with repair: %v", err)
+	}
+	if !report.Healthy() {
+		t.Fatalf("repair left errors: %+v", report.Issues)
+	}
+
+	opts.Repair = false
+	report, err = CheckHistory(tmpDir, "fsck", opts)
+	if err != nil {
+		t.Fatalf("CheckHistory after repair: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("issues after repair = %+v, want none", report.Issues)
+	}
+	if report.IndexedLines != 8 {
+		t.Errorf("IndexedLines = %d, want 8", report.IndexedLines)
+	}
+
+	h, err := OpenHistoryReader(tmpDir, "fsck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := h.ReadLineRange(0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range text {
+		if lines[i] == nil || cellsToString(lines[i].Cells) != want {
+			t.Errorf("line %d after repair = %v, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestOpenWriteAheadLog_WaitsForRepair(t *testing.T) {
+	defer func(d time.Duration) { historyLockWait = d }(historyLockWait)
+	historyLockWait = 100 * time.Millisecond
+	tmpDir := t.TempDir()
+	config := DefaultWALConfig(tmpDir, "busy")
+	config.CheckpointInterval = 0
+
+	// A repair in progress holds the lock exclusively: the terminal must
+	// not open (or create) anything under it.
+	repair, err := lockHistory(config.WALDir, syscall.LOCK_EX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenWriteAheadLog(config); !errors.Is(err, ErrHistoryInUse) {
+		t.Fatalf("open during repair: err = %v, want ErrHistoryInUse", err)
+	}
+	if _, err := os.Stat(filepath.Join(config.WALDir, "wal.log")); !os.IsNotExist(err) {
+		t.Fatalf("wal.log created during repair: %v", err)
+	}
+
+	// A repair that finishes within the wait lets the open through.
+	historyLockWait = 5 * time.Second
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		repair.Close()
+	}()
+	wal, err := OpenWriteAheadLog(config)
+	if err != nil {
+		t.Fatalf("open after repair: %v", err)
+	}
+	defer wal.Close()
+	if _, err := lockHistory(config.WALDir, syscall.LOCK_EX); !errors.Is(err, ErrHistoryInUse) {
+		t.Fatalf("repair while open: err = %v, want ErrHistoryInUse", err)
+	}
+}
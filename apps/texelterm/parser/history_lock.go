@@ -0,0 +1,69 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/parser/history_lock.go
+// Summary: Advisory lock that tells offline tools a terminal's history is
+// open.
+// Usage: OpenWriteAheadLog takes a shared flock on wal.lock in its
+//   directory before touching any file and holds it while open;
+//   CheckHistory takes it exclusively before repairing and fails with
+//   ErrHistoryInUse while any terminal has the history open.
+// Notes: Shared, so the old and new server can both hold it while live
+//   panes are handed over on upgrade. A terminal opening during a repair
+//   waits up to historyLockWait for it, then fails.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// walLockName is the lock file in a WAL directory.
+const walLockName = "wal.lock"
+
+// ErrHistoryInUse is returned when a repair is asked for while a terminal
+// has the history open, and when a terminal opens a history that is being
+// repaired.
+var ErrHistoryInUse = errors.New("history is open in a running terminal")
+
+// historyLockWait bounds how long a terminal waits for a repair to
+// finish before giving up on its history. A variable for tests.
+var historyLockWait = 5 * time.Second
+
+// lockHistoryWait is lockHistory retried while the lock is held in a
+// conflicting mode, for at most wait.
+func lockHistoryWait(dir string, how int, wait time.Duration) (*os.File, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		f, err := lockHistory(dir, how)
+		if !errors.Is(err, ErrHistoryInUse) || !time.Now().Before(deadline) {
+			return f, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// lockHistory opens dir's lock file and flocks it without blocking; how is
+// syscall.LOCK_SH or syscall.LOCK_EX. Closing the file releases the lock.
+func lockHistory(dir string, how int) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, walLockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrHistoryInUse
+		}
+		return nil, fmt.Errorf("lock %s: %w", dir, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,226 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/parser/history_reader.go
+// Summary: Read-only access to a terminal's persisted history.
+// Usage: OpenHistoryReader(baseDir, terminalID) from tools that inspect a
+//   pane's scrollback (e.g. texelterm export) while the pane may be running.
+// Notes: Unlike opening the WriteAheadLog, this never recovers, truncates
+//   or flushes anything. WAL entries written since the last checkpoint are
+//   replayed into memory on top of the flushed pages.
+
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// walLine is a line replayed from the WAL.
+type walLine struct {
+	line *LogicalLine
+	ts   time.Time
+}
+
+// HistoryReader reads a terminal's history without modifying it.
+type HistoryReader struct {
+	pages   *PageStore        // nil when nothing has been flushed yet
+	lines   map[int64]walLine // WAL lines newer than the pages
+	deleted [][2]int64        // WAL deletes, inclusive ranges
+	count   int64
+}
+
+// OpenHistoryReader opens the history of terminalID under baseDir. It
+// returns an error only when neither pages nor a WAL exist.
+func OpenHistoryReader(baseDir, terminalID string) (*HistoryReader, error) {
+	pages, err := OpenPageStore(DefaultPageStoreConfig(baseDir, terminalID))
+	if err != nil {
+		return nil, err
+	}
+	h := &HistoryReader{pages: pages, lines: make(map[int64]walLine)}
+	if pages != nil {
+		h.count = pages.LineCount()
+	}
+
+	walPath := filepath.Join(DefaultWALConfig(baseDir, terminalID).WALDir, "wal.log")
+	f, err := os.Open(walPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if pages == nil {
+			return nil, fmt.Errorf("no history for terminal %s", terminalID)
+		}
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(WALHeaderSize, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var w WriteAheadLog
+	r := bufio.NewReader(f)
+	for {
+		// A torn or corrupt tail ends the replay, as in recovery.
+		entry, _, err := w.readEntry(r)
+		if err != nil {
+			break
+		}
+		h.replay(entry)
+	}
+	return h, nil
+}
+
+// replay applies one WAL entry. A checkpoint means the entries before it
+// reached the pages, but replaying them again is harmless: the WAL's
+// latest entry for a line always wins.
+func (h *HistoryReader) replay(e WALEntry) {
+	switch e.Type {
+	case EntryTypeLineWrite, EntryTypeLineModify:
+		if e.Line == nil {
+			return
+		}
+		gi := int64(e.GlobalLineIdx)
+		h.lines[gi] = walLine{e.Line, e.Timestamp}
+		h.count = max(h.count, gi+1)
+	case EntryTypeLineDelete:
+		lo, hi := int64(e.GlobalLineIdx), e.DeleteHi
+		for gi := range h.lines {
+			if gi >= lo && gi <= hi {
+				delete(h.lines, gi)
+			}
+		}
+		h.deleted = append(h.deleted, [2]int64{lo, hi})
+	}
+}
+
+// LineCount returns the end of the global line index space.
+func (h *HistoryReader) LineCount() int64 {
+	return h.count
+}
+
+// isDeleted reports whether a WAL delete removed gi from the pages.
+func (h *HistoryReader) isDeleted(gi int64) bool {
+	for _, d := range h.deleted {
+		if gi >= d[0] && gi <= d[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadLineRange reads lines [start, end), with nil entries for gaps, like
+// PageStore.ReadLineRange.
+func (h *HistoryReader) ReadLineRange(start, end int64) ([]*LogicalLine, error) {
+	start = max(start, 0)
+	end = min(end, h.count)
+	if end <= start {
+		return nil, nil
+	}
+	var result []*LogicalLine
+	if h.pages != nil {
+		var err error
+		if result, err = h.pages.ReadLineRange(start, end); err != nil {
+			return nil, err
+		}
+	}
+	if len(result) == 0 {
+		result = make([]*LogicalLine, end-start)
+	}
+	for gi := start; gi < end; gi++ {
+		if l, ok := h.lines[gi]; ok {
+			result[gi-start] = l.line
+		} else if h.isDeleted(gi) {
+			result[gi-start] = nil
+		}
+	}
+	return result, nil
+}
+
+// Timestamp returns when line gi was last written, or the zero time if it
+// is not stored.
+func (h *HistoryReader) Timestamp(gi int64) time.Time {
+	if l, ok := h.lines[gi]; ok {
+		return l.ts
+	}
+	if h.pages == nil || h.isDeleted(gi) {
+		return time.Time{}
+	}
+	_, ts, err := h.pages.ReadLineWithTimestamp(gi)
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
+
+// FindLineAt returns the last stored line written at or before t, or -1
+// if there is none. Timestamps are assumed to grow with the line index.
+func (h *HistoryReader) FindLineAt(t time.Time) int64 {
+	found := int64(-1)
+	if h.pages != nil {
+		if gi, err := h.pages.FindLineAt(t); err == nil && gi >= 0 {
+			if ts := h.Timestamp(gi); !ts.IsZero() && !ts.After(t) {
+				found = gi
+			}
+		}
+	}
+	for gi, l := range h.lines {
+		if gi > found && !l.ts.After(t) {
+			found = gi
+		}
+	}
+	return found
+}
+
+// Scan calls fn for every stored line in ascending global index order,
+// loading each page once. It stops at the first error fn returns.
+func (h *HistoryReader) Scan(fn func(gi int64, line *LogicalLine, ts time.Time) error) error {
+	walIdx := make([]int64, 0, len(h.lines))
+	for gi := range h.lines {
+		walIdx = append(walIdx, gi)
+	}
+	slices.Sort(walIdx)
+	// emitWAL reports the WAL lines below upto.
+	emitWAL := func(upto int64) error {
+		for len(walIdx) > 0 && walIdx[0] < upto {
+			l := h.lines[walIdx[0]]
+			if err := fn(walIdx[0], l.line, l.ts); err != nil {
+				return err
+			}
+			walIdx = walIdx[1:]
+		}
+		return nil
+	}
+
+	if h.pages != nil {
+		ps := h.pages
+		ps.mu.RLock()
+		entries := slices.Clone(ps.pageIndex)
+		ps.mu.RUnlock()
+		var page *Page
+		for _, e := range entries {
+			if err := emitWAL(e.globalIdx + 1); err != nil {
+				return err
+			}
+			if _, ok := h.lines[e.globalIdx]; ok || h.isDeleted(e.globalIdx) {
+				continue
+			}
+			if page == nil || page.Header.PageID != e.pageID {
+				var err error
+				if page, err = ps.loadPage(e.pageID); err != nil {
+					return err
+				}
+			}
+			if err := fn(e.globalIdx, page.GetLine(e.offsetInPage), page.GetTimestamp(e.offsetInPage)); err != nil {
+				return err
+			}
+		}
+	}
+	return emitWAL(math.MaxInt64)
+}
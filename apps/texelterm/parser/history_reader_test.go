@@ -0,0 +1,75 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryReader_MergesPagesAndWALWithoutTouchingThem(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := DefaultWALConfig(tmpDir, "reader")
+	config.CheckpointInterval = 0
+	wal, err := OpenWriteAheadLog(config)
+	if err != nil {
+		t.Fatalf("OpenWriteAheadLog: %v", err)
+	}
+	defer wal.Close()
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	text := []string{"zero", "one", "two", "three", "four"}
+	for i, s := range text {
+		if err := wal.Append(int64(i), NewLogicalLineFromCells(makeCells(s)), base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			if err := wal.Checkpoint(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := wal.DeleteRange(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.SyncWAL(); err != nil {
+		t.Fatal(err)
+	}
+	walPath := filepath.Join(config.WALDir, "wal.log")
+	before, _ := os.Stat(walPath)
+
+	h, err := OpenHistoryReader(tmpDir, "reader")
+	if err != nil {
+		t.Fatalf("OpenHistoryReader: %v", err)
+	}
+	if n := h.LineCount(); n != 5 {
+		t.Fatalf("LineCount = %d, want 5", n)
+	}
+	lines, err := h.ReadLineRange(0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"zero", "", "two", "three", "four"} {
+		got := ""
+		if lines[i] != nil {
+			got = cellsToString(lines[i].Cells)
+		}
+		if got != want {
+			t.Errorf("line %d = %q, want %q", i, got, want)
+		}
+	}
+	if gi := h.FindLineAt(base.Add(3*time.Minute + time.Second)); gi != 3 {
+		t.Errorf("FindLineAt = %d, want 3", gi)
+	}
+	if gi := h.FindLineAt(base.Add(-time.Second)); gi != -1 {
+		t.Errorf("FindLineAt before history = %d, want -1", gi)
+	}
+
+	after, _ := os.Stat(walPath)
+	if before.Size() != after.Size() {
+		t.Errorf("reading changed the WAL size from %d to %d", before.Size(), after.Size())
+	}
+}
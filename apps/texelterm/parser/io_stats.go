@@ -0,0 +1,56 @@
+// Copyright 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/parser/io_stats.go
+// Summary: Process-wide persistence counters for live diagnostics.
+//
+// WAL checkpoints (the point where buffered writes are replayed into the
+// PageStore and fsynced) record their latency, and the search indexer
+// tracks how many output lines are queued but not yet in SQLite. The
+// server samples both once per diagnostics interval via
+// TakePersistenceStats; every terminal in the process contributes.
+
+package parser
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	checkpointCount atomic.Uint64
+	checkpointMax   atomic.Int64 // nanoseconds, reset by TakePersistenceStats
+	indexBacklog    atomic.Int64
+)
+
+// PersistenceStats is one diagnostics sample of persistence health.
+type PersistenceStats struct {
+	// CheckpointMax is the slowest WAL checkpoint since the last sample.
+	CheckpointMax time.Duration
+	// Checkpoints is the total number of checkpoints since process start.
+	Checkpoints uint64
+	// IndexBacklog is the number of lines waiting for the search indexer.
+	IndexBacklog int
+}
+
+// TakePersistenceStats returns the current counters and starts a new
+// interval for CheckpointMax.
+func TakePersistenceStats() PersistenceStats {
+	return PersistenceStats{
+		CheckpointMax: time.Duration(checkpointMax.Swap(0)),
+		Checkpoints:   checkpointCount.Load(),
+		IndexBacklog:  int(max(indexBacklog.Load(), 0)),
+	}
+}
+
+// recordCheckpoint is deferred by checkpointLocked with its start time.
+func recordCheckpoint(start time.Time) {
+	d := int64(time.Since(start))
+	checkpointCount.Add(1)
+	for {
+		cur := checkpointMax.Load()
+		if d <= cur || checkpointMax.CompareAndSwap(cur, d) {
+			return
+		}
+	}
+}
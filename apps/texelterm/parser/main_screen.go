@@ -139,6 +139,22 @@ type MainScreen interface {
 	// snaps the anchor to OldestRetained() and autoFollow remains false
 	// (the user's scrolled-back intent is preserved — Policy A).
 	RestoreViewport(viewBottom int64, wrapSeg uint16, autoFollow bool)
+
+	// CollapseLines hides the rows [start, end] behind a single summary
+	// row in the view. The rows stay in the store; only rendering and
+	// view navigation skip them. A collapse overlapping an existing one
+	// replaces it.
+	CollapseLines(start, end int64, summary []Cell)
+
+	// ExpandLines removes the collapse covering globalIdx. Returns false
+	// if globalIdx is not collapsed.
+	ExpandLines(globalIdx int64) bool
+
+	// CollapsedAt returns the range of the collapse covering globalIdx.
+	CollapsedAt(globalIdx int64) (start, end int64, ok bool)
+
+	// HiddenLines counts the rows in [lo, hi] hidden by collapses.
+	HiddenLines(lo, hi int64) int64
 }
 
 // MainScreenFactory creates a MainScreen for the given dimensions. Set by
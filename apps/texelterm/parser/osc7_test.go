@@ -7,18 +7,25 @@
 
 package parser
 
-import "testing"
+import (
+	"os"
+	"testing"
+)
 
 // TestOSC7_WorkingDirectory verifies that OSC 7 (current working directory)
 // sequences in both `file://host/path` and `file:///path` forms populate
 // CurrentWorkingDir, and that malformed sequences without the file:// prefix
-// are ignored.
+// or naming another host are ignored.
 func TestOSC7_WorkingDirectory(t *testing.T) {
 	v := NewVTerm(80, 24)
 	p := NewParser(v)
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("os.Hostname: %v", err)
+	}
 
 	// OSC 7 with hostname: ESC ] 7 ; <uri> BEL
-	parseString(p, "\x1b]7;file://myhost/home/user/projects\x07")
+	parseString(p, "\x1b]7;file://"+host+"/home/user/projects\x07")
 	if got, want := v.CurrentWorkingDir, "/home/user/projects"; got != want {
 		t.Errorf("OSC 7 with host: got %q, want %q", got, want)
 	}
@@ -35,4 +42,12 @@ func TestOSC7_WorkingDirectory(t *testing.T) {
 	if v.CurrentWorkingDir != "" {
 		t.Errorf("OSC 7 invalid URI: got %q, want empty", v.CurrentWorkingDir)
 	}
+
+	// OSC 7 naming a foreign host (e.g. printed by an ssh session or a
+	// cat-ed file) must not move the pane's cwd.
+	parseString(p, "\x1b]7;file://localhost/tmp/local\x07")
+	parseString(p, "\x1b]7;file://not-"+host+".example/tmp/evil\x07")
+	if got, want := v.CurrentWorkingDir, "/tmp/local"; got != want {
+		t.Errorf("OSC 7 foreign host: got %q, want %q", got, want)
+	}
 }
@@ -342,16 +342,8 @@ func (p *Parser) handleOSC52(payload string) {
 	// Handle query
 	if data == "?" {
 		if p.vterm.OnClipboardGet != nil {
-			clipData := p.vterm.OnClipboardGet()
-			if clipData != nil && p.vterm.WriteToPty != nil {
-				// Normalize line endings: CRLF -> LF
-				// Terminal applications expect Unix line endings
-				normalized := normalizeLineEndings(clipData)
-				// Encode response as base64
-				encoded := base64.StdEncoding.EncodeToString(normalized)
-				// Send response: OSC 52;c;<base64>ST (ST = ESC \)
-				response := fmt.Sprintf("\x1b]52;c;%s\x1b\\", encoded)
-				p.vterm.WriteToPty([]byte(response))
+			if clipData := p.vterm.OnClipboardGet(); clipData != nil {
+				p.vterm.ReplyClipboard(clipData)
 			}
 		}
 		return
@@ -367,6 +359,23 @@ func (p *Parser) handleOSC52(payload string) {
 	// Empty data clears the selection (not implemented yet)
 }
 
+// ReplyClipboard answers an OSC 52 query with data. OnClipboardGet replies
+// through it implicitly; hosts that answer later (after asking the user)
+// return nil from OnClipboardGet and call this once they have the data.
+func (v *VTerm) ReplyClipboard(data []byte) {
+	if v.WriteToPty == nil {
+		return
+	}
+	// Normalize line endings: CRLF -> LF
+	// Terminal applications expect Unix line endings
+	normalized := normalizeLineEndings(data)
+	// Encode response as base64
+	encoded := base64.StdEncoding.EncodeToString(normalized)
+	// Send response: OSC 52;c;<base64>ST (ST = ESC \)
+	response := fmt.Sprintf("\x1b]52;c;%s\x1b\\", encoded)
+	v.WriteToPty([]byte(response))
+}
+
 func (p *Parser) handleOSC133(payload string) {
 	parts := strings.Split(payload, ";")
 	if len(parts) == 0 {
@@ -334,6 +334,7 @@ func (si *SQLiteSearchIndex) batchIndexer() {
 			n = len(batch)
 		}
 		si.flushBatch(batch[:n])
+		indexBacklog.Add(-int64(n))
 		// Compact: keep remaining entries
 		remaining := len(batch) - n
 		copy(batch[:remaining], batch[n:])
@@ -346,6 +347,7 @@ func (si *SQLiteSearchIndex) batchIndexer() {
 			return
 		}
 		si.flushBatch(batch)
+		indexBacklog.Add(-int64(len(batch)))
 		batch = batch[:0]
 	}
 
@@ -355,6 +357,8 @@ func (si *SQLiteSearchIndex) batchIndexer() {
 			// Accumulate in memory; no flush on batch size.
 			if len(batch) < maxPendingIndexEntries {
 				batch = append(batch, entry)
+			} else {
+				indexBacklog.Add(-1)
 			}
 			// Reset timer on every entry — flush only fires after idle period.
 			// During active output (ls -lR), timer keeps resetting = zero FTS5 work.
@@ -468,6 +472,7 @@ func (si *SQLiteSearchIndex) IndexLine(lineIdx int64, timestamp time.Time, text
 	// Output queued for batch indexing
 	select {
 	case si.batchChan <- entry:
+		indexBacklog.Add(1)
 		return nil
 	default:
 		// Channel full - try non-blocking, otherwise drop
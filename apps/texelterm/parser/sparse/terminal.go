@@ -370,6 +370,23 @@ func (t *Terminal) RestoreViewport(viewBottom int64, wrapSeg uint16, autoFollow
 	t.view.ApplyResumeState(anchor, offset, viewBottom, false)
 }
 
+// CollapseLines hides [start, end] behind summary in the view.
+func (t *Terminal) CollapseLines(start, end int64, summary []parser.Cell) {
+	t.view.SetCollapse(Collapse{Start: start, End: end, Summary: summary})
+}
+
+// ExpandLines removes the collapse covering globalIdx.
+func (t *Terminal) ExpandLines(globalIdx int64) bool { return t.view.Expand(globalIdx) }
+
+// CollapsedAt returns the range of the collapse covering globalIdx.
+func (t *Terminal) CollapsedAt(globalIdx int64) (start, end int64, ok bool) {
+	c, ok := t.view.CollapseAt(globalIdx)
+	return c.Start, c.End, ok
+}
+
+// HiddenLines counts the rows in [lo, hi] hidden by collapses.
+func (t *Terminal) HiddenLines(lo, hi int64) int64 { return t.view.HiddenLines(lo, hi) }
+
 // ViewWindow returns the underlying ViewWindow. Intended for callers that
 // need to drive a reflow walk directly (e.g. the cursor-to-view mapping
 // path). Callers must not mutate the ViewWindow's view state through this
@@ -0,0 +1,88 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package sparse
+
+import (
+	"sort"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+// Collapse hides the store rows [Start, End] behind a single summary row
+// in the view. It is purely a projection: the Store keeps every row, so
+// persistence, search and copy are unaffected.
+type Collapse struct {
+	Start, End int64
+	Summary    []parser.Cell
+}
+
+// SetCollapse adds c, replacing any collapse it overlaps. A view anchored
+// inside the hidden rows moves to the summary row.
+func (v *ViewWindow) SetCollapse(c Collapse) {
+	if c.End < c.Start {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// The list is copied on write so walks can use a snapshot unlocked.
+	kept := make([]Collapse, 0, len(v.collapses)+1)
+	for _, old := range v.collapses {
+		if old.End < c.Start || old.Start > c.End {
+			kept = append(kept, old)
+		}
+	}
+	i := sort.Search(len(kept), func(i int) bool { return kept[i].Start > c.Start })
+	kept = append(kept, Collapse{})
+	copy(kept[i+1:], kept[i:])
+	kept[i] = c
+	v.collapses = kept
+	if v.viewAnchor > c.Start && v.viewAnchor <= c.End {
+		v.viewAnchor = c.Start
+		v.viewAnchorOffset = 0
+	}
+}
+
+// Expand removes the collapse covering globalIdx and reports whether
+// there was one.
+func (v *ViewWindow) Expand(globalIdx int64) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i, c := range v.collapses {
+		if globalIdx >= c.Start && globalIdx <= c.End {
+			v.collapses = append(v.collapses[:i:i], v.collapses[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// CollapseAt returns the collapse covering globalIdx, if any.
+func (v *ViewWindow) CollapseAt(globalIdx int64) (Collapse, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return collapseAt(v.collapses, globalIdx)
+}
+
+// HiddenLines counts the rows in [lo, hi] that collapses keep out of the
+// view; each collapse still shows one summary row.
+func (v *ViewWindow) HiddenLines(lo, hi int64) int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var n int64
+	for _, c := range v.collapses {
+		if c.Start >= lo && c.End <= hi {
+			n += c.End - c.Start
+		}
+	}
+	return n
+}
+
+// collapseAt finds the collapse in the sorted list cs covering gi.
+func collapseAt(cs []Collapse, gi int64) (Collapse, bool) {
+	i := sort.Search(len(cs), func(i int) bool { return cs[i].End >= gi })
+	if i < len(cs) && cs[i].Start <= gi {
+		return cs[i], true
+	}
+	return Collapse{}, false
+}
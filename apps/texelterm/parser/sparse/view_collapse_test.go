@@ -0,0 +1,103 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package sparse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+func collapseFixture() (*Store, *ViewWindow) {
+	s := NewStore(20)
+	for gi := int64(0); gi < 10; gi++ {
+		fillRow(s, gi, fmt.Sprintf("line %d", gi), false)
+	}
+	vw := NewViewWindow(20, 5)
+	vw.SetViewAnchor(0, 0)
+	vw.SetCollapse(Collapse{Start: 2, End: 6, Summary: []parser.Cell{{Rune: '>'}}})
+	return s, vw
+}
+
+func renderedText(out [][]parser.Cell) []string {
+	var rows []string
+	for _, row := range out {
+		rows = append(rows, strings.TrimRight(cellsToStringSparse(row), " "))
+	}
+	return rows
+}
+
+func TestCollapse_RenderShowsSummaryRow(t *testing.T) {
+	s, vw := collapseFixture()
+	out, rowGI := vw.Render(s)
+	want := []string{"line 0", "line 1", ">", "line 7", "line 8"}
+	if got := renderedText(out); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+	if fmt.Sprint(rowGI) != "[0 1 2 7 8]" {
+		t.Errorf("rowGI = %v", rowGI)
+	}
+	if row, _, ok := vw.CursorToView(s, 4, 3); !ok || row != 2 {
+		t.Errorf("hidden cursor maps to (%d, %v), want the summary row", row, ok)
+	}
+	if row, _, ok := vw.CursorToView(s, 8, 0); !ok || row != 4 {
+		t.Errorf("CursorToView(8) = (%d, %v), want row 4", row, ok)
+	}
+	if gi, _, ok := vw.ViewToCursor(s, 2, 0); !ok || gi != 2 {
+		t.Errorf("ViewToCursor(summary) = (%d, %v)", gi, ok)
+	}
+	if gi, _, ok := vw.ViewToCursor(s, 3, 0); !ok || gi != 7 {
+		t.Errorf("ViewToCursor(3) = (%d, %v)", gi, ok)
+	}
+
+	if !vw.Expand(5) || vw.Expand(5) {
+		t.Fatal("Expand should remove the collapse exactly once")
+	}
+	out, _ = vw.Render(s)
+	if got := renderedText(out); got[2] != "line 2" {
+		t.Errorf("after Expand row 2 = %q", got[2])
+	}
+}
+
+func TestCollapse_LiveAnchorCountsSummaryAsOneRow(t *testing.T) {
+	s, vw := collapseFixture()
+	vw.RecomputeLiveAnchor(s, 9, 0, 0)
+	out, _ := vw.Render(s)
+	want := []string{"line 1", ">", "line 7", "line 8", "line 9"}
+	if got := renderedText(out); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+	vw.OnWriteBottomChanged(9)
+	if top, bottom := vw.VisibleRange(); top != 1 || bottom != 9 {
+		t.Errorf("VisibleRange = (%d, %d), want (1, 9)", top, bottom)
+	}
+}
+
+func TestCollapse_ScrollStepsOverSummary(t *testing.T) {
+	s, vw := collapseFixture()
+	vw.SetViewAnchor(7, 0)
+	vw.ScrollUpRows(s, 1)
+	if anchor, _ := vw.Anchor(); anchor != 2 {
+		t.Fatalf("scrolling up onto a collapse anchors at %d, want 2", anchor)
+	}
+	vw.ScrollDownRows(s, 1, 100)
+	if anchor, _ := vw.Anchor(); anchor != 7 {
+		t.Fatalf("scrolling down past a collapse anchors at %d, want 7", anchor)
+	}
+
+	// Collapsing the rows under the anchor moves it to the summary.
+	vw.SetViewAnchor(4, 0)
+	vw.SetCollapse(Collapse{Start: 3, End: 5})
+	if anchor, _ := vw.Anchor(); anchor != 3 {
+		t.Errorf("anchor = %d, want 3", anchor)
+	}
+	if c, ok := vw.CollapseAt(6); ok {
+		t.Errorf("overlapping collapse should be replaced, found %+v", c)
+	}
+	if n := vw.HiddenLines(0, 9); n != 2 {
+		t.Errorf("HiddenLines = %d, want 2", n)
+	}
+}
@@ -145,6 +145,20 @@ func findChainStart(s *Store, gi int64, maxSteps int) int64 {
 	return start
 }
 
+// liveChainStart is findChainStart clamped at writeTop, so a chain that
+// spans writeTop yields only its live-side portion.
+func liveChainStart(s *Store, gi, writeTop int64, maxSteps int) int64 {
+	start := gi
+	for steps := 0; steps < maxSteps && start > writeTop; steps++ {
+		prevCells := s.GetLine(start - 1)
+		if len(prevCells) == 0 || !prevCells[len(prevCells)-1].Wrapped {
+			break
+		}
+		start--
+	}
+	return start
+}
+
 // rowHasPositionalGap reports whether the row at gi was placed via cursor
 // positioning (CUF/CUP) past existing content rather than written contiguously
 // from col 0. Concretely: the count of cells actually written via WriteCell
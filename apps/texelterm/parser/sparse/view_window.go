@@ -28,6 +28,10 @@ type ViewWindow struct {
 	viewAnchorOffset int
 	globalReflowOff  bool
 	autoJumpOnInput  bool
+
+	// collapses are store ranges drawn as one summary row, sorted by
+	// Start and non-overlapping; see view_collapse.go.
+	collapses []Collapse
 }
 
 // NewViewWindow creates a ViewWindow in autoFollow mode. viewBottom starts
@@ -109,6 +113,7 @@ func (v *ViewWindow) Render(s *Store) ([][]parser.Cell, []int64) {
 	anchor := v.viewAnchor
 	skip := v.viewAnchorOffset
 	reflowOff := v.globalReflowOff
+	collapses := v.collapses
 	v.mu.Unlock()
 
 	out := make([][]parser.Cell, 0, height)
@@ -121,6 +126,16 @@ func (v *ViewWindow) Render(s *Store) ([][]parser.Cell, []int64) {
 	gi := anchor
 	first := true
 	for len(out) < height {
+		// A collapsed range is one summary row, reported at its first gi.
+		if c, ok := collapseAt(collapses, gi); ok {
+			if !first || skip == 0 {
+				out = append(out, clipRow(c.Summary, width))
+				rowGI = append(rowGI, c.Start)
+			}
+			first = false
+			gi = c.End + 1
+			continue
+		}
 		// Gap / past content: emit a blank row for this gi and continue.
 		// Live mode may have interior gaps (EL/ED erasing lines inside the
 		// writeTop..writeTop+h-1 window), and the old Grid() path surfaced
@@ -222,6 +237,7 @@ func (v *ViewWindow) RecomputeLiveAnchor(s *Store, cursorGI int64, cursorCol int
 	width := v.width
 	reflowOff := v.globalReflowOff
 	autoFollow := v.autoFollow
+	collapses := v.collapses
 	v.mu.Unlock()
 
 	if !autoFollow {
@@ -262,6 +278,29 @@ func (v *ViewWindow) RecomputeLiveAnchor(s *Store, cursorGI int64, cursorCol int
 	accumulated := 0
 	gi := chainStart
 	for {
+		// A collapsed range counts as its one summary row.
+		if c, ok := collapseAt(collapses, gi); ok {
+			accumulated++
+			if accumulated >= height {
+				v.mu.Lock()
+				if !v.autoFollow {
+					v.mu.Unlock()
+					return
+				}
+				v.viewAnchor = c.Start
+				v.viewAnchorOffset = accumulated - height
+				v.mu.Unlock()
+				return
+			}
+			if c.Start <= writeTop {
+				break
+			}
+			gi = c.Start - 1
+			if len(s.GetLine(gi)) > 0 {
+				gi = liveChainStart(s, gi, writeTop, maxSteps)
+			}
+			continue
+		}
 		cells := s.GetLine(gi)
 		if len(cells) == 0 && !s.RowNoWrap(gi) {
 			accumulated++
@@ -290,19 +329,7 @@ func (v *ViewWindow) RecomputeLiveAnchor(s *Store, cursorGI int64, cursorCol int
 				gi = prevGI
 				continue
 			}
-			prevChainStart := prevGI
-			for steps := 0; steps < maxSteps && prevChainStart > writeTop; steps++ {
-				pp := prevChainStart - 1
-				ppCells := s.GetLine(pp)
-				if len(ppCells) == 0 {
-					break
-				}
-				if !ppCells[len(ppCells)-1].Wrapped {
-					break
-				}
-				prevChainStart = pp
-			}
-			gi = prevChainStart
+			gi = liveChainStart(s, prevGI, writeTop, maxSteps)
 			continue
 		}
 		end, nowrap := walkChain(s, gi, maxSteps)
@@ -337,19 +364,7 @@ func (v *ViewWindow) RecomputeLiveAnchor(s *Store, cursorGI int64, cursorCol int
 			gi = prevGI
 			continue
 		}
-		prevChainStart := prevGI
-		for steps := 0; steps < maxSteps && prevChainStart > writeTop; steps++ {
-			pp := prevChainStart - 1
-			ppCells := s.GetLine(pp)
-			if len(ppCells) == 0 {
-				break
-			}
-			if !ppCells[len(ppCells)-1].Wrapped {
-				break
-			}
-			prevChainStart = pp
-		}
-		gi = prevChainStart
+		gi = liveChainStart(s, prevGI, writeTop, maxSteps)
 	}
 
 	// First-stage walk hit writeTop without filling the viewport. If the
@@ -379,6 +394,25 @@ func (v *ViewWindow) RecomputeLiveAnchor(s *Store, cursorGI int64, cursorCol int
 	// scrollback portion would duplicate cells.
 	gi = writeTop - 1
 	for accumulated < height && gi >= 0 {
+		if c, ok := collapseAt(collapses, gi); ok {
+			// A collapse reaching writeTop was counted above.
+			if c.End < writeTop {
+				accumulated++
+				if accumulated >= height {
+					v.mu.Lock()
+					if !v.autoFollow {
+						v.mu.Unlock()
+						return
+					}
+					v.viewAnchor = c.Start
+					v.viewAnchorOffset = accumulated - height
+					v.mu.Unlock()
+					return
+				}
+			}
+			gi = c.Start - 1
+			continue
+		}
 		cells := s.GetLine(gi)
 		if len(cells) == 0 && !s.RowNoWrap(gi) {
 			accumulated++
@@ -449,12 +483,32 @@ func (v *ViewWindow) CursorToView(s *Store, cursorGI int64, cursorCol int) (view
 	height, width := v.height, v.width
 	anchor, offset := v.viewAnchor, v.viewAnchorOffset
 	reflowOff := v.globalReflowOff
+	collapses := v.collapses
 	v.mu.Unlock()
 
 	emitted := 0
 	gi := anchor
 	maxSteps := 4 * height
 	for emitted < height {
+		if c, ok := collapseAt(collapses, gi); ok {
+			startAt := 0
+			if gi == anchor {
+				startAt = offset
+			}
+			if cursorGI >= c.Start && cursorGI <= c.End {
+				// The cursor is hidden; report the summary row.
+				if startAt > 0 {
+					return 0, 0, false
+				}
+				return emitted, 0, true
+			}
+			emitted += 1 - startAt
+			gi = c.End + 1
+			if s.GetLine(gi) == nil {
+				break
+			}
+			continue
+		}
 		end, nowrap := walkChain(s, gi, maxSteps)
 		if reflowOff {
 			nowrap = true
@@ -565,6 +619,7 @@ func (v *ViewWindow) ViewToCursor(s *Store, viewRow, viewCol int) (globalIdx int
 	height, width := v.height, v.width
 	anchor, offset := v.viewAnchor, v.viewAnchorOffset
 	reflowOff := v.globalReflowOff
+	collapses := v.collapses
 	v.mu.Unlock()
 
 	if viewRow < 0 || viewRow >= height {
@@ -575,6 +630,19 @@ func (v *ViewWindow) ViewToCursor(s *Store, viewRow, viewCol int) (globalIdx int
 	gi := anchor
 	maxSteps := 4 * height
 	for emitted < height {
+		if c, ok := collapseAt(collapses, gi); ok {
+			startAt := 0
+			if gi == anchor {
+				startAt = offset
+			}
+			if startAt == 0 && viewRow == emitted {
+				// The summary row stands for the first hidden row.
+				return c.Start, viewCol, true
+			}
+			emitted += 1 - startAt
+			gi = c.End + 1
+			continue
+		}
 		if s.GetLine(gi) == nil {
 			break
 		}
@@ -638,7 +706,18 @@ func (v *ViewWindow) IsFollowing() bool {
 func (v *ViewWindow) VisibleRange() (top, bottom int64) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
-	return v.viewBottom - int64(v.height) + 1, v.viewBottom
+	if len(v.collapses) == 0 {
+		return v.viewBottom - int64(v.height) + 1, v.viewBottom
+	}
+	// Each collapse takes one row however many lines it hides.
+	top = v.viewBottom + 1
+	for rows := 0; rows < v.height; rows++ {
+		top--
+		if c, ok := collapseAt(v.collapses, top); ok {
+			top = c.Start
+		}
+	}
+	return top, v.viewBottom
 }
 
 // OnWriteBottomChanged is called when the bottom of the write window moves.
@@ -692,6 +771,14 @@ func (v *ViewWindow) ScrollUpRows(s *Store, n int) {
 	}
 	for remaining > 0 && v.viewAnchor > 0 {
 		prevGI := v.viewAnchor - 1
+		if c, ok := collapseAt(v.collapses, prevGI); ok {
+			// One row on screen; viewBottom still tracks the hidden rows.
+			remaining--
+			walked++
+			v.viewBottom -= c.End - c.Start
+			v.viewAnchor = c.Start
+			continue
+		}
 		prevStart := findChainStart(s, prevGI, maxSteps)
 		end, nowrap := walkChain(s, prevStart, maxSteps)
 		if reflowOff {
@@ -766,6 +853,16 @@ func (v *ViewWindow) ScrollDownRows(s *Store, n int, writeBottom int64) {
 	remaining := int(capped)
 	walked := 0
 	for remaining > 0 {
+		if c, ok := collapseAt(v.collapses, v.viewAnchor); ok {
+			if v.viewAnchorOffset == 0 {
+				remaining--
+				walked++
+			}
+			v.viewBottom += c.End - c.Start
+			v.viewAnchor = c.End + 1
+			v.viewAnchorOffset = 0
+			continue
+		}
 		cells := s.GetLine(v.viewAnchor)
 		if len(cells) == 0 && !s.RowNoWrap(v.viewAnchor) {
 			// 1-row empty chain.
@@ -10,6 +10,7 @@ package parser
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 
@@ -71,6 +72,7 @@ type VTerm struct {
 	InputStartGlobalLine   int64  // Global line index of last OSC 133;B (input start); -1 = unknown
 	CommandStartGlobalLine int64  // Global line index of last OSC 133;C while a command is running; -1 = not in-command
 	CurrentWorkingDir      string // Last known CWD from OSC 7
+	OnWorkingDirChange     func(dir string)
 	// Clipboard operations (OSC 52)
 	OnClipboardSet func(data []byte) // Called when app sets clipboard via OSC 52
 	OnClipboardGet func() []byte     // Called when app queries clipboard via OSC 52
@@ -538,6 +540,10 @@ func (v *VTerm) MarkCommandStart() {
 
 // setWorkingDirectory parses an OSC 7 file URI and stores the path.
 // Format: file://hostname/path or file:///path
+//
+// The hostname must be empty, localhost or this machine's name: anything
+// printed to the terminal (a cat-ed file, a remote session) can emit OSC 7,
+// and the cwd is later used to run commands such as git status.
 func (v *VTerm) setWorkingDirectory(uri string) {
 	// Strip "file://" prefix
 	const prefix = "file://"
@@ -545,12 +551,32 @@ func (v *VTerm) setWorkingDirectory(uri string) {
 		return
 	}
 	rest := uri[len(prefix):]
-	// Skip hostname (everything before the first '/' after the prefix)
+	// Split off the hostname (everything before the first '/' after the prefix)
 	idx := strings.Index(rest, "/")
-	if idx < 0 {
+	if idx < 0 || !isLocalHost(rest[:idx]) {
+		return
+	}
+	v.setCurrentWorkingDir(rest[idx:])
+}
+
+// isLocalHost reports whether an OSC 7 hostname names this machine.
+func isLocalHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	name, err := os.Hostname()
+	return err == nil && strings.EqualFold(host, name)
+}
+
+// setCurrentWorkingDir stores dir and reports a change to OnWorkingDirChange.
+func (v *VTerm) setCurrentWorkingDir(dir string) {
+	if dir == v.CurrentWorkingDir {
 		return
 	}
-	v.CurrentWorkingDir = rest[idx:]
+	v.CurrentWorkingDir = dir
+	if v.OnWorkingDirChange != nil {
+		v.OnWorkingDirChange(dir)
+	}
 }
 
 // LastWorkingDir returns the last known working directory from OSC 7.
@@ -588,8 +614,17 @@ func (v *VTerm) ViewportToContent(y, x int) (logicalLine int64, charOffset int,
 	if v.mainScreen == nil {
 		return 0, 0, false, false
 	}
-	visibleTop, _ := v.mainScreen.VisibleRange()
+	visibleTop, visibleBottom := v.mainScreen.VisibleRange()
 	logicalLine = visibleTop + int64(y)
+	if v.mainScreen.HiddenLines(visibleTop, visibleBottom) > 0 {
+		logicalLine = visibleTop
+		for row := 0; row < y; row++ {
+			if _, end, collapsed := v.mainScreen.CollapsedAt(logicalLine); collapsed {
+				logicalLine = end
+			}
+			logicalLine++
+		}
+	}
 	charOffset = x
 	// Check if this is the current cursor line.
 	cursorLine, _ := v.mainScreen.Cursor()
@@ -620,6 +655,20 @@ func (v *VTerm) ContentToViewport(logicalLine int64, charOffset int) (y, x int,
 		return 0, 0, false
 	}
 	y = int(rowOffset)
+	if v.mainScreen.HiddenLines(visibleTop, logicalLine) > 0 {
+		// Collapsed lines share their summary row.
+		y = 0
+		for gi := visibleTop; gi < logicalLine; gi++ {
+			if start, end, collapsed := v.mainScreen.CollapsedAt(gi); collapsed {
+				if logicalLine <= end {
+					gi = start
+					break
+				}
+				gi = end
+			}
+			y++
+		}
+	}
 	x = charOffset
 	visible = y >= 0 && y < v.height
 	return
@@ -1307,6 +1356,10 @@ func WithCommandEndHandler(handler func(int)) Option {
 	return func(v *VTerm) { v.OnCommandEnd = handler }
 }
 
+func WithWorkingDirChangeHandler(handler func(string)) Option {
+	return func(v *VTerm) { v.OnWorkingDirChange = handler }
+}
+
 func WithEnvironmentUpdateHandler(handler func(string)) Option {
 	return func(v *VTerm) { v.OnEnvironmentUpdate = handler }
 }
@@ -24,3 +24,38 @@ func TestVTermIsInTUIMode(t *testing.T) {
 		t.Error("IsInTUIMode should be false by default (no fixed width detector)")
 	}
 }
+
+func TestVTermModeReplaySequence(t *testing.T) {
+	feed := func(v *VTerm, s string) {
+		p := NewParser(v)
+		for _, r := range s {
+			p.Parse(r)
+		}
+	}
+
+	if seq := NewVTerm(80, 24).ModeReplaySequence(); len(seq) != 0 {
+		t.Fatalf("default VTerm should replay nothing, got %q", seq)
+	}
+
+	src := NewVTerm(80, 24)
+	feed(src, "\x1b[?1049h\x1b[?1h\x1b[?25l\x1b[?2004h\x1b[?7l")
+
+	dst := NewVTerm(80, 24)
+	feed(dst, string(src.ModeReplaySequence()))
+
+	if !dst.InAltScreen() {
+		t.Error("alt screen not replayed")
+	}
+	if !dst.AppCursorKeys() {
+		t.Error("application cursor keys not replayed")
+	}
+	if dst.CursorVisible() {
+		t.Error("hidden cursor not replayed")
+	}
+	if !dst.IsBracketedPasteModeEnabled() {
+		t.Error("bracketed paste not replayed")
+	}
+	if dst.autoWrapMode {
+		t.Error("autowrap reset not replayed")
+	}
+}
@@ -95,7 +95,7 @@ func (v *VTerm) EnableMemoryBufferWithDisk(diskPath string, opts MemoryBufferOpt
 		} else {
 			v.CommandStartGlobalLine = recoveredMeta.CommandStartLine
 		}
-		v.CurrentWorkingDir = recoveredMeta.WorkingDir
+		v.setCurrentWorkingDir(recoveredMeta.WorkingDir)
 		// Sync VTerm's cursor to the restored state so the next write
 		// lands at the correct row in the viewport. Without this, VTerm's
 		// cursorY stays 0 and subsequent writes overwrite the top row.
@@ -677,6 +677,8 @@ func (v *VTerm) ScrollToGlobalLine(globalLineIdx int64) bool {
 	if globalLineIdx < 0 || globalLineIdx >= contentEnd {
 		return false
 	}
+	// A line hidden in a collapse (e.g. a search hit) is shown expanded.
+	v.mainScreen.ExpandLines(globalLineIdx)
 	// Compute how far from the bottom this line is.
 	// writeBottom is the last writable row (writeTop + height - 1).
 	writeBottom := v.mainScreen.WriteBottom()
@@ -697,15 +699,51 @@ func (v *VTerm) ScrollToGlobalLine(globalLineIdx int64) bool {
 	}
 	current := v.ScrollOffset()
 	diff := targetOffset - current
+	// Offsets are in lines but scrolling is in view rows, and a collapse
+	// between the two positions shows its lines as one row.
+	_, viewBottom := v.mainScreen.VisibleRange()
 	if diff > 0 {
-		v.mainScreen.ScrollUp(int(diff))
+		diff -= v.mainScreen.HiddenLines(viewBottom-diff, viewBottom)
+		if diff > 0 {
+			v.mainScreen.ScrollUp(int(diff))
+		}
 	} else if diff < 0 {
-		v.mainScreen.ScrollDown(int(-diff))
+		diff += v.mainScreen.HiddenLines(viewBottom, viewBottom-diff)
+		if diff < 0 {
+			v.mainScreen.ScrollDown(int(-diff))
+		}
+	}
+	v.MarkAllDirty()
+	return true
+}
+
+// CollapseLines hides the lines [start, end] behind a one-row summary in
+// the main screen view. The lines stay in history.
+func (v *VTerm) CollapseLines(start, end int64, summary []Cell) {
+	if v.mainScreen == nil {
+		return
+	}
+	v.mainScreen.CollapseLines(start, end, summary)
+	v.MarkAllDirty()
+}
+
+// ExpandLines shows the collapsed lines covering globalLineIdx again.
+func (v *VTerm) ExpandLines(globalLineIdx int64) bool {
+	if v.mainScreen == nil || !v.mainScreen.ExpandLines(globalLineIdx) {
+		return false
 	}
 	v.MarkAllDirty()
 	return true
 }
 
+// CollapsedAt returns the collapsed range covering globalLineIdx.
+func (v *VTerm) CollapsedAt(globalLineIdx int64) (start, end int64, ok bool) {
+	if v.mainScreen == nil {
+		return 0, 0, false
+	}
+	return v.mainScreen.CollapsedAt(globalLineIdx)
+}
+
 // mainScreenEraseCharacters erases n characters starting at the cursor position.
 func (v *VTerm) mainScreenEraseCharacters(n int) {
 	if v.mainScreen == nil {
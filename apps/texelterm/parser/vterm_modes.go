@@ -130,3 +130,38 @@ func (v *VTerm) processPrivateCSI(command rune, params []int) {
 		}
 	}
 }
+
+// ModeReplaySequence returns DECSET/DECRST sequences that reproduce the
+// terminal modes a running application has switched away from their
+// defaults. Fed through a fresh parser, it brings a new VTerm into the
+// same mode state without the application re-sending anything — used
+// when a live PTY is handed to a new server during an upgrade. Screen
+// contents are not included; the main screen is recovered from the WAL
+// and alt-screen apps repaint on the SIGWINCH that follows adoption.
+func (v *VTerm) ModeReplaySequence() []byte {
+	var seq []byte
+	set := func(mode string) { seq = append(seq, "\x1b[?"+mode+"h"...) }
+	reset := func(mode string) { seq = append(seq, "\x1b[?"+mode+"l"...) }
+
+	// Alt screen first: entering it clears the alt buffer, which must not
+	// run after the other modes have been applied.
+	if v.inAltScreen {
+		set("1049")
+	}
+	if v.appCursorKeys {
+		set("1")
+	}
+	if !v.autoWrapMode {
+		reset("7")
+	}
+	if !v.cursorVisible {
+		reset("25")
+	}
+	if v.bracketedPasteMode {
+		set("2004")
+	}
+	if v.insertMode {
+		seq = append(seq, "\x1b[4h"...)
+	}
+	return seq
+}
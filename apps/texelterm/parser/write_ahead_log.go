@@ -33,6 +33,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -117,6 +118,9 @@ type WriteAheadLog struct {
 	// Owned PageStore
 	pageStore *PageStore
 
+	// Shared lock on wal.lock while open, so fsck --repair keeps off
+	lockFile *os.File
+
 	// State
 	walSize        int64  // Current WAL file size
 	entriesWritten int64  // Entries since last checkpoint
@@ -159,13 +163,27 @@ func openWriteAheadLogWithNow(config WALConfig, nowFunc func() time.Time) (*Writ
 		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
 	}
 
+	// Lock before opening or recovering anything, so a repair never sees
+	// a history half-opened and this open never reads one half-repaired.
+	lock, err := lockHistoryWait(config.WALDir, syscall.LOCK_SH, historyLockWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock WAL: %w", err)
+	}
+	opened := false
+	defer func() {
+		if !opened {
+			lock.Close()
+		}
+	}()
+
 	walPath := filepath.Join(config.WALDir, "wal.log")
 
 	w := &WriteAheadLog{
-		config:  config,
-		walPath: walPath,
-		nowFunc: nowFunc,
-		stopCh:  make(chan struct{}),
+		config:   config,
+		walPath:  walPath,
+		lockFile: lock,
+		nowFunc:  nowFunc,
+		stopCh:   make(chan struct{}),
 	}
 
 	// Copy terminal ID to header
@@ -222,6 +240,7 @@ func openWriteAheadLogWithNow(config WALConfig, nowFunc func() time.Time) (*Writ
 		w.startCheckpointTimer()
 	}
 
+	opened = true
 	return w, nil
 }
 
@@ -1087,6 +1106,7 @@ func (w *WriteAheadLog) checkpointLocked() error {
 	if w.stopped {
 		return nil
 	}
+	defer recordCheckpoint(time.Now())
 
 	// Sync WAL file before reading to ensure all writes are on disk
 	if err := w.walFile.Sync(); err != nil {
@@ -1449,6 +1469,9 @@ func (w *WriteAheadLog) Close() error {
 	if w.pageStore != nil {
 		psErr = w.pageStore.Close()
 	}
+	if w.lockFile != nil {
+		w.lockFile.Close()
+	}
 
 	if checkpointErr != nil {
 		return checkpointErr
@@ -0,0 +1,518 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/paste_safety.go
+// Summary: Paste sanitisation, confirmation of risky pastes and chunked
+// streaming of large ones.
+// Usage: HandlePaste filters the clipboard text, asks before sending a
+//   risky paste and streams multi-megabyte pastes from a goroutine. The
+//   "texelterm.paste" config section tunes it:
+//     sanitize: "strict" drops escape sequences and control characters,
+//       "markers" only drops bracketed paste markers, "off" sends as is.
+//     confirm: "auto" asks for multi-line pastes without bracketed paste
+//       and for pastes that look dangerous, "always" also asks for
+//       multi-line pastes under bracketed paste, "never" never asks.
+//     stream_threshold_bytes, chunk_bytes, chunk_delay_ms: pastes at
+//       least this large are written in chunks with a pause in between.
+//       Anything over maxInlinePaste streams regardless: only what the
+//       PTY buffers is written from the desktop loop.
+// Notes: An embedded "\x1b[201~" would end bracketed paste early and let
+//   the rest of the text run as typed input, so every mode but "off"
+//   removes the markers. While a paste streams, keys are held back so
+//   they don't land in its middle; Esc or Ctrl+C cancels it.
+
+package texelterm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/framegrace/texelui/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// maxInlinePaste is the largest paste written synchronously. A PTY
+	// buffers a few KiB; past that the write waits for the program to
+	// read, which must not happen on the desktop loop.
+	maxInlinePaste = 4096
+
+	defaultPasteStreamThreshold = maxInlinePaste
+	defaultPasteChunkBytes      = 4096
+	defaultPasteChunkDelay      = 2 * time.Millisecond
+	pastePreviewLines           = 3
+)
+
+var (
+	bracketedPasteStart = []byte("\x1b[200~")
+	bracketedPasteEnd   = []byte("\x1b[201~")
+)
+
+// riskyPastePatterns flag pastes worth a second look even when bracketed
+// paste keeps them from running on their own.
+var riskyPastePatterns = []struct {
+	re     *regexp.Regexp
+	reason string
+}{
+	{regexp.MustCompile(`(?m)(^|[;&|(\s])sudo\s`), "runs sudo"},
+	{regexp.MustCompile(`\b(curl|wget|fetch)\b[^\n|]*\|\s*(sudo\s+)?(ba|da|z|k|fi)?sh\b`), "pipes a download into a shell"},
+	{regexp.MustCompile(`\brm\s+(-\S+\s+)*-[a-zA-Z]*[rR]`), "removes files recursively"},
+}
+
+// pasteSettings is the "texelterm.paste" config section.
+type pasteSettings struct {
+	sanitize        string
+	confirm         string
+	streamThreshold int
+	chunkBytes      int
+	chunkDelay      time.Duration
+}
+
+func (a *TexelTerm) pasteSettings() pasteSettings {
+	cfg := a.paneConfig()
+	ps := pasteSettings{
+		sanitize:        cfg.GetString("texelterm.paste", "sanitize", "strict"),
+		confirm:         cfg.GetString("texelterm.paste", "confirm", "auto"),
+		streamThreshold: cfg.GetInt("texelterm.paste", "stream_threshold_bytes", defaultPasteStreamThreshold),
+		chunkBytes:      cfg.GetInt("texelterm.paste", "chunk_bytes", defaultPasteChunkBytes),
+		chunkDelay:      time.Duration(cfg.GetInt("texelterm.paste", "chunk_delay_ms", int(defaultPasteChunkDelay/time.Millisecond))) * time.Millisecond,
+	}
+	if ps.streamThreshold <= 0 {
+		ps.streamThreshold = defaultPasteStreamThreshold
+	}
+	if ps.chunkBytes <= 0 {
+		ps.chunkBytes = defaultPasteChunkBytes
+	}
+	return ps
+}
+
+// pendingPaste is a paste waiting for the user's confirmation.
+type pendingPaste struct {
+	data    []byte
+	reasons []string
+	lines   int
+}
+
+// pasteStream tracks a paste being written in chunks. written is guarded
+// by a.mu.
+type pasteStream struct {
+	total    int
+	written  int
+	cancel   chan struct{}
+	stopOnce sync.Once
+}
+
+func (st *pasteStream) stop() {
+	st.stopOnce.Do(func() { close(st.cancel) })
+}
+
+// sanitizePaste filters data according to mode and reports how many bytes
+// it removed.
+func sanitizePaste(data []byte, mode string) ([]byte, int) {
+	switch mode {
+	case "off":
+		return data, 0
+	case "markers":
+		out := data
+		for {
+			stripped := bytes.ReplaceAll(bytes.ReplaceAll(out, bracketedPasteStart, nil), bracketedPasteEnd, nil)
+			if len(stripped) == len(out) {
+				break
+			}
+			out = stripped // removing one marker may join the halves of another
+		}
+		return out, len(data) - len(out)
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == 0x1b:
+			i = skipEscapeSequence(data, i)
+		case c == '\t' || c == '\n' || c == '\r':
+			out = append(out, c)
+			i++
+		case c < 0x20 || c == 0x7f:
+			i++
+		case c >= 0x80:
+			r, size := utf8.DecodeRune(data[i:])
+			if (r >= 0x80 && r <= 0x9f) || (r == utf8.RuneError && size == 1 && c <= 0x9f) {
+				i += size // C1 controls, encoded or as raw 8-bit bytes
+				continue
+			}
+			out = append(out, data[i:i+size]...)
+			i += size
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out, len(data) - len(out)
+}
+
+// skipEscapeSequence returns the index just past the escape sequence that
+// starts at data[i].
+func skipEscapeSequence(data []byte, i int) int {
+	i++
+	if i >= len(data) {
+		return i
+	}
+	switch data[i] {
+	case '[': // CSI: parameters and intermediates, then a final byte
+		for i++; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return i + 1
+			}
+			if data[i] < 0x20 || data[i] > 0x7e {
+				return i // malformed; let the caller filter this byte
+			}
+		}
+		return i
+	case ']', 'P', '_', '^', 'X': // strings ended by BEL or ST
+		for i++; i < len(data); i++ {
+			if data[i] == 0x07 {
+				return i + 1
+			}
+			if data[i] == 0x1b && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	}
+	if data[i] >= 0x20 && data[i] <= 0x7e {
+		return i + 1
+	}
+	return i
+}
+
+// pasteLines counts the lines in data, ignoring trailing line breaks.
+func pasteLines(data []byte) int {
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\r\n")
+	if text == "" {
+		return 0
+	}
+	return strings.Count(strings.ReplaceAll(text, "\r", "\n"), "\n") + 1
+}
+
+// pasteRisks lists why data should be confirmed before it is pasted, or
+// nothing when it can go straight through.
+func pasteRisks(data []byte, bracketed bool, removed int, confirm string) []string {
+	if confirm == "never" {
+		return nil
+	}
+	var reasons []string
+	if removed > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d bytes of control sequences removed", removed))
+	}
+	if lines := pasteLines(data); lines > 1 {
+		switch {
+		case !bracketed:
+			reasons = append(reasons, fmt.Sprintf("%d lines will run as they arrive", lines))
+		case confirm == "always":
+			reasons = append(reasons, fmt.Sprintf("%d lines", lines))
+		}
+	}
+	for _, p := range riskyPastePatterns {
+		if p.re.Match(data) {
+			reasons = append(reasons, p.reason)
+		}
+	}
+	return reasons
+}
+
+// normalizePaste converts line endings the way the receiving side expects:
+// LF under bracketed paste, CR (the Enter key) otherwise.
+func normalizePaste(data []byte, bracketed bool) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n':
+			if bracketed {
+				out = append(out, '\n')
+			} else {
+				out = append(out, '\r')
+			}
+			i++ // skip the LF
+		case data[i] == '\n' && !bracketed:
+			out = append(out, '\r')
+		default:
+			out = append(out, data[i])
+		}
+	}
+	return out
+}
+
+// writePaste sends data to the PTY, streaming it from a goroutine unless
+// it fits the PTY's buffer.
+func (a *TexelTerm) writePaste(data []byte, ps pasteSettings) {
+	pty := a.pty
+	if pty == nil {
+		return
+	}
+	bracketed := a.bracketedPasteMode
+	body := normalizePaste(data, bracketed)
+	size := len(body)
+	if bracketed {
+		size += len(bracketedPasteStart) + len(bracketedPasteEnd)
+	}
+	if len(body) >= ps.streamThreshold || size > maxInlinePaste {
+		st := &pasteStream{total: len(body), cancel: make(chan struct{})}
+		a.mu.Lock()
+		a.pasteStream = st
+		a.mu.Unlock()
+		a.requestRefresh()
+		go a.streamPaste(st, pty, body, bracketed, ps)
+		return
+	}
+	payload := body
+	if bracketed {
+		payload = make([]byte, 0, len(body)+len(bracketedPasteStart)+len(bracketedPasteEnd))
+		payload = append(payload, bracketedPasteStart...)
+		payload = append(payload, body...)
+		payload = append(payload, bracketedPasteEnd...)
+	}
+	if _, err := pty.Write(payload); err != nil {
+		log.Printf("TexelTerm: paste write failed: %v", err)
+	}
+}
+
+// streamPaste writes body in chunks so neither the PTY nor the desktop
+// loop stalls on a huge paste. A cancelled paste still closes the bracket
+// so the application leaves paste mode.
+func (a *TexelTerm) streamPaste(st *pasteStream, pty io.Writer, body []byte, bracketed bool, ps pasteSettings) {
+	defer func() {
+		a.mu.Lock()
+		if a.pasteStream == st {
+			a.pasteStream = nil
+		}
+		if a.vterm != nil {
+			a.vterm.MarkAllDirty()
+		}
+		a.mu.Unlock()
+		a.requestRefresh()
+	}()
+	if bracketed {
+		if _, err := pty.Write(bracketedPasteStart); err != nil {
+			log.Printf("TexelTerm: paste prefix write failed: %v", err)
+			return
+		}
+		defer func() {
+			if _, err := pty.Write(bracketedPasteEnd); err != nil {
+				log.Printf("TexelTerm: paste suffix write failed: %v", err)
+			}
+		}()
+	}
+	for off := 0; off < len(body); {
+		end := min(off+ps.chunkBytes, len(body))
+		if _, err := pty.Write(body[off:end]); err != nil {
+			log.Printf("TexelTerm: paste write failed after %d of %d bytes: %v", off, len(body), err)
+			return
+		}
+		off = end
+		a.mu.Lock()
+		st.written = off
+		a.mu.Unlock()
+		a.requestRefresh()
+		if off == len(body) {
+			break
+		}
+		select {
+		case <-st.cancel:
+			log.Printf("TexelTerm: paste cancelled after %d of %d bytes", off, len(body))
+			return
+		case <-time.After(ps.chunkDelay):
+		}
+	}
+}
+
+// handlePasteKey answers the paste confirmation and holds keys back while
+// a paste streams. Returns true if the key was consumed.
+// Caller must hold a.mu; the lock is released while a confirmed paste is
+// written.
+func (a *TexelTerm) handlePasteKey(ev *tcell.EventKey) bool {
+	if st := a.pasteStream; st != nil {
+		if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
+			st.stop()
+		}
+		return true
+	}
+	p := a.pastePending
+	if p == nil {
+		return false
+	}
+	accept := ev.Key() == tcell.KeyEnter || (ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y'))
+	reject := ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && (ev.Rune() == 'n' || ev.Rune() == 'N'))
+	if !accept && !reject {
+		return true // absorb other keys while the dialog is shown
+	}
+	a.pastePending = nil
+	if a.vterm != nil {
+		a.vterm.MarkAllDirty()
+	}
+	a.requestRefresh()
+	if accept {
+		a.mu.Unlock()
+		a.writePaste(p.data, a.pasteSettings())
+		a.mu.Lock()
+	}
+	return true
+}
+
+// drawPasteOverlay draws the confirmation dialog or the streaming progress
+// line. Caller holds a.mu.
+func (a *TexelTerm) drawPasteOverlay(buf [][]texelcore.Cell) {
+	if len(buf) == 0 || len(buf[0]) == 0 {
+		return
+	}
+	tm := theme.Get()
+	bgColor := tm.GetSemanticColor("bg.surface")
+	fgColor := tm.GetSemanticColor("text.primary")
+	mutedColor := tm.GetSemanticColor("text.muted")
+	warnColor := tm.GetSemanticColor("action.warning")
+	if bgColor == tcell.ColorDefault {
+		bgColor = tcell.NewRGBColor(30, 30, 46)
+	}
+	if fgColor == tcell.ColorDefault {
+		fgColor = tcell.NewRGBColor(205, 214, 244)
+	}
+	if mutedColor == tcell.ColorDefault {
+		mutedColor = tcell.NewRGBColor(108, 112, 134)
+	}
+	if warnColor == tcell.ColorDefault {
+		warnColor = tcell.NewRGBColor(249, 226, 175)
+	}
+	bgStyle := tcell.StyleDefault.Background(bgColor.TrueColor()).Foreground(fgColor.TrueColor())
+	mutedStyle := bgStyle.Foreground(mutedColor.TrueColor())
+	warnStyle := bgStyle.Foreground(warnColor.TrueColor())
+
+	if st := a.pasteStream; st != nil {
+		drawPasteProgress(buf, st, bgStyle, warnStyle)
+		return
+	}
+	if a.pastePending != nil {
+		drawPasteConfirm(buf, a.pastePending, bgStyle, mutedStyle, warnStyle)
+	}
+}
+
+func drawPasteConfirm(buf [][]texelcore.Cell, p *pendingPaste, bgStyle, mutedStyle, warnStyle tcell.Style) {
+	bufH, bufW := len(buf), len(buf[0])
+
+	type line struct {
+		text  string
+		style tcell.Style
+	}
+	var lines []line
+	for _, r := range p.reasons {
+		lines = append(lines, line{"• " + r, warnStyle})
+	}
+	lines = append(lines, line{"", bgStyle})
+	preview := strings.Split(strings.TrimRight(strings.ReplaceAll(strings.ReplaceAll(string(p.data), "\r\n", "\n"), "\r", "\n"), "\n"), "\n")
+	for i, l := range preview {
+		if i == pastePreviewLines {
+			lines = append(lines, line{fmt.Sprintf("  … %d more lines", len(preview)-i), mutedStyle})
+			break
+		}
+		lines = append(lines, line{"  " + strings.ReplaceAll(l, "\t", "    "), mutedStyle})
+	}
+	lines = append(lines, line{"", bgStyle}, line{"Enter/y: paste   Esc/n: cancel", bgStyle})
+
+	title := fmt.Sprintf(" Paste %d line(s)? ", max(p.lines, 1))
+	boxW := utf8.RuneCountInString(title) + 4
+	for _, l := range lines {
+		boxW = max(boxW, utf8.RuneCountInString(l.text)+4)
+	}
+	boxW = min(boxW, 72, bufW)
+	boxH := min(len(lines)+2, bufH)
+	x := max((bufW-boxW)/2, 0)
+	y := max((bufH-boxH)/2, 0)
+
+	for row := 0; row < boxH; row++ {
+		for col := 0; col < boxW; col++ {
+			setCell(buf, x+col, y+row, ' ', bgStyle, bufW, bufH)
+		}
+	}
+	for col := 1; col < boxW-1; col++ {
+		setCell(buf, x+col, y, tcell.RuneHLine, warnStyle, bufW, bufH)
+		setCell(buf, x+col, y+boxH-1, tcell.RuneHLine, warnStyle, bufW, bufH)
+	}
+	for row := 1; row < boxH-1; row++ {
+		setCell(buf, x, y+row, tcell.RuneVLine, warnStyle, bufW, bufH)
+		setCell(buf, x+boxW-1, y+row, tcell.RuneVLine, warnStyle, bufW, bufH)
+	}
+	setCell(buf, x, y, tcell.RuneULCorner, warnStyle, bufW, bufH)
+	setCell(buf, x+boxW-1, y, tcell.RuneURCorner, warnStyle, bufW, bufH)
+	setCell(buf, x, y+boxH-1, tcell.RuneLLCorner, warnStyle, bufW, bufH)
+	setCell(buf, x+boxW-1, y+boxH-1, tcell.RuneLRCorner, warnStyle, bufW, bufH)
+
+	col := x + 2
+	for _, r := range title {
+		if col >= x+boxW-1 {
+			break
+		}
+		setCell(buf, col, y, r, warnStyle.Bold(true), bufW, bufH)
+		col++
+	}
+	for i, l := range lines {
+		row := y + 1 + i
+		if row >= y+boxH-1 {
+			break
+		}
+		col := x + 2
+		for _, r := range l.text {
+			if col >= x+boxW-2 {
+				setCell(buf, x+boxW-3, row, '…', l.style, bufW, bufH)
+				break
+			}
+			setCell(buf, col, row, r, l.style, bufW, bufH)
+			col++
+		}
+	}
+}
+
+func drawPasteProgress(buf [][]texelcore.Cell, st *pasteStream, bgStyle, barStyle tcell.Style) {
+	bufH, bufW := len(buf), len(buf[0])
+	y := bufH - 1
+	pct := 0
+	if st.total > 0 {
+		pct = st.written * 100 / st.total
+	}
+	label := fmt.Sprintf(" Pasting %s of %s  %3d%% ", formatPasteSize(st.written), formatPasteSize(st.total), pct)
+	hint := " Esc: cancel "
+	barW := bufW - utf8.RuneCountInString(label) - utf8.RuneCountInString(hint) - 2
+	text := label
+	if barW >= 4 {
+		filled := barW * pct / 100
+		text += strings.Repeat("█", filled) + strings.Repeat("░", barW-filled)
+	}
+	text += " " + hint
+	col := 0
+	for _, r := range text {
+		if col >= bufW {
+			break
+		}
+		setCell(buf, col, y, r, barStyle, bufW, bufH)
+		col++
+	}
+	for ; col < bufW; col++ {
+		setCell(buf, col, y, ' ', bgStyle, bufW, bufH)
+	}
+}
+
+// formatPasteSize renders n bytes as B, KiB or MiB.
+func formatPasteSize(n int) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,267 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/paste_safety_test.go
+// Summary: Exercises paste sanitisation, confirmation and streaming.
+
+package texelterm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct {
+		mode, in, want string
+	}{
+		{"strict", "echo hi\x1b[201~; ls\x1b]0;title\x07\x1b[31mred\x1b[0m\x00", "echo hi; lsred"},
+		{"strict", "tab\there\r\nnext\x7f \u009b2Jcafé \x9bx", "tab\there\r\nnext 2Jcafé x"},
+		{"markers", "a\x1b[20\x1b[201~0~b\x1b[31m", "ab\x1b[31m"},
+		{"off", "a\x1b[201~b", "a\x1b[201~b"},
+	}
+	for _, tt := range tests {
+		got, removed := sanitizePaste([]byte(tt.in), tt.mode)
+		if string(got) != tt.want {
+			t.Errorf("sanitizePaste(%q, %s) = %q, want %q", tt.in, tt.mode, got, tt.want)
+		}
+		if removed != len(tt.in)-len(tt.want) {
+			t.Errorf("sanitizePaste(%q, %s) removed %d bytes", tt.in, tt.mode, removed)
+		}
+	}
+}
+
+func TestPasteRisks(t *testing.T) {
+	tests := []struct {
+		in        string
+		bracketed bool
+		confirm   string
+		want      int
+	}{
+		{"ls -la\n", false, "auto", 0},
+		{"ls\npwd\n", false, "auto", 1},
+		{"ls\npwd\n", true, "auto", 0},
+		{"ls\npwd\n", true, "always", 1},
+		{"curl -fsSL https://x.sh | sudo bash", true, "auto", 2},
+		{"wget -qO- example.com|sh", true, "auto", 1},
+		{"rm -rf build", true, "auto", 1},
+		{"pseudo sudoku", true, "auto", 0},
+		{"sudo rm -r /\nls", false, "never", 0},
+	}
+	for _, tt := range tests {
+		if got := pasteRisks([]byte(tt.in), tt.bracketed, 0, tt.confirm); len(got) != tt.want {
+			t.Errorf("pasteRisks(%q, %v, %s) = %q, want %d reasons", tt.in, tt.bracketed, tt.confirm, got, tt.want)
+		}
+	}
+	if got := pasteRisks([]byte("ls"), true, 3, "auto"); len(got) != 1 {
+		t.Errorf("removed control bytes should be reported, got %q", got)
+	}
+}
+
+// newPasteTerm returns a terminal whose PTY is the write end of a pipe and
+// a channel carrying everything written to it once the pipe closes.
+func newPasteTerm(t *testing.T) (*TexelTerm, func() string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	a := &TexelTerm{pty: w}
+	return a, func() string {
+		w.Close()
+		select {
+		case s := <-out:
+			return s
+		case <-time.After(2 * time.Second):
+			t.Fatal("pipe never drained")
+			return ""
+		}
+	}
+}
+
+func TestHandlePasteConfirmsMultiLineWithoutBracketedPaste(t *testing.T) {
+	a, written := newPasteTerm(t)
+	a.HandlePaste([]byte("echo one\r\necho two\n"))
+	if a.pastePending == nil {
+		t.Fatal("multi-line paste without bracketed paste should wait for confirmation")
+	}
+
+	buf := make([][]texelcore.Cell, 12)
+	for i := range buf {
+		buf[i] = make([]texelcore.Cell, 60)
+	}
+	a.drawPasteOverlay(buf)
+	var screen strings.Builder
+	for _, row := range buf {
+		for _, c := range row {
+			screen.WriteRune(max(c.Ch, ' '))
+		}
+		screen.WriteByte('\n')
+	}
+	if !strings.Contains(screen.String(), "Paste 2 line(s)?") || !strings.Contains(screen.String(), "echo two") {
+		t.Fatalf("confirmation not drawn:\n%s", screen.String())
+	}
+
+	a.mu.Lock()
+	a.handlePasteKey(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone))
+	if a.pastePending == nil {
+		t.Fatal("other keys should leave the dialog open")
+	}
+	a.handlePasteKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	a.mu.Unlock()
+	if a.pastePending != nil {
+		t.Fatal("Enter should confirm the paste")
+	}
+
+	// A cancelled paste writes nothing.
+	a.HandlePaste([]byte("a\nb"))
+	a.mu.Lock()
+	a.handlePasteKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	a.mu.Unlock()
+
+	if got := written(); got != "echo one\recho two\r" {
+		t.Fatalf("pty got %q", got)
+	}
+}
+
+func TestHandlePasteBracketedStripsInjectedMarker(t *testing.T) {
+	a, written := newPasteTerm(t)
+	a.bracketedPasteMode = true
+	a.HandlePaste([]byte("echo hi\r\n\x1b[201~echo bye\n"))
+	if a.pastePending == nil {
+		t.Fatal("a paste that had control sequences removed should be confirmed")
+	}
+	a.mu.Lock()
+	a.handlePasteKey(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone))
+	a.mu.Unlock()
+	if got := written(); got != "\x1b[200~echo hi\necho bye\n\x1b[201~" {
+		t.Fatalf("pty got %q", got)
+	}
+}
+
+func TestPasteOverPTYBufferDoesNotBlock(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	a := &TexelTerm{pty: w}
+
+	// Nothing reads the PTY yet, so an inline write of more than the pipe
+	// buffers would hang; the paste must stream even though it is far
+	// below the configured threshold.
+	body := bytes.Repeat([]byte("x"), 256<<10)
+	returned := make(chan struct{})
+	go func() {
+		a.writePaste(body, pasteSettings{streamThreshold: 1 << 20, chunkBytes: 4096})
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("writePaste blocked on a full PTY")
+	}
+	a.mu.Lock()
+	streaming := a.pasteStream != nil
+	a.mu.Unlock()
+	if !streaming {
+		t.Fatal("a paste over the PTY buffer should stream")
+	}
+
+	read := make(chan int, 1)
+	go func() {
+		b, _ := io.ReadAll(r)
+		read <- len(b)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		a.mu.Lock()
+		done := a.pasteStream == nil
+		a.mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("paste stream never finished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.Close()
+	if n := <-read; n != len(body) {
+		t.Fatalf("pty got %d bytes, want %d", n, len(body))
+	}
+
+	// A small paste is still written inline.
+	a2, written := newPasteTerm(t)
+	a2.writePaste([]byte("ls"), pasteSettings{streamThreshold: 1 << 20, chunkBytes: 4096})
+	if a2.pasteStream != nil {
+		t.Fatal("a small paste should not stream")
+	}
+	if got := written(); got != "ls" {
+		t.Fatalf("pty got %q", got)
+	}
+}
+
+func TestLargePasteStreamsAndCancels(t *testing.T) {
+	a, written := newPasteTerm(t)
+	a.bracketedPasteMode = true
+	a.writePaste([]byte("0123456789abcdef"), pasteSettings{streamThreshold: 8, chunkBytes: 4})
+	waitStream := func() {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			a.mu.Lock()
+			done := a.pasteStream == nil
+			a.mu.Unlock()
+			if done {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("paste stream never finished")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitStream()
+
+	// A slow stream is cancelled between chunks and still closes the bracket.
+	a.writePaste([]byte("ABCDEFGHIJKLMNOP"), pasteSettings{streamThreshold: 8, chunkBytes: 4, chunkDelay: time.Hour})
+	for {
+		a.mu.Lock()
+		st := a.pasteStream
+		started := st != nil && st.written > 0
+		if started {
+			if !a.handlePasteKey(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)) {
+				t.Fatal("keys should be held back while a paste streams")
+			}
+			a.handlePasteKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+		}
+		a.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waitStream()
+
+	got := written()
+	want := "\x1b[200~0123456789abcdef\x1b[201~" + "\x1b[200~ABCD\x1b[201~"
+	if got != want {
+		t.Fatalf("pty got %q, want %q", got, want)
+	}
+	if !bytes.Equal(normalizePaste([]byte("a\r\nb\nc"), false), []byte("a\rb\rc")) {
+		t.Error("unbracketed paste should send CR line endings")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/pty_handoff.go
+// Summary: Detaching and re-adopting live PTYs across server upgrades.
+// Usage: texel-server calls DetachPTY on every terminal before exec'ing
+//   its replacement; the replacement registers the received PTYs with
+//   AdoptInheritedPTYs before restoring the snapshot.
+// Notes: VTerm state travels through the WAL/MainScreenState exactly as
+//   on a normal restart; only terminal modes ride along in the handle.
+
+package texelterm
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+
+	"github.com/framegrace/texelation/internal/handoff"
+)
+
+// errNoPTY is returned by DetachPTY when the terminal has no running shell.
+var errNoPTY = errors.New("texelterm: no running PTY")
+
+// adoptedExitPoll is how often an adopted shell's PID is probed. The
+// shell is not our child after a handoff, so wait(2) is unavailable.
+const adoptedExitPoll = 250 * time.Millisecond
+
+var (
+	inheritedMu   sync.Mutex
+	inheritedPTYs map[string]handoff.Pane
+)
+
+// AdoptInheritedPTYs registers PTYs received from a previous server. A
+// terminal whose pane ID matches takes the PTY over on its first Run
+// instead of spawning a new shell. Must be called before the snapshot
+// that recreates the panes is applied.
+func AdoptInheritedPTYs(panes []handoff.Pane) {
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+	if inheritedPTYs == nil {
+		inheritedPTYs = make(map[string]handoff.Pane, len(panes))
+	}
+	for _, p := range panes {
+		inheritedPTYs[p.PaneID] = p
+	}
+}
+
+// ReleaseInheritedPTYs closes every registered PTY whose pane ID is not
+// in keep. Closing the master hangs up the shell, which is the right
+// outcome for a pane that did not survive the snapshot restore. Returns
+// the number of PTYs released.
+func ReleaseInheritedPTYs(keep map[string]bool) int {
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+	released := 0
+	for id, p := range inheritedPTYs {
+		if keep[id] {
+			continue
+		}
+		log.Printf("[HANDOFF] Releasing unclaimed PTY for pane %s (pid %d)", id, p.PID)
+		_ = p.PTY.Close()
+		delete(inheritedPTYs, id)
+		released++
+	}
+	return released
+}
+
+func takeInheritedPTY(paneID string) (handoff.Pane, bool) {
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+	p, ok := inheritedPTYs[paneID]
+	if ok {
+		delete(inheritedPTYs, paneID)
+	}
+	return p, ok
+}
+
+// DetachPTY stops the terminal without killing its shell and returns a
+// handle for the PTY master. The returned file is a duplicate owned by
+// the caller; the terminal's own descriptor is closed, which unblocks
+// the reader loop. History, search index and state are flushed exactly
+// as in Stop so the next server restores the same screen from the WAL.
+func (a *TexelTerm) DetachPTY() (handoff.Pane, error) {
+	return a.shutdown(true)
+}
+
+// runInheritedShell adopts a PTY from a previous server instead of
+// starting a new shell. Mirrors runShell's first-run path.
+func (a *TexelTerm) runInheritedShell(inherited handoff.Pane, cols, rows int) error {
+	a.mu.Lock()
+	a.pty = inherited.PTY
+	a.cmd = nil
+	a.adoptedPID = inherited.PID
+	paneID := a.paneID
+	a.mu.Unlock()
+
+	log.Printf("[TEXELTERM] Adopting inherited PTY: paneID=%s pid=%d", paneID, inherited.PID)
+
+	a.startCapture(cols, rows)
+	a.initializeVTermFirstRun(cols, rows, paneID)
+
+	if len(inherited.Modes) > 0 {
+		a.mu.Lock()
+		for _, r := range string(inherited.Modes) {
+			a.parser.Parse(r)
+		}
+		a.vterm.MarkAllDirty()
+		a.mu.Unlock()
+	}
+
+	// Toggle the window size so full-screen apps get a SIGWINCH and
+	// repaint: the alt screen is not persisted, only its mode is.
+	if err := pty.Setsize(inherited.PTY, &pty.Winsize{Rows: uint16(rows + 1), Cols: uint16(cols)}); err != nil {
+		log.Printf("[TEXELTERM] Adopted pty.Setsize failed: %v", err)
+	}
+	if err := pty.Setsize(inherited.PTY, &pty.Winsize{Rows: uint16(rows), Cols: uint16(cols)}); err != nil {
+		log.Printf("[TEXELTERM] Adopted pty.Setsize failed: %v", err)
+	}
+	a.requestRefresh()
+
+	err := a.runPtyReaderLoop(inherited.PTY, func() error {
+		return a.waitAdopted(inherited.PID)
+	})
+
+	a.mu.Lock()
+	a.adoptedPID = 0
+	a.mu.Unlock()
+	return err
+}
+
+// waitAdopted blocks until the adopted shell exits or the terminal stops.
+func (a *TexelTerm) waitAdopted(pid int) error {
+	ticker := time.NewTicker(adoptedExitPoll)
+	defer ticker.Stop()
+	for {
+		if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("adopted shell %d exited", pid)
+		}
+		select {
+		case <-ticker.C:
+		case <-a.stop:
+			return nil
+		}
+	}
+}
+
+// dupPTY duplicates the PTY master descriptor without switching the
+// original to blocking mode (which os.File.Fd would do).
+func dupPTY(f *os.File) (*os.File, error) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		newFD  int
+		dupErr error
+	)
+	if err := rc.Control(func(fd uintptr) {
+		newFD, dupErr = syscall.Dup(int(fd))
+	}); err != nil {
+		return nil, err
+	}
+	if dupErr != nil {
+		return nil, dupErr
+	}
+	syscall.CloseOnExec(newFD)
+	return os.NewFile(uintptr(newFD), f.Name()), nil
+}
@@ -49,11 +49,16 @@ type Selection struct {
 	Rendered bool
 }
 
+// WordSelector finds the word around a content position for double-click
+// selection. ok=false falls back to the per-line word rules.
+type WordSelector func(logicalLine int64, charOffset int) (startLine int64, startOffset int, endLine int64, endOffset int, ok bool)
+
 // SelectionStateMachine manages the selection lifecycle with clear state transitions.
 type SelectionStateMachine struct {
 	state         SelectionState
 	selection     Selection
 	vtermProvider VTermProvider
+	wordSelector  WordSelector
 	width, height int
 }
 
@@ -80,6 +85,12 @@ func (s *SelectionStateMachine) SetSize(width, height int) {
 	s.height = height
 }
 
+// SetWordSelector installs a WordSelector consulted before the per-line
+// word rules. nil removes it.
+func (s *SelectionStateMachine) SetWordSelector(fn WordSelector) {
+	s.wordSelector = fn
+}
+
 // Start begins a selection based on click type and content position.
 // logicalLine is -1 for the current uncommitted line.
 // viewportRow is used for coordinate conversion.
@@ -204,6 +215,15 @@ func (s *SelectionStateMachine) SelectionRange() (startLine int64, startOffset i
 
 // selectWord selects the word at the given content position.
 func (s *SelectionStateMachine) selectWord(logicalLine int64, charOffset int, viewportRow int) {
+	if s.wordSelector != nil {
+		if sl, so, el, eo, ok := s.wordSelector(logicalLine, charOffset); ok {
+			s.selection.AnchorLine = sl
+			s.selection.AnchorOffset = so
+			s.selection.CurrentLine = el
+			s.selection.CurrentOffset = eo
+			return
+		}
+	}
 	if s.vtermProvider == nil {
 		return
 	}
@@ -0,0 +1,82 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/shell_status.go
+// Summary: Reports the shell's cwd and command state to the desktop.
+// Usage: The VTerm's OSC 7 and OSC 133 handlers call noteWorkingDir,
+//   noteCommandStart and noteCommandEnd; the desktop reads the result
+//   through texel.ShellStatusProvider for status bar segments.
+// Notes: The status has its own lock because the desktop loop reads it
+//   while Parse may hold a.mu. OSC 7 only signals that the cwd changed:
+//   where the system knows the PTY's foreground process cwd, that is
+//   used in preference to the URI, which any program's output can forge.
+
+package texelterm
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/framegrace/texelation/texel"
+)
+
+var _ texel.ShellStatusProvider = (*TexelTerm)(nil)
+
+// ShellStatus implements texel.ShellStatusProvider.
+func (a *TexelTerm) ShellStatus() texel.ShellStatus {
+	a.shellMu.Lock()
+	defer a.shellMu.Unlock()
+	return a.shell
+}
+
+func (a *TexelTerm) noteWorkingDir(dir string) {
+	if fg := foregroundCwd(a.pty); fg != "" {
+		dir = fg
+	}
+	a.shellMu.Lock()
+	a.shell.Cwd = dir
+	a.shellMu.Unlock()
+	a.requestRefresh()
+}
+
+func (a *TexelTerm) noteCommandStart(cmd string) {
+	a.shellMu.Lock()
+	a.shell.Command = cmd
+	a.shell.Running = true
+	a.shell.Started = time.Now()
+	a.shellMu.Unlock()
+	a.requestRefresh()
+}
+
+func (a *TexelTerm) noteCommandEnd(exitCode int) {
+	a.shellMu.Lock()
+	a.shell.Running = false
+	a.shell.Finished = true
+	a.shell.ExitCode = exitCode
+	a.shell.Duration = 0
+	if !a.shell.Started.IsZero() {
+		a.shell.Duration = time.Since(a.shell.Started)
+	}
+	a.shellMu.Unlock()
+	a.requestRefresh()
+}
+
+// foregroundCwd returns the working directory of the PTY's foreground
+// process group leader, or "" where it cannot be read (no PTY, no /proc).
+func foregroundCwd(pty *os.File) string {
+	if pty == nil {
+		return ""
+	}
+	var pgid int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, pty.Fd(), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgid))); errno != 0 || pgid <= 0 {
+		return ""
+	}
+	dir, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pgid))
+	if err != nil {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,507 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/table_viewer.go
+// Summary: Full-pane overlay for exploring a table found in the terminal
+// output: sort, filter, hide columns, freeze the header and copy.
+// Usage: The texelterm.table action (unbound by default; run it from the
+//   command palette) opens the newest table in view. Tables tablefmt
+//   rendered are found by line range; other visible output is run through
+//   the table detectors on demand, so tables in old scrollback open too. Keys inside the viewer:
+//     ←/→ column   ↑/↓ PgUp/PgDn Home/End scroll   s/Enter sort
+//     / filter   h hide column   H show all   f freeze header
+//     c/t/m copy as CSV/TSV/Markdown   q/Esc close
+// Notes: The viewer has its own lock. Render takes it under a.mu, so
+//   HandleKey releases it before anything that takes a.mu (copying).
+
+package texelterm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/framegrace/texelation/apps/texelterm/tablefmt"
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/framegrace/texelui/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	maxTableColWidth = 40
+	// maxTableTrim is how many leading or trailing lines of a block of
+	// output (a command line, a prompt) are tried away when detecting.
+	maxTableTrim = 3
+)
+
+// tableViewer is the table overlay. All fields are guarded by mu.
+type tableViewer struct {
+	mu         sync.Mutex
+	view       *tablefmt.View
+	visible    bool
+	col        int  // selected column
+	left       int  // first column drawn
+	top        int  // first scrolled line drawn
+	frozen     bool // header stays put while rows scroll
+	filtering  bool
+	prevFilter string
+	pageRows   int
+	onCopy     func(text string, rows int, format tablefmt.ExportFormat)
+}
+
+func newTableViewer(onCopy func(text string, rows int, format tablefmt.ExportFormat)) *tableViewer {
+	return &tableViewer{frozen: true, onCopy: onCopy}
+}
+
+// Open shows t with a fresh view.
+func (tv *tableViewer) Open(t *tablefmt.Table) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+	tv.view = tablefmt.NewView(t)
+	tv.visible = true
+	tv.col, tv.left, tv.top = 0, 0, 0
+	tv.filtering = false
+}
+
+// Close hides the viewer.
+func (tv *tableViewer) Close() {
+	tv.mu.Lock()
+	tv.visible = false
+	tv.view = nil
+	tv.mu.Unlock()
+}
+
+// IsVisible reports whether the viewer is open.
+func (tv *tableViewer) IsVisible() bool {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+	return tv.visible
+}
+
+// HandleKey handles a key while the viewer is open. Every key is consumed.
+func (tv *tableViewer) HandleKey(ev *tcell.EventKey) bool {
+	tv.mu.Lock()
+	if !tv.visible {
+		tv.mu.Unlock()
+		return false
+	}
+	if tv.filtering {
+		tv.handleFilterKeyLocked(ev)
+		tv.mu.Unlock()
+		return true
+	}
+
+	var copyFormat tablefmt.ExportFormat
+	v := tv.view
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		tv.visible, tv.view = false, nil
+	case tcell.KeyLeft:
+		tv.moveColumnLocked(-1)
+	case tcell.KeyRight:
+		tv.moveColumnLocked(1)
+	case tcell.KeyUp:
+		tv.top--
+	case tcell.KeyDown:
+		tv.top++
+	case tcell.KeyPgUp:
+		tv.top -= max(tv.pageRows, 1)
+	case tcell.KeyPgDn:
+		tv.top += max(tv.pageRows, 1)
+	case tcell.KeyHome:
+		tv.top = 0
+	case tcell.KeyEnd:
+		tv.top = len(v.Rows()) + 2 // clamped when drawn
+	case tcell.KeyEnter:
+		v.CycleSort(tv.col)
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			tv.visible, tv.view = false, nil
+		case 's':
+			v.CycleSort(tv.col)
+		case '/':
+			tv.filtering = true
+			tv.prevFilter = v.Filter()
+		case 'h':
+			v.ToggleColumn(tv.col)
+			if v.Hidden(tv.col) {
+				tv.moveColumnLocked(1)
+				if v.Hidden(tv.col) {
+					tv.moveColumnLocked(-1)
+				}
+			}
+		case 'H':
+			v.ShowAllColumns()
+		case 'f':
+			tv.frozen = !tv.frozen
+		case 'c':
+			copyFormat = tablefmt.ExportCSV
+		case 't':
+			copyFormat = tablefmt.ExportTSV
+		case 'm':
+			copyFormat = tablefmt.ExportMarkdown
+		}
+	}
+	var text string
+	var rows int
+	if copyFormat != "" {
+		text, rows = v.Export(copyFormat), len(v.Rows())
+	}
+	onCopy := tv.onCopy
+	tv.mu.Unlock()
+
+	if text != "" && onCopy != nil {
+		onCopy(text, rows, copyFormat)
+	}
+	return true
+}
+
+// handleFilterKeyLocked edits the filter, applying it as it is typed.
+func (tv *tableViewer) handleFilterKeyLocked(ev *tcell.EventKey) {
+	v := tv.view
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		tv.filtering = false
+	case tcell.KeyEscape:
+		tv.filtering = false
+		v.SetFilter(tv.prevFilter)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if f := []rune(v.Filter()); len(f) > 0 {
+			v.SetFilter(string(f[:len(f)-1]))
+		}
+	case tcell.KeyCtrlU:
+		v.SetFilter("")
+	case tcell.KeyRune:
+		v.SetFilter(v.Filter() + string(ev.Rune()))
+	}
+	tv.top = 0
+}
+
+// moveColumnLocked selects the next visible column in direction dir.
+func (tv *tableViewer) moveColumnLocked(dir int) {
+	for c := tv.col + dir; c >= 0 && c < tv.view.NumColumns(); c += dir {
+		if !tv.view.Hidden(c) {
+			tv.col = c
+			return
+		}
+	}
+}
+
+// Render draws the viewer over the whole buffer.
+func (tv *tableViewer) Render(buf [][]texelcore.Cell) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+	if !tv.visible || tv.view == nil || len(buf) < 4 || len(buf[0]) == 0 {
+		return
+	}
+	bufH, bufW := len(buf), len(buf[0])
+	v := tv.view
+
+	tm := theme.Get()
+	bgColor := tm.GetSemanticColor("bg.surface")
+	fgColor := tm.GetSemanticColor("text.primary")
+	mutedColor := tm.GetSemanticColor("text.muted")
+	accentColor := tm.GetSemanticColor("accent.primary")
+	inverseColor := tm.GetSemanticColor("text.inverse")
+	if bgColor == tcell.ColorDefault {
+		bgColor = tcell.NewRGBColor(30, 30, 46)
+	}
+	if fgColor == tcell.ColorDefault {
+		fgColor = tcell.NewRGBColor(205, 214, 244)
+	}
+	if mutedColor == tcell.ColorDefault {
+		mutedColor = tcell.NewRGBColor(108, 112, 134)
+	}
+	if accentColor == tcell.ColorDefault {
+		accentColor = tcell.NewRGBColor(137, 180, 250)
+	}
+	if inverseColor == tcell.ColorDefault {
+		inverseColor = tcell.NewRGBColor(30, 30, 46)
+	}
+	bgStyle := tcell.StyleDefault.Background(bgColor.TrueColor()).Foreground(fgColor.TrueColor())
+	mutedStyle := bgStyle.Foreground(mutedColor.TrueColor())
+	headerStyle := bgStyle.Foreground(accentColor.TrueColor()).Bold(true)
+	selectedStyle := bgStyle.Background(accentColor.TrueColor()).Foreground(inverseColor.TrueColor()).Bold(true)
+
+	for y := 0; y < bufH; y++ {
+		for x := 0; x < bufW; x++ {
+			buf[y][x] = texelcore.Cell{Ch: ' ', Style: bgStyle}
+		}
+	}
+	put := func(x, y int, s string, style tcell.Style, limit int) int {
+		for _, r := range s {
+			if x >= limit {
+				break
+			}
+			setCell(buf, x, y, r, style, bufW, bufH)
+			x++
+		}
+		return x
+	}
+
+	// Title.
+	t := v.Table()
+	rows := v.Rows()
+	title := fmt.Sprintf(" Table · lines %d–%d · %d/%d rows", t.Start+1, t.End+1, len(rows), len(t.Rows))
+	if col, desc := v.Sort(); col >= 0 {
+		title += " · sorted by " + v.ColumnName(col) + sortArrow(desc)
+	}
+	if f := v.Filter(); f != "" {
+		title += fmt.Sprintf(" · filter %q", f)
+	}
+	put(0, 0, title+" ", headerStyle, bufW)
+
+	// Footer.
+	footer := " ←→ column  s sort  / filter  h hide  H show all  f freeze  c/t/m copy CSV/TSV/MD  q close"
+	if tv.filtering {
+		footer = " Filter: " + v.Filter() + "▏  Enter apply · Esc cancel"
+	}
+	put(0, bufH-1, footer, mutedStyle, bufW)
+
+	// Column widths, from every row so sorting and filtering don't shift
+	// the layout.
+	cols := v.VisibleColumns()
+	widths := make(map[int]int, len(cols))
+	for _, c := range cols {
+		w := utf8.RuneCountInString(v.ColumnName(c)) + 2 // room for the sort arrow
+		for _, row := range t.Rows {
+			w = max(w, utf8.RuneCountInString(row[c]))
+		}
+		widths[c] = min(w, maxTableColWidth)
+	}
+
+	// Scroll horizontally so the selected column is on screen.
+	tv.left = min(tv.left, tv.col)
+	for tv.left < tv.col {
+		span := 0
+		for _, c := range cols {
+			if c >= tv.left && c <= tv.col {
+				span += widths[c] + 3
+			}
+		}
+		if span <= bufW {
+			break
+		}
+		tv.left++
+	}
+
+	// Vertical layout: the header and its rule are pinned when frozen,
+	// otherwise they scroll away with the rows.
+	areaTop, areaH := 1, bufH-2
+	scrolled := len(rows)
+	if tv.frozen {
+		areaH -= 2
+	} else {
+		scrolled += 2
+	}
+	tv.pageRows = max(areaH, 1)
+	tv.top = max(min(tv.top, scrolled-areaH), 0)
+
+	drawRow := func(y int, cells func(c int) (string, tcell.Style, bool)) {
+		x := 1
+		for _, c := range cols {
+			if c < tv.left {
+				continue
+			}
+			if x >= bufW {
+				break
+			}
+			text, style, right := cells(c)
+			w := widths[c]
+			r := []rune(text)
+			if len(r) > w {
+				r = append(r[:w-1], '…')
+			}
+			pad := w - len(r)
+			if right {
+				x = put(x, y, strings.Repeat(" ", pad), style, bufW)
+			}
+			x = put(x, y, string(r), style, bufW)
+			if !right {
+				x = put(x, y, strings.Repeat(" ", pad), style, bufW)
+			}
+			x = put(x, y, " │ ", mutedStyle, bufW)
+		}
+	}
+	header := func(y int) {
+		drawRow(y, func(c int) (string, tcell.Style, bool) {
+			name := v.ColumnName(c)
+			if col, desc := v.Sort(); col == c {
+				name += sortArrow(desc)
+			}
+			if c == tv.col {
+				return name, selectedStyle, false
+			}
+			return name, headerStyle, false
+		})
+	}
+	rule := func(y int) {
+		put(0, y, strings.Repeat("─", bufW), mutedStyle, bufW)
+	}
+	data := func(y, i int) {
+		drawRow(y, func(c int) (string, tcell.Style, bool) {
+			return rows[i][c], bgStyle, t.Numeric(c)
+		})
+	}
+
+	y := areaTop
+	if tv.frozen {
+		header(y)
+		rule(y + 1)
+		y += 2
+	}
+	for line := tv.top; line < scrolled && y < bufH-1; line++ {
+		switch {
+		case tv.frozen:
+			data(y, line)
+		case line == 0:
+			header(y)
+		case line == 1:
+			rule(y)
+		default:
+			data(y, line-2)
+		}
+		y++
+	}
+	if len(rows) == 0 && y < bufH-1 {
+		put(1, y, "no rows match", mutedStyle, bufW)
+	}
+}
+
+func sortArrow(desc bool) string {
+	if desc {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// openTableViewer opens the newest table in view, if there is one.
+func (a *TexelTerm) openTableViewer() {
+	a.mu.Lock()
+	t := a.tableInViewLocked()
+	a.mu.Unlock()
+	if t == nil {
+		if a.statusBar != nil {
+			a.statusBar.ShowError("No table in view")
+		}
+		return
+	}
+	a.tableViewer.Open(t)
+	a.mu.Lock()
+	if a.vterm != nil {
+		a.vterm.MarkAllDirty()
+	}
+	a.mu.Unlock()
+	a.requestRefresh()
+}
+
+// copyTable puts an exported table on the clipboard.
+func (a *TexelTerm) copyTable(text string, rows int, format tablefmt.ExportFormat) {
+	a.SetClipboard("text/plain", []byte(text))
+	if a.statusBar != nil {
+		a.statusBar.ShowSuccess(fmt.Sprintf("Copied %d rows as %s", rows, strings.ToUpper(string(format))))
+	}
+}
+
+// tableInViewLocked finds the table to open: the newest one tablefmt
+// rendered within the viewport, else one detected in the visible text,
+// bottom first. Caller holds a.mu.
+func (a *TexelTerm) tableInViewLocked() *tablefmt.Table {
+	if a.vterm == nil || a.vterm.InAltScreen() {
+		return nil
+	}
+	var idx []int64
+	for _, g := range a.lastRowGlobalIdx {
+		if g >= 0 && (len(idx) == 0 || idx[len(idx)-1] != g) {
+			idx = append(idx, g)
+		}
+	}
+	if a.pipeline != nil {
+		for _, t := range a.pipeline.Transformers() {
+			tf, ok := t.(*tablefmt.TableFormatter)
+			if !ok {
+				continue
+			}
+			for i := len(idx) - 1; i >= 0; i-- {
+				if tbl := tf.TableAt(idx[i]); tbl != nil {
+					return tbl
+				}
+			}
+		}
+	}
+	lines := make([]string, len(idx))
+	for i, g := range idx {
+		if ll := a.vterm.GetLogicalLine(g); ll != nil {
+			var b strings.Builder
+			for _, c := range ll.Cells {
+				if c.Rune != 0 {
+					b.WriteRune(c.Rune)
+				}
+			}
+			lines[i] = b.String()
+		}
+	}
+	return detectTableInLines(idx, lines)
+}
+
+// detectTableInLines looks for a table in blocks of lines separated by
+// blank ones, last block first. The detectors happily absorb a stray
+// command line or prompt, so each block is also tried with up to
+// maxTableTrim lines cut from either end, and the cut whose cells are
+// fullest wins, the longer one on a tie. idx holds each line's global
+// index.
+func detectTableInLines(idx []int64, lines []string) *tablefmt.Table {
+	end := len(lines)
+	for end > 0 {
+		for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		start := end
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+			start--
+		}
+		var best *tablefmt.Table
+		bestFill := 0.0
+		for head := 0; head <= maxTableTrim; head++ {
+			for tail := 0; tail <= maxTableTrim; tail++ {
+				s, e := start+head, end-tail
+				if e-s < 2 {
+					continue
+				}
+				t := tablefmt.Detect(lines[s:e])
+				if t == nil {
+					continue
+				}
+				t.Start, t.End = idx[s], idx[e-1]
+				fill := tableFill(t)
+				if best == nil || fill > bestFill+1e-9 || (fill > bestFill-1e-9 && t.End-t.Start > best.End-best.Start) {
+					best, bestFill = t, fill
+				}
+			}
+		}
+		if best != nil {
+			return best
+		}
+		end = start
+	}
+	return nil
+}
+
+// tableFill is the fraction of non-empty cells in t, header included.
+func tableFill(t *tablefmt.Table) float64 {
+	filled, total := 0, 0
+	for _, row := range append([][]string{t.Header}, t.Rows...) {
+		for _, cell := range row {
+			total++
+			if strings.TrimSpace(cell) != "" {
+				filled++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(filled) / float64(total)
+}
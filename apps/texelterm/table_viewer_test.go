@@ -0,0 +1,134 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: apps/texelterm/table_viewer_test.go
+// Summary: Exercises finding a table in view and driving the table viewer.
+
+package texelterm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/tablefmt"
+	texelcore "github.com/framegrace/texelui/core"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDetectTableInLinesSkipsPromptAndProse(t *testing.T) {
+	lines := []string{
+		"$ cat notes",
+		"some prose that is not a table",
+		"",
+		"$ ps",
+		"PID    USER     COMMAND",
+		"1      root     init",
+		"4211   alice    vim",
+		"$ ",
+	}
+	idx := make([]int64, len(lines))
+	for i := range idx {
+		idx[i] = int64(100 + i)
+	}
+	tbl := detectTableInLines(idx, lines)
+	if tbl == nil {
+		t.Fatal("no table found")
+	}
+	if tbl.Start != 104 || tbl.End != 106 || len(tbl.Rows) != 2 || tbl.Header[2] != "COMMAND" {
+		t.Fatalf("table = %+v", tbl)
+	}
+	if detectTableInLines(idx[:3], lines[:3]) != nil {
+		t.Error("prose should not be detected")
+	}
+}
+
+func TestTableViewerOpenSortFilterCopy(t *testing.T) {
+	tt := NewTestTerm(60, 12)
+	a := tt.term
+	var copied string
+	var format tablefmt.ExportFormat
+	a.tableViewer = newTableViewer(func(text string, rows int, f tablefmt.ExportFormat) {
+		copied, format = text, f
+	})
+	tt.Write([]byte("$ ls\r\nname,size,kind\r\nbeta,20,f\r\nalpha,3,d\r\ngamma,100,f\r\n$ "))
+	a.Render()
+
+	a.openTableViewer()
+	if !a.tableViewer.IsVisible() {
+		t.Fatal("viewer did not open")
+	}
+	key := func(k tcell.Key, r rune) {
+		a.HandleKey(tcell.NewEventKey(k, r, tcell.ModNone))
+	}
+	screen := func() string {
+		var b strings.Builder
+		for _, row := range a.Render() {
+			for _, c := range row {
+				b.WriteRune(max(c.Ch, ' '))
+			}
+			b.WriteByte('\n')
+		}
+		return b.String()
+	}
+
+	key(tcell.KeyRight, 0)
+	key(tcell.KeyRune, 's')
+	key(tcell.KeyRune, 's') // size descending
+	out := screen()
+	if !strings.Contains(out, "sorted by size ▼") {
+		t.Fatalf("sort not shown:\n%s", out)
+	}
+	if g, b := strings.Index(out, "gamma"), strings.Index(out, "beta"); g < 0 || b < 0 || g > b {
+		t.Fatalf("rows not sorted numerically:\n%s", out)
+	}
+
+	key(tcell.KeyRune, '/')
+	for _, r := range "ALP" {
+		key(tcell.KeyRune, r)
+	}
+	key(tcell.KeyEnter, 0)
+	key(tcell.KeyRune, 'c')
+	if format != tablefmt.ExportCSV || copied != "name,size,kind\nalpha,3,d\n" {
+		t.Fatalf("copied %s %q", format, copied)
+	}
+
+	key(tcell.KeyRune, 'q')
+	if a.tableViewer.IsVisible() {
+		t.Fatal("q should close the viewer")
+	}
+	if out := screen(); strings.Contains(out, "Table ·") {
+		t.Fatalf("viewer still drawn:\n%s", out)
+	}
+}
+
+func TestTableViewerUnfrozenHeaderScrollsAway(t *testing.T) {
+	tbl := tablefmt.Detect([]string{"a,b,c", "1,x,x", "2,y,y", "3,z,z", "4,w,w", "5,v,v"})
+	if tbl == nil {
+		t.Fatal("table not detected")
+	}
+	tv := newTableViewer(nil)
+	tv.Open(tbl)
+	buf := make([][]texelcore.Cell, 6)
+	for i := range buf {
+		buf[i] = make([]texelcore.Cell, 30)
+	}
+	row := func(y int) string {
+		var b strings.Builder
+		for _, c := range buf[y] {
+			b.WriteRune(max(c.Ch, ' '))
+		}
+		return strings.TrimSpace(b.String())
+	}
+
+	tv.HandleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	tv.Render(buf)
+	if !strings.HasPrefix(row(1), "a") || !strings.HasPrefix(row(3), "2") {
+		t.Fatalf("frozen header should stay: %q / %q", row(1), row(3))
+	}
+
+	tv.HandleKey(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone))
+	tv.Render(buf)
+	if !strings.HasPrefix(row(1), "─") || !strings.HasPrefix(row(2), "1") {
+		t.Fatalf("unfrozen header should scroll: %q / %q", row(1), row(2))
+	}
+}
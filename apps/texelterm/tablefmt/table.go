@@ -0,0 +1,125 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package tablefmt
+
+import "sync"
+
+// maxRecordedTables bounds how many detected tables a formatter remembers.
+const maxRecordedTables = 64
+
+// Table is a detected table, kept addressable by the line range it
+// occupies so it can be reopened from scrollback.
+type Table struct {
+	Start, End int64      // global line indices of the table, inclusive
+	Header     []string   // column names; nil when the table has none
+	Rows       [][]string // data rows, each padded to the column count
+	types      []columnType
+}
+
+// NumColumns returns the number of columns.
+func (t *Table) NumColumns() int {
+	return len(t.types)
+}
+
+// Numeric reports whether column c holds numbers.
+func (t *Table) Numeric(c int) bool {
+	return c >= 0 && c < len(t.types) && t.types[c] == colNumber
+}
+
+// newTable converts a parsed structure into a Table, classifying each
+// column from its data rows.
+func newTable(ts *tableStructure) *Table {
+	n := len(ts.columns)
+	t := &Table{types: make([]columnType, n)}
+	for ri, row := range ts.rows {
+		row = padRow(row, n)
+		if ri == ts.headerRow {
+			t.Header = row
+			continue
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	for ci := range t.types {
+		values := make([]string, len(t.Rows))
+		for ri, row := range t.Rows {
+			values[ri] = row[ci]
+		}
+		t.types[ci] = classifyValues(values)
+	}
+	return t
+}
+
+// defaultDetectors returns the detectors in priority order with their
+// minimum scores.
+func defaultDetectors() []detectorThreshold {
+	return []detectorThreshold{
+		{&markdownDetector{}, 0.95},
+		{&pipeDetector{}, 0.7},
+		{&spaceAlignedDetector{}, 0.6},
+		{&csvDetector{}, 0.5},
+	}
+}
+
+// parseBest scores lines with every detector and parses them with the
+// highest-scoring one above its threshold. Returns nil when none fits.
+func parseBest(detectors []detectorThreshold, lines []string) *tableStructure {
+	var best tableDetector
+	bestScore := 0.0
+	for _, dt := range detectors {
+		score := dt.detector.Score(lines)
+		if score >= dt.threshold && score > bestScore {
+			bestScore = score
+			best = dt.detector
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.Parse(lines)
+}
+
+// Detect runs the table detectors over lines, for text that never went
+// through the formatter (for example scrollback from before it was
+// enabled). Start and End are left for the caller to fill in.
+func Detect(lines []string) *Table {
+	ts := parseBest(defaultDetectors(), lines)
+	if ts == nil || len(ts.columns) == 0 || len(ts.rows) == 0 {
+		return nil
+	}
+	return newTable(ts)
+}
+
+// tableLog remembers the most recent tables a formatter rendered.
+type tableLog struct {
+	mu     sync.Mutex
+	tables []*Table
+}
+
+func (l *tableLog) record(t *Table) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.tables) == maxRecordedTables {
+		l.tables = append(l.tables[:0], l.tables[1:]...)
+	}
+	l.tables = append(l.tables, t)
+}
+
+// TableAt returns the most recent rendered table covering lineIdx, or nil.
+func (tf *TableFormatter) TableAt(lineIdx int64) *Table {
+	tf.tables.mu.Lock()
+	defer tf.tables.mu.Unlock()
+	for i := len(tf.tables.tables) - 1; i >= 0; i-- {
+		if t := tf.tables.tables[i]; lineIdx >= t.Start && lineIdx <= t.End {
+			return t
+		}
+	}
+	return nil
+}
+
+// Tables returns the recorded tables, oldest first.
+func (tf *TableFormatter) Tables() []*Table {
+	tf.tables.mu.Lock()
+	defer tf.tables.mu.Unlock()
+	return append([]*Table(nil), tf.tables.tables...)
+}
@@ -73,18 +73,14 @@ type TableFormatter struct {
 	persistNotifyFunc   func(lineIdx int64)
 	detectors           []detectorThreshold
 	activeDetector      tableDetector
+	tables              tableLog
 }
 
 // New creates a TableFormatter with the given maximum buffer size.
 func New(maxBufferRows int) *TableFormatter {
 	return &TableFormatter{
 		maxBufferRows: maxBufferRows,
-		detectors: []detectorThreshold{
-			{&markdownDetector{}, 0.95},
-			{&pipeDetector{}, 0.7},
-			{&spaceAlignedDetector{}, 0.6},
-			{&csvDetector{}, 0.5},
-		},
+		detectors:     defaultDetectors(),
 	}
 }
 
@@ -242,29 +238,19 @@ func (tf *TableFormatter) flush() {
 	}
 
 	// Score with all detectors, pick the highest-scoring one above threshold.
-	var bestDetector tableDetector
-	bestScore := 0.0
-	for _, dt := range tf.detectors {
-		score := dt.detector.Score(lines)
-		if score >= dt.threshold && score > bestScore {
-			bestScore = score
-			bestDetector = dt.detector
+	if ts := parseBest(tf.detectors, lines); ts != nil {
+		ts.originalCells = make([][]parser.Cell, len(tf.buffer))
+		for i, bl := range tf.buffer {
+			ts.originalCells[i] = bl.line.Cells
 		}
-	}
-
-	if bestDetector != nil {
-		ts := bestDetector.Parse(lines)
-		if ts != nil {
-			ts.originalCells = make([][]parser.Cell, len(tf.buffer))
-			for i, bl := range tf.buffer {
-				ts.originalCells[i] = bl.line.Cells
-			}
-			rendered := renderTable(ts)
-			if rendered != nil {
-				tf.emitRendered(rendered)
-				tf.resetState()
-				return
-			}
+		rendered := renderTable(ts)
+		if rendered != nil {
+			t := newTable(ts)
+			t.Start = tf.buffer[0].lineIdx
+			t.End = tf.emitRendered(rendered)
+			tf.tables.record(t)
+			tf.resetState()
+			return
 		}
 	}
 
@@ -272,8 +258,9 @@ func (tf *TableFormatter) flush() {
 }
 
 // emitRendered writes rendered table rows into the buffer. Suppressed lines
-// are overlaid via overlayFunc; any extra rows are inserted.
-func (tf *TableFormatter) emitRendered(rendered [][]parser.Cell) {
+// are overlaid via overlayFunc; any extra rows are inserted. Returns the
+// index of the last line the table occupies.
+func (tf *TableFormatter) emitRendered(rendered [][]parser.Cell) int64 {
 	nBuf := len(tf.buffer)
 	insertBase := tf.buffer[nBuf-1].lineIdx + 1
 	extraCount := int64(0)
@@ -291,6 +278,10 @@ func (tf *TableFormatter) emitRendered(rendered [][]parser.Cell) {
 			extraCount++
 		}
 	}
+	if extraCount > 0 {
+		return insertBase + extraCount - 1
+	}
+	return tf.buffer[min(len(rendered), nBuf)-1].lineIdx
 }
 
 // flushRaw restores all buffered lines without formatting. Original Cells were
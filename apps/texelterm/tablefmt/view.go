@@ -0,0 +1,275 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package tablefmt
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat names a text format a View can be copied as.
+type ExportFormat string
+
+const (
+	ExportCSV      ExportFormat = "csv"
+	ExportTSV      ExportFormat = "tsv"
+	ExportMarkdown ExportFormat = "markdown"
+)
+
+// View is a sorted, filtered projection of a Table with some columns
+// hidden. The Table itself is never modified.
+type View struct {
+	table    *Table
+	sortCol  int // -1 keeps the original order
+	sortDesc bool
+	filter   string
+	hidden   []bool
+	rows     [][]string // filtered and sorted, rebuilt on change
+}
+
+// NewView returns an unsorted, unfiltered view of t with every column shown.
+func NewView(t *Table) *View {
+	v := &View{table: t, sortCol: -1, hidden: make([]bool, t.NumColumns())}
+	v.rebuild()
+	return v
+}
+
+// Table returns the table the view projects.
+func (v *View) Table() *Table { return v.table }
+
+// NumColumns returns the number of columns, hidden ones included.
+func (v *View) NumColumns() int { return v.table.NumColumns() }
+
+// ColumnName returns the header of column c, or a generated name when the
+// table has no header row.
+func (v *View) ColumnName(c int) string {
+	if c < len(v.table.Header) && v.table.Header[c] != "" {
+		return v.table.Header[c]
+	}
+	return fmt.Sprintf("#%d", c+1)
+}
+
+// Hidden reports whether column c is hidden.
+func (v *View) Hidden(c int) bool { return v.hidden[c] }
+
+// VisibleColumns returns the indices of the shown columns in order.
+func (v *View) VisibleColumns() []int {
+	var cols []int
+	for c, h := range v.hidden {
+		if !h {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// ToggleColumn hides or shows column c. The last visible column cannot be
+// hidden.
+func (v *View) ToggleColumn(c int) {
+	if c < 0 || c >= len(v.hidden) {
+		return
+	}
+	if !v.hidden[c] && len(v.VisibleColumns()) == 1 {
+		return
+	}
+	v.hidden[c] = !v.hidden[c]
+	v.rebuild()
+}
+
+// ShowAllColumns unhides every column.
+func (v *View) ShowAllColumns() {
+	clear(v.hidden)
+	v.rebuild()
+}
+
+// CycleSort sorts by column c ascending, then descending, then restores
+// the original order. Sorting another column starts over at ascending.
+func (v *View) CycleSort(c int) {
+	switch {
+	case c != v.sortCol:
+		v.sortCol, v.sortDesc = c, false
+	case !v.sortDesc:
+		v.sortDesc = true
+	default:
+		v.sortCol, v.sortDesc = -1, false
+	}
+	v.rebuild()
+}
+
+// Sort returns the sort column (-1 when unsorted) and direction.
+func (v *View) Sort() (col int, desc bool) { return v.sortCol, v.sortDesc }
+
+// SetFilter keeps only rows where some visible cell contains f, ignoring
+// case. An empty filter keeps every row.
+func (v *View) SetFilter(f string) {
+	v.filter = f
+	v.rebuild()
+}
+
+// Filter returns the current filter text.
+func (v *View) Filter() string { return v.filter }
+
+// Rows returns the filtered, sorted data rows. Rows hold every column;
+// use VisibleColumns to project them.
+func (v *View) Rows() [][]string { return v.rows }
+
+func (v *View) rebuild() {
+	needle := strings.ToLower(v.filter)
+	cols := v.VisibleColumns()
+	v.rows = v.rows[:0]
+	for _, row := range v.table.Rows {
+		if needle == "" || slices.ContainsFunc(cols, func(c int) bool {
+			return strings.Contains(strings.ToLower(row[c]), needle)
+		}) {
+			v.rows = append(v.rows, row)
+		}
+	}
+	if v.sortCol < 0 {
+		return
+	}
+	typ := v.table.types[v.sortCol]
+	slices.SortStableFunc(v.rows, func(a, b []string) int {
+		c := compareCells(a[v.sortCol], b[v.sortCol], typ)
+		if v.sortDesc {
+			return -c
+		}
+		return c
+	})
+}
+
+// compareCells orders two values of a column of type t. Number columns
+// compare numerically and duration columns by length; values that don't
+// parse sort after those that do.
+func compareCells(a, b string, t columnType) int {
+	var parse func(string) (float64, bool)
+	switch t {
+	case colNumber:
+		parse = parseNumber
+	case colDateTime:
+		parse = parseDuration
+	}
+	if parse != nil {
+		x, okX := parse(a)
+		y, okY := parse(b)
+		switch {
+		case okX && okY:
+			return cmp.Compare(x, y)
+		case okX:
+			return -1
+		case okY:
+			return 1
+		}
+	}
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// parseNumber reads values like "1,234", "-0.5" and "42%".
+func parseNumber(s string) (float64, bool) {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, ",", ""), "%")
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+var reDurationPart = regexp.MustCompile(`(\d+(?:\.\d+)?)([dhms])`)
+
+// parseDuration reads ages like "5d", "3h20m" or "<12m>" as seconds.
+// Dates and clock times don't parse and fall back to text order, which
+// already sorts them correctly.
+func parseDuration(s string) (float64, bool) {
+	s = strings.Trim(s, "<>")
+	parts := reDurationPart.FindAllStringSubmatch(s, -1)
+	if len(parts) == 0 {
+		return 0, false
+	}
+	total, consumed := 0.0, 0
+	for _, p := range parts {
+		n, _ := strconv.ParseFloat(p[1], 64)
+		switch p[2] {
+		case "d":
+			n *= 86400
+		case "h":
+			n *= 3600
+		case "m":
+			n *= 60
+		}
+		total += n
+		consumed += len(p[0])
+	}
+	return total, consumed == len(s)
+}
+
+// Export renders the visible columns and rows, header first, in format.
+func (v *View) Export(format ExportFormat) string {
+	cols := v.VisibleColumns()
+	project := func(row []string) []string {
+		out := make([]string, len(cols))
+		for i, c := range cols {
+			out[i] = row[c]
+		}
+		return out
+	}
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = v.ColumnName(c)
+	}
+
+	switch format {
+	case ExportTSV:
+		clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+		var b strings.Builder
+		for _, row := range append([][]string{header}, v.projectAll(project)...) {
+			for i, cell := range row {
+				if i > 0 {
+					b.WriteByte('\t')
+				}
+				b.WriteString(clean.Replace(cell))
+			}
+			b.WriteByte('\n')
+		}
+		return b.String()
+	case ExportMarkdown:
+		esc := strings.NewReplacer("|", `\|`, "\n", " ")
+		var b strings.Builder
+		writeRow := func(row []string) {
+			b.WriteString("|")
+			for _, cell := range row {
+				b.WriteString(" " + esc.Replace(cell) + " |")
+			}
+			b.WriteByte('\n')
+		}
+		writeRow(header)
+		b.WriteString("|")
+		for _, c := range cols {
+			if v.table.types[c] == colNumber {
+				b.WriteString(" ---: |")
+			} else {
+				b.WriteString(" --- |")
+			}
+		}
+		b.WriteByte('\n')
+		for _, row := range v.projectAll(project) {
+			writeRow(row)
+		}
+		return b.String()
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(header)
+	_ = w.WriteAll(v.projectAll(project)) // a bytes.Buffer never fails
+	return buf.String()
+}
+
+func (v *View) projectAll(project func([]string) []string) [][]string {
+	out := make([][]string, len(v.rows))
+	for i, row := range v.rows {
+		out[i] = project(row)
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package tablefmt
+
+import (
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+func TestFormatterRecordsTableRange(t *testing.T) {
+	tf := New(1000)
+	tf.NotifyPromptStart()
+	tf.SetOverlayFunc(func(int64, []parser.Cell) {})
+	tf.SetInsertFunc(func(int64, []parser.Cell) {})
+
+	lines := []string{
+		"| Name  | Age |",
+		"| ----- | --- |",
+		"| Alice | 30  |",
+		"| Bob   | 25  |",
+	}
+	for i, s := range lines {
+		tf.HandleLine(int64(10+i), makeCells(s), true)
+	}
+	tf.HandleLine(14, makeCells("$ next"), false)
+
+	// 4 source lines become 6 rendered ones (borders and separator).
+	tbl := tf.TableAt(15)
+	if tbl == nil || tbl.Start != 10 || tbl.End != 15 {
+		t.Fatalf("TableAt(15) = %+v", tbl)
+	}
+	if tf.TableAt(9) != nil || tf.TableAt(16) != nil {
+		t.Error("lines outside the table should not resolve")
+	}
+	if len(tbl.Header) != 2 || tbl.Header[0] != "Name" || len(tbl.Rows) != 2 || tbl.Rows[1][0] != "Bob" {
+		t.Fatalf("table = %+v", tbl)
+	}
+	if len(tf.Tables()) != 1 {
+		t.Errorf("Tables() = %d", len(tf.Tables()))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	if Detect([]string{"just some prose", "nothing tabular here"}) != nil {
+		t.Error("prose should not be detected as a table")
+	}
+	tbl := Detect([]string{"name,size,age", "a,10,5d", "b,9,12h"})
+	if tbl == nil || tbl.NumColumns() != 3 || len(tbl.Rows) != 2 {
+		t.Fatalf("Detect = %+v", tbl)
+	}
+	if tbl.types[1] != colNumber || tbl.types[2] != colDateTime {
+		t.Errorf("column types = %v", tbl.types)
+	}
+}
+
+func TestViewSortFilterHideExport(t *testing.T) {
+	tbl := &Table{
+		Header: []string{"name", "size", "age"},
+		Rows: [][]string{
+			{"beta", "1,000", "3h"},
+			{"alpha", "200", "2d"},
+			{"Gamma", "30", "45m"},
+			{"delta", "-", "<1d>"},
+		},
+	}
+	tbl.types = []columnType{colText, colNumber, colDateTime}
+	v := NewView(tbl)
+
+	names := func() []string {
+		var out []string
+		for _, r := range v.Rows() {
+			out = append(out, r[0])
+		}
+		return out
+	}
+	check := func(step string, want ...string) {
+		t.Helper()
+		got := names()
+		if len(got) != len(want) {
+			t.Fatalf("%s: rows = %v, want %v", step, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: rows = %v, want %v", step, got, want)
+			}
+		}
+	}
+
+	v.CycleSort(1)
+	check("size asc", "Gamma", "alpha", "beta", "delta")
+	v.CycleSort(1)
+	check("size desc", "delta", "beta", "alpha", "Gamma")
+	v.CycleSort(1)
+	check("unsorted", "beta", "alpha", "Gamma", "delta")
+	v.CycleSort(2)
+	check("age asc", "Gamma", "beta", "delta", "alpha")
+	v.CycleSort(0)
+	check("name asc", "alpha", "beta", "delta", "Gamma")
+
+	v.SetFilter("GAM")
+	check("filter", "Gamma")
+	v.SetFilter("")
+
+	v.ToggleColumn(2)
+	v.ToggleColumn(1)
+	v.ToggleColumn(0) // the last visible column stays
+	if cols := v.VisibleColumns(); len(cols) != 1 || cols[0] != 0 {
+		t.Fatalf("visible = %v", cols)
+	}
+	v.SetFilter("200") // hidden columns are not searched
+	check("filter hidden")
+	v.SetFilter("")
+	v.ShowAllColumns()
+	v.ToggleColumn(2)
+
+	if got, want := v.Export(ExportCSV), "name,size\nalpha,200\nbeta,\"1,000\"\ndelta,-\nGamma,30\n"; got != want {
+		t.Errorf("csv = %q, want %q", got, want)
+	}
+	if got, want := v.Export(ExportTSV), "name\tsize\nalpha\t200\nbeta\t1,000\ndelta\t-\nGamma\t30\n"; got != want {
+		t.Errorf("tsv = %q, want %q", got, want)
+	}
+	want := "| name | size |\n| --- | ---: |\n| alpha | 200 |\n| beta | 1,000 |\n| delta | - |\n| Gamma | 30 |\n"
+	if got := v.Export(ExportMarkdown); got != want {
+		t.Errorf("markdown = %q, want %q", got, want)
+	}
+}
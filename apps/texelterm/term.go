@@ -35,9 +35,11 @@ import (
 	"github.com/framegrace/texelation/apps/texelterm/shell"
 	"github.com/framegrace/texelation/apps/texelterm/transformer"
 	"github.com/framegrace/texelation/config"
+	"github.com/framegrace/texelation/internal/handoff"
 	"github.com/framegrace/texelation/internal/keybind"
 
 	// Import transformers for init() side-effect registration.
+	_ "github.com/framegrace/texelation/apps/texelterm/extfilter"
 	_ "github.com/framegrace/texelation/apps/texelterm/tablefmt"
 	_ "github.com/framegrace/texelation/apps/texelterm/txfmt"
 	"github.com/framegrace/texelation/internal/theming"
@@ -68,6 +70,10 @@ func init() {
 // Keeps the two in lockstep: signature drift surfaces at build time.
 var _ texel.ViewportRestorer = (*TexelTerm)(nil)
 
+// Compile-time assertion that TexelTerm reports its PTY output to the
+// pane's activity monitor.
+var _ texel.OutputNotifierSetter = (*TexelTerm)(nil)
+
 type TexelTerm struct {
 	title              string
 	command            string
@@ -76,22 +82,33 @@ type TexelTerm struct {
 	height             int
 	cmd                *exec.Cmd
 	pty                *os.File
+	adoptedPID         int // Shell PID when the PTY was inherited from a previous server (cmd is nil)
 	vterm              *parser.VTerm
 	parser             *parser.Parser
 	mu                 sync.Mutex
 	stop               chan struct{}
 	stopOnce           sync.Once
 	refreshChan        chan<- bool
+	outputNotify       func() // reports PTY output to the pane's monitor; guarded by mu
 	wg                 sync.WaitGroup
 	buf                [][]texelcore.Cell
 	colorPalette       [258]tcell.Color
 	controlBus         texelcore.ControlBus
-	bracketedPasteMode bool // Tracks if application has enabled bracketed paste
+	bracketedPasteMode bool          // Tracks if application has enabled bracketed paste
+	pastePending       *pendingPaste // paste awaiting confirmation; see paste_safety.go
+	pasteStream        *pasteStream  // large paste being written in chunks
+	tableViewer        *tableViewer  // see table_viewer.go
+	folds              foldView      // see fold_view.go
+	commands           commandFolds  // see command_fold.go
+	lastButtons        tcell.ButtonMask
+	inputClick         inputClick
 
 	// Mouse and selection handling (unified for standalone and embedded modes)
 	mouseCoordinator *MouseCoordinator
 	clipboardMu      sync.Mutex // Dedicated lock for clipboard to avoid deadlock with a.mu during Parse callbacks
 	clipboard        texelcore.ClipboardService
+	shellMu          sync.Mutex // guards shell; see shell_status.go
+	shell            texel.ShellStatus
 
 	// Scroll tracking for smooth velocity-based acceleration
 	scrollEventTime time.Time // For debouncing duplicate events
@@ -215,6 +232,7 @@ func New(title, command string) texelcore.App {
 		searchToggle:   srch,
 		cfgToggle:      cfg,
 	}
+	term.tableViewer = newTableViewer(term.copyTable)
 
 	// Wire config toggle to open/close config panel
 	cfg.OnToggle = func(active bool) {
@@ -242,9 +260,77 @@ func New(title, command string) texelcore.App {
 		}
 	}
 
+	// Publish the terminal actions on the control bus so the desktop's
+	// command palette can run them.
+	for _, action := range termActions {
+		term.controlBus.Register(string(action), keybind.ActionDescriptions[action].Description, func(interface{}) error {
+			term.runAction(action)
+			return nil
+		})
+	}
+
 	return term
 }
 
+// termActions are the keybinding actions runAction understands.
+var termActions = []keybind.Action{
+	keybind.TermSearch, keybind.TermScrollbar, keybind.TermTransformer, keybind.TermScreenshot, keybind.TermTableView,
+	keybind.TermFold, keybind.TermCollapse, keybind.TermExport,
+	keybind.TermScrollUp, keybind.TermScrollDown, keybind.TermScrollPgUp, keybind.TermScrollPgDn,
+}
+
+// runAction performs a terminal keybinding action and reports whether it
+// was handled. Key presses and the command palette both land here.
+func (a *TexelTerm) runAction(action keybind.Action) bool {
+	switch action {
+	case keybind.TermSearch:
+		if a.historyNavigator != nil {
+			if a.historyNavigator.IsVisible() {
+				a.closeSearch()
+			} else {
+				a.openSearch()
+			}
+			return true
+		}
+	case keybind.TermScrollbar:
+		if a.scrollbar != nil {
+			a.scrollbar.Toggle()
+		}
+		return true
+	case keybind.TermTransformer:
+		a.toggleTransformers()
+		return true
+	case keybind.TermScreenshot:
+		a.takeScreenshot()
+		return true
+	case keybind.TermTableView:
+		a.openTableViewer()
+		return true
+	case keybind.TermFold:
+		a.toggleFoldsInView()
+		return true
+	case keybind.TermCollapse:
+		a.toggleCommandInView()
+		return true
+	case keybind.TermExport:
+		a.exportInView()
+		return true
+	case keybind.TermScrollUp:
+		a.handleScrollAction(-1)
+		return true
+	case keybind.TermScrollDown:
+		a.handleScrollAction(1)
+		return true
+	case keybind.TermScrollPgUp:
+		a.handleScrollAction(-a.termHeight())
+		return true
+	case keybind.TermScrollPgDn:
+		a.handleScrollAction(a.termHeight())
+		return true
+	}
+	return false
+}
+
 func (a *TexelTerm) RequestClose() bool {
 	return a.RequestCloseWithCallback(func() {
 		// External close confirmed - stop the app
@@ -448,6 +534,14 @@ func (a *TexelTerm) RegisterControl(id, description string, handler func(payload
 	return a.controlBus.Register(id, description, texel.ControlHandler(handler))
 }
 
+// SetOutputNotifier implements texel.OutputNotifierSetter. notify runs
+// for every read from the PTY.
+func (a *TexelTerm) SetOutputNotifier(notify func()) {
+	a.mu.Lock()
+	a.outputNotify = notify
+	a.mu.Unlock()
+}
+
 func (a *TexelTerm) SetPaneID(id [16]byte) {
 	a.mu.Lock()
 	a.paneID = fmt.Sprintf("%x", id)
@@ -721,6 +815,7 @@ func (a *TexelTerm) Render() [][]texelcore.Cell {
 		return nil
 	}
 
+	a.showFoldBlocksLocked()
 	vtermGrid, vtermRowIdx := a.vterm.GridWithRowIdx()
 	termRows := len(vtermGrid)
 	if termRows == 0 {
@@ -801,6 +896,11 @@ func (a *TexelTerm) Render() [][]texelcore.Cell {
 		}
 	}
 
+	if a.tableViewer != nil && termRows <= len(a.buf) {
+		a.tableViewer.Render(a.buf[:termRows])
+	}
+	a.drawPasteOverlay(a.buf)
+
 	if a.confirmClose {
 		a.drawConfirmation(a.buf)
 	}
@@ -966,6 +1066,22 @@ func expandTildePath(path string) string {
 	return path
 }
 
+// ScrollbackDir returns the directory holding the panes' persisted
+// history, following the texelterm.history persist_dir setting. It is
+// empty when no directory can be determined.
+func ScrollbackDir(cfg config.Config) string {
+	dir := expandTildePath(cfg.GetString("texelterm.history", "persist_dir", ""))
+	if dir == "" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, ".texelation")
+		}
+	}
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "scrollback")
+}
+
 // handleConfirmationKey processes key events when the close confirmation dialog is shown.
 // Returns true if the key was handled by the confirmation dialog.
 // Caller must hold a.mu on entry; the lock may be released during callback execution.
@@ -1135,12 +1251,22 @@ func (a *TexelTerm) keyToEscapeSequence(ev *tcell.EventKey, appMode bool) []byte
 func (a *TexelTerm) HandleKey(ev *tcell.EventKey) {
 	// Handle confirmation dialog (if shown)
 	a.mu.Lock()
-	if a.handleConfirmationKey(ev) {
+	if a.handleConfirmationKey(ev) || a.handlePasteKey(ev) {
 		a.mu.Unlock()
 		return
 	}
 	a.mu.Unlock()
 
+	if a.tableViewer != nil && a.tableViewer.HandleKey(ev) {
+		a.mu.Lock()
+		if a.vterm != nil {
+			a.vterm.MarkAllDirty()
+		}
+		a.mu.Unlock()
+		a.requestRefresh()
+		return
+	}
+
 	// Route keys to config panel if visible (absorbs all keys)
 	if a.configPanel != nil && a.configPanel.IsVisible() {
 		if a.configPanel.HandleKey(ev) {
@@ -1157,41 +1283,8 @@ func (a *TexelTerm) HandleKey(ev *tcell.EventKey) {
 	}
 
 	// Keybinding-driven shortcuts (registry injected by desktop; nil in standalone mode)
-	if a.keybindings != nil {
-		switch a.keybindings.Match(ev) {
-		case keybind.TermSearch:
-			if a.historyNavigator != nil {
-				if a.historyNavigator.IsVisible() {
-					a.closeSearch()
-				} else {
-					a.openSearch()
-				}
-				return
-			}
-		case keybind.TermScrollbar:
-			if a.scrollbar != nil {
-				a.scrollbar.Toggle()
-			}
-			return
-		case keybind.TermTransformer:
-			a.toggleTransformers()
-			return
-		case keybind.TermScreenshot:
-			a.takeScreenshot()
-			return
-		case keybind.TermScrollUp:
-			a.handleScrollAction(-1)
-			return
-		case keybind.TermScrollDown:
-			a.handleScrollAction(1)
-			return
-		case keybind.TermScrollPgUp:
-			a.handleScrollAction(-a.termHeight())
-			return
-		case keybind.TermScrollPgDn:
-			a.handleScrollAction(a.termHeight())
-			return
-		}
+	if a.keybindings != nil && a.runAction(a.keybindings.Match(ev)) {
+		return
 	}
 
 	// Route keys to history navigator if visible
@@ -1201,7 +1294,7 @@ func (a *TexelTerm) HandleKey(ev *tcell.EventKey) {
 		}
 	}
 
-	if a.pty == nil {
+	if a.pty == nil || a.deleteInputSelection(ev) {
 		return
 	}
 
@@ -1530,63 +1623,34 @@ func (a *TexelTerm) HandlePaste(data []byte) {
 	if a.pty == nil || len(data) == 0 {
 		return
 	}
+	ps := a.pasteSettings()
+	data, removed := sanitizePaste(data, ps.sanitize)
+	if len(data) == 0 {
+		return
+	}
 
 	// Pasting is a user action - scroll to live edge
 	a.mu.Lock()
 	if a.vterm != nil {
 		a.vterm.EnsureLiveEdge()
 	}
-	a.mu.Unlock()
-
-	// Check if bracketed paste mode is enabled (bool reads are atomic)
-	if a.bracketedPasteMode {
-		// In bracketed paste mode, convert CRLF to LF for Unix-style line endings
-		// The application expects Unix newlines in paste data
-		normalized := make([]byte, 0, len(data))
-		for i := 0; i < len(data); i++ {
-			if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
-				// CRLF -> LF
-				normalized = append(normalized, '\n')
-				i++ // skip next byte (LF)
-			} else {
-				normalized = append(normalized, data[i])
-			}
-		}
-
-		prefix := []byte("\x1b[200~")
-		suffix := []byte("\x1b[201~")
-
-		// Write: prefix + normalized + suffix
-		if _, err := a.pty.Write(prefix); err != nil {
-			log.Printf("TexelTerm: paste prefix write failed: %v", err)
-			return
-		}
-		if _, err := a.pty.Write(normalized); err != nil {
-			log.Printf("TexelTerm: paste data write failed: %v", err)
-			return
-		}
-		if _, err := a.pty.Write(suffix); err != nil {
-			log.Printf("TexelTerm: paste suffix write failed: %v", err)
-		}
-	} else {
-		// No bracketed paste - convert CRLF or LF to CR (terminal behavior)
-		converted := make([]byte, 0, len(data))
-		for i := 0; i < len(data); i++ {
-			if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
-				// CRLF -> CR (skip the LF)
-				converted = append(converted, '\r')
-				i++ // skip next byte (LF)
-			} else if data[i] == '\n' {
-				// Lone LF -> CR
-				converted = append(converted, '\r')
-			} else {
-				converted = append(converted, data[i])
-			}
-		}
-		if _, err := a.pty.Write(converted); err != nil {
-			log.Printf("TexelTerm: paste write failed: %v", err)
+	if a.pasteStream != nil {
+		a.mu.Unlock()
+		log.Printf("TexelTerm: paste ignored while another paste is streaming")
+		return
+	}
+	if reasons := pasteRisks(data, a.bracketedPasteMode, removed, ps.confirm); len(reasons) > 0 {
+		a.pastePending = &pendingPaste{data: data, reasons: reasons, lines: pasteLines(data)}
+		if a.vterm != nil {
+			a.vterm.MarkAllDirty()
 		}
+		a.mu.Unlock()
+		a.requestRefresh()
+		return
 	}
+	a.mu.Unlock()
+
+	a.writePaste(data, ps)
 }
 
 // openSearch activates the search mode: shows the history navigator,
@@ -1636,6 +1700,12 @@ func (a *TexelTerm) HandleMouse(ev *tcell.EventMouse) {
 		return
 	}
 
+	// The table viewer is keyboard driven; keep clicks off the terminal
+	// underneath it.
+	if a.tableViewer != nil && a.tableViewer.IsVisible() {
+		return
+	}
+
 	// Check if config panel is visible (absorbs all mouse events)
 	if a.configPanel != nil && a.configPanel.IsVisible() {
 		if a.configPanel.HandleMouse(ev) {
@@ -1656,6 +1726,9 @@ func (a *TexelTerm) HandleMouse(ev *tcell.EventMouse) {
 
 	x, y := ev.Position()
 	buttons := ev.Buttons()
+	press := buttons&tcell.Button1 != 0 && a.lastButtons&tcell.Button1 == 0
+	release := buttons&tcell.Button1 == 0 && a.lastButtons&tcell.Button1 != 0
+	a.lastButtons = buttons
 
 	// Check if click is on the scrollbar (skip row 0 where toggle overlay sits)
 	if a.scrollbar != nil && a.scrollbar.IsVisible() && y > 0 {
@@ -1685,6 +1758,23 @@ func (a *TexelTerm) HandleMouse(ev *tcell.EventMouse) {
 		}
 	}
 
+	if a.handleHighlightClick(x, y, press, ev.Modifiers()) {
+		a.requestRefresh()
+		return
+	}
+
+	if a.handleCommandSummaryClick(y, press, ev.Modifiers()) {
+		a.requestRefresh()
+		return
+	}
+
+	if a.handleFoldClick(x, y, press, ev.Modifiers()) {
+		a.requestRefresh()
+		return
+	}
+
+	a.noteInputClick(x, y, press, release, ev.Modifiers())
+
 	// Delegate to mouse coordinator for terminal content
 	if a.mouseCoordinator != nil {
 		a.mouseCoordinator.HandleMouse(ev)
@@ -1722,6 +1812,43 @@ func (a *TexelTerm) SetClipboard(mime string, data []byte) {
 	}
 }
 
+// osc52Set handles an app setting the clipboard via OSC 52.
+// It uses clipboardMu (not a.mu) because Parse() fires it synchronously
+// while a.mu is held by the PTY reader — using a.mu here would deadlock.
+func (a *TexelTerm) osc52Set(data []byte) {
+	a.clipboardMu.Lock()
+	clipboard := a.clipboard
+	a.clipboardMu.Unlock()
+	if osc, ok := clipboard.(texel.OSC52Clipboard); ok {
+		osc.OSC52Write(data)
+	} else if clipboard != nil {
+		clipboard.SetClipboard("text/plain", data)
+	}
+}
+
+// osc52Get handles an app querying the clipboard via OSC 52. Under the
+// desktop the answer may need the user's consent, so the reply is written
+// later through ReplyClipboard and nothing is returned here.
+// Uses clipboardMu (not a.mu) — see osc52Set.
+func (a *TexelTerm) osc52Get() []byte {
+	a.clipboardMu.Lock()
+	clipboard := a.clipboard
+	a.clipboardMu.Unlock()
+	if osc, ok := clipboard.(texel.OSC52Clipboard); ok {
+		if v := a.vterm; v != nil {
+			osc.OSC52Read(v.ReplyClipboard)
+		}
+		return nil
+	}
+	if clipboard != nil {
+		_, data, ok := clipboard.GetClipboard()
+		if ok {
+			return data
+		}
+	}
+	return nil
+}
+
 // SetClipboardService implements texelcore.ClipboardAware.
 // This is called by the runtime (standalone) or desktop (embedded) to provide clipboard access.
 func (a *TexelTerm) SetClipboardService(clipboard texelcore.ClipboardService) {
@@ -1905,6 +2032,13 @@ func (a *TexelTerm) runShell() error {
 
 	log.Printf("[TEXELTERM] runShell starting: cols=%d, rows=%d, restart=%v, paneID=%s", cols, rows, isRestart, paneID)
 
+	// A server upgrade may have handed us this pane's running shell.
+	if !isRestart {
+		if inherited, ok := takeInheritedPTY(paneID); ok {
+			return a.runInheritedShell(inherited, cols, rows)
+		}
+	}
+
 	// Load environment and working directory from pane-specific file
 	env, cwd := a.loadShellEnvironment(paneID)
 
@@ -1935,7 +2069,7 @@ func (a *TexelTerm) runShell() error {
 	}
 
 	// Start PTY reader and wait for exit
-	return a.runPtyReaderLoop(ptmx, cmd)
+	return a.runPtyReaderLoop(ptmx, cmd.Wait)
 }
 
 // loadShellEnvironment loads environment variables and working directory from pane-specific file.
@@ -2025,28 +2159,8 @@ func (a *TexelTerm) updatePtyWriterForRestart() {
 			}
 		}
 		// Update clipboard callbacks (they reference a.clipboard which might change).
-		// These use clipboardMu (not a.mu) because Parse() fires them synchronously
-		// while a.mu is held by the PTY reader — using a.mu here would deadlock.
-		a.vterm.OnClipboardSet = func(data []byte) {
-			a.clipboardMu.Lock()
-			clipboard := a.clipboard
-			a.clipboardMu.Unlock()
-			if clipboard != nil {
-				clipboard.SetClipboard("text/plain", data)
-			}
-		}
-		a.vterm.OnClipboardGet = func() []byte {
-			a.clipboardMu.Lock()
-			clipboard := a.clipboard
-			a.clipboardMu.Unlock()
-			if clipboard != nil {
-				_, data, ok := clipboard.GetClipboard()
-				if ok {
-					return data
-				}
-			}
-			return nil
-		}
+		a.vterm.OnClipboardSet = a.osc52Set
+		a.vterm.OnClipboardGet = a.osc52Get
 	}
 }
 
@@ -2065,9 +2179,15 @@ func (a *TexelTerm) initializeVTermFirstRun(cols, rows int, paneID string) {
 		parser.WithCommandStartHandler(func(cmd string) {
 			if cmd != "" {
 				a.title = cmd
-				a.requestRefresh()
 			}
+			a.beginCommandBlockLocked(cmd)
+			a.noteCommandStart(cmd)
 		}),
+		parser.WithCommandEndHandler(func(exitCode int) {
+			a.endCommandBlockLocked(exitCode)
+			a.noteCommandEnd(exitCode)
+		}),
+		parser.WithWorkingDirChangeHandler(a.noteWorkingDir),
 		parser.WithPtyWriter(func(b []byte) {
 			if a.pty != nil {
 				if _, err := a.pty.Write(b); err != nil {
@@ -2093,31 +2213,8 @@ func (a *TexelTerm) initializeVTermFirstRun(cols, rows int, paneID string) {
 		parser.WithBracketedPasteModeChangeHandler(func(enabled bool) {
 			a.bracketedPasteMode = enabled
 		}),
-		parser.WithClipboardSetHandler(func(data []byte) {
-			// App is setting clipboard via OSC 52.
-			// Uses clipboardMu (not a.mu) because Parse() fires this synchronously
-			// while a.mu is held by the PTY reader — using a.mu here would deadlock.
-			a.clipboardMu.Lock()
-			clipboard := a.clipboard
-			a.clipboardMu.Unlock()
-			if clipboard != nil {
-				clipboard.SetClipboard("text/plain", data)
-			}
-		}),
-		parser.WithClipboardGetHandler(func() []byte {
-			// App is querying clipboard via OSC 52.
-			// Uses clipboardMu (not a.mu) — see comment above.
-			a.clipboardMu.Lock()
-			clipboard := a.clipboard
-			a.clipboardMu.Unlock()
-			if clipboard != nil {
-				_, data, ok := clipboard.GetClipboard()
-				if ok {
-					return data
-				}
-			}
-			return nil
-		}),
+		parser.WithClipboardSetHandler(a.osc52Set),
+		parser.WithClipboardGetHandler(a.osc52Get),
 		parser.WithBellHandler(func() {
 			bellEnabled := a.paneConfig().GetBool("texelterm", "visual_bell_enabled", false)
 			if bellEnabled {
@@ -2139,6 +2236,15 @@ func (a *TexelTerm) initializeVTermFirstRun(cols, rows int, paneID string) {
 		pipeline.SetInsertFunc(a.vterm.RequestLineInsert)
 		pipeline.SetOverlayFunc(a.vterm.RequestLineOverlay)
 		pipeline.SetPersistNotifyFunc(a.vterm.NotifyLinePersist)
+		// Replies from external filters arrive on their own goroutines.
+		pipeline.SetAsyncOverlayFunc(func(lineIdx int64, cells []parser.Cell) {
+			a.mu.Lock()
+			if a.vterm != nil {
+				a.vterm.RequestLineOverlay(lineIdx, cells)
+			}
+			a.mu.Unlock()
+			a.requestRefresh()
+		})
 
 		// Forward command start to the pipeline for command-aware detection.
 		origHandler := a.vterm.OnCommandStart
@@ -2220,6 +2326,7 @@ func (a *TexelTerm) initializeVTermFirstRun(cols, rows int, paneID string) {
 		a.requestRefresh,
 	)
 	a.mouseCoordinator.SetClipboardSetter(a) // Wire up clipboard for standalone mode
+	a.mouseCoordinator.SetWordSelector(a.inputWordAt)
 
 	// Load and apply persisted state
 	savedState := a.loadStateLocked()
@@ -2230,17 +2337,11 @@ func (a *TexelTerm) initializeVTermFirstRun(cols, rows int, paneID string) {
 // readWALWorkingDir pre-reads the last known CWD from the WAL before the full VTerm is initialized.
 // This allows the shell to start in the correct directory on reload.
 func (a *TexelTerm) readWALWorkingDir(paneID string) string {
-	cfg := a.paneConfig()
-	historyPersistDir := expandTildePath(cfg.GetString("texelterm.history", "persist_dir", ""))
-	if historyPersistDir == "" {
-		if homeDir, err := os.UserHomeDir(); err == nil {
-			historyPersistDir = filepath.Join(homeDir, ".texelation")
-		}
-	}
-	if historyPersistDir == "" {
+	scrollbackDir := ScrollbackDir(a.paneConfig())
+	if scrollbackDir == "" {
 		return ""
 	}
-	diskPath := filepath.Join(historyPersistDir, "scrollback", paneID+".hist3")
+	diskPath := filepath.Join(scrollbackDir, paneID+".hist3")
 	return parser.ReadWALWorkingDir(diskPath, paneID)
 }
 
@@ -2277,6 +2378,7 @@ func (a *TexelTerm) initializeMemoryBufferLocked(paneID string, cfg config.Confi
 			a.vterm.EnableMemoryBuffer()
 		} else {
 			log.Printf("[MEMORY_BUFFER] Enabled with disk persistence: %s", diskPath)
+			a.commands.logPath = parser.CommandLogPath(diskPath, paneID)
 		}
 
 		// Initialize search index (Phase 3 - Disk Layer)
@@ -2377,7 +2479,13 @@ func (a *TexelTerm) populateFromHistoryLocked(savedState terminalState) {
 
 	// With MemoryBuffer, history is automatically loaded from disk if available.
 	// The scroll offset is restored in applyRestoredStateLocked.
-	if savedState.LastPromptLine >= 0 {
+	if a.adoptedPID != 0 {
+		// The shell survived a server upgrade and will not reprint its
+		// prompt; the WAL-restored cursor is already where it left off.
+		if a.renderDebugLog != nil {
+			a.renderDebugLog("[RECOVERY] Adopted PTY, keeping restored cursor")
+		}
+	} else if savedState.LastPromptLine >= 0 {
 		// Rewind the write window to the stored prompt so the freshly-spawned
 		// shell's first PS1 prompt lands at col 0 of the same global line and
 		// overwrites the previous prompt instead of rendering below it. Works
@@ -2394,7 +2502,8 @@ func (a *TexelTerm) populateFromHistoryLocked(savedState terminalState) {
 }
 
 // runPtyReaderLoop reads from PTY, parses output, and handles shell exit.
-func (a *TexelTerm) runPtyReaderLoop(ptmx *os.File, cmd *exec.Cmd) error {
+// wait blocks until the shell process is gone.
+func (a *TexelTerm) runPtyReaderLoop(ptmx *os.File, wait func() error) error {
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
@@ -2422,8 +2531,13 @@ func (a *TexelTerm) runPtyReaderLoop(ptmx *os.File, cmd *exec.Cmd) error {
 				if syncEnded {
 					a.vterm.MarkAllDirty()
 				}
+				notify := a.outputNotify
 				a.mu.Unlock()
 
+				if notify != nil {
+					notify()
+				}
+
 				if syncEnded {
 					a.invalidateScrollbar()
 					a.requestRefresh()
@@ -2441,7 +2555,7 @@ func (a *TexelTerm) runPtyReaderLoop(ptmx *os.File, cmd *exec.Cmd) error {
 		}
 	}()
 
-	if err := cmd.Wait(); err != nil {
+	if err := wait(); err != nil {
 		log.Printf("[TEXELTERM] Shell exited with: %v", err)
 	}
 	a.wg.Wait()
@@ -2570,22 +2684,48 @@ func (a *TexelTerm) flushSetsize() {
 }
 
 func (a *TexelTerm) Stop() {
+	_, _ = a.shutdown(false)
+}
+
+// shutdown stops the terminal once. With detach, the shell is left
+// running and a duplicate of its PTY master is returned for handoff;
+// otherwise the shell is terminated.
+func (a *TexelTerm) shutdown(detach bool) (handoff.Pane, error) {
+	var (
+		detached  handoff.Pane
+		detachErr = errNoPTY
+	)
 	a.stopOnce.Do(func() {
 		close(a.stop)
 		var (
-			cmd     *exec.Cmd
-			ptyFile *os.File
+			cmd        *exec.Cmd
+			ptyFile    *os.File
+			adoptedPID int
 		)
 		a.mu.Lock()
+		if a.pasteStream != nil {
+			a.pasteStream.stop()
+		}
 
 		// Extract and nil out cmd/pty first so no new data arrives
 		cmd = a.cmd
 		ptyFile = a.pty
+		adoptedPID = a.adoptedPID
 		a.cmd = nil
 		a.pty = nil
 
+		if detach && ptyFile != nil {
+			detached, detachErr = a.detachHandleLocked(ptyFile, cmd, adoptedPID)
+			if detachErr == nil {
+				// The shell now belongs to the next server.
+				cmd = nil
+				adoptedPID = 0
+			}
+		}
+
 		// Close PTY to stop new data from arriving before persistence flush
 		if ptyFile != nil {
+			_ = ptyFile.SetReadDeadline(time.Now())
 			_ = ptyFile.Close()
 		}
 
@@ -2601,9 +2741,14 @@ func (a *TexelTerm) Stop() {
 		a.stopCapture()
 
 		// Signal process to terminate (with deferred SIGKILL fallback)
+		var proc *os.Process
 		if cmd != nil && cmd.Process != nil {
-			_ = cmd.Process.Signal(syscall.SIGTERM)
-			proc := cmd.Process
+			proc = cmd.Process
+		} else if adoptedPID != 0 {
+			proc, _ = os.FindProcess(adoptedPID)
+		}
+		if proc != nil {
+			_ = proc.Signal(syscall.SIGTERM)
 			go func() {
 				time.Sleep(500 * time.Millisecond)
 				proc.Signal(syscall.SIGKILL) // Ignore error; process may already be gone.
@@ -2620,6 +2765,11 @@ func (a *TexelTerm) Stop() {
 			}
 		}
 
+		// Stop external filter processes
+		if a.pipeline != nil {
+			a.pipeline.Close()
+		}
+
 		// Close search index (flushes pending writes)
 		if a.searchIndex != nil {
 			if err := a.searchIndex.Close(); err != nil {
@@ -2635,6 +2785,34 @@ func (a *TexelTerm) Stop() {
 		a.mu.Unlock()
 	})
 	a.wg.Wait()
+	if !detach {
+		return handoff.Pane{}, nil
+	}
+	return detached, detachErr
+}
+
+// detachHandleLocked builds the handoff handle for the running shell.
+// Must be called with a.mu held, before the original PTY is closed.
+func (a *TexelTerm) detachHandleLocked(ptyFile *os.File, cmd *exec.Cmd, adoptedPID int) (handoff.Pane, error) {
+	pid := adoptedPID
+	if cmd != nil && cmd.Process != nil {
+		pid = cmd.Process.Pid
+	}
+	if pid == 0 {
+		return handoff.Pane{}, errNoPTY
+	}
+	dup, err := dupPTY(ptyFile)
+	if err != nil {
+		return handoff.Pane{}, fmt.Errorf("dup pty: %w", err)
+	}
+	var modes []byte
+	if a.vterm != nil {
+		modes = a.vterm.ModeReplaySequence()
+	}
+	return handoff.Pane{
+		PaneHandle: handoff.PaneHandle{PaneID: a.paneID, PID: pid, Modes: modes},
+		PTY:        dup,
+	}, nil
 }
 
 func (a *TexelTerm) GetTitle() string {
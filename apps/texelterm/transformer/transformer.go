@@ -9,6 +9,8 @@ package transformer
 
 import (
 	"log"
+	"path"
+	"strings"
 	"sync"
 
 	"github.com/framegrace/texelation/apps/texelterm/parser"
@@ -37,6 +39,14 @@ type LineOverlayer interface {
 	SetOverlayFunc(fn func(lineIdx int64, cells []parser.Cell))
 }
 
+// AsyncOverlayer is an optional interface for transformers that compute
+// overlays off the parser goroutine, such as replies from external
+// processes. The callback may run on any goroutine, after the line has
+// been committed.
+type AsyncOverlayer interface {
+	SetAsyncOverlayFunc(fn func(lineIdx int64, cells []parser.Cell))
+}
+
 // LineSuppressor is an optional interface that transformers can implement
 // to consume a line, preventing further pipeline processing and scrollback
 // persistence. Used by buffering transformers like tablefmt.
@@ -59,6 +69,13 @@ type Resizer interface {
 	NotifyResize(cols, rows int)
 }
 
+// Closer is an optional interface for transformers that hold resources,
+// such as external processes, which must be released when the terminal
+// closes.
+type Closer interface {
+	Close()
+}
+
 // Config holds per-transformer configuration.
 type Config map[string]interface{}
 
@@ -108,6 +125,10 @@ func (p *Pipeline) SetEnabled(on bool) { p.enabled = on }
 // Enabled returns whether the pipeline is currently enabled.
 func (p *Pipeline) Enabled() bool { return p.enabled }
 
+// Transformers returns the transformers in pipeline order, so callers can
+// reach features specific to one of them.
+func (p *Pipeline) Transformers() []Transformer { return p.transformers }
+
 // SetInsertFunc sets the line insertion callback. The pipeline forwards
 // it to any transformer that implements LineInserter.
 func (p *Pipeline) SetInsertFunc(fn func(beforeIdx int64, cells []parser.Cell)) {
@@ -130,6 +151,17 @@ func (p *Pipeline) SetOverlayFunc(fn func(lineIdx int64, cells []parser.Cell)) {
 	}
 }
 
+// SetAsyncOverlayFunc sets the callback for overlays computed off the
+// parser goroutine. The pipeline forwards it to any transformer that
+// implements AsyncOverlayer.
+func (p *Pipeline) SetAsyncOverlayFunc(fn func(lineIdx int64, cells []parser.Cell)) {
+	for _, t := range p.transformers {
+		if ao, ok := t.(AsyncOverlayer); ok {
+			ao.SetAsyncOverlayFunc(fn)
+		}
+	}
+}
+
 // SetPersistNotifyFunc sets the persistence notification callback.
 // The pipeline forwards it to any transformer that implements LinePersistNotifier.
 func (p *Pipeline) SetPersistNotifyFunc(fn func(lineIdx int64)) {
@@ -188,9 +220,38 @@ func (p *Pipeline) NotifyResize(cols, rows int) {
 	}
 }
 
+// Close releases the resources of transformers that implement Closer.
+func (p *Pipeline) Close() {
+	for _, t := range p.transformers {
+		if c, ok := t.(Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// MatchCommand reports whether a command line matches glob, tried against
+// the whole line and against the base name of its first word. An empty
+// glob matches everything; an unknown (empty) command matches only that.
+func MatchCommand(glob, cmd string) bool {
+	if glob == "" {
+		return true
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(glob, cmd); ok {
+		return true
+	}
+	ok, _ := path.Match(glob, path.Base(fields[0]))
+	return ok
+}
+
 // BuildPipeline reads the "transformers" config section and creates an
 // ordered pipeline. Returns nil (no-op) if transformers are disabled or
-// the section is missing.
+// the section is missing. Settings too rich for a pipeline entry, such as
+// lists of rules, may live in a "transformers.<id>" section; keys on the
+// entry itself win.
 func BuildPipeline(cfg config.Config) *Pipeline {
 	if !cfg.GetBool("transformers", "enabled", true) {
 		return nil
@@ -229,6 +290,9 @@ func BuildPipeline(cfg config.Config) *Pipeline {
 			continue
 		}
 		tcfg := make(Config)
+		for k, v := range cfg.Section("transformers." + id) {
+			tcfg[k] = v
+		}
 		for k, v := range m {
 			if k != "id" && k != "enabled" {
 				tcfg[k] = v
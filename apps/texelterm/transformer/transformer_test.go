@@ -247,6 +247,36 @@ func TestBuildPipelinePassesConfig(t *testing.T) {
 	}
 }
 
+func TestBuildPipelineMergesSection(t *testing.T) {
+	const id = "test-build-config-section"
+	var receivedCfg Config
+	Register(id, func(cfg Config) (Transformer, error) {
+		receivedCfg = cfg
+		return &stubTransformer{id: id}, nil
+	})
+
+	cfg := config.Config{
+		"transformers": map[string]interface{}{
+			"pipeline": []interface{}{
+				map[string]interface{}{"id": id, "style": "entry"},
+			},
+		},
+		"transformers." + id: map[string]interface{}{
+			"rules": []interface{}{"r1"},
+			"style": "section",
+		},
+	}
+	if BuildPipeline(cfg) == nil {
+		t.Fatal("expected non-nil pipeline")
+	}
+	if rules, ok := receivedCfg["rules"].([]interface{}); !ok || len(rules) != 1 {
+		t.Errorf("rules from the section not passed: %v", receivedCfg["rules"])
+	}
+	if receivedCfg["style"] != "entry" {
+		t.Errorf("entry keys should win, got style=%v", receivedCfg["style"])
+	}
+}
+
 // suppressingTransformer suppresses even-numbered lines.
 type suppressingTransformer struct {
 	stubTransformer
@@ -386,3 +416,24 @@ func TestBuildPipelineDefaultEnabled(t *testing.T) {
 		t.Fatal("expected non-nil pipeline when entry has no explicit enabled flag")
 	}
 }
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		glob, cmd string
+		want      bool
+	}{
+		{"", "", true},
+		{"", "ls -l", true},
+		{"kubectl", "", false},
+		{"kubectl", "kubectl get pods", true},
+		{"kubectl", "/usr/bin/kubectl logs x", true},
+		{"git*", "git log", true},
+		{"git log*", "git log --oneline", true},
+		{"git", "gitk", false},
+	}
+	for _, tt := range tests {
+		if got := MatchCommand(tt.glob, tt.cmd); got != tt.want {
+			t.Errorf("MatchCommand(%q, %q) = %v, want %v", tt.glob, tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package txfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+const (
+	// defaultFoldThreshold is how many lines a structured block may span
+	// before its top-level folds start out collapsed.
+	defaultFoldThreshold = 200
+
+	// maxFoldLines bounds how many lines of one block are kept for folding.
+	maxFoldLines = 50000
+
+	// maxRecordedFoldBlocks bounds how many blocks a formatter remembers.
+	maxRecordedFoldBlocks = 64
+)
+
+// FoldKind tells what a fold holds.
+type FoldKind int
+
+const (
+	FoldObject FoldKind = iota // JSON object or YAML mapping
+	FoldArray                  // JSON array or YAML sequence
+)
+
+// Fold is a collapsible region of structured output. The header line stays
+// visible; when collapsed, lines Start+1 through End are hidden and the
+// header is drawn up to Col followed by Summary.
+type Fold struct {
+	Start, End int64 // header line and last hidden line, global indices
+	Col        int   // cell where the summary replaces the header text
+	Kind       FoldKind
+	Count      int // direct keys or items
+	Collapsed  bool
+	Children   []*Fold
+}
+
+// Summary describes the collapsed region, e.g. "{…} 42 keys".
+func (f *Fold) Summary() string {
+	if f.Kind == FoldArray {
+		return fmt.Sprintf("[…] %d %s", f.Count, plural(f.Count, "item"))
+	}
+	return fmt.Sprintf("{…} %d %s", f.Count, plural(f.Count, "key"))
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// FoldBlock is one structured block of output and its fold tree. Fold
+// state lives in the tree, so it survives for as long as the block is
+// remembered.
+type FoldBlock struct {
+	Start, End int64   // global line range of the block, inclusive
+	Folds      []*Fold // top-level folds in line order
+}
+
+// Contains reports whether lineIdx is inside the block.
+func (b *FoldBlock) Contains(lineIdx int64) bool {
+	return lineIdx >= b.Start && lineIdx <= b.End
+}
+
+// FoldAt returns the outermost fold whose header is lineIdx, or nil.
+func (b *FoldBlock) FoldAt(lineIdx int64) *Fold {
+	folds := b.Folds
+	for {
+		var next []*Fold
+		for _, f := range folds {
+			if f.Start == lineIdx {
+				return f
+			}
+			if lineIdx > f.Start && lineIdx <= f.End {
+				next = f.Children
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		folds = next
+	}
+}
+
+// Visible resolves lineIdx against the collapsed folds. It returns the
+// outermost collapsed fold covering the line and whether the line is
+// hidden by it; a nil fold means the line shows as is, a non-nil fold
+// with hidden false means the line is that fold's summary header.
+func (b *FoldBlock) Visible(lineIdx int64) (f *Fold, hidden bool) {
+	folds := b.Folds
+	for {
+		var next []*Fold
+		for _, f := range folds {
+			if lineIdx < f.Start || lineIdx > f.End {
+				continue
+			}
+			if f.Collapsed {
+				return f, lineIdx != f.Start
+			}
+			next = f.Children
+			break
+		}
+		if next == nil {
+			return nil, false
+		}
+		folds = next
+	}
+}
+
+// AnyCollapsed reports whether some fold in the block is collapsed.
+func (b *FoldBlock) AnyCollapsed() bool {
+	var walk func([]*Fold) bool
+	walk = func(folds []*Fold) bool {
+		for _, f := range folds {
+			if f.Collapsed || walk(f.Children) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(b.Folds)
+}
+
+// SetCollapsed collapses or expands the top-level folds. Expanding also
+// opens every nested fold so the whole block shows again.
+func (b *FoldBlock) SetCollapsed(collapsed bool) {
+	var walk func([]*Fold)
+	walk = func(folds []*Fold) {
+		for _, f := range folds {
+			f.Collapsed = false
+			walk(f.Children)
+		}
+	}
+	if !collapsed {
+		walk(b.Folds)
+		return
+	}
+	for _, f := range b.Folds {
+		f.Collapsed = true
+	}
+}
+
+// DetectFolds finds JSON and YAML blocks among lines and builds their fold
+// trees. idx holds each line's global index. Blocks without anything to
+// fold are left out.
+func DetectFolds(idx []int64, lines []string) []*FoldBlock {
+	var blocks []*FoldBlock
+	for i := 0; i < len(lines); {
+		trim := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trim, "{") || strings.HasPrefix(trim, "[") {
+			if folds, n := parseJSONBlock(idx[i:], lines[i:]); n > 0 {
+				if len(folds) > 0 {
+					blocks = append(blocks, &FoldBlock{Start: idx[i], End: idx[i+n-1], Folds: folds})
+				}
+				i += n
+				continue
+			}
+		}
+		if reYAMLLine.MatchString(lines[i]) || strings.HasPrefix(trim, "- ") {
+			j := i + 1
+			for j < len(lines) && isYAMLLine(lines[j]) {
+				j++
+			}
+			if folds := buildYAMLFolds(idx[i:j], lines[i:j]); len(folds) > 0 {
+				blocks = append(blocks, &FoldBlock{Start: idx[i], End: idx[j-1], Folds: folds})
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+	return blocks
+}
+
+// jsonFrame is an open JSON container while parsing.
+type jsonFrame struct {
+	fold  *Fold
+	line  int
+	items int
+	value bool // a value was seen since the last comma
+}
+
+// parseJSONBlock parses the JSON value starting on the first line and
+// returns its folds and how many lines it spans. n is 0 when the value
+// never closes.
+func parseJSONBlock(idx []int64, lines []string) (folds []*Fold, n int) {
+	var stack []*jsonFrame
+	inString, escaped := false, false
+	markValue := func() {
+		if len(stack) > 0 {
+			stack[len(stack)-1].value = true
+		}
+	}
+	for li, text := range lines {
+		runes := []rune(text)
+		for col, r := range runes {
+			if inString {
+				switch {
+				case escaped:
+					escaped = false
+				case r == '\\':
+					escaped = true
+				case r == '"':
+					inString = false
+				}
+				continue
+			}
+			switch r {
+			case '"':
+				inString = true
+				markValue()
+			case '{', '[':
+				markValue()
+				kind := FoldObject
+				if r == '[' {
+					kind = FoldArray
+				}
+				stack = append(stack, &jsonFrame{
+					fold: &Fold{Start: idx[li], Col: col, Kind: kind},
+					line: li,
+				})
+			case '}', ']':
+				if len(stack) == 0 {
+					return nil, 0
+				}
+				fr := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if fr.value {
+					fr.items++
+				}
+				fr.fold.Count = fr.items
+				end := li
+				// Keep a closing line like "}, {" visible: it opens the
+				// next sibling.
+				if strings.Trim(string(runes[col+1:]), " ,") != "" {
+					end--
+				}
+				if end > fr.line {
+					fr.fold.End = idx[end]
+					if len(stack) > 0 {
+						parent := stack[len(stack)-1].fold
+						parent.Children = append(parent.Children, fr.fold)
+					} else {
+						folds = append(folds, fr.fold)
+					}
+				}
+				if len(stack) == 0 {
+					return folds, li + 1
+				}
+			case ',':
+				if len(stack) > 0 {
+					stack[len(stack)-1].items++
+					stack[len(stack)-1].value = false
+				}
+			case ':':
+			default:
+				if !unicode.IsSpace(r) {
+					markValue()
+				}
+			}
+		}
+	}
+	return nil, 0
+}
+
+var reYAMLLine = regexp.MustCompile(`^\s*(?:- +)?(?:[\w./-]+|"[^"]*"|'[^']*')\s*:(?:\s|$)`)
+
+// isYAMLLine reports whether a line can belong to a YAML block: a key, a
+// sequence item, a document marker or an indented continuation.
+func isYAMLLine(s string) bool {
+	trim := strings.TrimSpace(s)
+	if trim == "" {
+		return false
+	}
+	return reYAMLLine.MatchString(s) || strings.HasPrefix(trim, "- ") || trim == "-" ||
+		trim == "---" || s[0] == ' '
+}
+
+// yamlEntry is a non-blank, non-comment YAML line.
+type yamlEntry struct {
+	line   int
+	indent int
+	text   string // trimmed
+}
+
+// buildYAMLFolds builds folds from indentation: a "key:" line folds the
+// deeper lines below it (or a sequence at its own indent), and a "- "
+// item folds its indented continuation lines.
+func buildYAMLFolds(idx []int64, lines []string) []*Fold {
+	var entries []yamlEntry
+	for i, s := range lines {
+		trim := strings.TrimSpace(s)
+		if trim == "" || strings.HasPrefix(trim, "#") {
+			continue
+		}
+		entries = append(entries, yamlEntry{line: i, indent: len(s) - len(strings.TrimLeft(s, " ")), text: trim})
+	}
+	var build func(lo, hi int) []*Fold
+	build = func(lo, hi int) []*Fold {
+		var folds []*Fold
+		for k := lo; k < hi; {
+			h := entries[k]
+			item := strings.HasPrefix(h.text, "- ")
+			keyHeader := !item && strings.HasSuffix(h.text, ":")
+			m := k + 1
+			for m < hi {
+				e := entries[m]
+				deeper := e.indent > h.indent
+				seq := keyHeader && e.indent == h.indent && (strings.HasPrefix(e.text, "- ") || e.text == "-")
+				if !deeper && !seq {
+					break
+				}
+				m++
+			}
+			if m == k+1 || (!item && !keyHeader) || h.text == "---" {
+				k++
+				continue
+			}
+			f := &Fold{Start: idx[h.line], End: idx[entries[m-1].line], Kind: FoldObject}
+			child := entries[k+1]
+			childIndent := child.indent
+			if item {
+				f.Col = h.indent + 2
+				f.Count = 1
+				childIndent = f.Col
+			} else {
+				f.Col = h.indent + len([]rune(h.text)) + 1
+				if strings.HasPrefix(child.text, "- ") || child.text == "-" {
+					f.Kind = FoldArray
+				}
+			}
+			for _, e := range entries[k+1 : m] {
+				if e.indent != childIndent {
+					continue
+				}
+				if f.Kind == FoldArray {
+					if strings.HasPrefix(e.text, "- ") || e.text == "-" {
+						f.Count++
+					}
+				} else if reYAMLLine.MatchString(strings.Repeat(" ", e.indent) + e.text) {
+					f.Count++
+				}
+			}
+			f.Children = build(k+1, m)
+			folds = append(folds, f)
+			k = m
+		}
+		return folds
+	}
+	return build(0, len(entries))
+}
+
+// foldLine is a structured output line waiting for its block to end.
+type foldLine struct {
+	lineIdx int64
+	text    string
+}
+
+// foldLog remembers the most recent fold blocks a formatter built.
+type foldLog struct {
+	mu     sync.Mutex
+	blocks []*FoldBlock
+}
+
+func (l *foldLog) record(blocks []*FoldBlock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocks = append(l.blocks, blocks...)
+	if over := len(l.blocks) - maxRecordedFoldBlocks; over > 0 {
+		l.blocks = append(l.blocks[:0], l.blocks[over:]...)
+	}
+}
+
+// cellText returns the text of cells with blanks kept, so rune offsets
+// match cell columns.
+func cellText(cells []parser.Cell) string {
+	var b strings.Builder
+	for _, c := range cells {
+		if c.Rune == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// recordFoldLine keeps a JSON or YAML output line for folding.
+func (f *Formatter) recordFoldLine(lineIdx int64, line *parser.LogicalLine) {
+	if len(f.foldLines) < maxFoldLines {
+		f.foldLines = append(f.foldLines, foldLine{lineIdx, cellText(line.Cells)})
+	}
+}
+
+// flushFolds builds the fold trees for the block that just ended. Blocks
+// taller than the fold threshold start with their top-level folds
+// collapsed so long output does not bury the prompt.
+func (f *Formatter) flushFolds() {
+	if len(f.foldLines) == 0 {
+		return
+	}
+	idx := make([]int64, len(f.foldLines))
+	lines := make([]string, len(f.foldLines))
+	for i, l := range f.foldLines {
+		idx[i], lines[i] = l.lineIdx, l.text
+	}
+	f.foldLines = f.foldLines[:0]
+	blocks := DetectFolds(idx, lines)
+	for _, b := range blocks {
+		if f.foldThreshold > 0 && b.End-b.Start+1 > int64(f.foldThreshold) {
+			b.SetCollapsed(true)
+		}
+	}
+	f.folds.record(blocks)
+}
+
+// FoldBlockAt returns the most recent fold block covering lineIdx, or nil.
+func (f *Formatter) FoldBlockAt(lineIdx int64) *FoldBlock {
+	f.folds.mu.Lock()
+	defer f.folds.mu.Unlock()
+	for i := len(f.folds.blocks) - 1; i >= 0; i-- {
+		if b := f.folds.blocks[i]; b.Contains(lineIdx) {
+			return b
+		}
+	}
+	return nil
+}
+
+// FoldBlocks returns the recorded fold blocks, oldest first.
+func (f *Formatter) FoldBlocks() []*FoldBlock {
+	f.folds.mu.Lock()
+	defer f.folds.mu.Unlock()
+	return append([]*FoldBlock(nil), f.folds.blocks...)
+}
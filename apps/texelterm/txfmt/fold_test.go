@@ -0,0 +1,160 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package txfmt
+
+import (
+	"testing"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+)
+
+func lineIndices(start int64, n int) []int64 {
+	idx := make([]int64, n)
+	for i := range idx {
+		idx[i] = start + int64(i)
+	}
+	return idx
+}
+
+func TestDetectFoldsJSON(t *testing.T) {
+	lines := []string{
+		"$ kubectl get pods -o json", // 10
+		"{",                          // 11
+		`  "kind": "List",`,          // 12
+		`  "items": [`,               // 13
+		"    {",                      // 14
+		`      "name": "a",`,         // 15
+		`      "tags": ["x", "y"]`,   // 16
+		"    }, {",                   // 17
+		`      "name": "b"`,          // 18
+		"    }",                      // 19
+		"  ]",                        // 20
+		"}",                          // 21
+		"$ ",                         // 22
+	}
+	blocks := DetectFolds(lineIndices(10, len(lines)), lines)
+	if len(blocks) != 1 {
+		t.Fatalf("blocks = %d, want 1", len(blocks))
+	}
+	b := blocks[0]
+	if b.Start != 11 || b.End != 21 || len(b.Folds) != 1 {
+		t.Fatalf("block = %+v", b)
+	}
+	root := b.Folds[0]
+	if root.Start != 11 || root.End != 21 || root.Count != 2 || root.Summary() != "{…} 2 keys" {
+		t.Fatalf("root = %+v %q", root, root.Summary())
+	}
+	items := b.FoldAt(13)
+	if items == nil || items.End != 20 || items.Count != 2 || items.Col != 11 || items.Summary() != "[…] 2 items" {
+		t.Fatalf("items = %+v", items)
+	}
+	if len(items.Children) != 2 {
+		t.Fatalf("items children = %d", len(items.Children))
+	}
+	// "}, {" opens the second item, so the first one stops short of it.
+	if first := items.Children[0]; first.Start != 14 || first.End != 16 || first.Count != 2 {
+		t.Errorf("first item = %+v", first)
+	}
+	if second := b.FoldAt(17); second == nil || second.End != 19 || second.Count != 1 {
+		t.Errorf("second item = %+v", second)
+	}
+
+	items.Collapsed = true
+	if f, hidden := b.Visible(13); f != items || hidden {
+		t.Error("header should show the summary")
+	}
+	if f, hidden := b.Visible(18); f != items || !hidden {
+		t.Error("inner line should be hidden")
+	}
+	if f, _ := b.Visible(21); f != nil {
+		t.Error("closing brace of the root should stay visible")
+	}
+	b.SetCollapsed(true)
+	if f, hidden := b.Visible(13); f != root || !hidden {
+		t.Error("collapsed root should hide everything below it")
+	}
+	b.SetCollapsed(false)
+	if b.AnyCollapsed() {
+		t.Error("expanding the block should open nested folds too")
+	}
+}
+
+func TestDetectFoldsYAML(t *testing.T) {
+	lines := []string{
+		"apiVersion: v1", // 0
+		"items:",         // 1
+		"- kind: Pod",    // 2
+		"  metadata:",    // 3
+		"    name: a",    // 4
+		"    labels:",    // 5
+		"      app: web", // 6
+		"  spec: {}",     // 7
+		"- kind: Pod",    // 8
+		"kind: List",     // 9
+	}
+	blocks := DetectFolds(lineIndices(0, len(lines)), lines)
+	if len(blocks) != 1 {
+		t.Fatalf("blocks = %d, want 1", len(blocks))
+	}
+	b := blocks[0]
+	items := b.FoldAt(1)
+	if items == nil || items.Kind != FoldArray || items.End != 8 || items.Count != 2 || items.Col != 7 {
+		t.Fatalf("items = %+v", items)
+	}
+	pod := b.FoldAt(2)
+	if pod == nil || pod.End != 7 || pod.Count != 3 || pod.Col != 2 {
+		t.Fatalf("pod = %+v", pod)
+	}
+	meta := b.FoldAt(3)
+	if meta == nil || meta.End != 6 || meta.Count != 2 || meta.Summary() != "{…} 2 keys" {
+		t.Fatalf("metadata = %+v", meta)
+	}
+	if b.FoldAt(8) != nil {
+		t.Error("an item without continuation lines has nothing to fold")
+	}
+}
+
+func TestDetectFoldsIgnoresProse(t *testing.T) {
+	lines := []string{"hello world", "[info] started", "done."}
+	if blocks := DetectFolds(lineIndices(0, len(lines)), lines); len(blocks) != 0 {
+		t.Fatalf("blocks = %+v", blocks)
+	}
+}
+
+func TestFormatterRecordsFolds(t *testing.T) {
+	f := New("")
+	f.foldThreshold = 4
+	next := int64(100)
+	inserted := int64(-1)
+	f.SetInsertFunc(func(before int64, _ []parser.Cell) {
+		// The vterm pushes the lines from before on down by one.
+		inserted = before
+		next++
+	})
+	f.NotifyPromptStart()
+
+	lines := []string{"{", `  "a": {`, `    "b": 1,`, `    "c": 2`, "  },", `  "d": 3`, "}"}
+	for _, s := range lines {
+		f.HandleLine(next, makeCells(s), true)
+		next++
+	}
+	f.NotifyPromptStart()
+
+	if inserted != 100 {
+		t.Fatalf("mode indicator inserted before %d", inserted)
+	}
+	b := f.FoldBlockAt(101)
+	if b == nil {
+		t.Fatalf("no fold block recorded: %+v", f.FoldBlocks())
+	}
+	if b.Start != 101 || b.End != 107 || len(b.Folds) != 1 || b.Folds[0].Count != 2 {
+		t.Fatalf("block = %+v", b)
+	}
+	if !b.Folds[0].Collapsed {
+		t.Error("a block over the threshold should start collapsed")
+	}
+	if inner := b.FoldAt(102); inner == nil || inner.Collapsed || inner.End != 105 || inner.Count != 2 {
+		t.Errorf("inner = %+v", inner)
+	}
+}
@@ -22,7 +22,11 @@ import (
 func init() {
 	transformer.Register("txfmt", func(cfg transformer.Config) (transformer.Transformer, error) {
 		styleName, _ := cfg["style"].(string)
-		return New(styleName), nil
+		f := New(styleName)
+		if v, ok := cfg["fold_threshold"].(float64); ok && v >= 0 {
+			f.foldThreshold = int(v)
+		}
+		return f, nil
 	})
 }
 
@@ -77,6 +81,9 @@ type Formatter struct {
 	codeLexer           string                // inferred language for modeCode (e.g. "go", "python")
 	codeLexerMethod     string                // detection method (e.g. "shebang", "classifier")
 	insertFunc          func(beforeIdx int64, cells []parser.Cell)
+	foldLines           []foldLine // JSON/YAML lines of the current block
+	folds               foldLog
+	foldThreshold       int // lines before a block starts collapsed; 0 never
 }
 
 // backlogEntry stores a line and its global index for deferred processing.
@@ -98,14 +105,16 @@ func New(styleName string) *Formatter {
 			maxSampleLines: 20,
 			requiredWins:   2,
 		},
-		style: chromaStyle(styleName),
+		style:         chromaStyle(styleName),
+		foldThreshold: defaultFoldThreshold,
 	}
 }
 
-// NotifyPromptStart records that shell integration is active.
-// Called from the OnPromptStart hook.
+// NotifyPromptStart records that shell integration is active and closes
+// the current block's folds. Called from the OnPromptStart hook.
 func (f *Formatter) NotifyPromptStart() {
 	f.hasShellIntegration = true
+	f.flushFolds()
 }
 
 // NotifyCommandStart records the current command for filename-based detection.
@@ -122,6 +131,7 @@ func (f *Formatter) NotifyResize(cols, rows int) {
 	f.chromaContext = f.chromaContext[:0]
 	f.codeLexer = ""
 	f.codeLexerMethod = ""
+	f.foldLines = f.foldLines[:0]
 }
 
 // prepareOverlay clones Cells into Overlay so colorization modifies the copy.
@@ -142,6 +152,7 @@ func (f *Formatter) HandleLine(lineIdx int64, line *parser.LogicalLine, isComman
 
 	// Reset detector on command→prompt transition
 	if f.wasCommand && !effectiveIsCommand {
+		f.flushFolds()
 		f.det.reset()
 		f.backlog = f.backlog[:0]
 		f.chromaContext = f.chromaContext[:0]
@@ -178,6 +189,9 @@ func (f *Formatter) HandleLine(lineIdx int64, line *parser.LogicalLine, isComman
 		if m == modeLog {
 			line.FixedWidth = len(line.Cells)
 		}
+		if m == modeJSON || m == modeYAML {
+			f.recordFoldLine(lineIdx, line)
+		}
 	}
 	// else: still detecting, line is in backlog, no colorization yet
 }
@@ -241,6 +255,17 @@ func (f *Formatter) recolorizeBacklog(m mode) {
 		f.insertModeIndicator(f.backlog[0].lineIdx, label)
 	}
 
+	// The indicator line pushed the backlog down by one.
+	if m == modeJSON || m == modeYAML {
+		shift := int64(0)
+		if f.insertFunc != nil {
+			shift = 1
+		}
+		for _, e := range f.backlog {
+			f.recordFoldLine(e.lineIdx+shift, e.line)
+		}
+	}
+
 	for _, line := range lines {
 		if m == modeLog {
 			line.FixedWidth = len(line.Cells)
@@ -632,7 +657,7 @@ func colorizeLogCells(line *parser.LogicalLine) {
 
 	// Build plain text and mapping from text index to cell index.
 	// We only consider printable runes (skip zero runes).
-	plain, textToCell := buildPlainTextMap(cells)
+	plain, textToCell := BuildPlainTextMap(cells)
 	if len(plain) == 0 {
 		return
 	}
@@ -640,14 +665,15 @@ func colorizeLogCells(line *parser.LogicalLine) {
 	text := string(plain)
 
 	// Apply regex-based colorization to ranges
-	applyRegexColor(cells, text, textToCell, reISOTime, colorCyan, parser.AttrDim)
-	applyRegexColor(cells, text, textToCell, reSyslog, colorCyan, parser.AttrDim)
+	ApplyRegexColor(cells, text, textToCell, reISOTime, colorCyan, parser.AttrDim)
+	ApplyRegexColor(cells, text, textToCell, reSyslog, colorCyan, parser.AttrDim)
 	applyLevelColors(cells, text, textToCell)
 	applyKVColors(cells, text, textToCell)
 }
 
-// buildPlainTextMap builds a rune slice and a mapping from rune index to cell index.
-func buildPlainTextMap(cells []parser.Cell) ([]rune, []int) {
+// BuildPlainTextMap builds a rune slice and a mapping from rune index to cell index.
+// Other transformers use it with ApplyRegexColor and ApplyRegexFunc.
+func BuildPlainTextMap(cells []parser.Cell) ([]rune, []int) {
 	plain := make([]rune, 0, len(cells))
 	textToCell := make([]int, 0, len(cells))
 	for i, c := range cells {
@@ -659,12 +685,20 @@ func buildPlainTextMap(cells []parser.Cell) ([]rune, []int) {
 	return plain, textToCell
 }
 
-// applyRegexColor applies a color and attribute to all regex matches.
-func applyRegexColor(cells []parser.Cell, text string, textToCell []int, re *regexp.Regexp, color parser.Color, attr parser.Attribute) {
+// ApplyRegexColor applies a color and attribute to all regex matches.
+// Cells the application already colored are left alone.
+func ApplyRegexColor(cells []parser.Cell, text string, textToCell []int, re *regexp.Regexp, color parser.Color, attr parser.Attribute) {
+	ApplyRegexFunc(cells, text, textToCell, re, func(c *parser.Cell) {
+		setFGAttr(c, color, attr)
+	})
+}
+
+// ApplyRegexFunc calls fn on every cell covered by a regex match. text and
+// textToCell come from BuildPlainTextMap.
+func ApplyRegexFunc(cells []parser.Cell, text string, textToCell []int, re *regexp.Regexp, fn func(*parser.Cell)) {
 	for _, loc := range re.FindAllStringIndex(text, -1) {
 		for ti := runeIndex(text, loc[0]); ti < runeIndex(text, loc[1]) && ti < len(textToCell); ti++ {
-			ci := textToCell[ti]
-			setFGAttr(&cells[ci], color, attr)
+			fn(&cells[textToCell[ti]])
 		}
 	}
 }
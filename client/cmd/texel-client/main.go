@@ -33,6 +33,7 @@ func run(args []string) error {
 	reconnect := fs.Bool("reconnect", false, "Attempt to resume previous session")
 	panicLogPath := fs.String("panic-log", "", "File to append panic stack traces")
 	clientName := fs.String("client-name", "", "Client identity slot for persistence (default: $TEXELATION_CLIENT_NAME or \"default\")")
+	compress := fs.Bool("compress", false, "Ask the server to compress large frames (for slow links such as SSH-forwarded sockets)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -59,6 +60,7 @@ func run(args []string) error {
 		Reconnect:  *reconnect,
 		PanicLog:   *panicLogPath,
 		ClientName: *clientName,
+		Compress:   *compress,
 	}
 	return runClient(opts)
 }
@@ -47,6 +47,7 @@ func main() {
 	resizeCols := flag.Int("cols", 120, "Advertised terminal columns")
 	resizeRows := flag.Int("rows", 40, "Advertised terminal rows")
 	logEvery := flag.Int("log-every", 500, "Log every N buffer deltas (0 disables periodic logging)")
+	diagnostics := flag.Bool("diagnostics", false, "Subscribe to server diagnostics samples and log each one")
 	flag.Parse()
 
 	logger := log.New(os.Stdout, "[headless] ", log.LstdFlags|log.Lmicroseconds)
@@ -59,6 +60,9 @@ func main() {
 	}
 
 	simple := client.NewSimpleClient(*socketPath)
+	if *diagnostics {
+		simple.EnableDiagnostics()
+	}
 	accept, conn, err := simple.Connect(&sessionID)
 	if err != nil {
 		logger.Fatalf("connect failed: %v", err)
@@ -195,6 +199,16 @@ func (s *headlessState) handleMessage(logger *log.Logger, hdr protocol.Header, p
 			return fmt.Errorf("encode clipboard data: %w", err)
 		}
 		return s.writeControl(protocol.MsgClipboardData, dataPayload)
+	case protocol.MsgDiagnostics:
+		d, err := protocol.DecodeDiagnostics(payload)
+		if err != nil {
+			return fmt.Errorf("decode diagnostics: %w", err)
+		}
+		logger.Printf("diag publish=%dus flush=%dus queue=%d dropped=%d coalesced=%d index=%d goroutines=%d panes=%d",
+			d.PublishMicros, d.FlushMicros, d.DiffQueueDepth, d.DroppedDiffs, d.CoalescedDiffs, d.IndexBacklog, d.Goroutines, len(d.Panes))
+		for _, p := range d.Panes {
+			logger.Printf("diag   pane %s %q render=%dus", client.FormatUUID(p.PaneID), p.Title, p.RenderMicros)
+		}
 	case protocol.MsgClipboardSet:
 		// No-op; server is pushing clipboard contents to the client.
 	case protocol.MsgDisconnectNotice:
@@ -0,0 +1,62 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: client/cmd/texel-web/main.go
+// Summary: Browser front-end: serves a local page that renders the desktop.
+// Usage: texel-web --socket /tmp/texelation.sock --listen 127.0.0.1:8088,
+//        then open the printed URL. Each browser tab is its own client session.
+// Notes: The page and renderer are embedded; no network access is needed.
+//        The printed URL carries a per-launch token; anyone holding it can
+//        type into your terminals, so it binds to loopback unless told
+//        otherwise.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	webrt "github.com/framegrace/texelation/internal/runtime/web"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("texel-web", flag.ContinueOnError)
+	socket := fs.String("socket", "/tmp/texelation.sock", "Unix socket path")
+	listen := fs.String("listen", "127.0.0.1:8088", "HTTP listen address")
+	allowNoOrigin := fs.Bool("allow-no-origin", false, "accept WebSocket clients that send no Origin header (non-browser tools)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", *listen, err)
+	}
+	if host, _, _ := net.SplitHostPort(ln.Addr().String()); !isLoopback(host) {
+		log.Printf("warning: %s is reachable from other machines over plain HTTP; the token is all that protects it", ln.Addr())
+	}
+	gateway := webrt.NewGateway(*socket)
+	gateway.SetListenAddr(ln.Addr())
+	gateway.SetAllowNoOrigin(*allowNoOrigin)
+	fmt.Printf("texel-web: serving %s on http://%s/?token=%s\n", *socket, ln.Addr(), gateway.Token())
+	return http.Serve(ln, gateway.Handler())
+}
+
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
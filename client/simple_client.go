@@ -20,7 +20,8 @@ import (
 
 // SimpleClient handles connection to the simple texel server for tree persistence
 type SimpleClient struct {
-	socketPath string
+	socketPath   string
+	capabilities uint32
 }
 
 // NewSimpleClient creates a new simple client
@@ -30,7 +31,29 @@ func NewSimpleClient(socketPath string) *SimpleClient {
 	}
 }
 
-// Connect performs the protocol handshake. If sessionID is nil or zeroed, the
+// EnableCompression asks the server to compress large frames. Worth it
+// over slow links such as SSH-forwarded sockets; on a local socket it
+// only costs CPU.
+func (c *SimpleClient) EnableCompression() {
+	c.capabilities |= protocol.CapCompression
+}
+
+// EnableBatching tells the server this client decodes
+// MsgBufferDeltaBatch and acks every delta frame, which lets the server
+// batch multi-pane updates and pace them by ack round trip.
+func (c *SimpleClient) EnableBatching() {
+	c.capabilities |= protocol.CapBatchedDeltas
+}
+
+// EnableDiagnostics subscribes the connection to the server's periodic
+// MsgDiagnostics health samples.
+func (c *SimpleClient) EnableDiagnostics() {
+	c.capabilities |= protocol.CapDiagnostics
+}
+
+// Connect performs the protocol handshake. When compression was agreed,
+// the returned conn is a *protocol.DecompressingConn; read it with
+// protocol.ReadMessage only. If sessionID is nil or zeroed, the
 // server will allocate a fresh session.
 func (c *SimpleClient) Connect(sessionID *[16]byte) (*protocol.ConnectAccept, net.Conn, error) {
 	conn, err := net.DialTimeout("unix", c.socketPath, 5*time.Second)
@@ -38,7 +61,7 @@ func (c *SimpleClient) Connect(sessionID *[16]byte) (*protocol.ConnectAccept, ne
 		return nil, nil, fmt.Errorf("dial failed: %w", err)
 	}
 
-	helloPayload, err := protocol.EncodeHello(protocol.Hello{ClientName: "simple-client"})
+	helloPayload, err := protocol.EncodeHello(protocol.Hello{ClientName: "simple-client", Capabilities: c.capabilities})
 	if err != nil {
 		conn.Close()
 		return nil, nil, err
@@ -57,6 +80,14 @@ func (c *SimpleClient) Connect(sessionID *[16]byte) (*protocol.ConnectAccept, ne
 		conn.Close()
 		return nil, nil, fmt.Errorf("unexpected message %v", hdr.Type)
 	}
+	welcome, err := protocol.DecodeWelcome(payload)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if welcome.Capabilities&c.capabilities&protocol.CapCompression != 0 {
+		conn = protocol.NewDecompressingConn(conn)
+	}
 
 	var req protocol.ConnectRequest
 	if sessionID != nil {
@@ -11,6 +11,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	texelcore "github.com/framegrace/texelui/core"
@@ -28,8 +29,13 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 
+	_ "github.com/framegrace/texelation/apps/cliphist"
 	_ "github.com/framegrace/texelation/apps/configeditor"
 	_ "github.com/framegrace/texelation/apps/help"
+	_ "github.com/framegrace/texelation/apps/monitor"
+	_ "github.com/framegrace/texelation/apps/palette"
+	_ "github.com/framegrace/texelation/apps/reopen"
+	_ "github.com/framegrace/texelation/apps/snapshots"
 	_ "github.com/framegrace/texelation/apps/texeluidemo"
 	"github.com/framegrace/texelation/apps/launcher"
 	"github.com/framegrace/texelation/apps/statusbar"
@@ -38,6 +44,7 @@ import (
 	"github.com/framegrace/texelation/config"
 	"github.com/framegrace/texelation/internal/keybind"
 	"github.com/framegrace/texelation/internal/runtime/server"
+	"github.com/framegrace/texelation/internal/screenlock"
 	runtimeadapter "github.com/framegrace/texelation/internal/runtimeadapter"
 	"github.com/framegrace/texelation/registry"
 	"github.com/framegrace/texelation/texel"
@@ -57,8 +64,22 @@ func main() {
 	pprofAddr := flag.String("pprof-http", "", "Enable live pprof at address (e.g. localhost:6060)")
 	verboseLogs := flag.Bool("verbose-logs", false, "Enable verbose server logging")
 	defaultApp := flag.String("default-app", "", "Default app for new panes (launcher, texelterm, help) - overrides config file")
+	handoffSocket := flag.String("handoff-socket", "", "Adopt running terminals from an upgrading server via this socket (internal)")
 	flag.Parse()
 
+	// During `texelation --upgrade` the old server starts us with
+	// --handoff-socket. Take over its PTYs before anything else: it keeps
+	// the PID lock until we ack, so the lock is acquired afterwards.
+	if *handoffSocket != "" {
+		n, err := receiveHandoff(*handoffSocket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "texel-server: handoff failed: %v\n", err)
+			os.Exit(1)
+		}
+		log.Printf("[UPGRADE] Received %d terminal(s) from previous server", n)
+		*fromScratch = false
+	}
+
 	// Acquire exclusive flock on the PID file before any other setup.
 	// This is the canonical "a server is alive" signal used by the
 	// supervisor in cmd/texelation/lifecycle. Holding the lock for the
@@ -67,9 +88,20 @@ func main() {
 	// The OS releases the lock automatically on process exit, even on
 	// crash, so stale PID files are self-healing.
 	var pidLock lifecyclepkg.PIDLock
+	// Set once our terminals belong to a replacement server: the PID file
+	// is then about to be re-locked by it and must not be removed.
+	handedOff := false
 	if *pidFilePath != "" {
 		pf := lifecyclepkg.NewPIDFile(*pidFilePath)
-		lock, err := pf.AcquireExclusiveLock(os.Getpid())
+		var (
+			lock lifecyclepkg.PIDLock
+			err  error
+		)
+		if *handoffSocket != "" {
+			lock, err = acquirePIDLockAfterHandoff(pf)
+		} else {
+			lock, err = pf.AcquireExclusiveLock(os.Getpid())
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "texel-server: cannot acquire PID lock at %s: %v\n", *pidFilePath, err)
 			os.Exit(1)
@@ -77,6 +109,9 @@ func main() {
 		pidLock = lock
 		defer func() {
 			_ = pidLock.Close()
+			if handedOff {
+				return
+			}
 			// Best-effort remove; OS already released the lock so a
 			// new server can start immediately after us regardless.
 			_ = os.Remove(*pidFilePath)
@@ -169,6 +204,14 @@ func main() {
 	serverKB = loadServerKeybindings()
 	desktop.SetKeybindings(serverKB)
 
+	// Screen lock: verify against ~/.texelation/lock.hash, or PAM if enabled.
+	if lockPath, err := screenlock.DefaultPath(); err == nil {
+		usePAM := cfg != nil && cfg.GetBool("screensaver", "lock_pam", false)
+		desktop.SetLockVerifier(screenlock.NewVerifier(lockPath, usePAM))
+	} else {
+		log.Printf("screen lock disabled: %v", err)
+	}
+
 	// Register snapshot factory for launcher
 	desktop.RegisterSnapshotFactory("launcher", func(title string, config map[string]interface{}) texelcore.App {
 		return launcher.New(desktop.Registry())
@@ -247,6 +290,8 @@ func main() {
 		}
 		if snapPath != "" {
 			store := server.NewSnapshotStore(snapPath)
+			store.SetGenerations(cfg.GetInt("snapshots", "generations", server.DefaultSnapshotGenerations))
+			desktop.SetSnapshotHistory(store)
 			srv.SetSnapshotStore(store, 5*time.Second)
 			log.Printf("Session persistence enabled: %s", snapPath)
 			// Plan D2: cross-restart session/viewport persistence.
@@ -277,6 +322,10 @@ func main() {
 		return publisher
 	})
 
+	if *handoffSocket != "" {
+		releaseUnclaimedPTYs(desktop)
+	}
+
 	go func() {
 		if err := srv.Start(); err != nil {
 			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
@@ -288,10 +337,24 @@ func main() {
 	fmt.Println("Use the integration test client or proto harness to connect and send key events.")
 
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR2)
 
 	for {
 		sig := <-sigCh
+		if sig == syscall.SIGUSR2 {
+			log.Println("Received SIGUSR2, upgrading in place...")
+			err := performUpgrade(desktop, srv, *socketPath)
+			if errors.Is(err, errUpgradeAborted) {
+				log.Printf("Upgrade failed, continuing with current server: %v", err)
+				continue
+			}
+			if err != nil {
+				log.Printf("Upgrade handoff failed after shutdown began: %v", err)
+			} else {
+				handedOff = true
+			}
+			break
+		}
 		if sig == syscall.SIGHUP {
 			log.Println("Received SIGHUP, reloading configuration...")
 			if err := config.Reload(); err != nil {
@@ -331,28 +394,21 @@ func main() {
 
 // loadServerKeybindings loads the keybinding registry from the user's config.
 func loadServerKeybindings() *keybind.Registry {
-	preset := "auto"
-	var extraPreset string
-	var overrides map[string][]string
+	cfg := keybind.Config{Preset: "auto"}
 
 	home, err := os.UserHomeDir()
 	if err == nil {
 		data, err := os.ReadFile(filepath.Join(home, ".config", "texelation", "keybindings.json"))
 		if err == nil {
-			var cfg struct {
-				Preset      string              `json:"preset"`
-				ExtraPreset string              `json:"extraPreset"`
-				Actions     map[string][]string `json:"actions"`
-			}
-			if json.Unmarshal(data, &cfg) == nil {
-				if cfg.Preset != "" {
-					preset = cfg.Preset
+			var fileCfg keybind.Config
+			if json.Unmarshal(data, &fileCfg) == nil {
+				cfg = fileCfg
+				if cfg.Preset == "" {
+					cfg.Preset = "auto"
 				}
-				extraPreset = cfg.ExtraPreset
-				overrides = cfg.Actions
 			}
 		}
 	}
 
-	return keybind.NewRegistry(preset, extraPreset, overrides)
+	return keybind.NewRegistryFromConfig(cfg)
 }
@@ -0,0 +1,192 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: cmd/texel-server/upgrade.go
+// Summary: Graceful in-place upgrade with live PTY handoff.
+// Usage: SIGUSR2 (sent by `texelation --upgrade`) makes the running
+//   server exec its on-disk binary and hand every terminal's PTY master
+//   over a Unix socket; the new server is started with --handoff-socket.
+// Notes: Until the new server connects the upgrade can be abandoned
+//   without side effects. After the listener is stopped there is no way
+//   back: the old server exits whether or not the transfer succeeded.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm"
+	lifecyclepkg "github.com/framegrace/texelation/cmd/texelation/lifecycle"
+	"github.com/framegrace/texelation/internal/handoff"
+	"github.com/framegrace/texelation/internal/runtime/server"
+	"github.com/framegrace/texelation/texel"
+)
+
+const (
+	// handoffAcceptTimeout bounds how long the old server waits for its
+	// replacement to start and dial back.
+	handoffAcceptTimeout = 15 * time.Second
+	// handoffTransferTimeout bounds the descriptor transfer and ack.
+	handoffTransferTimeout = 10 * time.Second
+	// handoffLockTimeout bounds how long the new server waits for the
+	// old one to release the PID lock after acking.
+	handoffLockTimeout = 60 * time.Second
+)
+
+// errUpgradeAborted marks failures that happened before the point of no
+// return; the old server keeps running normally.
+var errUpgradeAborted = errors.New("upgrade aborted")
+
+// performUpgrade starts the replacement server and hands over every
+// terminal. A nil return means the caller should exit without removing
+// the PID file, which now belongs to the new server.
+func performUpgrade(desktop *texel.DesktopEngine, srv *server.Server, socketPath string) error {
+	hsPath := handoff.SocketPath(socketPath)
+	_ = os.Remove(hsPath)
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: hsPath, Net: "unix"})
+	if err != nil {
+		return fmt.Errorf("%w: listen on %s: %v", errUpgradeAborted, hsPath, err)
+	}
+	defer os.Remove(hsPath)
+	defer l.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("%w: resolve executable: %v", errUpgradeAborted, err)
+	}
+	cmd := exec.Command(exe, handoffArgs(os.Args[1:], hsPath)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w: start %s: %v", errUpgradeAborted, exe, err)
+	}
+	log.Printf("[UPGRADE] Started replacement server %s (PID %d)", exe, cmd.Process.Pid)
+
+	abort := func(err error) error {
+		_ = cmd.Process.Kill()
+		_, _ = cmd.Process.Wait()
+		return fmt.Errorf("%w: %v", errUpgradeAborted, err)
+	}
+
+	_ = l.SetDeadline(time.Now().Add(handoffAcceptTimeout))
+	conn, err := l.AcceptUnix()
+	if err != nil {
+		return abort(fmt.Errorf("replacement did not connect: %v", err))
+	}
+	defer conn.Close()
+
+	// Point of no return. Stopping the server disconnects clients (they
+	// reconnect to the new server) and persists the final snapshot.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	_ = srv.Stop(ctx)
+	cancel()
+
+	panes := detachTerminals(desktop)
+	defer func() {
+		for _, p := range panes {
+			_ = p.PTY.Close()
+		}
+	}()
+
+	_ = conn.SetDeadline(time.Now().Add(handoffTransferTimeout))
+	if err := handoff.Send(conn, panes); err != nil {
+		return fmt.Errorf("hand over %d terminal(s): %w", len(panes), err)
+	}
+	_ = cmd.Process.Release()
+	log.Printf("[UPGRADE] Handed %d terminal(s) to PID %d", len(panes), cmd.Process.Pid)
+	return nil
+}
+
+// detachTerminals detaches every texelterm pane and collects its handle.
+// Panes running other apps are left alone; the new server recreates them
+// from the snapshot.
+func detachTerminals(desktop *texel.DesktopEngine) []handoff.Pane {
+	var panes []handoff.Pane
+	for id, app := range desktop.PaneApps() {
+		tt, ok := app.(*texelterm.TexelTerm)
+		if !ok {
+			continue
+		}
+		p, err := tt.DetachPTY()
+		if err != nil {
+			log.Printf("[UPGRADE] Pane %x not handed over: %v", id, err)
+			continue
+		}
+		panes = append(panes, p)
+	}
+	return panes
+}
+
+// handoffArgs rebuilds the command line for the replacement server: the
+// original flags minus any previous handoff or from-scratch flag, plus
+// the handoff socket.
+func handoffArgs(args []string, hsPath string) []string {
+	out := make([]string, 0, len(args)+2)
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name = name[:eq]
+		} else if name == "handoff-socket" {
+			i++ // skip the separate value
+			continue
+		}
+		if name == "handoff-socket" || name == "from-scratch" {
+			continue
+		}
+		out = append(out, args[i])
+	}
+	return append(out, "--handoff-socket", hsPath)
+}
+
+// receiveHandoff connects to the old server, takes over its PTYs and
+// registers them for adoption by the restored terminals.
+func receiveHandoff(hsPath string) (int, error) {
+	raddr := &net.UnixAddr{Name: hsPath, Net: "unix"}
+	conn, err := net.DialUnix("unix", nil, raddr)
+	if err != nil {
+		return 0, fmt.Errorf("dial %s: %w", hsPath, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(handoffAcceptTimeout + handoffTransferTimeout))
+
+	panes, err := handoff.Receive(conn)
+	if err != nil {
+		return 0, err
+	}
+	texelterm.AdoptInheritedPTYs(panes)
+	if err := handoff.Ack(conn); err != nil {
+		return 0, fmt.Errorf("ack: %w", err)
+	}
+	return len(panes), nil
+}
+
+// acquirePIDLockAfterHandoff waits for the old server to release the PID
+// lock. It holds the lock until its deferred cleanup runs on exit.
+func acquirePIDLockAfterHandoff(pf lifecyclepkg.PIDFile) (lifecyclepkg.PIDLock, error) {
+	if err := pf.WaitForUnlock(handoffLockTimeout); err != nil {
+		return nil, err
+	}
+	return pf.AcquireExclusiveLock(os.Getpid())
+}
+
+// releaseUnclaimedPTYs hangs up inherited shells whose pane did not
+// survive the snapshot restore.
+func releaseUnclaimedPTYs(desktop *texel.DesktopEngine) {
+	keep := make(map[string]bool)
+	for id := range desktop.PaneApps() {
+		keep[fmt.Sprintf("%x", id)] = true
+	}
+	if n := texelterm.ReleaseInheritedPTYs(keep); n > 0 {
+		log.Printf("[UPGRADE] Released %d inherited PTY(s) with no pane", n)
+	}
+}
@@ -66,6 +66,11 @@ type DaemonManager interface {
 	// Restart performs stop + start atomically
 	Restart(ctx context.Context, opts ServerOptions) error
 
+	// Upgrade asks the running server to exec its on-disk binary and hand
+	// its live terminals over (SIGUSR2), then waits for the replacement to
+	// take the PID lock. Returns the new server's PID.
+	Upgrade(ctx context.Context) (int, error)
+
 	// GetPID returns the current PID if running, or 0
 	GetPID() int
 }
@@ -292,3 +297,39 @@ func (d *standardDaemonManager) Restart(ctx context.Context, opts ServerOptions)
 
 	return d.Start(ctx, opts)
 }
+
+func (d *standardDaemonManager) Upgrade(ctx context.Context) (int, error) {
+	oldPID, err := d.pidFile.Read()
+	if err != nil {
+		return 0, fmt.Errorf("server not running: %w", err)
+	}
+	process, err := os.FindProcess(oldPID)
+	if err != nil {
+		return 0, fmt.Errorf("find server process: %w", err)
+	}
+	if err := process.Signal(syscall.SIGUSR2); err != nil {
+		return 0, fmt.Errorf("send SIGUSR2: %w", err)
+	}
+
+	// The old server keeps the lock until the handoff is acked, then the
+	// replacement waits for it to exit and rewrites the PID file under a
+	// fresh lock. A failed upgrade leaves the old PID locked; that is
+	// indistinguishable from "still in progress" until the deadline.
+	const upgradeTimeout = 90 * time.Second
+	const pollInterval = 100 * time.Millisecond
+	deadline := time.Now().Add(upgradeTimeout)
+	for time.Now().Before(deadline) {
+		if pid, err := d.pidFile.Read(); err == nil && pid != oldPID && d.pidFile.IsLocked() {
+			return pid, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+	if err := process.Signal(syscall.Signal(0)); err == nil {
+		return 0, fmt.Errorf("server PID %d is still running; upgrade failed (see server log)", oldPID)
+	}
+	return 0, fmt.Errorf("no replacement server took over within %s", upgradeTimeout)
+}
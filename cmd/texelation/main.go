@@ -21,8 +21,11 @@ import (
 	"syscall"
 	"time"
 
+	"golang.org/x/term"
+
 	"github.com/framegrace/texelation/cmd/texelation/lifecycle"
 	clientrt "github.com/framegrace/texelation/internal/runtime/client"
+	"github.com/framegrace/texelation/internal/screenlock"
 )
 
 func main() {
@@ -42,6 +45,10 @@ func run() error {
 	stopServer := fs.Bool("stop", false, "Stop running server daemon")
 	resetState := fs.Bool("reset-state", false, "Delete all state and start fresh (requires confirmation)")
 	showStatus := fs.Bool("status", false, "Show server status and exit")
+	upgrade := fs.Bool("upgrade", false, "Restart the server on its current binary, keeping running terminals alive")
+	setLockPassword := fs.Bool("set-lock-password", false, "Set the password that unlocks the screen lock")
+	listSnapshots := fs.Bool("list-snapshots", false, "List saved session layouts and exit")
+	restoreSnapshot := fs.String("restore-snapshot", "", "Restore the saved session layout with this ID (server must be stopped)")
 
 	// Shared flags
 	socketPath := fs.String("socket", "/tmp/texelation.sock", "Unix socket path")
@@ -60,6 +67,7 @@ func run() error {
 	reconnect := fs.Bool("reconnect", false, "Attempt to resume previous session")
 	panicLog := fs.String("panic-log", "", "File to append panic stack traces")
 	clientName := fs.String("client-name", "", "Client identity slot for persistence (default: $TEXELATION_CLIENT_NAME or \"default\")")
+	compress := fs.Bool("compress", false, "Ask the server to compress large frames (for slow links such as SSH-forwarded sockets)")
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		if err == flag.ErrHelp {
@@ -126,6 +134,18 @@ func run() error {
 	case *stopServer:
 		return handleStopServer(ctx, paths, *socketPath)
 
+	case *upgrade:
+		return handleUpgrade(ctx, paths, *socketPath)
+
+	case *setLockPassword:
+		return handleSetLockPassword(paths)
+
+	case *listSnapshots:
+		return handleListSnapshots(*snapshotPath)
+
+	case *restoreSnapshot != "":
+		return handleRestoreSnapshot(ctx, paths, *socketPath, *snapshotPath, *restoreSnapshot)
+
 	case *serverOnly:
 		return handleServerOnly(lifecycle.ServerOptions{
 			SocketPath:   *socketPath,
@@ -143,6 +163,7 @@ func run() error {
 			Reconnect:  *reconnect,
 			PanicLog:   *panicLog,
 			ClientName: *clientName,
+			Compress:   *compress,
 		})
 
 	default:
@@ -159,6 +180,7 @@ func run() error {
 			Reconnect:  *reconnect,
 			PanicLog:   *panicLog,
 			ClientName: *clientName,
+			Compress:   *compress,
 		})
 	}
 }
@@ -305,6 +327,67 @@ func handleStopServer(ctx context.Context, paths *Paths, socketPath string) erro
 	return nil
 }
 
+func handleUpgrade(ctx context.Context, paths *Paths, socketPath string) error {
+	health := lifecycle.NewSocketHealthChecker(2 * time.Second)
+	pidFile := lifecycle.NewPIDFile(paths.PIDPath)
+	daemon := lifecycle.NewDaemonManager(pidFile, socketPath, health)
+
+	state, err := daemon.GetState(ctx)
+	if err != nil {
+		return fmt.Errorf("get server state: %w", err)
+	}
+	if state != lifecycle.StateRunning {
+		return fmt.Errorf("server is %s; nothing to upgrade", state)
+	}
+
+	oldPID := daemon.GetPID()
+	fmt.Printf("Upgrading server (PID %d)...\n", oldPID)
+
+	newPID, err := daemon.Upgrade(ctx)
+	if err != nil {
+		return fmt.Errorf("upgrade server: %w", err)
+	}
+
+	fmt.Printf("Server upgraded (PID %d -> %d)\n", oldPID, newPID)
+	fmt.Println("Attached clients were disconnected; run texelation again to resume.")
+	return nil
+}
+
+func handleSetLockPassword(paths *Paths) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("--set-lock-password needs an interactive terminal")
+	}
+	fmt.Print("New lock password: ")
+	first, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("read password: %w", err)
+	}
+	if len(first) == 0 {
+		return fmt.Errorf("password must not be empty")
+	}
+	fmt.Print("Repeat password: ")
+	second, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("read password: %w", err)
+	}
+	if string(first) != string(second) {
+		return fmt.Errorf("passwords do not match")
+	}
+
+	stored, err := screenlock.HashPassword(string(first))
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(paths.ConfigDir, "lock.hash")
+	if err := screenlock.Save(path, stored); err != nil {
+		return fmt.Errorf("save lock password: %w", err)
+	}
+	fmt.Printf("Lock password saved to %s\n", path)
+	return nil
+}
+
 func handleResetState(ctx context.Context, paths *Paths, socketPath string) error {
 	// Enumerate what will be deleted
 	fmt.Println("WARNING: This will delete ALL saved state:")
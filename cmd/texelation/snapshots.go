@@ -0,0 +1,61 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: cmd/texelation/snapshots.go
+// Summary: --list-snapshots and --restore-snapshot, which list and restore
+// saved generations of the session layout.
+// Usage: `texelation --list-snapshots`, then
+//   `texelation --restore-snapshot ID` with the server stopped.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/cmd/texelation/lifecycle"
+	"github.com/framegrace/texelation/internal/runtime/server"
+)
+
+func handleListSnapshots(snapshotPath string) error {
+	generations, err := server.NewSnapshotStore(snapshotPath).Generations()
+	if err != nil {
+		return err
+	}
+	if len(generations) == 0 {
+		fmt.Println("No saved snapshots")
+		return nil
+	}
+	fmt.Println("Saved session layouts (newest first); restore one with --restore-snapshot ID:")
+	for _, g := range generations {
+		fmt.Printf("\n%s  %s\n", g.ID, g.SavedAt.Local().Format("Mon Jan 2 15:04:05"))
+		for _, ws := range g.Workspaces {
+			name := ws.Name
+			if name == "" {
+				name = fmt.Sprintf("workspace %d", ws.ID)
+			}
+			fmt.Printf("  %s: %s\n", name, strings.Join(ws.PaneTitles, ", "))
+		}
+	}
+	return nil
+}
+
+func handleRestoreSnapshot(ctx context.Context, paths *Paths, socketPath, snapshotPath, id string) error {
+	health := lifecycle.NewSocketHealthChecker(2 * time.Second)
+	pidFile := lifecycle.NewPIDFile(paths.PIDPath)
+	daemon := lifecycle.NewDaemonManager(pidFile, socketPath, health)
+	if state, _ := daemon.GetState(ctx); state != lifecycle.StateStopped {
+		return errors.New("the server is running; stop it with --stop first, or restore from the session with Ctrl+A S")
+	}
+
+	store := server.NewSnapshotStore(snapshotPath)
+	if err := store.Restore(id); err != nil {
+		return err
+	}
+	fmt.Printf("Restored snapshot %s; the next start of texelation brings it back.\n", id)
+	fmt.Println("The layout it replaced is kept and listed by --list-snapshots.")
+	return nil
+}
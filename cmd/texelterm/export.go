@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm"
+	"github.com/framegrace/texelation/apps/texelterm/export"
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/config"
+)
+
+// handleExport implements `texelterm export`, which writes part of a
+// pane's persisted scrollback as HTML, ANSI text or plain text. It only
+// reads the history files, so it is safe while the pane is running.
+func handleExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	pane := fs.String("pane", "", "ID (or unique prefix) of the pane to export; omit to list panes")
+	from := fs.String("from", "", "first line: line:N, time:T or command:N (default: start of history)")
+	to := fs.String("to", "", "last line: line:N, time:T or command:N (default: end of history)")
+	command := fs.String("command", "", "export a single command block, e.g. -1 for the last one")
+	formatName := fs.String("format", "text", "output format: html, ansi or text")
+	out := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texelterm export --pane ID [--from B] [--to B | --command N] [--format F] [-o FILE]\n\n")
+		fmt.Fprintf(fs.Output(), "Negative line and command numbers count back from the end; T is RFC 3339 or HH:MM[:SS] today.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	scrollbackDir := texelterm.ScrollbackDir(config.App("texelterm"))
+	if *pane == "" {
+		return listPanes(scrollbackDir)
+	}
+	paneID, err := findPane(scrollbackDir, *pane)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	fromBound, err := export.ParseBound(*from, now)
+	if err != nil {
+		return err
+	}
+	toBound, err := export.ParseBound(*to, now)
+	if err != nil {
+		return err
+	}
+	if *command != "" {
+		if *from != "" || *to != "" {
+			return errors.New("--command cannot be combined with --from or --to")
+		}
+		if fromBound, err = export.ParseBound("command:"+*command, now); err != nil {
+			return err
+		}
+		toBound = fromBound
+	}
+
+	diskPath := filepath.Join(scrollbackDir, paneID+".hist3")
+	history, err := parser.OpenHistoryReader(diskPath, paneID)
+	if err != nil {
+		return err
+	}
+	cmds, err := parser.ReadCommandRecords(parser.CommandLogPath(diskPath, paneID))
+	if err != nil {
+		return err
+	}
+	start, end, err := export.Resolve(history, cmds, fromBound, toBound)
+	if err != nil {
+		return err
+	}
+	lines, err := history.ReadLineRange(start, end)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	palette := texelterm.DefaultPalette()
+	if err := export.Write(w, lines, format, &palette); err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "Exported lines %d-%d to %s\n", start, end-1, *out)
+	}
+	return nil
+}
+
+// paneIDs returns the IDs of the panes with persisted history, newest
+// first.
+func paneIDs(scrollbackDir string) ([]string, map[string]time.Time, error) {
+	entries, err := os.ReadDir(scrollbackDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	var ids []string
+	modified := make(map[string]time.Time)
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".hist3")
+		if !ok || !e.IsDir() {
+			continue
+		}
+		ids = append(ids, id)
+		if info, err := e.Info(); err == nil {
+			modified[id] = info.ModTime()
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return modified[ids[i]].After(modified[ids[j]]) })
+	return ids, modified, nil
+}
+
+// findPane resolves a pane ID or unique ID prefix.
+func findPane(scrollbackDir, prefix string) (string, error) {
+	ids, _, err := paneIDs(scrollbackDir)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, id := range ids {
+		if id == prefix {
+			return id, nil
+		}
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no history for pane %q in %s", prefix, scrollbackDir)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("pane prefix %q is ambiguous (%d panes match)", prefix, len(matches))
+}
+
+// listPanes prints the panes that can be exported.
+func listPanes(scrollbackDir string) error {
+	ids, modified, err := paneIDs(scrollbackDir)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Printf("No pane history found in %s\n", scrollbackDir)
+		return nil
+	}
+	fmt.Println("Panes with history (newest first); pass one with --pane:")
+	for _, id := range ids {
+		fmt.Printf("  %s  %s\n", id, modified[id].Format("2006-01-02 15:04"))
+	}
+	return nil
+}
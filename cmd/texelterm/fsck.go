@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/framegrace/texelation/apps/texelterm"
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/config"
+)
+
+// handleFsck implements `texelterm fsck`, which checks the persisted
+// history of one or all panes and, with --repair, fixes what it can. It
+// also lists pane histories that no saved session refers to any more.
+func handleFsck(args []string) error {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	pane := fs.String("pane", "", "ID (or unique prefix) of the pane to check")
+	all := fs.Bool("all", false, "check every pane")
+	repair := fs.Bool("repair", false, "fix what can be fixed; refused while a pane is open")
+	snapshot := fs.String("snapshot", "", "session snapshot used to find orphaned panes (default: ~/.texelation/snapshot.json)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: texelterm fsck --pane ID|--all [--repair]\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if (*pane == "") == !*all {
+		fs.Usage()
+		return errors.New("pass either --pane or --all")
+	}
+	scrollbackDir := texelterm.ScrollbackDir(config.App("texelterm"))
+	var ids []string
+	if *all {
+		var err error
+		if ids, _, err = paneIDs(scrollbackDir); err != nil {
+			return err
+		}
+	} else {
+		id, err := findPane(scrollbackDir, *pane)
+		if err != nil {
+			return err
+		}
+		ids = []string{id}
+	}
+	healthy, skipped := true, 0
+	for _, id := range ids {
+		report, err := parser.CheckHistory(filepath.Join(scrollbackDir, id+".hist3"), id, parser.FsckOptions{
+			Repair:    *repair,
+			IndexPath: filepath.Join(scrollbackDir, id+".index.db"),
+		})
+		if errors.Is(err, parser.ErrHistoryInUse) {
+			fmt.Printf("%s: skipped, the pane is open\n", id)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("pane %s: %w", id, err)
+		}
+		printFsckReport(report)
+		healthy = healthy && report.Healthy()
+	}
+	if *all {
+		reportOrphans(scrollbackDir, ids, *snapshot)
+	}
+	if skipped > 0 {
+		return fmt.Errorf("%d open panes were not repaired; close them or stop texelation first", skipped)
+	}
+	if !healthy {
+		if *repair {
+			return errors.New("some problems could not be repaired")
+		}
+		return errors.New("problems found; run again with --repair to fix them")
+	}
+	return nil
+}
+
+func printFsckReport(r *parser.FsckReport) {
+	status := "ok"
+	if !r.Healthy() {
+		status = "DAMAGED"
+	}
+	fmt.Printf("%s: %s (%d pages, %d lines, %d WAL entries, %d indexed)\n",
+		r.TerminalID, status, r.Pages, r.Lines, r.WALEntries, r.IndexedLines)
+	for _, is := range r.Issues {
+		repaired := ""
+		if is.Repaired {
+			repaired = " [repaired]"
+		}
+		fmt.Printf("  %s: %s: %s%s\n", is.Severity, is.Where, is.Message, repaired)
+	}
+}
+
+// reportOrphans lists the pane histories that neither the session
+// snapshot nor its saved generations refer to. They are left alone: the
+// user may still want them.
+func reportOrphans(scrollbackDir string, ids []string, snapshotPath string) {
+	if snapshotPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		snapshotPath = filepath.Join(home, ".texelation", "snapshot.json")
+	}
+	known := map[string]bool{texelterm.StandalonePaneID: true}
+	generations, _ := filepath.Glob(filepath.Join(filepath.Dir(snapshotPath), "snapshots", "*.json"))
+	for _, path := range append([]string{snapshotPath}, generations...) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Cannot check for orphaned panes: %v\n", err)
+			return
+		}
+		var snap struct {
+			Panes []struct {
+				ID string `json:"id"`
+			} `json:"panes"`
+		}
+		if err := json.Unmarshal(data, &snap); err != nil {
+			fmt.Printf("Cannot check for orphaned panes: %s: %v\n", path, err)
+			return
+		}
+		for _, p := range snap.Panes {
+			known[p.ID] = true
+		}
+	}
+	var orphans []string
+	for _, id := range ids {
+		if !known[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) == 0 {
+		return
+	}
+	fmt.Printf("%d pane histories are not in any saved session layout (closed panes); remove them by hand if unneeded:\n", len(orphans))
+	for _, id := range orphans {
+		fmt.Printf("  %s\n", filepath.Join(scrollbackDir, id+".hist3"))
+	}
+}
@@ -20,6 +20,22 @@ var resetHistory = flag.Bool("reset-history", false, "Remove all scrollback hist
 var reindexSearch = flag.Bool("reindex", false, "Rebuild the search index from existing history")
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		// The texelterm package discards the log, so report on stderr.
+		if err := handleExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "texelterm export: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "fsck" {
+		if err := handleFsck(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "texelterm fsck: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	flag.Parse()
 
 	if *resetHistory {
@@ -90,6 +90,26 @@ func DefaultBindings() []BindingSpec {
 				"duration_ms": 160,
 			},
 		},
+		{
+			Event:  TriggerPaneActivity,
+			Target: TargetPane,
+			Effect: "fadeTint",
+			Config: EffectConfig{
+				"color":       "#50fa7b",
+				"intensity":   0.25,
+				"duration_ms": 1200,
+			},
+		},
+		{
+			Event:  TriggerPaneSilence,
+			Target: TargetPane,
+			Effect: "fadeTint",
+			Config: EffectConfig{
+				"color":       "#ffb86c",
+				"intensity":   0.25,
+				"duration_ms": 1200,
+			},
+		},
 		{
 			Event:  TriggerWorkspaceControl,
 			Target: TargetWorkspace,
@@ -153,6 +173,10 @@ func ParseTrigger(name string) (EffectTriggerType, bool) {
 		return TriggerClockTick, true
 	case "session.state":
 		return TriggerSessionState, true
+	case "pane.activity":
+		return TriggerPaneActivity, true
+	case "pane.silence":
+		return TriggerPaneSilence, true
 	default:
 		return 0, false
 	}
@@ -180,6 +204,8 @@ func TriggerNames() []string {
 		"clipboard.changed",
 		"clock.tick",
 		"session.state",
+		"pane.activity",
+		"pane.silence",
 	}
 }
 
@@ -78,4 +78,8 @@ const (
 	TriggerClipboardChanged
 	TriggerClockTick
 	TriggerSessionState
+
+	// Monitor alerts; Active reports whether the alert is raised.
+	TriggerPaneActivity
+	TriggerPaneSilence
 )
@@ -115,6 +115,14 @@ func (e *fadeTintEffect) HandleTrigger(trigger EffectTrigger) {
 			target = e.intensity
 		}
 		e.Animate(trigger.PaneID, target, trigger.Timestamp)
+	case TriggerPaneActivity, TriggerPaneSilence:
+		// Raised while the pane is out of view; clearing it when the pane
+		// is shown fades the tint out, so the pane flashes on arrival.
+		target := float32(0)
+		if trigger.Active {
+			target = e.intensity
+		}
+		e.Animate(trigger.PaneID, target, trigger.Timestamp)
 	case TriggerWorkspaceControl:
 		e.mu.Lock()
 		e.wsActive = trigger.Active
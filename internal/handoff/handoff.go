@@ -0,0 +1,239 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// Package handoff transfers live PTY masters from a running texel-server
+// to its freshly exec'd replacement during `texelation --upgrade`.
+//
+// The wire format is intentionally tiny: the old server sends one
+// message per pane over a Unix stream socket. Each message carries a
+// length-prefixed JSON PaneHandle in the data bytes and exactly one file
+// descriptor (the PTY master) as SCM_RIGHTS ancillary data. A final
+// message with an empty PaneID and no descriptor terminates the stream,
+// and the receiver answers with a single ack byte once every descriptor
+// has been taken over. Until the ack arrives the old server still owns
+// the children and may abort the upgrade.
+
+package handoff
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Version is the manifest format version. Bump on incompatible changes;
+// a receiver refuses handles from a different version so a downgrade
+// never misinterprets fields.
+const Version = 1
+
+// maxHandleSize bounds a single encoded PaneHandle. Mode sequences are a
+// few dozen bytes, so anything near this limit is a corrupt stream.
+const maxHandleSize = 64 * 1024
+
+// ackByte is written by the receiver once all handles are adopted.
+const ackByte = 'K'
+
+// PaneHandle describes one PTY being handed over. The PTY master itself
+// travels out-of-band as SCM_RIGHTS and is attached by Receive.
+type PaneHandle struct {
+	Version int    `json:"version"`
+	PaneID  string `json:"paneID"`
+	// PID is the shell (session leader) running on the PTY slave. It is
+	// no longer a child of the receiving process, so exit is detected
+	// by polling rather than wait(2).
+	PID int `json:"pid"`
+	// Modes is a replayable escape sequence that restores terminal modes
+	// (alt screen, cursor keys, bracketed paste...) the VTerm state in
+	// the WAL does not cover. Fed through the new parser before reading.
+	Modes []byte `json:"modes,omitempty"`
+}
+
+// Pane pairs a handle with its PTY master file.
+type Pane struct {
+	PaneHandle
+	PTY *os.File
+}
+
+// SocketPath returns the handoff socket path used alongside the main
+// server socket.
+func SocketPath(serverSocket string) string {
+	return serverSocket + ".handoff"
+}
+
+// Send writes every pane to conn, terminates the stream and waits for the
+// receiver's ack. The caller keeps ownership of the PTY files and should
+// close them only after Send returns nil.
+func Send(conn *net.UnixConn, panes []Pane) error {
+	for _, p := range panes {
+		if p.PTY == nil {
+			return fmt.Errorf("pane %s: nil PTY", p.PaneID)
+		}
+		h := p.PaneHandle
+		h.Version = Version
+		if err := writeHandle(conn, h, int(p.PTY.Fd())); err != nil {
+			return fmt.Errorf("pane %s: %w", p.PaneID, err)
+		}
+	}
+	if err := writeHandle(conn, PaneHandle{Version: Version}, -1); err != nil {
+		return fmt.Errorf("terminate stream: %w", err)
+	}
+
+	var ack [1]byte
+	if _, err := io.ReadFull(conn, ack[:]); err != nil {
+		return fmt.Errorf("read ack: %w", err)
+	}
+	if ack[0] != ackByte {
+		return fmt.Errorf("unexpected ack byte %q", ack[0])
+	}
+	return nil
+}
+
+// Receive reads panes from conn until the terminating message. It does
+// not ack; call Ack once the panes are safely registered so the sender
+// can exit.
+func Receive(conn *net.UnixConn) ([]Pane, error) {
+	var panes []Pane
+	for {
+		h, file, err := readHandle(conn)
+		if err != nil {
+			closePanes(panes)
+			return nil, err
+		}
+		if h.PaneID == "" {
+			if file != nil {
+				_ = file.Close()
+			}
+			return panes, nil
+		}
+		if file == nil {
+			closePanes(panes)
+			return nil, fmt.Errorf("pane %s: missing PTY descriptor", h.PaneID)
+		}
+		panes = append(panes, Pane{PaneHandle: h, PTY: file})
+	}
+}
+
+// closePanes closes the PTYs of panes received before a failure.
+func closePanes(panes []Pane) {
+	for _, p := range panes {
+		_ = p.PTY.Close()
+	}
+}
+
+// Ack tells the sender that every descriptor has been adopted.
+func Ack(conn *net.UnixConn) error {
+	_, err := conn.Write([]byte{ackByte})
+	return err
+}
+
+func writeHandle(conn *net.UnixConn, h PaneHandle, fd int) error {
+	body, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if len(body) > maxHandleSize {
+		return fmt.Errorf("handle too large (%d bytes)", len(body))
+	}
+	data := make([]byte, 4+len(body))
+	binary.BigEndian.PutUint32(data, uint32(len(body)))
+	copy(data[4:], body)
+
+	var oob []byte
+	if fd >= 0 {
+		oob = syscall.UnixRights(fd)
+	}
+	n, oobn, err := conn.WriteMsgUnix(data, oob, nil)
+	if err != nil {
+		return err
+	}
+	if n != len(data) || oobn != len(oob) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+func readHandle(conn *net.UnixConn) (PaneHandle, *os.File, error) {
+	var h PaneHandle
+
+	// The length prefix and the descriptor arrive in the same sendmsg
+	// call, so the first read must be a ReadMsgUnix with room for the
+	// ancillary data. The body may still be split across reads.
+	prefix := make([]byte, 4)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(prefix, oob)
+	if err != nil {
+		return h, nil, fmt.Errorf("read handle: %w", err)
+	}
+	if n < len(prefix) {
+		if _, err := io.ReadFull(conn, prefix[n:]); err != nil {
+			return h, nil, fmt.Errorf("read handle length: %w", err)
+		}
+	}
+
+	file, err := parseRights(oob[:oobn])
+	if err != nil {
+		return h, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix)
+	if size > maxHandleSize {
+		closeFile(file)
+		return h, nil, fmt.Errorf("handle too large (%d bytes)", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		closeFile(file)
+		return h, nil, fmt.Errorf("read handle body: %w", err)
+	}
+	if err := json.Unmarshal(body, &h); err != nil {
+		closeFile(file)
+		return h, nil, fmt.Errorf("decode handle: %w", err)
+	}
+	if h.Version != Version {
+		closeFile(file)
+		return h, nil, fmt.Errorf("handle version %d, want %d", h.Version, Version)
+	}
+	return h, file, nil
+}
+
+func parseRights(oob []byte) (*os.File, error) {
+	if len(oob) == 0 {
+		return nil, nil
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, fmt.Errorf("parse control message: %w", err)
+	}
+	var file *os.File
+	for _, m := range msgs {
+		fds, err := syscall.ParseUnixRights(&m)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if file != nil {
+				// One descriptor per message; close extras so they do
+				// not leak into the new server.
+				_ = syscall.Close(fd)
+				continue
+			}
+			syscall.CloseOnExec(fd)
+			file = os.NewFile(uintptr(fd), "pty-master")
+		}
+	}
+	if file == nil {
+		return nil, errors.New("control message carried no descriptor")
+	}
+	return file, nil
+}
+
+func closeFile(f *os.File) {
+	if f != nil {
+		_ = f.Close()
+	}
+}
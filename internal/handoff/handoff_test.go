@@ -0,0 +1,152 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handoff
+
+import (
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func unixPair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatalf("socketpair: %v", err)
+	}
+	toConn := func(fd int, name string) *net.UnixConn {
+		f := os.NewFile(uintptr(fd), name)
+		defer f.Close()
+		c, err := net.FileConn(f)
+		if err != nil {
+			t.Fatalf("FileConn: %v", err)
+		}
+		return c.(*net.UnixConn)
+	}
+	a, b := toConn(fds[0], "a"), toConn(fds[1], "b")
+	t.Cleanup(func() {
+		_ = a.Close()
+		_ = b.Close()
+	})
+	return a, b
+}
+
+func TestSendReceiveTransfersDescriptors(t *testing.T) {
+	sender, receiver := unixPair(t)
+
+	r1, w1, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r1.Close()
+	defer w1.Close()
+	r2, w2, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	defer w2.Close()
+
+	sent := []Pane{
+		{PaneHandle: PaneHandle{PaneID: "aa", PID: 101, Modes: []byte("\x1b[?1049h")}, PTY: w1},
+		{PaneHandle: PaneHandle{PaneID: "bb", PID: 202}, PTY: w2},
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- Send(sender, sent) }()
+
+	got, err := Receive(receiver)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d panes, want 2", len(got))
+	}
+	if got[0].PaneID != "aa" || got[0].PID != 101 || string(got[0].Modes) != "\x1b[?1049h" {
+		t.Fatalf("pane 0 = %+v", got[0].PaneHandle)
+	}
+	if got[1].PaneID != "bb" || got[1].PID != 202 || len(got[1].Modes) != 0 {
+		t.Fatalf("pane 1 = %+v", got[1].PaneHandle)
+	}
+	if err := Ack(receiver); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// The received descriptors must refer to the same pipes.
+	for i, p := range got {
+		msg := []byte{byte('0' + i)}
+		if _, err := p.PTY.Write(msg); err != nil {
+			t.Fatalf("write via received fd %d: %v", i, err)
+		}
+		_ = p.PTY.Close()
+	}
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(r1, buf); err != nil || buf[0] != '0' {
+		t.Fatalf("pipe 1 read %q, %v", buf, err)
+	}
+	if _, err := io.ReadFull(r2, buf); err != nil || buf[0] != '1' {
+		t.Fatalf("pipe 2 read %q, %v", buf, err)
+	}
+}
+
+func TestReceiveRejectsVersionMismatch(t *testing.T) {
+	sender, receiver := unixPair(t)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	go func() {
+		_ = writeHandle(sender, PaneHandle{Version: Version + 1, PaneID: "aa"}, int(w.Fd()))
+	}()
+	if _, err := Receive(receiver); err == nil {
+		t.Fatal("expected version mismatch error")
+	}
+}
+
+func TestSendWithoutAckFails(t *testing.T) {
+	sender, receiver := unixPair(t)
+	go func() {
+		_, _ = Receive(receiver)
+		_ = receiver.Close()
+	}()
+	if err := Send(sender, nil); err == nil {
+		t.Fatal("expected error when receiver closes without ack")
+	}
+}
+
+func TestReceiveClosesPanesWhenDescriptorMissing(t *testing.T) {
+	sender, receiver := unixPair(t)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	go func() {
+		_ = writeHandle(sender, PaneHandle{Version: Version, PaneID: "aa"}, int(w.Fd()))
+		_ = writeHandle(sender, PaneHandle{Version: Version, PaneID: "bb"}, -1)
+	}()
+	if _, err := Receive(receiver); err == nil {
+		t.Fatal("expected missing descriptor error")
+	}
+
+	// With our write end closed too, the pipe only reaches EOF if the
+	// received copy of it was closed.
+	_ = w.Close()
+	_ = r.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("read = %v, want EOF (received descriptor leaked)", err)
+	}
+}
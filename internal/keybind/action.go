@@ -57,6 +57,25 @@ const (
 	ControlRenameTab Action = "control.rename_tab"
 	ControlNewTab    Action = "control.new_tab"
 	ControlCloseTab  Action = "control.close_tab"
+	ControlStack     Action = "control.stack"
+	ControlStackNext Action = "control.stack.next"
+	ControlStackPrev Action = "control.stack.prev"
+	ControlLayout    Action = "control.layout"
+	ControlEqualize  Action = "control.equalize"
+	ControlSendPane  Action = "control.pane.send"
+	ControlBreakPane Action = "control.pane.break"
+	ControlJoinPane  Action = "control.pane.join"
+	ControlUndoClose Action = "control.pane.undo_close"
+	ControlReopen    Action = "control.pane.reopen"
+	ControlSnapshots Action = "control.snapshots"
+	ControlLock      Action = "control.lock"
+	ControlClipboard Action = "control.clipboard"
+	ControlPalette   Action = "control.palette"
+
+	ControlOSC52Allow     Action = "control.clipboard.allow"
+	ControlOSC52AllowPane Action = "control.clipboard.allow_pane"
+	ControlOSC52Deny      Action = "control.clipboard.deny"
+	ControlOSC52DenyPane  Action = "control.clipboard.deny_pane"
 )
 
 // Texelterm actions.
@@ -65,6 +84,10 @@ const (
 	TermScrollbar   Action = "texelterm.scrollbar"
 	TermTransformer Action = "texelterm.transformer"
 	TermScreenshot  Action = "texelterm.screenshot"
+	TermTableView   Action = "texelterm.table"
+	TermFold        Action = "texelterm.fold"
+	TermCollapse    Action = "texelterm.collapse"
+	TermExport      Action = "texelterm.export"
 	TermScrollUp    Action = "texelterm.scroll.up"
 	TermScrollDown  Action = "texelterm.scroll.down"
 	TermScrollPgUp  Action = "texelterm.scroll.pgup"
@@ -106,12 +129,35 @@ var ActionDescriptions = map[Action]ActionInfo{
 	ControlRenameTab: {Description: "Rename workspace", Category: "Control"},
 	ControlNewTab:   {Description: "Create new workspace", Category: "Control"},
 	ControlCloseTab: {Description: "Close workspace", Category: "Control"},
+	ControlStack:     {Description: "Toggle tabbed container", Category: "Control"},
+	ControlStackNext: {Description: "Next tab in container", Category: "Control"},
+	ControlStackPrev: {Description: "Previous tab in container", Category: "Control"},
+	ControlLayout:    {Description: "Cycle preset layouts", Category: "Control"},
+	ControlEqualize:  {Description: "Equalise pane sizes", Category: "Control"},
+	ControlSendPane:  {Description: "Send pane to workspace (then digit)", Category: "Control"},
+	ControlBreakPane: {Description: "Break pane out into a new workspace", Category: "Control"},
+	ControlJoinPane:  {Description: "Join pane from workspace (then digit)", Category: "Control"},
+	ControlUndoClose: {Description: "Reopen last closed pane", Category: "Control"},
+	ControlReopen:    {Description: "Pick a closed pane to reopen", Category: "Control"},
+	ControlSnapshots: {Description: "Restore an earlier session layout", Category: "Control"},
+	ControlLock:      {Description: "Lock the screen", Category: "Control"},
+	ControlClipboard: {Description: "Paste from clipboard history", Category: "Control"},
+	ControlPalette:   {Description: "Open command palette", Category: "Control"},
+
+	ControlOSC52Allow:     {Description: "Allow pending terminal clipboard request", Category: "Control"},
+	ControlOSC52AllowPane: {Description: "Always allow clipboard requests from this pane", Category: "Control"},
+	ControlOSC52Deny:      {Description: "Deny pending terminal clipboard request", Category: "Control"},
+	ControlOSC52DenyPane:  {Description: "Always deny clipboard requests from this pane", Category: "Control"},
 
 	// Terminal
 	TermSearch:      {Description: "Toggle history search", Category: "Terminal"},
 	TermScrollbar:   {Description: "Toggle scrollbar", Category: "Terminal"},
 	TermTransformer: {Description: "Toggle transformer pipeline", Category: "Terminal"},
 	TermScreenshot:  {Description: "Save pane screenshot as PNG", Category: "Terminal"},
+	TermTableView:   {Description: "Open the table viewer on a table in view", Category: "Terminal"},
+	TermFold:        {Description: "Fold or unfold JSON/YAML output in view", Category: "Terminal"},
+	TermCollapse:    {Description: "Collapse or expand command output in view", Category: "Terminal"},
+	TermExport:      {Description: "Export command output in view to a file", Category: "Terminal"},
 	TermScrollUp:    {Description: "Scroll up one line", Category: "Terminal"},
 	TermScrollDown:  {Description: "Scroll down one line", Category: "Terminal"},
 	TermScrollPgUp:  {Description: "Scroll up one page", Category: "Terminal"},
@@ -0,0 +1,288 @@
+package keybind
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultChordTimeout is how long a partial chord waits for its next key.
+const DefaultChordTimeout = 1500 * time.Millisecond
+
+// Config is the layout of keybindings.json and the keybindings-*.json
+// presets.
+//
+// Chords map key sequences ("ctrl+a g s", keys separated by spaces) to a
+// binding. Tables are named key tables: modes entered with a "table:<name>"
+// binding in which the table's own sequences apply until Esc, an unbound
+// key or the table's timeout.
+//
+// A binding is an action name ("control.zoom"), "send:<text>" to type text
+// into the active pane, or "table:<name>" to enter a key table.
+type Config struct {
+	Preset         string                 `json:"preset"`
+	ExtraPreset    string                 `json:"extraPreset"`
+	Actions        map[string][]string    `json:"actions"`
+	ChordTimeoutMs int                    `json:"chordTimeoutMs,omitempty"`
+	Chords         map[string]string      `json:"chords,omitempty"`
+	Tables         map[string]TableConfig `json:"tables,omitempty"`
+}
+
+// TableConfig describes one named key table.
+type TableConfig struct {
+	// TimeoutMs leaves the table after that long without a key; 0 keeps it
+	// until Esc or an unbound key.
+	TimeoutMs int               `json:"timeoutMs,omitempty"`
+	Keys      map[string]string `json:"keys"`
+}
+
+// NewRegistryFromConfig builds a Registry with the action bindings, chords
+// and key tables of cfg.
+func NewRegistryFromConfig(cfg Config) *Registry {
+	r := NewRegistry(cfg.Preset, cfg.ExtraPreset, cfg.Actions)
+	r.chordTimeout = DefaultChordTimeout
+	if cfg.ChordTimeoutMs > 0 {
+		r.chordTimeout = time.Duration(cfg.ChordTimeoutMs) * time.Millisecond
+	}
+
+	prefixes := r.KeysForAction(ControlToggle)
+	r.chords = newKeyTable("")
+	r.controlChords = newKeyTable("")
+	for seqStr, target := range cfg.Chords {
+		seq, err := ParseKeySequence(seqStr)
+		if err != nil {
+			log.Printf("keybind: skipping chord %q: %v", seqStr, err)
+			continue
+		}
+		if len(seq) < 2 {
+			log.Printf("keybind: skipping chord %q: a chord needs at least two keys", seqStr)
+			continue
+		}
+		// Sequences behind the control prefix are matched inside control
+		// mode, so the prefix keeps entering control mode straight away.
+		table := r.chords
+		if comboIn(seq[0], prefixes) {
+			table, seq = r.controlChords, seq[1:]
+		}
+		table.add(seqStr, seq, ParseBinding(target))
+	}
+
+	r.tables = make(map[string]*KeyTable, len(cfg.Tables))
+	for name, tc := range cfg.Tables {
+		table := newKeyTable(name)
+		table.Timeout = time.Duration(tc.TimeoutMs) * time.Millisecond
+		for seqStr, target := range tc.Keys {
+			seq, err := ParseKeySequence(seqStr)
+			if err != nil {
+				log.Printf("keybind: skipping key %q in table %q: %v", seqStr, name, err)
+				continue
+			}
+			table.add(seqStr, seq, ParseBinding(target))
+		}
+		r.tables[name] = table
+	}
+	return r
+}
+
+func comboIn(kc KeyCombo, list []KeyCombo) bool {
+	for _, c := range list {
+		if c == kc {
+			return true
+		}
+	}
+	return false
+}
+
+// ChordTimeout returns how long a partial chord waits for its next key.
+func (r *Registry) ChordTimeout() time.Duration {
+	if r.chordTimeout <= 0 {
+		return DefaultChordTimeout
+	}
+	return r.chordTimeout
+}
+
+// Chords returns the chords matched outside control mode, or nil.
+func (r *Registry) Chords() *KeyTable {
+	return r.chords
+}
+
+// ControlChords returns the chords that start with the control prefix,
+// with the prefix removed, or nil.
+func (r *Registry) ControlChords() *KeyTable {
+	return r.controlChords
+}
+
+// Table returns the named key table, or nil.
+func (r *Registry) Table(name string) *KeyTable {
+	return r.tables[name]
+}
+
+// Binding is what a chord or key table entry does. Exactly one field is set.
+type Binding struct {
+	Action Action
+	Text   string
+	Table  string
+}
+
+// ParseBinding parses a chord target: "send:<text>", "table:<name>" or an
+// action name.
+func ParseBinding(s string) Binding {
+	switch {
+	case strings.HasPrefix(s, "send:"):
+		return Binding{Text: strings.TrimPrefix(s, "send:")}
+	case strings.HasPrefix(s, "table:"):
+		return Binding{Table: strings.TrimPrefix(s, "table:")}
+	}
+	return Binding{Action: Action(s)}
+}
+
+// Describe returns a short human-readable label for b.
+func (b Binding) Describe() string {
+	switch {
+	case b.Table != "":
+		return b.Table + " mode"
+	case b.Text != "":
+		return fmt.Sprintf("send %q", b.Text)
+	}
+	if info, ok := ActionDescriptions[b.Action]; ok {
+		return info.Description
+	}
+	return string(b.Action)
+}
+
+// ParseKeySequence parses space-separated key combos like "ctrl+a g s".
+func ParseKeySequence(s string) ([]KeyCombo, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("keybind: empty key sequence")
+	}
+	seq := make([]KeyCombo, 0, len(fields))
+	for _, f := range fields {
+		kc, err := ParseKeyCombo(f)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, kc)
+	}
+	return seq, nil
+}
+
+// FormatKeySequence formats seq like "Ctrl+A G S".
+func FormatKeySequence(seq []KeyCombo) string {
+	parts := make([]string, len(seq))
+	for i, kc := range seq {
+		parts[i] = FormatKeyCombo(kc)
+	}
+	return strings.Join(parts, " ")
+}
+
+// KeyTable is a trie of key sequences.
+type KeyTable struct {
+	Name    string
+	Timeout time.Duration
+	root    chordNode
+}
+
+type chordNode struct {
+	combo    KeyCombo
+	children []*chordNode
+	binding  *Binding
+}
+
+func newKeyTable(name string) *KeyTable {
+	return &KeyTable{Name: name}
+}
+
+func (t *KeyTable) add(source string, seq []KeyCombo, b Binding) {
+	n := &t.root
+	overlaps := false
+	for _, kc := range seq {
+		var next *chordNode
+		for _, c := range n.children {
+			if c.combo == kc {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			next = &chordNode{combo: kc}
+			n.children = append(n.children, next)
+		}
+		n = next
+		overlaps = overlaps || n.binding != nil
+	}
+	if overlaps || len(n.children) > 0 {
+		log.Printf("keybind: chord %q overlaps a longer one; the shorter fires only after the chord timeout", source)
+	}
+	n.binding = &b
+}
+
+// Empty reports whether the table has no sequences.
+func (t *KeyTable) Empty() bool {
+	return t == nil || len(t.root.children) == 0
+}
+
+// Lookup walks the events typed so far. bound is the binding of exactly
+// that sequence, if any; more reports whether longer sequences continue
+// it. Neither means the events match nothing.
+func (t *KeyTable) Lookup(events []*tcell.EventKey) (bound *Binding, more bool) {
+	n := t.walk(events)
+	if n == nil {
+		return nil, false
+	}
+	return n.binding, len(n.children) > 0
+}
+
+func (t *KeyTable) walk(events []*tcell.EventKey) *chordNode {
+	if t == nil {
+		return nil
+	}
+	n := &t.root
+	for _, ev := range events {
+		var next *chordNode
+		for _, c := range n.children {
+			if c.combo.MatchesEvent(ev) {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// Continuation is one way to finish a partial chord.
+type Continuation struct {
+	Keys    string
+	Binding Binding
+}
+
+// Continuations lists the sequences that can follow the events typed so
+// far, sorted by keys. Longer sequences show their remaining keys.
+func (t *KeyTable) Continuations(events []*tcell.EventKey) []Continuation {
+	n := t.walk(events)
+	if n == nil {
+		return nil
+	}
+	var out []Continuation
+	var visit func(n *chordNode, prefix []KeyCombo)
+	visit = func(n *chordNode, prefix []KeyCombo) {
+		for _, c := range n.children {
+			seq := append(append([]KeyCombo(nil), prefix...), c.combo)
+			if c.binding != nil {
+				out = append(out, Continuation{Keys: FormatKeySequence(seq), Binding: *c.binding})
+			}
+			visit(c, seq)
+		}
+	}
+	visit(n, nil)
+	sort.Slice(out, func(i, j int) bool { return out[i].Keys < out[j].Keys })
+	return out
+}
@@ -0,0 +1,111 @@
+package keybind
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func runeEvents(s string) []*tcell.EventKey {
+	var evs []*tcell.EventKey
+	for _, r := range s {
+		evs = append(evs, makeKeyEvent(tcell.KeyRune, r, 0))
+	}
+	return evs
+}
+
+func TestParseBinding(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Binding
+	}{
+		{"control.zoom", Binding{Action: ControlZoom}},
+		{"send:git status\r", Binding{Text: "git status\r"}},
+		{"table:resize", Binding{Table: "resize"}},
+	}
+	for _, tt := range tests {
+		if got := ParseBinding(tt.in); got != tt.want {
+			t.Errorf("ParseBinding(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	seq, err := ParseKeySequence("ctrl+a g s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatKeySequence(seq); got != "Ctrl+A G S" {
+		t.Errorf("FormatKeySequence = %q", got)
+	}
+	if _, err := ParseKeySequence("  "); err == nil {
+		t.Error("expected error for empty sequence")
+	}
+}
+
+func TestNewRegistryFromConfig_SplitsControlChords(t *testing.T) {
+	r := NewRegistryFromConfig(Config{
+		Preset:         "linux",
+		ChordTimeoutMs: 500,
+		Chords: map[string]string{
+			"ctrl+a g s": "send:git status",
+			"g d":        "control.zoom",
+			"x":          "control.close", // too short, skipped
+		},
+	})
+	if r.ChordTimeout() != 500*time.Millisecond {
+		t.Errorf("ChordTimeout = %v", r.ChordTimeout())
+	}
+
+	bound, more := r.ControlChords().Lookup(runeEvents("g"))
+	if bound != nil || !more {
+		t.Fatalf("control g: bound=%v more=%v, want a partial chord", bound, more)
+	}
+	bound, more = r.ControlChords().Lookup(runeEvents("gs"))
+	if bound == nil || bound.Text != "git status" || more {
+		t.Fatalf("control g s: bound=%v more=%v", bound, more)
+	}
+
+	if bound, _ := r.Chords().Lookup(runeEvents("gd")); bound == nil || bound.Action != ControlZoom {
+		t.Fatalf("root g d: bound=%v", bound)
+	}
+	if bound, more := r.Chords().Lookup(runeEvents("x")); bound != nil || more {
+		t.Errorf("single-key chord should be skipped, got bound=%v more=%v", bound, more)
+	}
+}
+
+func TestKeyTable_ContinuationsAndTimeout(t *testing.T) {
+	r := NewRegistryFromConfig(Config{
+		Preset: "linux",
+		Tables: map[string]TableConfig{
+			"git": {TimeoutMs: 2000, Keys: map[string]string{
+				"s":   "send:git status\r",
+				"l o": "send:git log --oneline\r",
+				"l p": "send:git log -p\r",
+			}},
+		},
+	})
+	table := r.Table("git")
+	if table == nil || table.Empty() {
+		t.Fatal("expected table git")
+	}
+	if table.Timeout != 2*time.Second {
+		t.Errorf("Timeout = %v", table.Timeout)
+	}
+	if r.Table("missing") != nil {
+		t.Error("unknown table should be nil")
+	}
+
+	conts := table.Continuations(nil)
+	if len(conts) != 3 || conts[0].Keys != "L O" || conts[2].Keys != "S" {
+		t.Fatalf("Continuations(nil) = %+v", conts)
+	}
+	conts = table.Continuations(runeEvents("l"))
+	if len(conts) != 2 || conts[0].Keys != "O" || conts[0].Binding.Describe() != `send "git log --oneline\r"` {
+		t.Fatalf("Continuations(l) = %+v", conts)
+	}
+	if conts := table.Continuations(runeEvents("q")); conts != nil {
+		t.Errorf("Continuations(q) = %+v, want none", conts)
+	}
+}
@@ -34,11 +34,37 @@ var linuxPreset = map[Action][]string{
 	ControlRenameTab: {"t"},
 	ControlNewTab:    {"T"},
 	ControlCloseTab:  {"X"},
+	ControlStack:     {"s"},
+	ControlStackNext: {"]"},
+	ControlStackPrev: {"["},
+	ControlLayout:    {"space"},
+	ControlEqualize:  {"="},
+	ControlSendPane:  {"m"},
+	ControlBreakPane: {"!"},
+	ControlJoinPane:  {"J"},
+	ControlUndoClose: {"u"},
+	ControlReopen:    {"U"},
+	ControlSnapshots: {"S"},
+	ControlLock:      {"L"},
+	ControlClipboard: {"p"},
+	ControlPalette:   {":"},
+
+	ControlOSC52Allow:     {"y"},
+	ControlOSC52AllowPane: {"Y"},
+	ControlOSC52Deny:      {"n"},
+	ControlOSC52DenyPane:  {"N"},
 
 	TermSearch:      {"f3"},
 	TermScrollbar:   {"f7"},
 	TermTransformer: {"f8"},
 	TermScreenshot:  {"ctrl+p"},
+	// Unbound: the function keys these could take belong to full-screen
+	// programs (htop, mc). They run from the command palette or a user
+	// binding.
+	TermTableView:   {},
+	TermFold:        {},
+	TermCollapse:    {},
+	TermExport:      {},
 	TermScrollUp:    {"alt+up"},
 	TermScrollDown:  {"alt+down"},
 	TermScrollPgUp:  {"alt+pgup"},
@@ -3,6 +3,7 @@ package keybind
 import (
 	"log"
 	"sort"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -11,6 +12,12 @@ import (
 type Registry struct {
 	keyToAction  map[KeyCombo]Action
 	actionToKeys map[Action][]KeyCombo
+
+	// Multi-key chords and key tables; see NewRegistryFromConfig.
+	chords        *KeyTable
+	controlChords *KeyTable
+	tables        map[string]*KeyTable
+	chordTimeout  time.Duration
 }
 
 // NewRegistry builds a Registry by merging presets and overrides.
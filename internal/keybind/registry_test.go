@@ -160,3 +160,14 @@ func TestPresets_AllActionsPresent(t *testing.T) {
 		}
 	}
 }
+
+// Terminal actions that would take keys full-screen programs rely on ship
+// unbound.
+func TestPresets_PaletteOnlyTerminalActions(t *testing.T) {
+	r := NewRegistry("linux", "", nil)
+	for _, action := range []Action{TermTableView, TermFold, TermCollapse, TermExport} {
+		if keys := r.KeysForAction(action); len(keys) != 0 {
+			t.Errorf("%s bound to %v by default, want unbound", action, keys)
+		}
+	}
+}
@@ -38,6 +38,8 @@ type Options struct {
 	PanicLog                string
 	ShowRestartNotification bool   // Show notification that server was restarted
 	ClientName              string // --client-name slot for multi-client persistence (issue #199 Plan D)
+	// Compress negotiates compression of large server frames.
+	Compress bool
 }
 
 func Run(opts Options) error {
@@ -52,6 +54,10 @@ func Run(opts Options) error {
 	}
 
 	simple := client.NewSimpleClient(opts.Socket)
+	simple.EnableBatching()
+	if opts.Compress {
+		simple.EnableCompression()
+	}
 
 	// Plan D: load persisted client state if any. Failures (missing,
 	// parse error, mismatch) all yield (nil, nil) and we proceed as
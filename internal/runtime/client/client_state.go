@@ -303,6 +303,7 @@ func (s *clientState) applyEffectConfig() {
 		mgr := s.effects
 		fadeIn := ssCfg.FadeIn
 		fadeOut := ssCfg.FadeOut
+		conn, writeMu, sessionID := s.conn, s.writeMu, s.sessionID
 		s.idleWatcher = effects.NewIdleWatcher(effects.IdleWatcherConfig{
 			Timeout:     ssCfg.Timeout,
 			EffectID:    ssCfg.EffectID,
@@ -324,6 +325,13 @@ func (s *clientState) applyEffectConfig() {
 					FadeOut: fadeOut,
 				})
 			},
+			OnLock: func() {
+				// The lock itself lives in the server so it holds across
+				// detach; the client only asks for it.
+				if conn != nil {
+					sendLockRequest(writeMu, conn, sessionID)
+				}
+			},
 		})
 	}
 	if s.cache != nil {
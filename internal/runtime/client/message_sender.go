@@ -87,6 +87,14 @@ func sendClipboardSet(writeMu *sync.Mutex, conn net.Conn, sessionID [16]byte, mi
 	}
 }
 
+// sendLockRequest asks the server to lock the screen.
+func sendLockRequest(writeMu *sync.Mutex, conn net.Conn, sessionID [16]byte) {
+	header := protocol.Header{Version: protocol.Version, Type: protocol.MsgLockRequest, Flags: protocol.FlagChecksum, SessionID: sessionID}
+	if err := writeMessage(writeMu, conn, header, nil); err != nil {
+		log.Printf("send lock request failed: %v", err)
+	}
+}
+
 func writeMessage(mu *sync.Mutex, conn net.Conn, header protocol.Header, payload []byte) error {
 	mu.Lock()
 	defer mu.Unlock()
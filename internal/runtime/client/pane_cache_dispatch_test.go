@@ -106,6 +106,45 @@ func TestClientState_BufferDeltaAppliesToPaneCache(t *testing.T) {
 	}
 }
 
+func TestClientState_BufferDeltaBatchAppliesEveryPaneAndAcks(t *testing.T) {
+	state := makeTestState()
+	panes := [][16]byte{{1}, {2}}
+	var encoded [][]byte
+	for i, id := range panes {
+		payload, err := protocol.EncodeBufferDelta(protocol.BufferDelta{
+			PaneID:  id,
+			RowBase: 10,
+			Styles:  []protocol.StyleEntry{{}},
+			Rows:    []protocol.RowDelta{{Row: 0, Spans: []protocol.CellSpan{{Text: string(rune('a' + i))}}}},
+		})
+		if err != nil {
+			t.Fatalf("encode delta: %v", err)
+		}
+		encoded = append(encoded, payload)
+	}
+	payload, err := protocol.EncodeBufferDeltaBatch(encoded)
+	if err != nil {
+		t.Fatalf("encode batch: %v", err)
+	}
+
+	hdr := protocol.Header{Type: protocol.MsgBufferDeltaBatch, Sequence: 7}
+	var pendingAck, lastSeq atomic.Uint64
+	ackCh := make(chan struct{}, 1)
+	if !handleControlMessage(state, nil, hdr, payload, [16]byte{}, &lastSeq, nil, &pendingAck, ackCh) {
+		t.Fatal("batch should request a render")
+	}
+
+	for i, id := range panes {
+		row, ok := state.paneCacheFor(id).RowAt(10)
+		if !ok || row[0].Ch != rune('a'+i) {
+			t.Fatalf("pane %d not updated from batch", i)
+		}
+	}
+	if pendingAck.Load() != 7 || lastSeq.Load() != 7 {
+		t.Fatalf("ack=%d lastSeq=%d, want 7", pendingAck.Load(), lastSeq.Load())
+	}
+}
+
 func TestClientState_FetchRangeResponseAppliesToPaneCache(t *testing.T) {
 	state := makeTestState()
 
@@ -77,24 +77,21 @@ func handleControlMessage(state *clientState, conn net.Conn, hdr protocol.Header
 			log.Printf("decode delta failed: %v", err)
 			return false
 		}
-		cache.ApplyDelta(delta)
-		state.paneCacheFor(delta.PaneID).ApplyDelta(delta)
-		// Update viewport tracker: alt-screen transitions + AutoFollow advance.
-		if state.viewports != nil {
-			state.onBufferDelta(delta)
-		}
-		scheduleAck(pendingAck, ackSignal, hdr.Sequence)
-		if lastSequence != nil {
-			// Atomic-safe check-then-set. Plan D's only writer is this
-			// loop, so any race against persistSnapshot's Load() is a
-			// benign read of "either the old or new value", both of which
-			// are valid sequences to persist. If a future change adds a
-			// second writer, switch to CompareAndSwap.
-			cur := lastSequence.Load()
-			if hdr.Sequence > cur {
-				lastSequence.Store(hdr.Sequence)
-			}
+		applyBufferDelta(state, delta)
+		recordDeltaSequence(hdr.Sequence, lastSequence, pendingAck, ackSignal)
+		return true
+	case protocol.MsgBufferDeltaBatch:
+		deltas, err := protocol.DecodeBufferDeltaBatch(payload)
+		if err != nil {
+			log.Printf("decode delta batch failed: %v", err)
+			return false
+		}
+		for _, delta := range deltas {
+			applyBufferDelta(state, delta)
 		}
+		// The batch header carries the sequence of its last delta; acks
+		// are cumulative, so acking it covers the whole batch.
+		recordDeltaSequence(hdr.Sequence, lastSequence, pendingAck, ackSignal)
 		return true
 	case protocol.MsgFetchRangeResponse:
 		resp, err := protocol.DecodeFetchRangeResponse(payload)
@@ -197,6 +194,8 @@ func handleControlMessage(state *clientState, conn net.Conn, hdr protocol.Header
 			ts := state.effects.PaneStateTriggerTimestamp()
 			state.effects.HandleTrigger(effects.EffectTrigger{Type: effects.TriggerPaneActive, PaneID: paneFlags.PaneID, Active: active, Timestamp: ts})
 			state.effects.HandleTrigger(effects.EffectTrigger{Type: effects.TriggerPaneResizing, PaneID: paneFlags.PaneID, Resizing: resizing, Timestamp: ts})
+			state.effects.HandleTrigger(effects.EffectTrigger{Type: effects.TriggerPaneActivity, PaneID: paneFlags.PaneID, Active: paneFlags.Flags&protocol.PaneStateActivity != 0, Timestamp: ts})
+			state.effects.HandleTrigger(effects.EffectTrigger{Type: effects.TriggerPaneSilence, PaneID: paneFlags.PaneID, Active: paneFlags.Flags&protocol.PaneStateSilence != 0, Timestamp: ts})
 		}
 		return true
 	case protocol.MsgStateUpdate:
@@ -248,3 +247,31 @@ func handleControlMessage(state *clientState, conn net.Conn, hdr protocol.Header
 	}
 	return false
 }
+
+// applyBufferDelta updates both caches and the viewport tracker for one
+// pane delta, whether it arrived alone or inside a batch.
+func applyBufferDelta(state *clientState, delta protocol.BufferDelta) {
+	state.cache.ApplyDelta(delta)
+	state.paneCacheFor(delta.PaneID).ApplyDelta(delta)
+	// Update viewport tracker: alt-screen transitions + AutoFollow advance.
+	if state.viewports != nil {
+		state.onBufferDelta(delta)
+	}
+}
+
+// recordDeltaSequence acks a delta frame and advances the persisted
+// resume sequence.
+func recordDeltaSequence(seq uint64, lastSequence *atomic.Uint64, pendingAck *atomic.Uint64, ackSignal chan<- struct{}) {
+	scheduleAck(pendingAck, ackSignal, seq)
+	if lastSequence != nil {
+		// Atomic-safe check-then-set. Plan D's only writer is this
+		// loop, so any race against persistSnapshot's Load() is a
+		// benign read of "either the old or new value", both of which
+		// are valid sequences to persist. If a future change adds a
+		// second writer, switch to CompareAndSwap.
+		cur := lastSequence.Load()
+		if seq > cur {
+			lastSequence.Store(seq)
+		}
+	}
+}
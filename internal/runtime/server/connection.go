@@ -14,6 +14,7 @@ import (
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/framegrace/texelation/protocol"
@@ -47,6 +48,23 @@ type connection struct {
 	pending             chan struct{}
 	stop                chan struct{}
 	initialSnapshotSent bool // Track if we've sent the first snapshot
+	// compressor is non-nil when the client negotiated CapCompression.
+	// Guarded by writeMu so frames enter the shared flate stream in
+	// wire order.
+	compressor *protocol.Compressor
+	// pacer is non-nil when the client negotiated CapBatchedDeltas; it
+	// holds delta frames while too many await an ack. paceWait is how long
+	// the last sendPending was held, so serve can retry when the window's
+	// stall timeout lapses even if no ack arrives.
+	pacer    *framePacer
+	paceWait time.Duration
+	// diagnostics is set when the client negotiated CapDiagnostics; the
+	// desktop event goroutine reads it when forwarding samples.
+	diagnostics atomic.Bool
+	// paneStates remembers the flags last sent per pane so monitor alerts
+	// can be re-sent without the rest of the pane's state.
+	paneStateMu sync.Mutex
+	paneStates  map[[16]byte]sentPaneState
 }
 
 type protocolMessage struct {
@@ -128,9 +146,16 @@ func (c *connection) serve() (retErr error) {
 			return err
 		}
 
+		var paceTimeout <-chan time.Time
+		if c.paceWait > 0 {
+			paceTimeout = time.After(c.paceWait)
+		}
+
 		select {
 		case <-c.pending:
 			continue
+		case <-paceTimeout:
+			continue
 		case err := <-c.readErr:
 			if err == io.EOF {
 				debugLog.Printf("%s read EOF", prefix)
@@ -214,9 +239,24 @@ func (c *connection) writeControlMessage(msgType protocol.MessageType, payload [
 func (c *connection) writeMessage(header protocol.Header, payload []byte) error {
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
+	payload = c.compressor.Compress(&header, payload)
 	return protocol.WriteMessage(c.conn, header, payload)
 }
 
+// applyCapabilities enables the optional features agreed during the
+// handshake. Frames written before it runs simply go out uncompressed.
+func (c *connection) applyCapabilities(caps uint32) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if caps&protocol.CapCompression != 0 {
+		c.compressor = protocol.NewCompressor()
+	}
+	if caps&protocol.CapBatchedDeltas != 0 {
+		c.pacer = newFramePacer()
+	}
+	c.diagnostics.Store(caps&protocol.CapDiagnostics != 0)
+}
+
 func (c *connection) nudge() {
 	if c.pending == nil {
 		return
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/framegrace/texelation/protocol"
 )
@@ -24,6 +25,7 @@ func (c *connection) handleMessage(prefix string, header protocol.Header, payloa
 			return err
 		}
 		c.session.Ack(ack.Sequence)
+		c.pacer.acked(ack.Sequence, time.Now())
 		if ack.Sequence > c.lastAcked {
 			c.lastAcked = ack.Sequence
 		}
@@ -317,6 +319,10 @@ func (c *connection) handleMessage(prefix string, header protocol.Header, payloa
 		if sink, ok := c.sink.(*DesktopSink); ok {
 			sink.Publish()
 		}
+	case protocol.MsgLockRequest:
+		if sink, ok := c.sink.(*DesktopSink); ok && sink.Desktop() != nil {
+			sink.Desktop().RequestLock()
+		}
 	case protocol.MsgFetchRange:
 		if err := c.handleFetchRange(payload); err != nil {
 			return fmt.Errorf("fetch range: %w", err)
@@ -388,7 +394,7 @@ func (c *connection) handleClientReady(ready protocol.ClientReady) {
 
 	states := snapshotMergedPaneStates(snapshot, desktop)
 	for _, state := range states {
-		c.sendPaneState(state.ID, state.Active, state.Resizing, state.ZOrder, state.HandlesMouse)
+		c.sendPaneStateSnapshot(state)
 	}
 
 	c.initialSnapshotSent = true
@@ -456,7 +462,7 @@ func (c *connection) handleResize(size protocol.Resize) {
 
 	states := snapshotMergedPaneStates(snapshot, desktop)
 	for _, state := range states {
-		c.sendPaneState(state.ID, state.Active, state.Resizing, state.ZOrder, state.HandlesMouse)
+		c.sendPaneStateSnapshot(state)
 	}
 
 	// Reset diff state so the publish sends full buffers instead of diffs
@@ -529,6 +535,11 @@ func (c *connection) handleFetchRange(payload []byte) error {
 		return sendStub(protocol.FetchRangeEmpty)
 	}
 
+	if desktop.IsLocked() {
+		// Scrollback stays hidden until the screen is unlocked.
+		return sendStub(protocol.FetchRangeEmpty)
+	}
+
 	app := desktop.AppByID(req.PaneID)
 	if app == nil {
 		debugLog.Printf("handleFetchRange pane %x: no app found for pane", req.PaneID[:4])
@@ -10,18 +10,45 @@ package server
 
 import (
 	"log"
+	"time"
 
 	"github.com/framegrace/texelation/protocol"
 	"github.com/framegrace/texelation/texel"
 )
 
+// maxBatchBytes caps one MsgBufferDeltaBatch payload; a longer run of
+// queued deltas is split across several frames.
+const maxBatchBytes = 1 << 20
+
 func (c *connection) sendPending() error {
 	if c.awaitResume {
 		return nil
 	}
-	pending := c.session.Pending(c.lastAcked)
-	for _, diff := range pending {
-		if diff.Sequence <= c.lastSent {
+	c.paceWait = 0
+	now := time.Now()
+	if wait, held := c.pacer.hold(now); held {
+		// Only wait out the stall if something is actually queued.
+		if c.session.Stats().NextSequence > c.lastSent {
+			c.paceWait = wait
+		}
+		return nil
+	}
+	pending := c.session.TakePending(max(c.lastSent, c.lastAcked))
+	for i := 0; i < len(pending); {
+		diff := pending[i]
+		if c.pacer != nil && diff.Message.Type == protocol.MsgBufferDelta {
+			end := i + 1
+			size := len(diff.Payload)
+			for end < len(pending) && pending[end].Message.Type == protocol.MsgBufferDelta &&
+				size+len(pending[end].Payload) <= maxBatchBytes && end-i < protocol.MaxBatchDeltas {
+				size += len(pending[end].Payload)
+				end++
+			}
+			if err := c.writeDeltaBatch(pending[i:end]); err != nil {
+				return err
+			}
+			c.pacer.sent(c.lastSent, now)
+			i = end
 			continue
 		}
 		header := diff.Message
@@ -31,7 +58,35 @@ func (c *connection) sendPending() error {
 			return err
 		}
 		c.lastSent = diff.Sequence
+		i++
+	}
+	return nil
+}
+
+// writeDeltaBatch sends a run of queued deltas as one frame stamped with
+// the last delta's sequence. A run of one goes out as a plain
+// MsgBufferDelta.
+func (c *connection) writeDeltaBatch(run []DiffPacket) error {
+	last := run[len(run)-1]
+	header := last.Message
+	header.Sequence = last.Sequence
+	header.SessionID = c.session.ID()
+	payload := last.Payload
+	if len(run) > 1 {
+		encoded := make([][]byte, len(run))
+		for i, diff := range run {
+			encoded[i] = diff.Payload
+		}
+		var err error
+		if payload, err = protocol.EncodeBufferDeltaBatch(encoded); err != nil {
+			return err
+		}
+		header.Type = protocol.MsgBufferDeltaBatch
+	}
+	if err := c.writeMessage(header, payload); err != nil {
+		return err
 	}
+	c.lastSent = last.Sequence
 	return nil
 }
 
@@ -90,6 +145,25 @@ func colorToRGB(r, g, b int32) uint32 {
 	return ((uint32(r) & 0xFF) << 16) | ((uint32(g) & 0xFF) << 8) | (uint32(b) & 0xFF)
 }
 
+// sentPaneState is the last pane state written to the client.
+type sentPaneState struct {
+	flags protocol.PaneStateFlags
+	z     int32
+}
+
+const paneAlertFlags = protocol.PaneStateActivity | protocol.PaneStateSilence
+
+func alertFlags(alert texel.PaneAlert) protocol.PaneStateFlags {
+	var flags protocol.PaneStateFlags
+	if alert&texel.AlertActivity != 0 {
+		flags |= protocol.PaneStateActivity
+	}
+	if alert&texel.AlertSilence != 0 {
+		flags |= protocol.PaneStateSilence
+	}
+	return flags
+}
+
 func (c *connection) sendPaneState(id [16]byte, active, resizing bool, z int, handlesSelection bool) {
 	var flags protocol.PaneStateFlags
 	if active {
@@ -101,16 +175,40 @@ func (c *connection) sendPaneState(id [16]byte, active, resizing bool, z int, ha
 	if handlesSelection {
 		flags |= protocol.PaneStateSelectionDelegated
 	}
-	payload, err := protocol.EncodePaneState(protocol.PaneState{PaneID: id, Flags: flags, ZOrder: int32(z)})
+	c.paneStateMu.Lock()
+	if c.paneStates == nil {
+		c.paneStates = make(map[[16]byte]sentPaneState)
+	}
+	flags |= c.paneStates[id].flags & paneAlertFlags
+	c.paneStates[id] = sentPaneState{flags: flags, z: int32(z)}
+	c.paneStateMu.Unlock()
+	c.writePaneState(id, flags, int32(z))
+}
+
+func (c *connection) writePaneState(id [16]byte, flags protocol.PaneStateFlags, z int32) {
+	payload, err := protocol.EncodePaneState(protocol.PaneState{PaneID: id, Flags: flags, ZOrder: z})
 	if err != nil {
 		return
 	}
 	_ = c.writeControlMessage(protocol.MsgPaneState, payload)
 }
 
+// sendPaneStateSnapshot sends a full pane state, monitor alerts included.
+func (c *connection) sendPaneStateSnapshot(state texel.PaneStateSnapshot) {
+	c.paneStateMu.Lock()
+	if c.paneStates == nil {
+		c.paneStates = make(map[[16]byte]sentPaneState)
+	}
+	sent := c.paneStates[state.ID]
+	sent.flags = sent.flags&^paneAlertFlags | alertFlags(state.Alert)
+	c.paneStates[state.ID] = sent
+	c.paneStateMu.Unlock()
+	c.sendPaneState(state.ID, state.Active, state.Resizing, state.ZOrder, state.HandlesMouse)
+}
+
 func (c *connection) sendPaneStateSnapshots(states []texel.PaneStateSnapshot) {
 	for _, state := range states {
-		c.sendPaneState(state.ID, state.Active, state.Resizing, state.ZOrder, state.HandlesMouse)
+		c.sendPaneStateSnapshot(state)
 	}
 }
 
@@ -140,7 +238,7 @@ func (c *connection) sendTreeSnapshot() {
 	_ = c.writeMessage(header, payload)
 	states := snapshotMergedPaneStates(snapshot, sink.Desktop())
 	for _, pane := range states {
-		c.sendPaneState(pane.ID, pane.Active, pane.Resizing, pane.ZOrder, pane.HandlesMouse)
+		c.sendPaneStateSnapshot(pane)
 	}
 }
 
@@ -148,6 +246,19 @@ func (c *connection) PaneStateChanged(id [16]byte, active bool, resizing bool, z
 	c.sendPaneState(id, active, resizing, z, handlesSelection)
 }
 
+// PaneAlertChanged implements texel.PaneAlertListener.
+func (c *connection) PaneAlertChanged(id [16]byte, alert texel.PaneAlert) {
+	c.paneStateMu.Lock()
+	if c.paneStates == nil {
+		c.paneStates = make(map[[16]byte]sentPaneState)
+	}
+	sent := c.paneStates[id]
+	sent.flags = sent.flags&^paneAlertFlags | alertFlags(alert)
+	c.paneStates[id] = sent
+	c.paneStateMu.Unlock()
+	c.writePaneState(id, sent.flags, sent.z)
+}
+
 func (c *connection) PaneFocused(paneID [16]byte) {
 	payload, err := protocol.EncodePaneFocus(protocol.PaneFocus{PaneID: paneID})
 	if err != nil {
@@ -168,6 +279,14 @@ func (c *connection) OnEvent(event texel.Event) {
 		c.sendStateUpdate(payload)
 	case texel.EventTreeChanged:
 		c.sendTreeSnapshot()
+	case texel.EventDiagnostics:
+		sample, ok := event.Payload.(texel.DiagnosticsPayload)
+		if !ok || !c.diagnostics.Load() {
+			return
+		}
+		if payload, err := encodeDiagnostics(sample); err == nil {
+			_ = c.writeControlMessage(protocol.MsgDiagnostics, payload)
+		}
 	case texel.EventWorkspacesChanged, texel.EventWorkspaceSwitched,
 		texel.EventModeChanged, texel.EventActivePaneChanged,
 		texel.EventPerformanceUpdate, texel.EventToast:
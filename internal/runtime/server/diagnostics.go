@@ -0,0 +1,118 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/server/diagnostics.go
+// Summary: Periodic server health sampler feeding the diagnostics channel.
+// Usage: Server.Start runs the sampler; each tick is broadcast on the desktop
+//   as texel.EventDiagnostics, which in-process apps (the monitor app) graph
+//   and connections that negotiated CapDiagnostics forward as MsgDiagnostics.
+
+package server
+
+import (
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/framegrace/texelation/apps/texelterm/parser"
+	"github.com/framegrace/texelation/protocol"
+	"github.com/framegrace/texelation/texel"
+)
+
+const defaultDiagnosticsInterval = time.Second
+
+// SetDiagnosticsInterval overrides how often health samples are taken.
+// Call before Start.
+func (s *Server) SetDiagnosticsInterval(interval time.Duration) {
+	if interval > 0 {
+		s.diagInterval = interval
+	}
+}
+
+// startDiagnosticsLoopLocked starts the sampler. MUST be called with
+// s.lifecycleMu held; the loop exits when s.quit closes.
+func (s *Server) startDiagnosticsLoopLocked() {
+	interval := s.diagInterval
+	if interval <= 0 {
+		interval = defaultDiagnosticsInterval
+	}
+	ticker := time.NewTicker(interval)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.publishDiagnostics(interval)
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) publishDiagnostics(interval time.Duration) {
+	if s.desktopSink == nil {
+		return
+	}
+	desktop := s.desktopSink.Desktop()
+	if desktop == nil {
+		return
+	}
+	desktop.BroadcastDiagnostics(s.sampleDiagnostics(desktop, interval))
+}
+
+// sampleDiagnostics gathers one sample. Queue depth and diff counters are
+// summed over live sessions; latencies are the worst since the last sample.
+func (s *Server) sampleDiagnostics(desktop *texel.DesktopEngine, interval time.Duration) texel.DiagnosticsPayload {
+	render := desktop.TakeRenderStats()
+	persist := parser.TakePersistenceStats()
+	sample := texel.DiagnosticsPayload{
+		At:             time.Now(),
+		Interval:       interval,
+		PublishLatency: render.PublishMax,
+		FlushLatency:   persist.CheckpointMax,
+		IndexBacklog:   persist.IndexBacklog,
+		Goroutines:     runtime.NumGoroutine(),
+		Panes:          render.Panes,
+	}
+	for _, st := range s.manager.SessionStats() {
+		sample.DiffQueueDepth += st.PendingCount
+		sample.DroppedDiffs += st.DroppedDiffs
+		sample.CoalescedDiffs += st.CoalescedDiffs
+	}
+	return sample
+}
+
+// encodeDiagnostics converts a desktop sample to its wire form.
+func encodeDiagnostics(sample texel.DiagnosticsPayload) ([]byte, error) {
+	panes := sample.Panes
+	if len(panes) > protocol.MaxDiagnosticsPanes {
+		panes = panes[:protocol.MaxDiagnosticsPanes]
+	}
+	msg := protocol.Diagnostics{
+		UnixMillis:     sample.At.UnixMilli(),
+		IntervalMicros: micros(sample.Interval),
+		PublishMicros:  micros(sample.PublishLatency),
+		FlushMicros:    micros(sample.FlushLatency),
+		DiffQueueDepth: clampUint32(sample.DiffQueueDepth),
+		DroppedDiffs:   sample.DroppedDiffs,
+		CoalescedDiffs: sample.CoalescedDiffs,
+		IndexBacklog:   clampUint32(sample.IndexBacklog),
+		Goroutines:     clampUint32(sample.Goroutines),
+		Panes:          make([]protocol.PaneDiagnostics, len(panes)),
+	}
+	for i, p := range panes {
+		msg.Panes[i] = protocol.PaneDiagnostics{PaneID: p.ID, Title: p.Title, RenderMicros: micros(p.RenderTime)}
+	}
+	return protocol.EncodeDiagnostics(msg)
+}
+
+func micros(d time.Duration) uint32 {
+	return clampUint32(int(d / time.Microsecond))
+}
+
+func clampUint32(v int) uint32 {
+	return uint32(min(max(v, 0), math.MaxUint32))
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/protocol"
+	"github.com/framegrace/texelation/texel"
+)
+
+func TestDiagnosticsSampleReachesOptedInClient(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	sink, desktop, cleanup := newDesktopSink(t)
+	defer cleanup()
+
+	mgr := NewManager()
+	session, err := mgr.NewSession()
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	srv := NewServer("", mgr)
+	srv.SetEventSink(sink)
+
+	stopInitial := make(chan struct{})
+	var initialWG sync.WaitGroup
+	initialWG.Add(1)
+	go func() {
+		defer initialWG.Done()
+		drainInitialMessages(clientConn, stopInitial)
+	}()
+	conn := newConnection(serverConn, session, sink, false, false)
+	conn.applyCapabilities(protocol.CapDiagnostics)
+	close(stopInitial)
+	initialWG.Wait()
+
+	// Render once so the sample carries per-pane timings.
+	desktop.SnapshotBuffers()
+	go srv.publishDiagnostics(time.Second)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for diagnostics")
+		}
+		_ = clientConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		hdr, payload, err := protocol.ReadMessage(clientConn)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			t.Fatalf("read: %v", err)
+		}
+		if hdr.Type != protocol.MsgDiagnostics {
+			continue
+		}
+		sample, err := protocol.DecodeDiagnostics(payload)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if sample.IntervalMicros != 1_000_000 || sample.Goroutines == 0 {
+			t.Fatalf("unexpected sample %+v", sample)
+		}
+		if len(sample.Panes) == 0 {
+			t.Fatalf("expected per-pane render times, got none")
+		}
+		return
+	}
+}
+
+func TestDiagnosticsNotSentWithoutCapability(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	sink, _, cleanup := newDesktopSink(t)
+	defer cleanup()
+
+	stopInitial := make(chan struct{})
+	var initialWG sync.WaitGroup
+	initialWG.Add(1)
+	go func() {
+		defer initialWG.Done()
+		drainInitialMessages(clientConn, stopInitial)
+	}()
+	conn := newConnection(serverConn, NewSession([16]byte{4}, 64), sink, false, false)
+	close(stopInitial)
+	initialWG.Wait()
+
+	// net.Pipe writes block until read, so returning at all proves no
+	// frame was written.
+	done := make(chan struct{})
+	go func() {
+		conn.OnEvent(texel.Event{Type: texel.EventDiagnostics, Payload: texel.DiagnosticsPayload{}})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("connection without CapDiagnostics tried to write a sample")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/server/frame_pacer.go
+// Summary: Ack-clocked pacing of buffer delta frames per connection.
+// Usage: A connection whose client negotiated CapBatchedDeltas records each
+//   delta frame it writes and each MsgBufferAck it receives; sendPending
+//   holds further frames while the window is full.
+// Notes: While frames are held, Session.EnqueueDiff keeps folding new deltas
+//   into the unsent ones, so a flooding pane costs one slot per frame and a
+//   quiet pane's update rides in the very next batch.
+
+package server
+
+import "time"
+
+const (
+	// pacingWindow is how many delta frames may await an ack. Two keeps
+	// the link busy across one round trip without building a backlog.
+	pacingWindow = 2
+	// minStall and maxStall bound how long a full window may block before
+	// the oldest frame's ack is presumed lost and sending resumes anyway.
+	minStall = 250 * time.Millisecond
+	maxStall = 2 * time.Second
+)
+
+type pacedFrame struct {
+	seq  uint64
+	sent time.Time
+}
+
+// framePacer tracks delta frames in flight and a smoothed ack round-trip
+// time. Only the connection's serve goroutine touches it. A nil pacer never
+// holds frames back, which is how pre-batching clients are served.
+type framePacer struct {
+	inflight []pacedFrame
+	srtt     time.Duration
+}
+
+func newFramePacer() *framePacer {
+	return &framePacer{}
+}
+
+// hold reports whether the window is full and, if so, how long until the
+// stall timeout frees it. Once the timeout has passed the window is
+// cleared and hold reports false.
+func (p *framePacer) hold(now time.Time) (time.Duration, bool) {
+	if p == nil || len(p.inflight) < pacingWindow {
+		return 0, false
+	}
+	if wait := p.inflight[0].sent.Add(p.stallTimeout()).Sub(now); wait > 0 {
+		return wait, true
+	}
+	p.inflight = p.inflight[:0]
+	return 0, false
+}
+
+// sent records a delta frame whose header carried seq.
+func (p *framePacer) sent(seq uint64, now time.Time) {
+	if p == nil {
+		return
+	}
+	p.inflight = append(p.inflight, pacedFrame{seq: seq, sent: now})
+}
+
+// acked retires every frame covered by the cumulative ack and folds the
+// newest one's round trip into the smoothed RTT (RFC 6298 style, 1/8 gain).
+func (p *framePacer) acked(seq uint64, now time.Time) {
+	if p == nil {
+		return
+	}
+	n := 0
+	for n < len(p.inflight) && p.inflight[n].seq <= seq {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	sample := now.Sub(p.inflight[n-1].sent)
+	if p.srtt == 0 {
+		p.srtt = sample
+	} else {
+		p.srtt += (sample - p.srtt) / 8
+	}
+	p.inflight = append(p.inflight[:0], p.inflight[n:]...)
+}
+
+// rtt returns the smoothed ack round trip, or 0 before the first sample.
+func (p *framePacer) rtt() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.srtt
+}
+
+func (p *framePacer) stallTimeout() time.Duration {
+	return min(max(4*p.srtt, minStall), maxStall)
+}
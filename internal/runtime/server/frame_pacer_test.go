@@ -0,0 +1,145 @@
+package server
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/protocol"
+)
+
+func TestFramePacerWindowAndRTT(t *testing.T) {
+	p := newFramePacer()
+	now := time.Unix(1000, 0)
+	p.sent(1, now)
+	if _, held := p.hold(now); held {
+		t.Fatalf("one frame in flight must not hold")
+	}
+	p.sent(2, now.Add(10*time.Millisecond))
+	wait, held := p.hold(now.Add(20 * time.Millisecond))
+	if !held || wait != minStall-20*time.Millisecond {
+		t.Fatalf("full window: held=%v wait=%v", held, wait)
+	}
+
+	p.acked(1, now.Add(40*time.Millisecond))
+	if p.rtt() != 40*time.Millisecond {
+		t.Fatalf("rtt = %v, want 40ms", p.rtt())
+	}
+	if _, held := p.hold(now.Add(40 * time.Millisecond)); held {
+		t.Fatalf("ack should reopen the window")
+	}
+	p.acked(2, now.Add(90*time.Millisecond))
+	if want := 40*time.Millisecond + (80*time.Millisecond-40*time.Millisecond)/8; p.rtt() != want {
+		t.Fatalf("smoothed rtt = %v, want %v", p.rtt(), want)
+	}
+
+	// A window whose acks never come frees itself after the stall timeout.
+	p.sent(3, now)
+	p.sent(4, now)
+	if _, held := p.hold(now.Add(maxStall)); held {
+		t.Fatalf("stalled window should be released")
+	}
+
+	var none *framePacer
+	none.sent(1, now)
+	none.sent(2, now)
+	if _, held := none.hold(now); held {
+		t.Fatalf("nil pacer must never hold")
+	}
+}
+
+func TestPacedConnectionCoalescesFloodingPane(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	sessionID := [16]byte{9}
+	session := NewSession(sessionID, 64)
+	conn := newConnection(serverConn, session, nopSink{}, false, false)
+	conn.applyCapabilities(protocol.CapBatchedDeltas)
+	errCh := make(chan error, 1)
+	go func() { errCh <- conn.serve() }()
+
+	flood, quiet := [16]byte{1}, [16]byte{2}
+	rev := map[[16]byte]uint32{}
+	enqueue := func(pane [16]byte, text string) {
+		rev[pane]++
+		err := session.EnqueueDiff(protocol.BufferDelta{
+			PaneID:   pane,
+			Revision: rev[pane],
+			Styles:   []protocol.StyleEntry{{}},
+			Rows:     []protocol.RowDelta{{Row: 0, Spans: []protocol.CellSpan{{Text: text}}}},
+		})
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		conn.nudge()
+	}
+	read := func() (protocol.Header, []byte) {
+		t.Helper()
+		_ = clientConn.SetReadDeadline(time.Now().Add(time.Second))
+		hdr, payload, err := protocol.ReadMessage(clientConn)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return hdr, payload
+	}
+
+	// Fill the window with two frames.
+	enqueue(flood, "f1")
+	first, _ := read()
+	enqueue(flood, "f2")
+	second, _ := read()
+	if first.Type != protocol.MsgBufferDelta || second.Type != protocol.MsgBufferDelta {
+		t.Fatalf("expected plain deltas, got %v and %v", first.Type, second.Type)
+	}
+
+	// With the window full, a burst from the flooding pane and one
+	// keystroke echo from the quiet pane must wait...
+	for i := 3; i < 40; i++ {
+		enqueue(flood, "f"+itoa(i))
+	}
+	enqueue(quiet, "typed")
+	_ = clientConn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, _, err := protocol.ReadMessage(clientConn); !os.IsTimeout(err) {
+		t.Fatalf("expected no frame while the window is full, got %v", err)
+	}
+
+	// ...and go out as one batch once an ack arrives.
+	ack, _ := protocol.EncodeBufferAck(protocol.BufferAck{Sequence: first.Sequence})
+	if err := protocol.WriteMessage(clientConn, protocol.Header{Version: protocol.Version, Type: protocol.MsgBufferAck, Flags: protocol.FlagChecksum, SessionID: sessionID}, ack); err != nil {
+		t.Fatalf("write ack: %v", err)
+	}
+	hdr, payload := read()
+	if hdr.Type != protocol.MsgBufferDeltaBatch {
+		t.Fatalf("expected a batch, got %v", hdr.Type)
+	}
+	deltas, err := protocol.DecodeBufferDeltaBatch(payload)
+	if err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+	if len(deltas) != 2 {
+		t.Fatalf("expected flood coalesced to one delta plus the quiet pane, got %d", len(deltas))
+	}
+	if deltas[0].PaneID != flood || deltas[0].Revision != rev[flood] || deltas[0].Rows[0].Spans[0].Text != "f39" {
+		t.Fatalf("flooding pane should carry only its latest state: %+v", deltas[0])
+	}
+	if deltas[1].PaneID != quiet {
+		t.Fatalf("quiet pane missing from batch")
+	}
+	if stats := session.Stats(); stats.CoalescedDiffs != 36 || stats.DroppedDiffs != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	clientConn.Close()
+	select {
+	case err := <-errCh:
+		if err != nil && err != io.EOF {
+			t.Fatalf("serve returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection serve did not exit")
+	}
+}
@@ -34,20 +34,33 @@ var (
 //     whether to clear stale per-connection state (e.g.
 //     c.lastAcked from the prior daemon's lifetime).
 func handleHandshake(rw io.ReadWriter, mgr *Manager) (*Session, bool, bool, error) {
+	session, resuming, rehydrated, _, err := negotiateHandshake(rw, mgr)
+	return session, resuming, rehydrated, err
+}
+
+// serverCapabilities lists the optional features this server offers; the
+// Welcome carries their intersection with the client's Hello.
+const serverCapabilities = protocol.CapCompression | protocol.CapBatchedDeltas | protocol.CapDiagnostics
+
+// negotiateHandshake is handleHandshake plus the capability bits both
+// sides agreed on, which the caller applies to the connection.
+func negotiateHandshake(rw io.ReadWriter, mgr *Manager) (*Session, bool, bool, uint32, error) {
 	hdr, payload, err := protocol.ReadMessage(rw)
 	if err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 	if hdr.Type != protocol.MsgHello {
-		return nil, false, false, errUnexpectedMessage
+		return nil, false, false, 0, errUnexpectedMessage
 	}
-	if _, err := protocol.DecodeHello(payload); err != nil {
-		return nil, false, false, err
+	hello, err := protocol.DecodeHello(payload)
+	if err != nil {
+		return nil, false, false, 0, err
 	}
+	caps := hello.Capabilities & serverCapabilities
 
-	welcomePayload, err := protocol.EncodeWelcome(protocol.Welcome{ServerName: "texelation-server"})
+	welcomePayload, err := protocol.EncodeWelcome(protocol.Welcome{ServerName: "texelation-server", Capabilities: caps})
 	if err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 	welcomeHeader := protocol.Header{
 		Version: protocol.Version,
@@ -55,19 +68,19 @@ func handleHandshake(rw io.ReadWriter, mgr *Manager) (*Session, bool, bool, erro
 		Flags:   protocol.FlagChecksum,
 	}
 	if err := protocol.WriteMessage(rw, welcomeHeader, welcomePayload); err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 
 	hdr, payload, err = protocol.ReadMessage(rw)
 	if err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 	if hdr.Type != protocol.MsgConnectRequest {
-		return nil, false, false, errUnexpectedMessage
+		return nil, false, false, 0, errUnexpectedMessage
 	}
 	connectReq, err := protocol.DecodeConnectRequest(payload)
 	if err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 
 	var session *Session
@@ -77,18 +90,18 @@ func handleHandshake(rw io.ReadWriter, mgr *Manager) (*Session, bool, bool, erro
 	if bytes.Equal(connectReq.SessionID[:], zeroID[:]) {
 		session, err = mgr.NewSession()
 		if err != nil {
-			return nil, false, false, err
+			return nil, false, false, 0, err
 		}
 	} else {
 		session, rehydrated, err = mgr.LookupOrRehydrate(connectReq.SessionID)
 		if err != nil {
-			return nil, false, false, err
+			return nil, false, false, 0, err
 		}
 	}
 
 	connectPayload, err := protocol.EncodeConnectAccept(protocol.ConnectAccept{SessionID: session.ID(), ResumeSupported: true})
 	if err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 
 	connectHeader := protocol.Header{
@@ -99,8 +112,8 @@ func handleHandshake(rw io.ReadWriter, mgr *Manager) (*Session, bool, bool, erro
 		Sequence:  1,
 	}
 	if err := protocol.WriteMessage(rw, connectHeader, connectPayload); err != nil {
-		return nil, false, false, err
+		return nil, false, false, 0, err
 	}
 
-	return session, resuming, rehydrated, nil
+	return session, resuming, rehydrated, caps, nil
 }
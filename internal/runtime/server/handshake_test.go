@@ -10,6 +10,7 @@ package server
 
 import (
 	"net"
+	"strings"
 	"testing"
 
 	"github.com/framegrace/texelation/protocol"
@@ -90,3 +91,58 @@ func TestHandleHandshakeCreatesSession(t *testing.T) {
 		t.Fatalf("expected 1 active session, got %d", mgr.ActiveSessions())
 	}
 }
+
+func TestNegotiateHandshakeEnablesCompression(t *testing.T) {
+	mgr := NewManager()
+	client, srv := net.Pipe()
+	defer client.Close()
+	defer srv.Close()
+
+	big := []byte(strings.Repeat("row of terminal output ", 200))
+	done := make(chan error, 1)
+	go func() {
+		_, _, _, caps, err := negotiateHandshake(srv, mgr)
+		if err != nil {
+			done <- err
+			return
+		}
+		c := &connection{conn: srv}
+		c.applyCapabilities(caps)
+		done <- c.writeMessage(protocol.Header{Version: protocol.Version, Type: protocol.MsgTreeSnapshot, Flags: protocol.FlagChecksum}, big)
+	}()
+
+	helloPayload, _ := protocol.EncodeHello(protocol.Hello{ClientName: "test-client", Capabilities: protocol.CapCompression | 1<<31})
+	if err := protocol.WriteMessage(client, protocol.Header{Version: protocol.Version, Type: protocol.MsgHello, Flags: protocol.FlagChecksum}, helloPayload); err != nil {
+		t.Fatalf("write hello: %v", err)
+	}
+	_, payload, err := protocol.ReadMessage(client)
+	if err != nil {
+		t.Fatalf("read welcome: %v", err)
+	}
+	welcome, err := protocol.DecodeWelcome(payload)
+	if err != nil {
+		t.Fatalf("decode welcome: %v", err)
+	}
+	if welcome.Capabilities != protocol.CapCompression {
+		t.Fatalf("welcome capabilities = %#x, want only CapCompression", welcome.Capabilities)
+	}
+	connectPayload, _ := protocol.EncodeConnectRequest(protocol.ConnectRequest{})
+	if err := protocol.WriteMessage(client, protocol.Header{Version: protocol.Version, Type: protocol.MsgConnectRequest, Flags: protocol.FlagChecksum}, connectPayload); err != nil {
+		t.Fatalf("write connect: %v", err)
+	}
+	if _, _, err := protocol.ReadMessage(client); err != nil {
+		t.Fatalf("read connect accept: %v", err)
+	}
+
+	dc := protocol.NewDecompressingConn(client)
+	hdr, got, err := protocol.ReadMessage(dc)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if hdr.Type != protocol.MsgTreeSnapshot || string(got) != string(big) {
+		t.Fatalf("snapshot did not round-trip (type %v, %d bytes)", hdr.Type, len(got))
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("server side: %v", err)
+	}
+}
@@ -35,6 +35,7 @@ type Server struct {
 	snapshotStore    *SnapshotStore
 	snapshotInterval time.Duration
 	snapshotQuit     chan struct{}
+	diagInterval     time.Duration
 	desktopSink      *DesktopSink
 	focusMetrics     *FocusMetrics
 	bootSnapshotMu   sync.RWMutex
@@ -135,6 +136,7 @@ func (s *Server) Start() error {
 	// called concurrently with Start (common in tests via `go srv.Start()`).
 	s.wg.Add(1)
 	s.startSnapshotLoopLocked()
+	s.startDiagnosticsLoopLocked()
 	s.started = true
 	s.lifecycleMu.Unlock()
 
@@ -159,11 +161,12 @@ func (s *Server) acceptLoop(l net.Listener) {
 		go func(c net.Conn) {
 			defer s.wg.Done()
 			defer c.Close()
-			session, resuming, rehydrated, err := handleHandshake(c, s.manager)
+			session, resuming, rehydrated, caps, err := negotiateHandshake(c, s.manager)
 			if err != nil {
 				return
 			}
 			conn := newConnection(c, session, s.sink, resuming, rehydrated)
+			conn.applyCapabilities(caps)
 			publisher := (*DesktopPublisher)(nil)
 			if s.publisherFactory != nil {
 				publisher = s.publisherFactory(session)
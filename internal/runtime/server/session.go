@@ -43,6 +43,9 @@ type DiffPacket struct {
 	Sequence uint64
 	Payload  []byte
 	Message  protocol.Header
+	// delta is the decoded form of a MsgBufferDelta payload, kept so a
+	// later delta for the same pane can be merged in while unsent.
+	delta *protocol.BufferDelta
 }
 
 // storedMeta is the in-memory mirror of the Plan F session-level metadata
@@ -66,6 +69,8 @@ type Session struct {
 	maxDiffs       int
 	droppedDiffs   uint64
 	lastDroppedSeq uint64
+	coalescedDiffs uint64
+	sentThrough    uint64 // highest sequence handed out by TakePending
 	viewports      *ClientViewports
 	revisionsMu    sync.Mutex
 	revisions      map[[16]byte]uint32
@@ -235,7 +240,10 @@ func (s *Session) ID() [16]byte {
 	return s.id
 }
 
-// EnqueueDiff registers a new buffer delta for broadcast to clients.
+// EnqueueDiff registers a new buffer delta for broadcast to clients. If a
+// delta for the same pane is still queued and unsent, the new one is folded
+// into it instead, so a pane changing faster than the connection drains
+// holds a single slot carrying only its latest state.
 func (s *Session) EnqueueDiff(delta protocol.BufferDelta) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -243,6 +251,10 @@ func (s *Session) EnqueueDiff(delta protocol.BufferDelta) error {
 		return ErrSessionClosed
 	}
 
+	if s.coalesceLocked(delta) {
+		return nil
+	}
+
 	payload, err := protocol.EncodeBufferDelta(delta)
 	if err != nil {
 		return err
@@ -261,6 +273,7 @@ func (s *Session) EnqueueDiff(delta protocol.BufferDelta) error {
 		Sequence: seq,
 		Payload:  payload,
 		Message:  hdr,
+		delta:    &delta,
 	})
 	s.nextSequence = seq
 
@@ -272,6 +285,39 @@ func (s *Session) EnqueueDiff(delta protocol.BufferDelta) error {
 	return nil
 }
 
+// coalesceLocked merges delta into the newest unsent queued delta for the
+// same pane. The scan walks back from the tail and stops at the first
+// packet already sent or at a non-delta packet (image messages), so
+// per-pane ordering relative to everything else is preserved. The merged
+// packet keeps its earlier sequence: the client has not seen it yet, and
+// acks stay cumulative.
+func (s *Session) coalesceLocked(delta protocol.BufferDelta) bool {
+	for i := len(s.diffs) - 1; i >= 0; i-- {
+		queued := &s.diffs[i]
+		if queued.Sequence <= s.sentThrough || queued.delta == nil {
+			return false
+		}
+		if queued.delta.PaneID != delta.PaneID {
+			continue
+		}
+		merged, ok := protocol.MergeBufferDeltas(*queued.delta, delta)
+		if !ok {
+			return false
+		}
+		payload, err := protocol.EncodeBufferDelta(merged)
+		if err != nil {
+			return false
+		}
+		// Replace rather than mutate: Pending hands out copies of the
+		// packet that may still reference the old payload.
+		queued.delta = &merged
+		queued.Payload = payload
+		s.coalescedDiffs++
+		return true
+	}
+	return false
+}
+
 // EnqueueImage registers an image protocol message for broadcast to clients.
 func (s *Session) EnqueueImage(msgType uint8, payload []byte) error {
 	s.mu.Lock()
@@ -344,6 +390,27 @@ func (s *Session) Pending(after uint64) []DiffPacket {
 	return nil
 }
 
+// TakePending is Pending for a connection about to write the packets: in
+// the same critical section it marks them sent, so no later delta can be
+// coalesced into a packet the caller is already holding a copy of.
+func (s *Session) TakePending(after uint64) []DiffPacket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := 0
+	for start < len(s.diffs) && s.diffs[start].Sequence <= after {
+		start++
+	}
+	if start == len(s.diffs) {
+		return nil
+	}
+	out := make([]DiffPacket, len(s.diffs)-start)
+	copy(out, s.diffs[start:])
+	if last := out[len(out)-1].Sequence; last > s.sentThrough {
+		s.sentThrough = last
+	}
+	return out
+}
+
 func (s *Session) Close() {
 	s.mu.Lock()
 	s.closed = true
@@ -394,6 +461,7 @@ func (s *Session) statsLocked() SessionStats {
 		NextSequence:     s.nextSequence,
 		DroppedDiffs:     s.droppedDiffs,
 		LastDroppedSeq:   s.lastDroppedSeq,
+		CoalescedDiffs:   s.coalescedDiffs,
 		LastSnapshotTime: s.lastSnapshot,
 	}
 }
@@ -405,5 +473,6 @@ type SessionStats struct {
 	NextSequence     uint64
 	DroppedDiffs     uint64
 	LastDroppedSeq   uint64
+	CoalescedDiffs   uint64
 	LastSnapshotTime time.Time
 }
@@ -67,9 +67,15 @@ func TestSessionRetentionLimit(t *testing.T) {
 		}
 	}
 
-	_ = session.EnqueueDiff(makeDelta(1, "one"))
-	_ = session.EnqueueDiff(makeDelta(2, "two"))
-	_ = session.EnqueueDiff(makeDelta(3, "three"))
+	// Retention applies to sent-but-unacked history; mark each diff sent
+	// so the same-pane deltas are not coalesced.
+	enqueueSent := func(d protocol.BufferDelta) {
+		_ = session.EnqueueDiff(d)
+		session.TakePending(0)
+	}
+	enqueueSent(makeDelta(1, "one"))
+	enqueueSent(makeDelta(2, "two"))
+	enqueueSent(makeDelta(3, "three"))
 
 	pending := session.Pending(0)
 	if len(pending) != 2 {
@@ -80,7 +86,7 @@ func TestSessionRetentionLimit(t *testing.T) {
 	}
 
 	session.setMaxDiffs(1)
-	_ = session.EnqueueDiff(makeDelta(4, "four"))
+	enqueueSent(makeDelta(4, "four"))
 	pending = session.Pending(0)
 	if len(pending) != 1 || pending[0].Sequence != 4 {
 		t.Fatalf("expected retention update to keep only latest diff, got %v", pending)
@@ -106,6 +112,7 @@ func TestSessionStatsReporter(t *testing.T) {
 	if err := session.EnqueueDiff(delta); err != nil {
 		t.Fatalf("enqueue failed: %v", err)
 	}
+	session.TakePending(0)
 	_ = session.EnqueueDiff(protocol.BufferDelta{PaneID: id, Revision: 2})
 
 	select {
@@ -238,3 +245,56 @@ func TestSession_CloseRacesWithApplyViewportUpdate(t *testing.T) {
 		t.Fatalf("sessionID mismatch: got %x want %x", got.SessionID, id)
 	}
 }
+
+func TestSessionCoalescesUnsentDiffsPerPane(t *testing.T) {
+	var id [16]byte
+	copy(id[:], []byte("session-coalesc"))
+	session := NewSession(id, 0)
+	flood := [16]byte{1}
+	quiet := [16]byte{2}
+
+	row := func(pane [16]byte, rev uint32, r uint16, text string) protocol.BufferDelta {
+		return protocol.BufferDelta{
+			PaneID:   pane,
+			Revision: rev,
+			Styles:   []protocol.StyleEntry{{}},
+			Rows:     []protocol.RowDelta{{Row: r, Spans: []protocol.CellSpan{{Text: text}}}},
+		}
+	}
+
+	_ = session.EnqueueDiff(row(flood, 1, 0, "a"))
+	session.TakePending(0)
+	// Sent packets are never rewritten; these three pile onto one slot.
+	_ = session.EnqueueDiff(row(flood, 2, 0, "b"))
+	_ = session.EnqueueDiff(row(quiet, 1, 0, "typed"))
+	_ = session.EnqueueDiff(row(flood, 3, 1, "c"))
+	_ = session.EnqueueDiff(row(flood, 4, 0, "d"))
+
+	pending := session.Pending(0)
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 packets (sent, merged flood, quiet), got %d", len(pending))
+	}
+	if pending[1].Sequence != 2 || pending[2].Sequence != 3 {
+		t.Fatalf("unexpected sequences %d, %d", pending[1].Sequence, pending[2].Sequence)
+	}
+	merged, err := protocol.DecodeBufferDelta(pending[1].Payload)
+	if err != nil {
+		t.Fatalf("decode merged: %v", err)
+	}
+	if merged.PaneID != flood || merged.Revision != 4 || len(merged.Rows) != 2 {
+		t.Fatalf("unexpected merged delta %+v", merged)
+	}
+	if merged.Rows[0].Spans[0].Text != "d" || merged.Rows[1].Spans[0].Text != "c" {
+		t.Fatalf("merged rows carry stale content: %+v", merged.Rows)
+	}
+	if got := session.Stats().CoalescedDiffs; got != 2 {
+		t.Fatalf("CoalescedDiffs = %d, want 2", got)
+	}
+
+	// An image packet is an ordering barrier.
+	_ = session.EnqueueImage(uint8(protocol.MsgImagePlace), []byte{0})
+	_ = session.EnqueueDiff(row(flood, 5, 0, "e"))
+	if got := len(session.Pending(0)); got != 5 {
+		t.Fatalf("expected delta after image to queue separately, got %d packets", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/server/snapshot_generations.go
+// Summary: Rotating generations of the session snapshot for point-in-time
+//   restore.
+// Usage: SnapshotStore.Save records a generation whenever the layout
+//   changes; `texelation --list-snapshots` / `--restore-snapshot ID` and the
+//   in-app snapshot picker read them back.
+// Notes: Generations live in snapshots/ next to snapshot.json, one file per
+//   distinct layout, named by its UTC save time plus a counter suffix
+//   (-2, -3, ...) when several land in the same second. A layout that is already
+//   kept moves to the front instead of repeating, so switching back and
+//   forth does not push older layouts out. Pane titles and app state are
+//   not part of the layout: a shell retitling itself is not a new
+//   generation.
+
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+)
+
+// DefaultSnapshotGenerations is how many layouts a SnapshotStore keeps.
+const DefaultSnapshotGenerations = 20
+
+// generationIDLayout formats a generation's save time into its ID.
+const generationIDLayout = "20060102-150405"
+
+// SetGenerations sets how many generations are kept; 0 keeps none. Older
+// generations are pruned on the next save.
+func (s *SnapshotStore) SetGenerations(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keep = max(n, 0)
+}
+
+// Generations lists the kept generations, newest first. Unreadable
+// generation files are skipped.
+func (s *SnapshotStore) Generations() ([]texel.SnapshotGeneration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadGenerationsLocked(); err != nil {
+		return nil, err
+	}
+	ids := s.generationIDsLocked()
+	out := make([]texel.SnapshotGeneration, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		stored, err := s.loadGenerationLocked(ids[i])
+		if err != nil {
+			log.Printf("snapshot generation %s: %v", ids[i], err)
+			continue
+		}
+		out = append(out, stored.summary(ids[i]))
+	}
+	return out, nil
+}
+
+// LoadGeneration reads the generation with the given ID.
+func (s *SnapshotStore) LoadGeneration(id string) (StoredSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadGenerationLocked(id)
+}
+
+// GenerationCapture loads the generation with the given ID for the
+// desktop to apply; it implements texel.SnapshotHistory.
+func (s *SnapshotStore) GenerationCapture(id string) (texel.TreeCapture, error) {
+	stored, err := s.LoadGeneration(id)
+	if err != nil {
+		return texel.TreeCapture{}, err
+	}
+	return stored.ToTreeCapture(), nil
+}
+
+// Restore makes the generation with the given ID the snapshot the server
+// boots from. The snapshot it replaces is kept as a generation first, so
+// a restore can itself be undone. The server must not be running, or its
+// next save overwrites the restored snapshot.
+func (s *SnapshotStore) Restore(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, err := s.loadGenerationLocked(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if cur, err := os.ReadFile(s.path); err == nil {
+		var current StoredSnapshot
+		if json.Unmarshal(cur, &current) == nil && len(current.Panes) > 0 {
+			current.LayoutHash = layoutHash(current.ToTreeCapture())
+			if cur, err = json.MarshalIndent(current, "", "  "); err != nil {
+				return err
+			}
+			if err := s.recordGenerationLocked(current, cur); err != nil {
+				return fmt.Errorf("keep current snapshot: %w", err)
+			}
+		}
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+// recordGenerationLocked keeps data, the serialized form of stored, as the
+// newest generation unless the newest one already has its layout.
+func (s *SnapshotStore) recordGenerationLocked(stored StoredSnapshot, data []byte) error {
+	if s.keep <= 0 {
+		return nil
+	}
+	if err := s.loadGenerationsLocked(); err != nil {
+		return err
+	}
+	ids := s.generationIDsLocked()
+	if n := len(ids); n > 0 && s.generations[ids[n-1]] == stored.LayoutHash {
+		return nil
+	}
+	dir := s.generationsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if s.generations[id] == stored.LayoutHash {
+			if err := os.Remove(filepath.Join(dir, id+".json")); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			delete(s.generations, id)
+		}
+	}
+
+	base := stored.Timestamp.UTC().Format(generationIDLayout)
+	id := base
+	for n := 2; ; n++ {
+		if _, taken := s.generations[id]; !taken {
+			break
+		}
+		id = base + "-" + strconv.Itoa(n)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, id+".json"), data); err != nil {
+		return err
+	}
+	s.generations[id] = stored.LayoutHash
+
+	ids = s.generationIDsLocked()
+	for len(ids) > s.keep {
+		if err := os.Remove(filepath.Join(dir, ids[0]+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(s.generations, ids[0])
+		ids = ids[1:]
+	}
+	return nil
+}
+
+func (s *SnapshotStore) generationsDir() string {
+	return filepath.Join(filepath.Dir(s.path), "snapshots")
+}
+
+// loadGenerationsLocked reads the layout hashes of the kept generations
+// the first time they are needed.
+func (s *SnapshotStore) loadGenerationsLocked() error {
+	if s.generations != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(s.generationsDir())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	generations := make(map[string]string, len(entries))
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !validGenerationID(id) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.generationsDir(), e.Name()))
+		if err != nil {
+			return err
+		}
+		var stored StoredSnapshot
+		if err := json.Unmarshal(data, &stored); err != nil {
+			log.Printf("snapshot generation %s: %v", id, err)
+			continue
+		}
+		generations[id] = stored.LayoutHash
+	}
+	s.generations = generations
+	return nil
+}
+
+// generationIDsLocked returns the kept generation IDs, oldest first.
+func (s *SnapshotStore) generationIDsLocked() []string {
+	ids := make([]string, 0, len(s.generations))
+	for id := range s.generations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		bi, ni, _ := splitGenerationID(ids[i])
+		bj, nj, _ := splitGenerationID(ids[j])
+		if bi != bj {
+			return bi < bj
+		}
+		return ni < nj
+	})
+	return ids
+}
+
+func (s *SnapshotStore) loadGenerationLocked(id string) (StoredSnapshot, error) {
+	var stored StoredSnapshot
+	if !validGenerationID(id) {
+		return stored, fmt.Errorf("bad snapshot ID %q (want YYYYMMDD-HHMMSS or YYYYMMDD-HHMMSS-N)", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.generationsDir(), id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return stored, fmt.Errorf("no snapshot %s", id)
+	}
+	if err != nil {
+		return stored, err
+	}
+	err = json.Unmarshal(data, &stored)
+	return stored, err
+}
+
+func validGenerationID(id string) bool {
+	_, _, ok := splitGenerationID(id)
+	return ok
+}
+
+// splitGenerationID splits an ID into its save time and its counter, 1
+// for the first generation saved in that second.
+func splitGenerationID(id string) (string, int, bool) {
+	if len(id) < len(generationIDLayout) {
+		return "", 0, false
+	}
+	base, suffix := id[:len(generationIDLayout)], id[len(generationIDLayout):]
+	if _, err := time.Parse(generationIDLayout, base); err != nil {
+		return "", 0, false
+	}
+	if suffix == "" {
+		return base, 1, true
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(suffix, "-"))
+	if err != nil || n < 2 || suffix != "-"+strconv.Itoa(n) {
+		return "", 0, false
+	}
+	return base, n, true
+}
+
+// layoutHash identifies the layout of capture: every workspace tree in ID
+// order, hashed with hashTreeCapture, and the ID and app type of the pane
+// in each leaf. Panes outside the trees (status panes) do not count.
+func layoutHash(capture texel.TreeCapture) string {
+	hasher := sha1.New()
+	ids := make([]int, 0, len(capture.WorkspaceRoots))
+	for id := range capture.WorkspaceRoots {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		root := capture.WorkspaceRoots[id]
+		_ = binary.Write(hasher, binary.LittleEndian, int32(id))
+		hashTreeCapture(root, hasher)
+		hashLeafPanes(root, capture.Panes, hasher)
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func hashLeafPanes(node *texel.TreeNodeCapture, panes []texel.PaneSnapshot, hasher hash.Hash) {
+	if node == nil {
+		return
+	}
+	if len(node.Children) == 0 {
+		if node.PaneIndex >= 0 && node.PaneIndex < len(panes) {
+			hasher.Write(panes[node.PaneIndex].ID[:])
+			hasher.Write([]byte(panes[node.PaneIndex].AppType))
+		}
+		return
+	}
+	for _, child := range node.Children {
+		hashLeafPanes(child, panes, hasher)
+	}
+}
+
+// summary describes the generation for listings: its workspaces and the
+// titles of their panes.
+func (s StoredSnapshot) summary(id string) texel.SnapshotGeneration {
+	g := texel.SnapshotGeneration{ID: id, SavedAt: s.Timestamp}
+	roots := s.Workspaces
+	if len(roots) == 0 {
+		roots = map[int]StoredNode{1: s.Tree}
+	}
+	names := make(map[int]string, len(s.WorkspaceMetadata))
+	for _, meta := range s.WorkspaceMetadata {
+		names[meta.ID] = meta.Name
+	}
+	ids := make([]int, 0, len(roots))
+	for wsID := range roots {
+		ids = append(ids, wsID)
+	}
+	sort.Ints(ids)
+	for _, wsID := range ids {
+		g.Workspaces = append(g.Workspaces, texel.SnapshotWorkspace{
+			ID:         wsID,
+			Name:       names[wsID],
+			PaneTitles: s.paneTitles(roots[wsID], nil),
+		})
+	}
+	return g
+}
+
+func (s StoredSnapshot) paneTitles(node StoredNode, titles []string) []string {
+	if len(node.Children) == 0 {
+		if node.PaneIndex >= 0 && node.PaneIndex < len(s.Panes) {
+			p := s.Panes[node.PaneIndex]
+			title := p.Title
+			if title == "" {
+				title = p.AppType
+			}
+			titles = append(titles, title)
+		}
+		return titles
+	}
+	for _, child := range node.Children {
+		titles = s.paneTitles(child, titles)
+	}
+	return titles
+}
@@ -0,0 +1,185 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/server/snapshot_generations_test.go
+// Summary: Exercises snapshot generations: deduplication, rotation and restore.
+// Usage: Executed during `go test` to guard against regressions.
+
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/framegrace/texelation/texel"
+)
+
+func generationCapture(titles ...string) *texel.TreeCapture {
+	capture := &texel.TreeCapture{WorkspaceRoots: map[int]*texel.TreeNodeCapture{}}
+	root := &texel.TreeNodeCapture{PaneIndex: -1, Split: texel.Vertical}
+	for i, title := range titles {
+		capture.Panes = append(capture.Panes, texel.PaneSnapshot{ID: [16]byte{byte(i + 1)}, Title: title, AppType: "test"})
+		root.Children = append(root.Children, &texel.TreeNodeCapture{PaneIndex: i})
+		root.SplitRatios = append(root.SplitRatios, 1/float64(len(titles)))
+	}
+	if len(titles) == 1 {
+		root = root.Children[0]
+	}
+	capture.WorkspaceRoots[1] = root
+	capture.Root = root
+	capture.WorkspaceMetadata = []texel.WorkspaceMetadata{{ID: 1, Name: "main"}}
+	return capture
+}
+
+func TestSnapshotGenerationsDeduplicateAndRotate(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSnapshotStore(filepath.Join(dir, "snapshot.json"))
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	ids := func() []string {
+		gens, err := store.Generations()
+		if err != nil {
+			t.Fatalf("Generations: %v", err)
+		}
+		var out []string
+		for _, g := range gens {
+			out = append(out, g.ID)
+		}
+		return out
+	}
+
+	save := func(c *texel.TreeCapture) {
+		if err := store.Save(c); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+	save(generationCapture("vim"))
+	save(generationCapture("vim: retitled")) // same layout
+	if got := ids(); len(got) != 1 || got[0] != "20260501-090100" {
+		t.Fatalf("generations = %v, want only the first save", got)
+	}
+
+	save(generationCapture("vim", "htop"))
+	save(generationCapture("vim")) // back to the first layout
+	if got := ids(); strings.Join(got, " ") != "20260501-090400 20260501-090300" {
+		t.Fatalf("generations = %v, want the repeated layout moved to the front", got)
+	}
+
+	gens, _ := store.Generations()
+	if ws := gens[1].Workspaces; len(ws) != 1 || ws[0].Name != "main" || strings.Join(ws[0].PaneTitles, ",") != "vim,htop" {
+		t.Fatalf("summary = %+v, want workspace main with vim,htop", ws)
+	}
+
+	store.SetGenerations(2)
+	save(generationCapture("a", "b", "c"))
+	if got := ids(); strings.Join(got, " ") != "20260501-090500 20260501-090400" {
+		t.Fatalf("generations = %v, want the oldest pruned", got)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "snapshots"))
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 generation files, got %d", len(entries))
+	}
+
+	// A fresh store reads the kept generations back.
+	if gens, err := NewSnapshotStore(filepath.Join(dir, "snapshot.json")).Generations(); err != nil || len(gens) != 2 {
+		t.Fatalf("reopened store lists %d generations (%v), want 2", len(gens), err)
+	}
+}
+
+func TestSnapshotGenerationsSavedInTheSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSnapshotStore(filepath.Join(dir, "snapshot.json"))
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+	for _, titles := range [][]string{{"vim"}, {"vim", "htop"}, {"a", "b", "c"}} {
+		if err := store.Save(generationCapture(titles...)); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+	gens, err := NewSnapshotStore(filepath.Join(dir, "snapshot.json")).Generations()
+	if err != nil {
+		t.Fatalf("Generations: %v", err)
+	}
+	var got []string
+	for _, g := range gens {
+		got = append(got, g.ID)
+	}
+	if strings.Join(got, " ") != "20260501-090000-3 20260501-090000-2 20260501-090000" {
+		t.Fatalf("generations = %v, want three newest first", got)
+	}
+	if stored, err := store.LoadGeneration("20260501-090000-2"); err != nil || len(stored.Panes) != 2 {
+		t.Fatalf("LoadGeneration(-2) = %d panes, %v; want the vim,htop layout", len(stored.Panes), err)
+	}
+
+	for _, id := range []string{"20260501-090000-1", "20260501-090000-02", "20260501-090000-", "20260501-090000x2"} {
+		if validGenerationID(id) {
+			t.Errorf("validGenerationID(%q) = true", id)
+		}
+	}
+}
+
+func TestSnapshotRestoreKeepsReplacedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.json")
+	store := NewSnapshotStore(path)
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	if err := store.Save(generationCapture("vim", "htop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(generationCapture("vim")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Restore("20260501-090100"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Panes) != 2 || loaded.Panes[1].Title != "htop" {
+		t.Fatalf("snapshot after restore has panes %+v, want vim and htop", loaded.Panes)
+	}
+	capture := loaded.ToTreeCapture()
+	if len(capture.WorkspaceRoots[1].Children) != 2 {
+		t.Fatalf("restored layout lost its split")
+	}
+	if gens, _ := store.Generations(); len(gens) != 2 {
+		t.Fatalf("expected both layouts kept, got %d", len(gens))
+	}
+
+	if err := store.Restore("../snapshot"); err == nil {
+		t.Fatal("expected a bad ID to be rejected")
+	}
+	if err := store.Restore("20200101-000000"); err == nil {
+		t.Fatal("expected an unknown ID to be rejected")
+	}
+
+	// Snapshots written before generations existed have no layout hash;
+	// restoring over one keeps it as a generation.
+	legacy := StoredSnapshot{Timestamp: clock.Add(time.Hour), Panes: []StoredPane{{ID: "0a", Title: "old"}}, Tree: StoredNode{PaneIndex: 0, Split: "none"}}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Restore("20260501-090200"); err != nil {
+		t.Fatalf("Restore over legacy snapshot: %v", err)
+	}
+	gens, _ := store.Generations()
+	if len(gens) != 3 || gens[0].Workspaces[0].PaneTitles[0] != "old" {
+		t.Fatalf("expected the legacy snapshot kept as newest generation, got %+v", gens)
+	}
+}
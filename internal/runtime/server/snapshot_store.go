@@ -25,9 +25,13 @@ import (
 )
 
 // SnapshotStore persists pane snapshots to disk with a content hash for integrity checks.
+// It also keeps earlier layouts as generations; see snapshot_generations.go.
 type SnapshotStore struct {
-	path string
-	mu   sync.Mutex
+	path        string
+	keep        int               // generations to keep; 0 disables them
+	generations map[string]string // generation ID -> layout hash; nil until read
+	now         func() time.Time
+	mu          sync.Mutex
 }
 
 // StoredWorkspaceMetadata captures the display name and color for a workspace.
@@ -46,6 +50,7 @@ type StoredSnapshot struct {
 	Workspaces        map[int]StoredNode        `json:"workspaces,omitempty"`
 	ActiveWorkspaceID int                       `json:"active_workspace_id"`
 	WorkspaceMetadata []StoredWorkspaceMetadata `json:"workspace_metadata,omitempty"`
+	LayoutHash        string                    `json:"layout_hash,omitempty"`
 }
 
 // StoredNode captures the persisted tree layout.
@@ -54,6 +59,9 @@ type StoredNode struct {
 	Split     string       `json:"split"`
 	Ratios    []float64    `json:"ratios,omitempty"`
 	Children  []StoredNode `json:"children,omitempty"`
+	ActiveTab int          `json:"active_tab,omitempty"`
+	// UntabbedSplit is the split a tabbed node returns to when toggled.
+	UntabbedSplit string `json:"untabbed_split,omitempty"`
 }
 
 // StoredPane represents a single pane's textual content.
@@ -70,7 +78,7 @@ type StoredPane struct {
 }
 
 func NewSnapshotStore(path string) *SnapshotStore {
-	return &SnapshotStore{path: path}
+	return &SnapshotStore{path: path, keep: DefaultSnapshotGenerations, now: time.Now}
 }
 
 // Save writes the current snapshots to disk, computing a SHA-1 hash for integrity.
@@ -79,7 +87,7 @@ func (s *SnapshotStore) Save(capture *texel.TreeCapture) error {
 	defer s.mu.Unlock()
 
 	stored := StoredSnapshot{
-		Timestamp:         time.Now().UTC(),
+		Timestamp:         s.now().UTC(),
 		Panes:             make([]StoredPane, len(capture.Panes)),
 		Workspaces:        make(map[int]StoredNode),
 		ActiveWorkspaceID: capture.ActiveWorkspaceID,
@@ -148,6 +156,7 @@ func (s *SnapshotStore) Save(capture *texel.TreeCapture) error {
 	}
 
 	stored.Hash = hex.EncodeToString(hasher.Sum(nil))
+	stored.LayoutHash = layoutHash(stored.ToTreeCapture())
 
 	data, err := json.MarshalIndent(stored, "", "  ")
 	if err != nil {
@@ -157,13 +166,19 @@ func (s *SnapshotStore) Save(capture *texel.TreeCapture) error {
 	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
 		return err
 	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	return s.recordGenerationLocked(stored, data)
+}
 
-	// Atomic write: write to temp file then rename
-	tmpPath := s.path + ".tmp"
+// writeFileAtomic writes to a temp file then renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
 		return err
 	}
-	return os.Rename(tmpPath, s.path)
+	return os.Rename(tmpPath, path)
 }
 
 // Load retrieves the most recent stored snapshot from disk.
@@ -246,6 +261,14 @@ func (sn StoredNode) toTreeNodeCapture() *texel.TreeNodeCapture {
 		node.Split = texel.Vertical
 	case "horizontal":
 		node.Split = texel.Horizontal
+	case "tabbed":
+		node.Split = texel.Tabbed
+		node.ActiveTab = sn.ActiveTab
+		if sn.UntabbedSplit == "horizontal" {
+			node.UntabbedSplit = texel.Horizontal
+		} else {
+			node.UntabbedSplit = texel.Vertical
+		}
 	default:
 		// SplitNone default
 	}
@@ -335,6 +358,10 @@ func hashTreeCapture(node *texel.TreeNodeCapture, hasher hash.Hash) {
 	for _, ratio := range node.SplitRatios {
 		_ = binary.Write(hasher, binary.LittleEndian, ratio)
 	}
+	if node.Split == texel.Tabbed {
+		_ = binary.Write(hasher, binary.LittleEndian, int32(node.ActiveTab))
+		hasher.Write([]byte{byte(node.UntabbedSplit)})
+	}
 	for _, child := range node.Children {
 		hashTreeCapture(child, hasher)
 	}
@@ -376,6 +403,13 @@ func storeTreeNode(node *texel.TreeNodeCapture) StoredNode {
 		stored.Split = "vertical"
 	case texel.Horizontal:
 		stored.Split = "horizontal"
+	case texel.Tabbed:
+		stored.Split = "tabbed"
+		stored.ActiveTab = node.ActiveTab
+		stored.UntabbedSplit = "vertical"
+		if node.UntabbedSplit == texel.Horizontal {
+			stored.UntabbedSplit = "horizontal"
+		}
 	default:
 		stored.Split = "none"
 	}
@@ -395,6 +429,13 @@ func (sn StoredNode) toProtocolNode() protocol.TreeNodeSnapshot {
 		node.Split = protocol.SplitVertical
 	case "horizontal":
 		node.Split = protocol.SplitHorizontal
+	case "tabbed":
+		node.Split = protocol.SplitTabbed
+		node.ActiveTab = uint16(sn.ActiveTab)
+		node.UntabbedSplit = protocol.SplitVertical
+		if sn.UntabbedSplit == "horizontal" {
+			node.UntabbedSplit = protocol.SplitHorizontal
+		}
 	default:
 		node.Split = protocol.SplitNone
 	}
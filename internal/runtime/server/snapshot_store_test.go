@@ -224,3 +224,31 @@ func TestStoreTreeNodeRoundTrip(t *testing.T) {
 		t.Fatalf("expected empty node defaults, got %+v", empty)
 	}
 }
+
+func TestStoreTreeNodeTabbedRoundTrip(t *testing.T) {
+	root := &texel.TreeNodeCapture{
+		PaneIndex:   -1,
+		Split:       texel.Tabbed,
+		SplitRatios: []float64{0.5, 0.5},
+		ActiveTab:   1,
+		// Not the vertical default of a missing value, so losing it shows.
+		UntabbedSplit: texel.Horizontal,
+		Children: []*texel.TreeNodeCapture{
+			{PaneIndex: 0},
+			{PaneIndex: 1},
+		},
+	}
+
+	stored := storeTreeNode(root)
+	if stored.Split != "tabbed" || stored.ActiveTab != 1 || stored.UntabbedSplit != "horizontal" {
+		t.Fatalf("expected tabbed node on tab 1, got %+v", stored)
+	}
+	back := stored.toTreeNodeCapture()
+	if back.Split != texel.Tabbed || back.ActiveTab != 1 || back.UntabbedSplit != texel.Horizontal {
+		t.Fatalf("expected tabbed capture on tab 1, got %+v", back)
+	}
+	proto := stored.toProtocolNode()
+	if proto.Split != protocol.SplitTabbed || proto.ActiveTab != 1 || proto.UntabbedSplit != protocol.SplitHorizontal {
+		t.Fatalf("expected protocol tabbed node on tab 1, got %+v", proto)
+	}
+}
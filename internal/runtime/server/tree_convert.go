@@ -75,6 +75,14 @@ func protocolNodeToCapture(node protocol.TreeNodeSnapshot) *texel.TreeNodeCaptur
 		capture.Split = texel.Vertical
 	case protocol.SplitHorizontal:
 		capture.Split = texel.Horizontal
+	case protocol.SplitTabbed:
+		capture.Split = texel.Tabbed
+		capture.ActiveTab = int(node.ActiveTab)
+		if node.UntabbedSplit == protocol.SplitHorizontal {
+			capture.UntabbedSplit = texel.Horizontal
+		} else {
+			capture.UntabbedSplit = texel.Vertical
+		}
 	default:
 		capture.Split = texel.Vertical
 	}
@@ -101,10 +109,12 @@ func protocolNodeToCapture(node protocol.TreeNodeSnapshot) *texel.TreeNodeCaptur
 
 func cloneProtocolTree(node protocol.TreeNodeSnapshot) protocol.TreeNodeSnapshot {
 	clone := protocol.TreeNodeSnapshot{
-		PaneIndex:   node.PaneIndex,
-		Split:       node.Split,
-		SplitRatios: make([]float32, len(node.SplitRatios)),
-		Children:    make([]protocol.TreeNodeSnapshot, len(node.Children)),
+		PaneIndex:     node.PaneIndex,
+		Split:         node.Split,
+		SplitRatios:   make([]float32, len(node.SplitRatios)),
+		Children:      make([]protocol.TreeNodeSnapshot, len(node.Children)),
+		ActiveTab:     node.ActiveTab,
+		UntabbedSplit: node.UntabbedSplit,
 	}
 	copy(clone.SplitRatios, node.SplitRatios)
 	for i, child := range node.Children {
@@ -149,6 +159,13 @@ func buildProtocolTreeNode(node *texel.TreeNodeCapture) protocol.TreeNodeSnapsho
 		protoNode.Split = protocol.SplitVertical
 	case texel.Horizontal:
 		protoNode.Split = protocol.SplitHorizontal
+	case texel.Tabbed:
+		protoNode.Split = protocol.SplitTabbed
+		protoNode.ActiveTab = uint16(node.ActiveTab)
+		protoNode.UntabbedSplit = protocol.SplitVertical
+		if node.UntabbedSplit == texel.Horizontal {
+			protoNode.UntabbedSplit = protocol.SplitHorizontal
+		}
 	default:
 		protoNode.Split = protocol.SplitNone
 	}
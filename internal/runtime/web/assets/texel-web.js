@@ -0,0 +1,321 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/assets/texel-web.js
+// Summary: Canvas renderer and input forwarder for texel-web.
+// Notes: Wire format is documented in internal/runtime/web/frame.go and
+//   input.go. The gateway sends pane rects and pane rows; the page keeps
+//   both and composes the screen, redrawing only the rows a message
+//   touches. No external dependencies so the page works offline.
+
+(function () {
+  "use strict";
+
+  var FONT_SIZE = 15;
+  var FONT = FONT_SIZE + "px ui-monospace, Menlo, Consolas, 'DejaVu Sans Mono', monospace";
+  var DEFAULT_FG = "#d0d0d0";
+  var DEFAULT_BG = "#000000";
+
+  var ATTR_BOLD = 1, ATTR_DIM = 2, ATTR_ITALIC = 4, ATTR_UNDERLINE = 8,
+      ATTR_REVERSE = 16, ATTR_STRIKE = 32;
+
+  var MOD_SHIFT = 1, MOD_CTRL = 2, MOD_ALT = 4, MOD_META = 8;
+  var WHEEL_UP = 0x100, WHEEL_DOWN = 0x200;
+
+  var canvas = document.getElementById("screen");
+  var ctx = canvas.getContext("2d");
+  var status = document.getElementById("status");
+
+  var cellW = 8, cellH = 18, baseline = 14;
+  var cols = 80, rows = 24;
+  var desktopBg = DEFAULT_BG;
+  var styles = [];
+  var panes = {};
+  var order = [];
+  var placements = [];
+  var images = {};
+  var ws = null;
+
+  function measure() {
+    ctx.font = FONT;
+    var m = ctx.measureText("M");
+    cellW = Math.ceil(m.width);
+    cellH = Math.ceil(FONT_SIZE * 1.25);
+    baseline = Math.round(FONT_SIZE * 0.95);
+  }
+
+  function fitCanvas(w, h) {
+    var dpr = window.devicePixelRatio || 1;
+    canvas.width = w * cellW * dpr;
+    canvas.height = h * cellH * dpr;
+    canvas.style.width = w * cellW + "px";
+    canvas.style.height = h * cellH + "px";
+    ctx.setTransform(dpr, 0, 0, dpr, 0, 0);
+    ctx.textBaseline = "alphabetic";
+  }
+
+  function gridSize() {
+    return {
+      w: Math.max(1, Math.floor(window.innerWidth / cellW)),
+      h: Math.max(1, Math.floor(window.innerHeight / cellH))
+    };
+  }
+
+  function showStatus(text) {
+    status.textContent = text;
+    status.style.display = text ? "block" : "none";
+  }
+
+  function colors(st) {
+    var fg = (st && st.fg) || DEFAULT_FG;
+    var bg = (st && st.bg) || DEFAULT_BG;
+    if (st && (st.a & ATTR_REVERSE)) {
+      var t = fg; fg = bg; bg = t;
+    }
+    return { fg: fg, bg: bg };
+  }
+
+  // drawRun draws one run of a pane row whose top-left cell is at
+  // screen cell (ox, oy).
+  function drawRun(ox, oy, run) {
+    var x = run[0], n = run[1], st = styles[run[2]], text = run[3];
+    var c = colors(st);
+    var a = st ? st.a : 0;
+    var px = (ox + x) * cellW, py = oy * cellH;
+    ctx.fillStyle = c.bg;
+    ctx.fillRect(px, py, n * cellW, cellH);
+    if (text.trim() === "") {
+      return;
+    }
+    ctx.font = (a & ATTR_ITALIC ? "italic " : "") + (a & ATTR_BOLD ? "bold " : "") + FONT;
+    ctx.fillStyle = c.fg;
+    ctx.globalAlpha = a & ATTR_DIM ? 0.6 : 1;
+    var chars = Array.from(text);
+    if (chars.length === n) {
+      for (var i = 0; i < n; i++) {
+        if (chars[i] !== " ") {
+          ctx.fillText(chars[i], px + i * cellW, py + baseline);
+        }
+      }
+    } else {
+      ctx.fillText(text, px, py + baseline, n * cellW);
+    }
+    ctx.globalAlpha = 1;
+    if (a & ATTR_UNDERLINE) {
+      ctx.fillRect(px, py + cellH - 2, n * cellW, 1);
+    }
+    if (a & ATTR_STRIKE) {
+      ctx.fillRect(px, py + Math.round(cellH / 2), n * cellW, 1);
+    }
+  }
+
+  // drawRow redraws screen row y: the desktop, then every pane crossing
+  // it in draw order, each clipped to its own rect.
+  function drawRow(y) {
+    ctx.fillStyle = desktopBg;
+    ctx.fillRect(0, y * cellH, cols * cellW, cellH);
+    for (var i = 0; i < order.length; i++) {
+      var p = panes[order[i]];
+      if (!p || y < p.y || y >= p.y + p.h) {
+        continue;
+      }
+      ctx.save();
+      ctx.beginPath();
+      ctx.rect(p.x * cellW, y * cellH, p.w * cellW, cellH);
+      ctx.clip();
+      ctx.fillStyle = DEFAULT_BG;
+      ctx.fillRect(p.x * cellW, y * cellH, p.w * cellW, cellH);
+      var runs = p.rows[y - p.y] || [];
+      for (var j = 0; j < runs.length; j++) {
+        drawRun(p.x, y, runs[j]);
+      }
+      ctx.restore();
+    }
+  }
+
+  function drawImages() {
+    var sorted = placements.slice().sort(function (a, b) { return a[5] - b[5]; });
+    for (var i = 0; i < sorted.length; i++) {
+      var p = sorted[i], img = images[p[0]];
+      if (img && img.complete && img.naturalWidth > 0) {
+        ctx.drawImage(img, p[1] * cellW, p[2] * cellH, p[3] * cellW, p[4] * cellH);
+      }
+    }
+  }
+
+  function redrawAll() {
+    for (var y = 0; y < rows; y++) {
+      drawRow(y);
+    }
+    drawImages();
+  }
+
+  function applyTree(list) {
+    var next = {};
+    order = [];
+    list.forEach(function (t) {
+      var p = panes[t[0]] || { rows: [] };
+      p.x = t[1]; p.y = t[2]; p.w = t[3]; p.h = t[4];
+      next[t[0]] = p;
+      order.push(t[0]);
+    });
+    panes = next;
+    redrawAll();
+  }
+
+  function applyRows(msg) {
+    (msg.s || []).forEach(function (s) {
+      styles[s[0]] = { fg: s[1], bg: s[2], a: s[3] };
+    });
+    var p = panes[msg.p];
+    if (!p) {
+      p = panes[msg.p] = { x: 0, y: 0, w: 0, h: 0, rows: [] };
+    }
+    var dirty = {};
+    msg.r.forEach(function (r) {
+      p.rows[r[0]] = r.slice(1);
+      dirty[p.y + r[0]] = true;
+    });
+    Object.keys(dirty).forEach(function (y) {
+      if (y < rows) {
+        drawRow(+y);
+      }
+    });
+    if (placements.length) {
+      drawImages();
+    }
+  }
+
+  function receiveImage(buf) {
+    var view = new DataView(buf);
+    if (view.byteLength < 5 || view.getUint8(0) !== 0x49) { // 'I'
+      return;
+    }
+    var sid = view.getUint32(1);
+    var img = new Image();
+    img.onload = redrawAll;
+    img.src = URL.createObjectURL(new Blob([buf.slice(5)]));
+    images[sid] = img;
+  }
+
+  function send(msg) {
+    if (ws && ws.readyState === WebSocket.OPEN) {
+      ws.send(JSON.stringify(msg));
+    }
+  }
+
+  function mods(e) {
+    return (e.shiftKey ? MOD_SHIFT : 0) | (e.ctrlKey ? MOD_CTRL : 0) |
+      (e.altKey ? MOD_ALT : 0) | (e.metaKey ? MOD_META : 0);
+  }
+
+  function cellAt(e) {
+    var r = canvas.getBoundingClientRect();
+    return {
+      x: Math.max(0, Math.min(cols - 1, Math.floor((e.clientX - r.left) / cellW))),
+      y: Math.max(0, Math.min(rows - 1, Math.floor((e.clientY - r.top) / cellH)))
+    };
+  }
+
+  function connect() {
+    var g = gridSize();
+    var scheme = location.protocol === "https:" ? "wss:" : "ws:";
+    ws = new WebSocket(scheme + "//" + location.host + "/ws?cols=" + g.w + "&rows=" + g.h);
+    ws.binaryType = "arraybuffer";
+    ws.onopen = function () { showStatus(""); };
+    ws.onmessage = function (ev) {
+      if (typeof ev.data !== "string") {
+        receiveImage(ev.data);
+        return;
+      }
+      var msg = JSON.parse(ev.data);
+      if (msg.t === "tree") {
+        applyTree(msg.p);
+      } else if (msg.t === "rows") {
+        applyRows(msg);
+      } else if (msg.t === "img") {
+        placements = msg.i;
+        redrawAll();
+      } else if (msg.t === "bg") {
+        desktopBg = msg.d || DEFAULT_BG;
+        redrawAll();
+      } else if (msg.t === "clip") {
+        if (navigator.clipboard && navigator.clipboard.writeText) {
+          navigator.clipboard.writeText(msg.d).catch(function () {});
+        }
+      } else if (msg.t === "bye") {
+        showStatus("Disconnected: " + msg.d);
+      }
+    };
+    ws.onclose = function () {
+      if (status.style.display === "none") {
+        showStatus("Disconnected");
+      }
+    };
+  }
+
+  window.addEventListener("keydown", function (e) {
+    if (e.isComposing || ["Shift", "Control", "Alt", "Meta", "Dead"].indexOf(e.key) >= 0) {
+      return;
+    }
+    // Leave the platform paste shortcuts to the paste event.
+    if ((e.metaKey && e.key === "v") || (e.ctrlKey && e.shiftKey && e.key === "V")) {
+      return;
+    }
+    e.preventDefault();
+    send({ t: "key", k: e.key, m: mods(e) });
+  });
+
+  window.addEventListener("paste", function (e) {
+    e.preventDefault();
+    var text = (e.clipboardData || window.clipboardData).getData("text");
+    if (text) {
+      send({ t: "paste", d: text });
+    }
+  });
+
+  var lastMouse = "";
+  function mouse(e) {
+    var c = cellAt(e);
+    var key = c.x + "," + c.y + "," + e.buttons;
+    if (e.type === "mousemove" && key === lastMouse) {
+      return;
+    }
+    lastMouse = key;
+    send({ t: "mouse", x: c.x, y: c.y, b: e.buttons, m: mods(e) });
+  }
+  canvas.addEventListener("mousedown", function (e) { canvas.focus(); mouse(e); });
+  window.addEventListener("mouseup", mouse);
+  canvas.addEventListener("mousemove", mouse);
+  canvas.addEventListener("contextmenu", function (e) { e.preventDefault(); });
+  canvas.addEventListener("wheel", function (e) {
+    e.preventDefault();
+    var c = cellAt(e);
+    var b = e.deltaY < 0 ? WHEEL_UP : WHEEL_DOWN;
+    send({ t: "mouse", x: c.x, y: c.y, b: b | e.buttons, m: mods(e) });
+  }, { passive: false });
+
+  var resizeTimer = null;
+  window.addEventListener("resize", function () {
+    clearTimeout(resizeTimer);
+    resizeTimer = setTimeout(function () {
+      var g = gridSize();
+      if (g.w !== cols || g.h !== rows) {
+        cols = g.w;
+        rows = g.h;
+        fitCanvas(cols, rows);
+        redrawAll();
+        send({ t: "resize", w: g.w, h: g.h });
+      }
+    }, 50);
+  });
+
+  measure();
+  var g = gridSize();
+  cols = g.w;
+  rows = g.h;
+  fitCanvas(cols, rows);
+  redrawAll();
+  canvas.focus();
+  connect();
+})();
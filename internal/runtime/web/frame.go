@@ -0,0 +1,226 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/frame.go
+// Summary: Compact browser stream encoding of panes, rows and images.
+// Usage: session translates each server message into one of these and
+//        sends it as a WebSocket text message; streamEncoder keeps the
+//        state (styles, images) the browser already has.
+// Notes: Wire format (JSON, short keys to keep messages small):
+//
+//   {"t":"tree","p":[[pid,x,y,w,h],...]}    panes in draw order
+//   {"t":"rows","p":pid,
+//    "s":[[id,fg,bg,attrs],...],            new style table entries
+//    "r":[[y,[x,n,sid,text],...],...]}      pane rows as runs
+//   {"t":"img","i":[[sid,x,y,w,h,z],...]}   image placements
+//   {"t":"bg","d":css}                      desktop background
+//   {"t":"clip","d":text}                   clipboard from the server
+//   {"t":"bye","d":reason}                  the session ended
+//
+//   Row and run coordinates are pane-relative; tree and img are in
+//   screen cells. Style 0 is the terminal default and never sent. A run
+//   covers n cells. When text has exactly n code points each one
+//   occupies a cell; otherwise (wide or combining) text fills all n.
+//   Image bytes travel separately as binary messages: 'I', uint32 sid
+//   (big endian), then the encoded image.
+
+package webruntime
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/framegrace/texelation/protocol"
+)
+
+// Style attribute bits as understood by the browser renderer.
+const (
+	attrBold = 1 << iota
+	attrDim
+	attrItalic
+	attrUnderline
+	attrReverse
+	attrStrike
+	attrBlink
+)
+
+// cell is one pane cell as the browser will draw it.
+type cell struct {
+	ch  rune
+	sid int
+}
+
+// rowsMsg updates some rows of one pane.
+type rowsMsg struct {
+	T      string  `json:"t"`
+	P      int     `json:"p"`
+	Styles [][]any `json:"s,omitempty"`
+	Rows   [][]any `json:"r"`
+}
+
+// styleKey is the static part of a protocol style. Dynamic colours are
+// drawn with their base colour.
+type styleKey struct {
+	attrs            uint16
+	fgModel, bgModel protocol.ColorModel
+	fgValue, bgValue uint32
+}
+
+// streamEncoder tracks what the browser has so only new styles and
+// images are sent.
+type streamEncoder struct {
+	styles     map[styleKey]int
+	sentImages map[uint32]bool
+	placements [][]int
+}
+
+func newStreamEncoder() *streamEncoder {
+	return &streamEncoder{
+		styles:     map[styleKey]int{{}: 0},
+		sentImages: make(map[uint32]bool),
+	}
+}
+
+// styleIDs maps a delta's style table to stream style IDs, adding the
+// styles the browser has not seen to msg.
+func (e *streamEncoder) styleIDs(msg *rowsMsg, entries []protocol.StyleEntry) []int {
+	ids := make([]int, len(entries))
+	for i, entry := range entries {
+		key := styleKey{
+			attrs:   entry.AttrFlags &^ protocol.AttrHasDynamic,
+			fgModel: entry.FgModel, fgValue: entry.FgValue,
+			bgModel: entry.BgModel, bgValue: entry.BgValue,
+		}
+		id, ok := e.styles[key]
+		if !ok {
+			id = len(e.styles)
+			e.styles[key] = id
+			msg.Styles = append(msg.Styles, []any{id,
+				cssColor(key.fgModel, key.fgValue), cssColor(key.bgModel, key.bgValue), styleAttrs(key.attrs)})
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// cssColor returns the CSS colour for a protocol colour, or "" for the
+// terminal default.
+func cssColor(model protocol.ColorModel, value uint32) string {
+	switch model {
+	case protocol.ColorModelRGB:
+		return fmt.Sprintf("#%06X", value&0xFFFFFF)
+	case protocol.ColorModelANSI16, protocol.ColorModelANSI256:
+		return tcell.PaletteColor(int(value)).CSS()
+	default:
+		return ""
+	}
+}
+
+func styleAttrs(flags uint16) int {
+	var out int
+	if flags&protocol.AttrBold != 0 {
+		out |= attrBold
+	}
+	if flags&protocol.AttrDim != 0 {
+		out |= attrDim
+	}
+	if flags&protocol.AttrItalic != 0 {
+		out |= attrItalic
+	}
+	if flags&protocol.AttrUnderline != 0 {
+		out |= attrUnderline
+	}
+	if flags&protocol.AttrReverse != 0 {
+		out |= attrReverse
+	}
+	if flags&protocol.AttrBlink != 0 {
+		out |= attrBlink
+	}
+	return out
+}
+
+// encodeRow splits the first width cells of a row into runs. A wide
+// character takes its own two-cell run and hides the cell after it.
+func encodeRow(y int, row []cell, width int) []any {
+	if width > 0 && len(row) > width {
+		row = row[:width]
+	}
+	out := []any{y}
+	var (
+		runX, runN, runSID int
+		runText            []rune
+		open               bool
+	)
+	flush := func() {
+		if open {
+			out = append(out, []any{runX, runN, runSID, string(runText)})
+		}
+		open = false
+	}
+	for x := 0; x < len(row); x++ {
+		c := row[x]
+		ch := c.ch
+		if ch == 0 {
+			ch = ' '
+		}
+		if runewidth.RuneWidth(ch) == 2 && x+1 < len(row) {
+			flush()
+			out = append(out, []any{x, 2, c.sid, string(ch)})
+			x++
+			continue
+		}
+		if open && c.sid == runSID && runX+runN == x {
+			runN++
+			runText = append(runText, ch)
+			continue
+		}
+		flush()
+		runX, runN, runSID, open = x, 1, c.sid, true
+		runText = append(runText[:0], ch)
+	}
+	flush()
+	return out
+}
+
+// placedImage is one image placement in screen cells.
+type placedImage struct {
+	surfaceID  uint32
+	data       []byte
+	x, y, w, h int
+	z          int
+}
+
+// pendingImages returns binary messages for images the browser has not
+// received yet.
+func (e *streamEncoder) pendingImages(images []placedImage) [][]byte {
+	var out [][]byte
+	for _, im := range images {
+		if e.sentImages[im.surfaceID] {
+			continue
+		}
+		e.sentImages[im.surfaceID] = true
+		buf := make([]byte, 5, 5+len(im.data))
+		buf[0] = 'I'
+		binary.BigEndian.PutUint32(buf[1:], im.surfaceID)
+		out = append(out, append(buf, im.data...))
+	}
+	return out
+}
+
+// placementsChanged returns the placement list when it differs from
+// what the browser last got.
+func (e *streamEncoder) placementsChanged(images []placedImage) ([][]int, bool) {
+	pls := make([][]int, 0, len(images))
+	for _, im := range images {
+		pls = append(pls, []int{int(im.surfaceID), im.x, im.y, im.w, im.h, im.z})
+	}
+	if slices.EqualFunc(pls, e.placements, slices.Equal[[]int]) {
+		return nil, false
+	}
+	e.placements = pls
+	return pls, true
+}
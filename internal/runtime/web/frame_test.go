@@ -0,0 +1,75 @@
+package webruntime
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/framegrace/texelation/protocol"
+)
+
+func testRow(text string, sid int) []cell {
+	row := make([]cell, 0, len(text))
+	for _, r := range text {
+		row = append(row, cell{ch: r, sid: sid})
+	}
+	return row
+}
+
+func TestStreamEncoderSendsEachStyleOnce(t *testing.T) {
+	enc := newStreamEncoder()
+	bold := protocol.StyleEntry{AttrFlags: protocol.AttrBold, FgModel: protocol.ColorModelANSI16, FgValue: 1}
+	msg := &rowsMsg{}
+	ids := enc.styleIDs(msg, []protocol.StyleEntry{{}, bold})
+	if ids[0] != 0 || ids[1] != 1 {
+		t.Fatalf("ids = %v, want default 0 and a new id", ids)
+	}
+	if got := fmt.Sprint(msg.Styles); got != "[[1 #800000  1]]" {
+		t.Fatalf("styles = %s, want only the new bold style", got)
+	}
+
+	// The dynamic bit does not make a style new.
+	dynamic := bold
+	dynamic.AttrFlags |= protocol.AttrHasDynamic
+	msg = &rowsMsg{}
+	if ids := enc.styleIDs(msg, []protocol.StyleEntry{dynamic}); ids[0] != 1 || len(msg.Styles) != 0 {
+		t.Fatalf("known style resent: ids %v, styles %v", ids, msg.Styles)
+	}
+}
+
+func TestEncodeRowSplitsRunsOnStyle(t *testing.T) {
+	row := append(testRow("ab", 0), testRow("cd", 2)...)
+	if got := fmt.Sprint(encodeRow(4, row, 4)); got != "[4 [0 2 0 ab] [2 2 2 cd]]" {
+		t.Fatalf("row = %s", got)
+	}
+	// Cells past the pane width are dropped.
+	if got := fmt.Sprint(encodeRow(0, row, 3)); got != "[0 [0 2 0 ab] [2 1 2 c]]" {
+		t.Fatalf("clipped row = %s", got)
+	}
+}
+
+func TestEncodeRowGivesWideCharactersTwoCells(t *testing.T) {
+	row := testRow("a世 b", 0)
+	if got := fmt.Sprint(encodeRow(0, row, 0)); got != "[0 [0 1 0 a] [1 2 0 世] [3 1 0 b]]" {
+		t.Fatalf("row = %s", got)
+	}
+}
+
+func TestStreamEncoderImagePlacements(t *testing.T) {
+	enc := newStreamEncoder()
+	imgs := []placedImage{{surfaceID: 3, data: []byte{1}, x: 1}}
+	if pending := enc.pendingImages(imgs); len(pending) != 1 {
+		t.Fatalf("expected one upload, got %d", len(pending))
+	}
+	if pending := enc.pendingImages(imgs); len(pending) != 0 {
+		t.Fatalf("image uploaded twice")
+	}
+	if pls, changed := enc.placementsChanged(imgs); !changed || len(pls) != 1 {
+		t.Fatalf("expected placement update, got %v %v", pls, changed)
+	}
+	if _, changed := enc.placementsChanged(imgs); changed {
+		t.Fatal("unchanged placements resent")
+	}
+	if pls, changed := enc.placementsChanged(nil); !changed || len(pls) != 0 {
+		t.Fatalf("removal not sent: %v %v", pls, changed)
+	}
+}
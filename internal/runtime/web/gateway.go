@@ -0,0 +1,208 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/gateway.go
+// Summary: HTTP/WebSocket gateway that runs one protocol session per tab.
+// Usage: cmd texel-web mounts Gateway.Handler on a local listener. Each
+//        WebSocket on /ws connects to the server socket as a new client
+//        and translates tree snapshots and buffer deltas for the browser.
+// Notes: Assets are embedded; the page works without network access.
+//        Every request must name this listener in Host (a DNS-rebinding
+//        page cannot) and carry the per-launch token, either as ?token=
+//        on the printed URL or in the cookie that URL sets.
+//        The browser gets pane rects and pane rows and composes them
+//        itself; client-side effects and overlays are texel-client only.
+
+package webruntime
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const (
+	defaultCols = 80
+	defaultRows = 24
+	maxCells    = 1000
+)
+
+// Gateway serves the browser client for one texel-server socket.
+type Gateway struct {
+	socket        string
+	allowNoOrigin bool
+	token         string
+
+	// listenIP and listenPort describe the listener; Host headers must
+	// match them. Unset, only loopback hosts are accepted.
+	listenIP   net.IP
+	listenPort string
+}
+
+// NewGateway returns a gateway connecting to the server at socket, with
+// a fresh random access token.
+func NewGateway(socket string) *Gateway {
+	return &Gateway{socket: socket, token: newToken()}
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
+
+// Token returns the secret a browser must present: texel-web prints it
+// as part of the page URL.
+func (g *Gateway) Token() string {
+	return g.token
+}
+
+// SetListenAddr tells the gateway where it is listening, so requests
+// naming any other host or port are refused.
+func (g *Gateway) SetListenAddr(addr net.Addr) {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return
+	}
+	g.listenIP = net.ParseIP(host)
+	g.listenPort = port
+}
+
+// SetAllowNoOrigin lets WebSocket clients that send no Origin header
+// (scripts, not browsers) connect. Off by default.
+func (g *Gateway) SetAllowNoOrigin(allow bool) {
+	g.allowNoOrigin = allow
+}
+
+// Handler serves the embedded page and the /ws endpoint.
+func (g *Gateway) Handler() http.Handler {
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err) // embedded tree is fixed at build time
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/ws", g.serveWS)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !g.allowedHost(r.Host) {
+			http.Error(w, "unexpected Host", http.StatusForbidden)
+			return
+		}
+		if !g.authorized(w, r) {
+			http.Error(w, "missing or wrong token: open the URL texel-web printed", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost accepts "localhost" and IP literals that are loopback or
+// the listen address. Hostnames are refused: a DNS-rebinding page reaches
+// the listener under its own name, which the browser puts in Host.
+func (g *Gateway) allowedHost(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, "80"
+	}
+	if g.listenPort != "" && port != g.listenPort {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return false
+	case ip.IsLoopback():
+		return true
+	case g.listenIP == nil:
+		return false
+	case g.listenIP.IsUnspecified():
+		return true
+	default:
+		return ip.Equal(g.listenIP)
+	}
+}
+
+// authorized checks the token from the query or the cookie. A valid
+// query token also sets the cookie, so the page's own requests (script,
+// WebSocket) pass without repeating it.
+func (g *Gateway) authorized(w http.ResponseWriter, r *http.Request) bool {
+	name := "texel-web-token"
+	if g.listenPort != "" {
+		name += "-" + g.listenPort // cookies ignore the port
+	}
+	if t := r.URL.Query().Get("token"); t != "" && g.tokenMatches(t) {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: g.token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		return true
+	}
+	c, err := r.Cookie(name)
+	return err == nil && g.tokenMatches(c.Value)
+}
+
+func (g *Gateway) tokenMatches(t string) bool {
+	return subtle.ConstantTimeCompare([]byte(t), []byte(g.token)) == 1
+}
+
+func (g *Gateway) serveWS(w http.ResponseWriter, r *http.Request) {
+	cols := sizeParam(r, "cols", defaultCols)
+	rows := sizeParam(r, "rows", defaultRows)
+	ws, err := upgradeWebSocket(w, r, g.allowNoOrigin)
+	if err != nil {
+		log.Printf("texel-web: %v", err)
+		return
+	}
+
+	sess, err := dialSession(g.socket, ws, cols, rows)
+	if err != nil {
+		log.Printf("texel-web: connect: %v", err)
+		sendJSON(ws, map[string]string{"t": "bye", "d": err.Error()})
+		ws.Close()
+		return
+	}
+
+	inputDone := make(chan struct{})
+	go func() {
+		defer close(inputDone)
+		sess.forwardInput()
+		// Tab closed: end the server session, which stops the pump.
+		sess.close()
+	}()
+
+	reason := sess.pump()
+	sess.close()
+	sendJSON(ws, map[string]string{"t": "bye", "d": reason})
+	// Closing the socket also ends forwardInput.
+	ws.Close()
+	<-inputDone
+}
+
+func sizeParam(r *http.Request, name string, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n <= 0 || n > maxCells {
+		return def
+	}
+	return n
+}
+
+func sendJSON(ws *wsConn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(opText, data)
+}
@@ -0,0 +1,536 @@
+package webruntime
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/framegrace/texelation/protocol"
+)
+
+// testWS is a minimal WebSocket client for driving the gateway.
+type testWS struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWS(t *testing.T, srv *httptest.Server, path string, header http.Header) (*testWS, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	keyBytes := make([]byte, 16)
+	_, _ = rand.Read(keyBytes)
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+	if header == nil {
+		header = http.Header{"Origin": {srv.URL}}
+	}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if h := header.Get("Host"); h != "" {
+		host = h
+	}
+	req := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n", path, host, key)
+	for name, vals := range header {
+		if name == "Host" {
+			continue
+		}
+		for _, v := range vals {
+			req += name + ": " + v + "\r\n"
+		}
+	}
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		t.Fatalf("write handshake: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		if got := resp.Header.Get("Sec-WebSocket-Accept"); got != wsAccept(key) {
+			t.Fatalf("accept = %q, want %q", got, wsAccept(key))
+		}
+	}
+	c := &testWS{conn: conn, br: br}
+	t.Cleanup(func() { conn.Close() })
+	return c, resp
+}
+
+func (c *testWS) writeFrame(op byte, payload []byte) error {
+	hdr := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, 0x80|byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 0x80|126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 0x80|127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	hdr = append(hdr, mask...)
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	_, err := c.conn.Write(append(hdr, masked...))
+	return err
+}
+
+func (c *testWS) send(t *testing.T, v any) {
+	t.Helper()
+	data, _ := json.Marshal(v)
+	if err := c.writeFrame(opText, data); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+}
+
+func (c *testWS) read(t *testing.T) (byte, []byte) {
+	t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	if hdr[1]&0x80 != 0 {
+		t.Fatalf("server frame must not be masked")
+	}
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	return hdr[0] & 0x0F, payload
+}
+
+// readText skips messages until one of type t arrives.
+func (c *testWS) readText(t *testing.T, typ string) map[string]any {
+	t.Helper()
+	for {
+		op, data := c.read(t)
+		if op != opText {
+			continue
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("bad json %q: %v", data, err)
+		}
+		if msg["t"] == typ {
+			return msg
+		}
+	}
+}
+
+// fakeServer stands in for texel-server: it accepts protocol clients on a
+// unix socket and hands each one to the test after the handshake.
+type fakeServer struct {
+	socket string
+	conns  chan *serverConn
+}
+
+// serverConn is one handshaken client as the server sees it.
+type serverConn struct {
+	conn       net.Conn
+	sessionID  [16]byte
+	caps       uint32
+	cols, rows uint16
+	seq        uint64
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	// Short path: unix socket names are limited to ~100 bytes.
+	dir, err := os.MkdirTemp("", "tw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	f := &fakeServer{socket: filepath.Join(dir, "s.sock"), conns: make(chan *serverConn, 4)}
+	ln, err := net.Listen("unix", f.socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.handshake(t, conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeServer) handshake(t *testing.T, conn net.Conn) {
+	sc := &serverConn{conn: conn, sessionID: [16]byte{0xAB}}
+	_, payload, err := protocol.ReadMessage(conn)
+	if err != nil {
+		t.Errorf("read hello: %v", err)
+		return
+	}
+	hello, _ := protocol.DecodeHello(payload)
+	sc.caps = hello.Capabilities & protocol.CapBatchedDeltas
+	welcome, _ := protocol.EncodeWelcome(protocol.Welcome{SessionID: sc.sessionID, Capabilities: sc.caps})
+	sc.write(t, protocol.MsgWelcome, welcome)
+	if _, _, err := protocol.ReadMessage(conn); err != nil {
+		t.Errorf("read connect: %v", err)
+		return
+	}
+	accept, _ := protocol.EncodeConnectAccept(protocol.ConnectAccept{SessionID: sc.sessionID})
+	sc.write(t, protocol.MsgConnectAccept, accept)
+	hdr, payload, err := protocol.ReadMessage(conn)
+	if err != nil || hdr.Type != protocol.MsgClientReady {
+		t.Errorf("read client ready: %v %v", hdr.Type, err)
+		return
+	}
+	ready, _ := protocol.DecodeClientReady(payload)
+	sc.cols, sc.rows = ready.Cols, ready.Rows
+	f.conns <- sc
+}
+
+func (f *fakeServer) accept(t *testing.T) *serverConn {
+	t.Helper()
+	select {
+	case sc := <-f.conns:
+		t.Cleanup(func() { sc.conn.Close() })
+		return sc
+	case <-time.After(5 * time.Second):
+		t.Fatal("gateway did not connect to the server")
+		return nil
+	}
+}
+
+func (sc *serverConn) write(t *testing.T, typ protocol.MessageType, payload []byte) {
+	sc.seq++
+	if err := protocol.WriteMessage(sc.conn, protocol.Header{
+		Version: protocol.Version, Type: typ, Flags: protocol.FlagChecksum,
+		SessionID: sc.sessionID, Sequence: sc.seq,
+	}, payload); err != nil {
+		t.Errorf("write %v: %v", typ, err)
+	}
+}
+
+// expect reads the next client message, which must be of type typ.
+func (sc *serverConn) expect(t *testing.T, typ protocol.MessageType) []byte {
+	t.Helper()
+	_ = sc.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	hdr, payload, err := protocol.ReadMessage(sc.conn)
+	if err != nil {
+		t.Fatalf("waiting for %v: %v", typ, err)
+	}
+	if hdr.Type != typ {
+		t.Fatalf("got %v, want %v", hdr.Type, typ)
+	}
+	return payload
+}
+
+const testToken = "test-token"
+
+// withToken appends the gateway token to a request path.
+func withToken(path string) string {
+	if strings.Contains(path, "?") {
+		return path + "&token=" + testToken
+	}
+	return path + "?token=" + testToken
+}
+
+func newTestGateway(socket string) *Gateway {
+	g := NewGateway(socket)
+	g.token = testToken
+	return g
+}
+
+func startGateway(t *testing.T, socket string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(newTestGateway(socket).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGatewayServesEmbeddedAssets(t *testing.T) {
+	srv := startGateway(t, "unused.sock")
+	for _, path := range []string{"/", "/texel-web.js"} {
+		resp, err := http.Get(srv.URL + withToken(path))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(body) == 0 {
+			t.Fatalf("GET %s: status %d, %d bytes", path, resp.StatusCode, len(body))
+		}
+		if strings.Contains(string(body), "https://") {
+			t.Fatalf("%s references a remote resource", path)
+		}
+	}
+}
+
+func TestGatewayTranslatesPanesAndForwardsInput(t *testing.T) {
+	f := newFakeServer(t)
+	srv := startGateway(t, f.socket)
+	c, resp := dialWS(t, srv, withToken("/ws?cols=20&rows=5"), nil)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status %d", resp.StatusCode)
+	}
+	sc := f.accept(t)
+	if sc.cols != 20 || sc.rows != 5 {
+		t.Fatalf("client ready %dx%d, want 20x5", sc.cols, sc.rows)
+	}
+	if sc.caps&protocol.CapBatchedDeltas == 0 {
+		t.Fatal("gateway should ack deltas (CapBatchedDeltas)")
+	}
+
+	paneID := [16]byte{1}
+	tree, _ := protocol.EncodeTreeSnapshot(protocol.TreeSnapshot{Panes: []protocol.PaneSnapshot{
+		{PaneID: paneID, X: 2, Y: 1, Width: 10, Height: 3, Rows: []string{"plain"}},
+	}})
+	sc.write(t, protocol.MsgTreeSnapshot, tree)
+
+	// The gateway asks the server to publish the pane at its live edge.
+	vp, _ := protocol.DecodeViewportUpdate(sc.expect(t, protocol.MsgViewportUpdate))
+	if vp.PaneID != paneID || vp.Cols != 10 || vp.Rows != 3 || !vp.AutoFollow || vp.AltScreen {
+		t.Fatalf("viewport = %+v", vp)
+	}
+	msg := c.readText(t, "tree")
+	if got := fmt.Sprint(msg["p"]); got != "[[1 2 1 10 3]]" {
+		t.Fatalf("tree = %s", got)
+	}
+	msg = c.readText(t, "rows")
+	if rows := msg["r"].([]any); len(rows) != 3 || fmt.Sprint(rows[0]) != "[0 [0 5 0 plain]]" {
+		t.Fatalf("initial rows = %v", msg["r"])
+	}
+
+	delta, _ := protocol.EncodeBufferDelta(protocol.BufferDelta{
+		PaneID: paneID,
+		Styles: []protocol.StyleEntry{{
+			AttrFlags: protocol.AttrBold, FgModel: protocol.ColorModelRGB, FgValue: 0xFF0000,
+		}},
+		DecorRows: []protocol.DecorRowDelta{{RowIdx: 1, Spans: []protocol.CellSpan{{Text: "hi 世 "}}}},
+	})
+	sc.write(t, protocol.MsgBufferDelta, delta)
+	if ack, _ := protocol.DecodeBufferAck(sc.expect(t, protocol.MsgBufferAck)); ack.Sequence != sc.seq {
+		t.Fatalf("ack %d, want %d", ack.Sequence, sc.seq)
+	}
+	msg = c.readText(t, "rows")
+	if msg["p"].(float64) != 1 {
+		t.Fatalf("rows for pane %v", msg["p"])
+	}
+	if got := fmt.Sprint(msg["s"]); got != "[[1 #FF0000  1]]" {
+		t.Fatalf("styles = %s, want red bold", got)
+	}
+	if got := fmt.Sprint(msg["r"]); got != "[[1 [0 3 1 hi ] [3 2 1 世]]]" {
+		t.Fatalf("rows = %s", got)
+	}
+
+	// Alt-screen rows are positional in Rows; entering it re-sends the
+	// viewport.
+	delta, _ = protocol.EncodeBufferDelta(protocol.BufferDelta{
+		PaneID: paneID,
+		Flags:  protocol.BufferDeltaAltScreen,
+		Styles: []protocol.StyleEntry{{}},
+		Rows:   []protocol.RowDelta{{Row: 2, Spans: []protocol.CellSpan{{StartCol: 1, Text: "vi"}}}},
+	})
+	sc.write(t, protocol.MsgBufferDelta, delta)
+	if vp, _ := protocol.DecodeViewportUpdate(sc.expect(t, protocol.MsgViewportUpdate)); !vp.AltScreen {
+		t.Fatalf("viewport after alt switch = %+v", vp)
+	}
+	sc.expect(t, protocol.MsgBufferAck)
+	if msg := c.readText(t, "rows"); fmt.Sprint(msg["r"]) != "[[2 [0 3 0  vi]]]" {
+		t.Fatalf("alt rows = %v", msg["r"])
+	}
+
+	// Image bytes arrive as a binary message before the placement using
+	// them; placements are in screen cells, inside the pane border.
+	upload, _ := protocol.EncodeImageUpload(protocol.ImageUpload{PaneID: paneID, SurfaceID: 7, Width: 1, Height: 1, Data: []byte("PNGDATA")})
+	sc.write(t, protocol.MsgImageUpload, upload)
+	place, _ := protocol.EncodeImagePlace(protocol.ImagePlace{PaneID: paneID, SurfaceID: 7, X: 1, W: 3, H: 2})
+	sc.write(t, protocol.MsgImagePlace, place)
+	op, data := c.read(t)
+	if op != opBinary || data[0] != 'I' || binary.BigEndian.Uint32(data[1:5]) != 7 || string(data[5:]) != "PNGDATA" {
+		t.Fatalf("unexpected image message op=%d %q", op, data)
+	}
+	if msg := c.readText(t, "img"); fmt.Sprint(msg["i"]) != "[[7 4 2 3 2 0]]" {
+		t.Fatalf("placements = %v", msg["i"])
+	}
+
+	c.send(t, map[string]any{"t": "key", "k": "a"})
+	if ev, _ := protocol.DecodeKeyEvent(sc.expect(t, protocol.MsgKeyEvent)); tcell.Key(ev.KeyCode) != tcell.KeyRune || ev.RuneValue != 'a' {
+		t.Fatalf("expected rune a, got %+v", ev)
+	}
+	c.send(t, map[string]any{"t": "key", "k": "x", "m": modCtrl})
+	if ev, _ := protocol.DecodeKeyEvent(sc.expect(t, protocol.MsgKeyEvent)); tcell.Key(ev.KeyCode) != tcell.KeyCtrlX {
+		t.Fatalf("expected Ctrl+X, got %+v", ev)
+	}
+	c.send(t, map[string]any{"t": "mouse", "x": 4, "y": 2, "b": btnPrimary})
+	if ev, _ := protocol.DecodeMouseEvent(sc.expect(t, protocol.MsgMouseEvent)); ev.X != 4 || ev.Y != 2 || tcell.ButtonMask(ev.ButtonMask) != tcell.Button1 {
+		t.Fatalf("expected Button1 press at 4,2, got %+v", ev)
+	}
+	c.send(t, map[string]any{"t": "paste", "d": "a\r\nb\nc"})
+	if paste, _ := protocol.DecodePaste(sc.expect(t, protocol.MsgPaste)); string(paste.Data) != "a\rb\rc" {
+		t.Fatalf("paste = %q", paste.Data)
+	}
+	c.send(t, map[string]any{"t": "resize", "w": 30, "h": 8})
+	if rs, _ := protocol.DecodeResize(sc.expect(t, protocol.MsgResize)); rs.Cols != 30 || rs.Rows != 8 {
+		t.Fatalf("resize = %+v", rs)
+	}
+
+	clip, _ := protocol.EncodeClipboardSet(protocol.ClipboardSet{MimeType: "text/plain", Data: []byte("copied")})
+	sc.write(t, protocol.MsgClipboardSet, clip)
+	if msg := c.readText(t, "clip"); msg["d"] != "copied" {
+		t.Fatalf("clipboard = %v", msg["d"])
+	}
+
+	// Closing the tab ends the server connection.
+	_ = c.writeFrame(opClose, nil)
+	_ = sc.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := protocol.ReadMessage(sc.conn); !errors.Is(err, io.EOF) {
+		t.Fatalf("server read after tab close: %v, want EOF", err)
+	}
+}
+
+func TestGatewaySaysByeWhenSessionEnds(t *testing.T) {
+	f := newFakeServer(t)
+	srv := startGateway(t, f.socket)
+	c, _ := dialWS(t, srv, withToken("/ws"), nil)
+	sc := f.accept(t)
+	if sc.cols != defaultCols || sc.rows != defaultRows {
+		t.Fatalf("default size %dx%d", sc.cols, sc.rows)
+	}
+	notice, _ := protocol.EncodeDisconnectNotice(protocol.DisconnectNotice{Message: "server shutting down"})
+	sc.write(t, protocol.MsgDisconnectNotice, notice)
+	if bye := c.readText(t, "bye"); bye["d"] != "server shutting down" {
+		t.Fatalf("bye = %v", bye)
+	}
+
+	c2, _ := dialWS(t, srv, withToken("/ws"), nil)
+	f.accept(t).conn.Close()
+	if bye := c2.readText(t, "bye"); bye["d"] != "session ended" {
+		t.Fatalf("bye = %v", bye)
+	}
+}
+
+func TestGatewaySaysByeWhenServerIsDown(t *testing.T) {
+	srv := startGateway(t, filepath.Join(t.TempDir(), "missing.sock"))
+	c, _ := dialWS(t, srv, withToken("/ws"), nil)
+	if bye := c.readText(t, "bye"); !strings.Contains(bye["d"].(string), "dial") {
+		t.Fatalf("bye = %v", bye)
+	}
+}
+
+func TestGatewayRejectsCrossOrigin(t *testing.T) {
+	srv := startGateway(t, "unused.sock")
+	_, resp := dialWS(t, srv, withToken("/ws"), http.Header{"Origin": {"http://evil.example"}})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestGatewayRejectsMissingOriginUnlessAllowed(t *testing.T) {
+	srv := startGateway(t, "unused.sock")
+	if _, resp := dialWS(t, srv, withToken("/ws"), http.Header{}); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status %d without Origin, want 403", resp.StatusCode)
+	}
+
+	g := newTestGateway("unused.sock")
+	g.SetAllowNoOrigin(true)
+	allowed := httptest.NewServer(g.Handler())
+	t.Cleanup(allowed.Close)
+	if _, resp := dialWS(t, allowed, withToken("/ws"), http.Header{}); resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status %d without Origin when allowed, want 101", resp.StatusCode)
+	}
+}
+
+func TestGatewayRequiresToken(t *testing.T) {
+	srv := startGateway(t, "unused.sock")
+	for _, path := range []string{"/", "/ws", "/?token=wrong"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("GET %s: status %d, want 401", path, resp.StatusCode)
+		}
+	}
+
+	// The printed URL sets a cookie that admits the page's later requests.
+	resp, err := http.Get(srv.URL + withToken("/"))
+	if err != nil {
+		t.Fatalf("GET with token: %v", err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %v, want one HttpOnly token cookie", cookies)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/texel-web.js", nil)
+	req.AddCookie(cookies[0])
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET with cookie: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET with cookie: status %d", resp.StatusCode)
+	}
+}
+
+func TestGatewayRejectsForeignHost(t *testing.T) {
+	srv := startGateway(t, "unused.sock")
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	// A DNS-rebinding page reaches the listener under its own name.
+	_, resp := dialWS(t, srv, withToken("/ws"), http.Header{
+		"Host":   {"rebind.example:" + port},
+		"Origin": {"http://rebind.example:" + port},
+	})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestGatewayAllowedHosts(t *testing.T) {
+	g := NewGateway("unused.sock")
+	if !g.allowedHost("localhost:8088") || !g.allowedHost("127.0.0.1:8088") || !g.allowedHost("[::1]:8088") {
+		t.Fatal("loopback hosts should pass before the listen address is known")
+	}
+	if g.allowedHost("192.168.1.5:8088") || g.allowedHost("evil.example:8088") {
+		t.Fatal("non-loopback hosts should be refused")
+	}
+	g.SetListenAddr(&net.TCPAddr{IP: net.ParseIP("192.168.1.5"), Port: 8088})
+	if !g.allowedHost("192.168.1.5:8088") {
+		t.Fatal("the listen address should pass")
+	}
+	if g.allowedHost("192.168.1.5:9999") || g.allowedHost("10.0.0.1:8088") || g.allowedHost("evil.example:8088") {
+		t.Fatal("other ports, addresses and names should be refused")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/input.go
+// Summary: Translates browser input messages into protocol messages.
+// Usage: session.forwardInput decodes each WebSocket text message into
+//        inputMsg and sends what translateInput returns to the server.
+// Notes: Key names follow DOM KeyboardEvent.key; mouse buttons follow
+//        MouseEvent.buttons plus two wheel bits. Keys and mouse go through
+//        tcell events so they carry the codes texel-client sends.
+
+package webruntime
+
+import (
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/framegrace/texelation/protocol"
+)
+
+// Modifier bits sent by the browser.
+const (
+	modShift = 1 << iota
+	modCtrl
+	modAlt
+	modMeta
+)
+
+// Button bits sent by the browser (MouseEvent.buttons + wheel).
+const (
+	btnPrimary   = 1
+	btnSecondary = 2
+	btnMiddle    = 4
+	btnWheelUp   = 0x100
+	btnWheelDown = 0x200
+)
+
+// inputMsg is one browser→server message.
+//
+//	{"t":"key","k":"ArrowUp","m":mods}
+//	{"t":"mouse","x":col,"y":row,"b":buttons,"m":mods}
+//	{"t":"paste","d":"text"}
+//	{"t":"resize","w":cols,"h":rows}
+type inputMsg struct {
+	T string `json:"t"`
+	K string `json:"k,omitempty"`
+	M int    `json:"m,omitempty"`
+	X int    `json:"x,omitempty"`
+	Y int    `json:"y,omitempty"`
+	B int    `json:"b,omitempty"`
+	D string `json:"d,omitempty"`
+	W int    `json:"w,omitempty"`
+	H int    `json:"h,omitempty"`
+}
+
+var namedKeys = map[string]tcell.Key{
+	"Enter":      tcell.KeyEnter,
+	"Tab":        tcell.KeyTab,
+	"Backspace":  tcell.KeyBackspace2,
+	"Escape":     tcell.KeyEscape,
+	"ArrowUp":    tcell.KeyUp,
+	"ArrowDown":  tcell.KeyDown,
+	"ArrowLeft":  tcell.KeyLeft,
+	"ArrowRight": tcell.KeyRight,
+	"Home":       tcell.KeyHome,
+	"End":        tcell.KeyEnd,
+	"PageUp":     tcell.KeyPgUp,
+	"PageDown":   tcell.KeyPgDn,
+	"Insert":     tcell.KeyInsert,
+	"Delete":     tcell.KeyDelete,
+	"F1":         tcell.KeyF1,
+	"F2":         tcell.KeyF2,
+	"F3":         tcell.KeyF3,
+	"F4":         tcell.KeyF4,
+	"F5":         tcell.KeyF5,
+	"F6":         tcell.KeyF6,
+	"F7":         tcell.KeyF7,
+	"F8":         tcell.KeyF8,
+	"F9":         tcell.KeyF9,
+	"F10":        tcell.KeyF10,
+	"F11":        tcell.KeyF11,
+	"F12":        tcell.KeyF12,
+}
+
+func tcellMods(m int) tcell.ModMask {
+	var mod tcell.ModMask
+	if m&modShift != 0 {
+		mod |= tcell.ModShift
+	}
+	if m&modCtrl != 0 {
+		mod |= tcell.ModCtrl
+	}
+	if m&modAlt != 0 {
+		mod |= tcell.ModAlt
+	}
+	if m&modMeta != 0 {
+		mod |= tcell.ModMeta
+	}
+	return mod
+}
+
+// keyEvent maps a DOM key name to a tcell key event, or nil for keys
+// with no terminal meaning (bare modifiers, dead keys, media keys).
+func keyEvent(name string, mods int) *tcell.EventKey {
+	mod := tcellMods(mods)
+	if k, ok := namedKeys[name]; ok {
+		if k == tcell.KeyTab && mod == tcell.ModShift {
+			return tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone)
+		}
+		return tcell.NewEventKey(k, 0, mod)
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		return nil
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	// The browser already applied Shift to the character.
+	mod &^= tcell.ModShift
+	return tcell.NewEventKey(tcell.KeyRune, r, mod)
+}
+
+// mouseEvent maps browser button bits to a tcell mouse event.
+func mouseEvent(x, y, buttons, mods int) *tcell.EventMouse {
+	var btn tcell.ButtonMask
+	if buttons&btnPrimary != 0 {
+		btn |= tcell.Button1
+	}
+	if buttons&btnSecondary != 0 {
+		btn |= tcell.Button2
+	}
+	if buttons&btnMiddle != 0 {
+		btn |= tcell.Button3
+	}
+	if buttons&btnWheelUp != 0 {
+		btn |= tcell.WheelUp
+	}
+	if buttons&btnWheelDown != 0 {
+		btn |= tcell.WheelDown
+	}
+	return tcell.NewEventMouse(x, y, btn, tcellMods(mods))
+}
+
+// translateInput encodes a browser message as a protocol message.
+func translateInput(msg inputMsg) (protocol.MessageType, []byte, bool) {
+	var (
+		typ     protocol.MessageType
+		payload []byte
+		err     error
+	)
+	switch msg.T {
+	case "key":
+		ev := keyEvent(msg.K, msg.M)
+		if ev == nil {
+			return 0, nil, false
+		}
+		typ = protocol.MsgKeyEvent
+		payload, err = protocol.EncodeKeyEvent(protocol.KeyEvent{
+			KeyCode: uint32(ev.Key()), RuneValue: ev.Rune(), Modifiers: uint16(ev.Modifiers()),
+		})
+	case "mouse":
+		ev := mouseEvent(msg.X, msg.Y, msg.B, msg.M)
+		x, y := ev.Position()
+		typ = protocol.MsgMouseEvent
+		payload, err = protocol.EncodeMouseEvent(protocol.MouseEvent{
+			X: int16(x), Y: int16(y), ButtonMask: uint32(ev.Buttons()), Modifiers: uint16(ev.Modifiers()),
+		})
+	case "paste":
+		if msg.D == "" {
+			return 0, nil, false
+		}
+		typ = protocol.MsgPaste
+		payload, err = protocol.EncodePaste(protocol.Paste{Data: pasteData(msg.D)})
+	case "resize":
+		if msg.W <= 0 || msg.H <= 0 || msg.W > maxCells || msg.H > maxCells {
+			return 0, nil, false
+		}
+		typ = protocol.MsgResize
+		payload, err = protocol.EncodeResize(protocol.Resize{Cols: uint16(msg.W), Rows: uint16(msg.H)})
+	default:
+		return 0, nil, false
+	}
+	if err != nil {
+		log.Printf("texel-web: encode %s: %v", msg.T, err)
+		return 0, nil, false
+	}
+	return typ, payload, true
+}
+
+// pasteData turns line breaks into the carriage returns a terminal's
+// paste delivers, as texel-client does.
+func pasteData(text string) []byte {
+	text = strings.ReplaceAll(text, "\r\n", "\r")
+	return []byte(strings.ReplaceAll(text, "\n", "\r"))
+}
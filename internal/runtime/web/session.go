@@ -0,0 +1,506 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/session.go
+// Summary: One browser tab's protocol connection to texel-server.
+// Usage: serveWS calls dialSession, then runs pump (server to browser)
+//        and forwardInput (browser to server) until either side ends.
+// Notes: The session is a protocol client like texel-client without the
+//        compositor: it keeps each pane's rows by position and the
+//        browser draws the panes at their rects. Main-screen rows come
+//        from the positional copy (DecorRows) the publisher sends of every
+//        visible row; alt-screen rows are positional already. Every pane
+//        gets an AutoFollow viewport so the publisher emits it; scrolling
+//        is the app's own, driven by the forwarded mouse wheel. Deltas are
+//        acked once they are written to the browser, so a slow tab slows
+//        its own stream instead of queueing frames in the gateway.
+
+package webruntime
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelation/client"
+	"github.com/framegrace/texelation/protocol"
+)
+
+// webPane is what the session knows about one pane.
+type webPane struct {
+	id         int
+	x, y, w, h int
+	z          int
+	alt        bool
+	placed     bool // a snapshot has given the pane a rect
+	rows       [][]cell
+}
+
+// row returns row y, growing the pane when a delta outruns its rect.
+func (p *webPane) row(y int) []cell {
+	for len(p.rows) <= y {
+		p.rows = append(p.rows, nil)
+	}
+	return p.rows[y]
+}
+
+// resize fits the rows to the pane's rect.
+func (p *webPane) resize() {
+	if len(p.rows) > p.h {
+		p.rows = p.rows[:p.h]
+	}
+	for y := range p.rows {
+		if len(p.rows[y]) > p.w {
+			p.rows[y] = p.rows[y][:p.w]
+		}
+	}
+}
+
+type session struct {
+	conn      net.Conn
+	sessionID [16]byte
+	writeMu   sync.Mutex
+	ws        *wsConn
+
+	enc    *streamEncoder
+	panes  map[[16]byte]*webPane
+	nextID int
+	images *client.ImageCache
+	bg     string
+}
+
+// dialSession connects to the server at socket as a new client and
+// announces a cols×rows screen.
+func dialSession(socket string, ws *wsConn, cols, rows int) (*session, error) {
+	sc := client.NewSimpleClient(socket)
+	sc.EnableBatching()
+	accept, conn, err := sc.Connect(nil)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{
+		conn:      conn,
+		sessionID: accept.SessionID,
+		ws:        ws,
+		enc:       newStreamEncoder(),
+		panes:     make(map[[16]byte]*webPane),
+		images:    client.NewImageCache(),
+	}
+	payload, err := protocol.EncodeClientReady(protocol.ClientReady{Cols: uint16(cols), Rows: uint16(rows)})
+	if err == nil {
+		err = s.send(protocol.MsgClientReady, payload)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *session) send(typ protocol.MessageType, payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return protocol.WriteMessage(s.conn, protocol.Header{
+		Version:   protocol.Version,
+		Type:      typ,
+		Flags:     protocol.FlagChecksum,
+		SessionID: s.sessionID,
+	}, payload)
+}
+
+// close ends the server connection, which also stops pump.
+func (s *session) close() {
+	s.conn.Close()
+}
+
+// errBrowserGone stops pump when a write to the browser fails.
+var errBrowserGone = errors.New("browser disconnected")
+
+// pump translates server messages for the browser until the server
+// connection ends. It returns the reason to show the user.
+func (s *session) pump() string {
+	for {
+		hdr, payload, err := protocol.ReadMessage(s.conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("texel-web: read from server: %v", err)
+			}
+			return "session ended"
+		}
+		if hdr.Type == protocol.MsgDisconnectNotice {
+			notice, err := protocol.DecodeDisconnectNotice(payload)
+			if err != nil || notice.Message == "" {
+				return "session ended"
+			}
+			return notice.Message
+		}
+		if err := s.handle(hdr, payload); err != nil {
+			if !errors.Is(err, errBrowserGone) {
+				log.Printf("texel-web: %v message: %v", hdr.Type, err)
+				continue
+			}
+			return err.Error()
+		}
+	}
+}
+
+// handle applies one server message. Decode errors are returned as is;
+// a failed browser write is errBrowserGone.
+func (s *session) handle(hdr protocol.Header, payload []byte) error {
+	switch hdr.Type {
+	case protocol.MsgTreeSnapshot:
+		snap, err := protocol.DecodeTreeSnapshot(payload)
+		if err != nil {
+			return err
+		}
+		return s.applyTree(snap)
+	case protocol.MsgBufferDelta:
+		delta, err := protocol.DecodeBufferDelta(payload)
+		if err != nil {
+			return err
+		}
+		if err := s.applyDelta(delta); err != nil {
+			return err
+		}
+		return s.ack(hdr.Sequence)
+	case protocol.MsgBufferDeltaBatch:
+		deltas, err := protocol.DecodeBufferDeltaBatch(payload)
+		if err != nil {
+			return err
+		}
+		for _, delta := range deltas {
+			if err := s.applyDelta(delta); err != nil {
+				return err
+			}
+		}
+		// Acks are cumulative; the batch carries its last sequence.
+		return s.ack(hdr.Sequence)
+	case protocol.MsgPaneState:
+		st, err := protocol.DecodePaneState(payload)
+		if err != nil {
+			return err
+		}
+		p := s.pane(st.PaneID)
+		if p.z == int(st.ZOrder) {
+			return nil
+		}
+		p.z = int(st.ZOrder)
+		if err := s.sendTree(); err != nil {
+			return err
+		}
+		return s.sendImages()
+	case protocol.MsgStateUpdate:
+		update, err := protocol.DecodeStateUpdate(payload)
+		if err != nil {
+			return err
+		}
+		bg := cssColor(protocol.ColorModelRGB, update.DesktopBgRGB)
+		if bg == s.bg {
+			return nil
+		}
+		s.bg = bg
+		return s.sendJSON(map[string]string{"t": "bg", "d": bg})
+	case protocol.MsgImageUpload:
+		up, err := protocol.DecodeImageUpload(payload)
+		if err != nil {
+			return err
+		}
+		s.images.Upload(up.PaneID, up.SurfaceID, int(up.Width), int(up.Height), up.Data)
+		return s.sendImages()
+	case protocol.MsgImagePlace:
+		pl, err := protocol.DecodeImagePlace(payload)
+		if err != nil {
+			return err
+		}
+		s.images.Place(pl.PaneID, pl.SurfaceID, int(pl.X), int(pl.Y), int(pl.W), int(pl.H), int(pl.ZIndex))
+		return s.sendImages()
+	case protocol.MsgImageDelete:
+		del, err := protocol.DecodeImageDelete(payload)
+		if err != nil {
+			return err
+		}
+		s.images.Delete(del.PaneID, del.SurfaceID)
+		delete(s.enc.sentImages, del.SurfaceID)
+		return s.sendImages()
+	case protocol.MsgImageReset:
+		reset, err := protocol.DecodeImageReset(payload)
+		if err != nil {
+			return err
+		}
+		s.images.ResetPlacements(reset.PaneID)
+		return s.sendImages()
+	case protocol.MsgClipboardSet:
+		clip, err := protocol.DecodeClipboardSet(payload)
+		if err != nil {
+			return err
+		}
+		return s.sendJSON(map[string]string{"t": "clip", "d": string(clip.Data)})
+	case protocol.MsgClipboardData:
+		clip, err := protocol.DecodeClipboardData(payload)
+		if err != nil {
+			return err
+		}
+		return s.sendJSON(map[string]string{"t": "clip", "d": string(clip.Data)})
+	case protocol.MsgPing:
+		pong, _ := protocol.EncodePong(protocol.Pong{Timestamp: time.Now().UnixNano()})
+		return s.send(protocol.MsgPong, pong)
+	}
+	return nil
+}
+
+func (s *session) pane(id [16]byte) *webPane {
+	p := s.panes[id]
+	if p == nil {
+		s.nextID++
+		p = &webPane{id: s.nextID}
+		s.panes[id] = p
+	}
+	return p
+}
+
+// applyTree updates pane rects, forgets closed panes and sends the new
+// layout. New and resized panes get a viewport and a full repaint.
+func (s *session) applyTree(snap protocol.TreeSnapshot) error {
+	live := make(map[[16]byte]bool, len(snap.Panes))
+	var repaint []*webPane
+	for _, ps := range snap.Panes {
+		live[ps.PaneID] = true
+		p := s.pane(ps.PaneID)
+		p.x, p.y = int(ps.X), int(ps.Y)
+		w, h := int(ps.Width), int(ps.Height)
+		if p.placed && p.w == w && p.h == h {
+			continue
+		}
+		if !p.placed && len(p.rows) == 0 {
+			// Plain text until the first delta brings styles.
+			for y, text := range ps.Rows {
+				row := p.row(y)
+				for _, r := range text {
+					row = append(row, cell{ch: r})
+				}
+				p.rows[y] = row
+			}
+		}
+		p.w, p.h, p.placed = w, h, true
+		p.resize()
+		repaint = append(repaint, p)
+		if err := s.sendViewport(ps.PaneID, p); err != nil {
+			return err
+		}
+	}
+	for id := range s.panes {
+		if !live[id] {
+			delete(s.panes, id)
+			s.images.ResetPlacements(id)
+		}
+	}
+	if err := s.sendTree(); err != nil {
+		return err
+	}
+	for _, p := range repaint {
+		msg := &rowsMsg{T: "rows", P: p.id, Rows: [][]any{}}
+		for y := 0; y < p.h; y++ {
+			var row []cell
+			if y < len(p.rows) {
+				row = p.rows[y]
+			}
+			msg.Rows = append(msg.Rows, encodeRow(y, row, p.w))
+		}
+		if err := s.sendJSON(msg); err != nil {
+			return err
+		}
+	}
+	return s.sendImages()
+}
+
+// sendViewport asks the server to publish the pane as it follows its
+// live edge.
+func (s *session) sendViewport(id [16]byte, p *webPane) error {
+	if p.w <= 0 || p.h <= 0 {
+		return nil
+	}
+	payload, err := protocol.EncodeViewportUpdate(protocol.ViewportUpdate{
+		PaneID:        id,
+		AltScreen:     p.alt,
+		ViewBottomIdx: int64(p.h) - 1,
+		Rows:          uint16(p.h),
+		Cols:          uint16(p.w),
+		AutoFollow:    true,
+	})
+	if err != nil {
+		return err
+	}
+	return s.send(protocol.MsgViewportUpdate, payload)
+}
+
+// applyDelta merges a pane delta and sends the rows it touched.
+func (s *session) applyDelta(delta protocol.BufferDelta) error {
+	p := s.pane(delta.PaneID)
+	if alt := delta.Flags&protocol.BufferDeltaAltScreen != 0; alt != p.alt {
+		p.alt = alt
+		if err := s.sendViewport(delta.PaneID, p); err != nil {
+			return err
+		}
+	}
+	msg := &rowsMsg{T: "rows", P: p.id}
+	sids := s.enc.styleIDs(msg, delta.Styles)
+	touched := make(map[int]bool)
+	apply := func(y int, spans []protocol.CellSpan) {
+		if y >= int(protocol.MaxDecorRowIdx) || (p.placed && y >= p.h) {
+			return
+		}
+		row := p.row(y)
+		for _, span := range spans {
+			sid := 0
+			if int(span.StyleIndex) < len(sids) {
+				sid = sids[span.StyleIndex]
+			}
+			x := int(span.StartCol)
+			for _, r := range span.Text {
+				for len(row) <= x {
+					row = append(row, cell{ch: ' '})
+				}
+				row[x] = cell{ch: r, sid: sid}
+				x++
+			}
+		}
+		p.rows[y] = row
+		touched[y] = true
+	}
+	if p.alt {
+		for _, r := range delta.Rows {
+			apply(int(r.Row), r.Spans)
+		}
+	} else {
+		for _, r := range delta.DecorRows {
+			apply(int(r.RowIdx), r.Spans)
+		}
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+	ys := make([]int, 0, len(touched))
+	for y := range touched {
+		ys = append(ys, y)
+	}
+	sort.Ints(ys)
+	for _, y := range ys {
+		msg.Rows = append(msg.Rows, encodeRow(y, p.rows[y], p.w))
+	}
+	return s.sendJSON(msg)
+}
+
+func (s *session) ack(seq uint64) error {
+	payload, err := protocol.EncodeBufferAck(protocol.BufferAck{Sequence: seq})
+	if err != nil {
+		return err
+	}
+	return s.send(protocol.MsgBufferAck, payload)
+}
+
+// sortedPanes returns the panes in draw order, the order texel-client
+// composites them in.
+func (s *session) sortedPanes() []*webPane {
+	panes := make([]*webPane, 0, len(s.panes))
+	for _, p := range s.panes {
+		if p.placed {
+			panes = append(panes, p)
+		}
+	}
+	sort.Slice(panes, func(i, j int) bool {
+		a, b := panes[i], panes[j]
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		if a.y != b.y {
+			return a.y < b.y
+		}
+		if a.x != b.x {
+			return a.x < b.x
+		}
+		return a.id < b.id
+	})
+	return panes
+}
+
+func (s *session) sendTree() error {
+	list := make([][]int, 0, len(s.panes))
+	for _, p := range s.sortedPanes() {
+		list = append(list, []int{p.id, p.x, p.y, p.w, p.h})
+	}
+	return s.sendJSON(map[string]any{"t": "tree", "p": list})
+}
+
+// sendImages uploads new image data and sends the placements when they
+// changed. Placements are content-relative; +1 skips the pane border.
+func (s *session) sendImages() error {
+	var images []placedImage
+	for id, p := range s.panes {
+		if !p.placed {
+			continue
+		}
+		for _, pl := range s.images.Placements(id) {
+			img := s.images.Get(pl.SurfaceID)
+			if img == nil || len(img.Data) == 0 {
+				continue
+			}
+			images = append(images, placedImage{
+				surfaceID: pl.SurfaceID,
+				data:      img.Data,
+				x:         p.x + 1 + pl.X,
+				y:         p.y + 1 + pl.Y,
+				w:         pl.W,
+				h:         pl.H,
+				z:         pl.ZIndex,
+			})
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].surfaceID < images[j].surfaceID })
+	for _, m := range s.enc.pendingImages(images) {
+		if err := s.ws.WriteMessage(opBinary, m); err != nil {
+			return errBrowserGone
+		}
+	}
+	if pls, changed := s.enc.placementsChanged(images); changed {
+		return s.sendJSON(map[string]any{"t": "img", "i": pls})
+	}
+	return nil
+}
+
+func (s *session) sendJSON(v any) error {
+	if err := sendJSON(s.ws, v); err != nil {
+		return errBrowserGone
+	}
+	return nil
+}
+
+// forwardInput sends browser input to the server until the socket
+// closes.
+func (s *session) forwardInput() {
+	for {
+		op, data, err := s.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		if op != opText {
+			continue
+		}
+		var msg inputMsg
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("texel-web: bad input message: %v", err)
+			continue
+		}
+		typ, payload, ok := translateInput(msg)
+		if !ok {
+			continue
+		}
+		if err := s.send(typ, payload); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/runtime/web/websocket.go
+// Summary: Minimal RFC 6455 WebSocket server endpoint.
+// Usage: upgradeWebSocket hijacks an HTTP request; the returned wsConn
+//        reads client messages and writes text/binary messages.
+// Notes: Only what the gateway needs: no extensions, no subprotocols,
+//        server-side only. Kept in-tree so texel-web has no extra deps.
+
+package webruntime
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WebSocket opcodes (RFC 6455 §5.2).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+const (
+	wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// wsMaxMessage caps a reassembled client message. Pastes are the
+	// largest thing a browser sends.
+	wsMaxMessage = 8 << 20
+)
+
+var errMessageTooLarge = errors.New("websocket: message too large")
+
+// wsConn is one accepted WebSocket connection. Reads must come from a
+// single goroutine; writes are serialised internally.
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	wmu    sync.Mutex
+	closed bool
+}
+
+// wsAccept computes the Sec-WebSocket-Accept value for a client key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerContains reports whether a comma-separated header has token.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin rejects cross-site pages: anything able to reach the
+// listener could otherwise type into the user's terminals. Browsers always
+// send Origin on a WebSocket handshake, so a request without one is a
+// non-browser client and only passes when allowNoOrigin is set.
+func sameOrigin(r *http.Request, allowNoOrigin bool) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return allowNoOrigin
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// upgradeWebSocket validates the handshake and hijacks the connection.
+// On failure an HTTP error has already been written.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, allowNoOrigin bool) (*wsConn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: method %s", r.Method)
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing key")
+	}
+	if !sameOrigin(r, allowNoOrigin) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %q rejected", r.Header.Get("Origin"))
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response writer is not a Hijacker")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: write handshake: %w", err)
+	}
+	return &wsConn{conn: conn, br: rw.Reader}, nil
+}
+
+// ReadMessage returns the next complete data message. Ping and close
+// frames are answered internally; a close frame yields io.EOF.
+func (c *wsConn) ReadMessage() (byte, []byte, error) {
+	var (
+		msgOp byte
+		msg   []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = c.writeFrame(opClose, payload)
+			return 0, nil, io.EOF
+		case opContinuation:
+			if msgOp == 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		case opText, opBinary:
+			if msgOp != 0 {
+				return 0, nil, errors.New("websocket: interleaved data frames")
+			}
+			msgOp = op
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %#x", op)
+		}
+		if len(msg)+len(payload) > wsMaxMessage {
+			return 0, nil, errMessageTooLarge
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msgOp, msg, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(c.br, hdr[:]); err != nil {
+		return
+	}
+	fin = hdr[0]&0x80 != 0
+	op = hdr[0] & 0x0F
+	if hdr[0]&0x70 != 0 {
+		err = errors.New("websocket: reserved bits set")
+		return
+	}
+	masked := hdr[1]&0x80 != 0
+	if !masked {
+		err = errors.New("websocket: client frame not masked")
+		return
+	}
+	length := uint64(hdr[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessage {
+		err = errMessageTooLarge
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// WriteMessage sends one unfragmented text or binary message.
+func (c *wsConn) WriteMessage(op byte, data []byte) error {
+	return c.writeFrame(op, data)
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	hdr := make([]byte, 0, 10)
+	hdr = append(hdr, 0x80|op)
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	if _, err := c.conn.Write(append(hdr, payload...)); err != nil {
+		return err
+	}
+	if op == opClose {
+		c.closed = true
+	}
+	return nil
+}
+
+// Close sends a normal-closure frame (best effort) and closes the socket.
+func (c *wsConn) Close() error {
+	_ = c.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000 normal closure
+	return c.conn.Close()
+}
@@ -0,0 +1,69 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/screenlock/pam.go
+// Summary: PAM password check for the screen lock.
+// Usage: Compiled only with `-tags pam` (needs cgo and libpam headers);
+//   enabled at runtime by screensaver.lock_pam in texelation.json.
+// Notes: Authenticates the server's own user against the "login" service,
+//   the same stack a console login uses.
+
+//go:build pam && cgo
+
+package screenlock
+
+/*
+#cgo LDFLAGS: -lpam
+#include <security/pam_appl.h>
+#include <stdlib.h>
+#include <string.h>
+
+static int texel_conv(int n, const struct pam_message **msg, struct pam_response **resp, void *data) {
+	struct pam_response *r = calloc(n, sizeof(struct pam_response));
+	if (r == NULL) {
+		return PAM_BUF_ERR;
+	}
+	for (int i = 0; i < n; i++) {
+		if (msg[i]->msg_style == PAM_PROMPT_ECHO_OFF || msg[i]->msg_style == PAM_PROMPT_ECHO_ON) {
+			r[i].resp = strdup((const char *)data);
+		}
+	}
+	*resp = r;
+	return PAM_SUCCESS;
+}
+
+static int texel_authenticate(const char *service, const char *user, const char *password) {
+	struct pam_conv conv = { texel_conv, (void *)password };
+	pam_handle_t *h = NULL;
+	int rc = pam_start(service, user, &conv, &h);
+	if (rc != PAM_SUCCESS) {
+		return rc;
+	}
+	rc = pam_authenticate(h, 0);
+	if (rc == PAM_SUCCESS) {
+		rc = pam_acct_mgmt(h, 0);
+	}
+	pam_end(h, rc);
+	return rc;
+}
+*/
+import "C"
+
+import "unsafe"
+
+const pamSupported = true
+
+const pamService = "login"
+
+func pamAuthenticate(user, password string) bool {
+	cService := C.CString(pamService)
+	cUser := C.CString(user)
+	cPassword := C.CString(password)
+	defer C.free(unsafe.Pointer(cService))
+	defer C.free(unsafe.Pointer(cUser))
+	defer func() {
+		C.memset(unsafe.Pointer(cPassword), 0, C.size_t(len(password)))
+		C.free(unsafe.Pointer(cPassword))
+	}()
+	return C.texel_authenticate(cService, cUser, cPassword) == C.PAM_SUCCESS
+}
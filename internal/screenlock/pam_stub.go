@@ -0,0 +1,13 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/screenlock/pam_stub.go
+// Summary: Stand-in used when PAM support is not compiled in.
+
+//go:build !pam || !cgo
+
+package screenlock
+
+const pamSupported = false
+
+func pamAuthenticate(user, password string) bool { return false }
@@ -0,0 +1,164 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: internal/screenlock/screenlock.go
+// Summary: Password storage and verification for the desktop screen lock.
+// Usage: `texelation --set-lock-password` writes a salted hash with
+//   HashPassword/Save; texel-server hands NewVerifier to the desktop, which
+//   checks the unlock prompt against it.
+// Notes: Hashes are PBKDF2-SHA256 stored as
+//   "pbkdf2-sha256$<iterations>$<salt>$<key>" (base64, no padding). The file
+//   is re-read on every attempt so a new password applies without restart.
+
+package screenlock
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashScheme = "pbkdf2-sha256"
+	// DefaultIterations is the PBKDF2 work factor for new hashes.
+	DefaultIterations = 600_000
+	saltLen           = 16
+	keyLen            = 32
+)
+
+// ErrNoPassword is returned by Load when no lock password has been set.
+var ErrNoPassword = errors.New("screenlock: no lock password set")
+
+// DefaultPath returns ~/.texelation/lock.hash.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".texelation", "lock.hash"), nil
+}
+
+// HashPassword returns a freshly salted hash of password.
+func HashPassword(password string) (string, error) {
+	return hashWithIterations(password, DefaultIterations)
+}
+
+func hashWithIterations(password string, iterations int) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, keyLen)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, iterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches the stored hash.
+func Verify(stored, password string) bool {
+	parts := strings.Split(strings.TrimSpace(stored), "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// Save writes a hash to path, readable only by the owner.
+func Save(path, stored string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(stored+"\n"), 0o600)
+}
+
+// Load reads the hash stored at path.
+func Load(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoPassword
+	}
+	if err != nil {
+		return "", err
+	}
+	stored := strings.TrimSpace(string(data))
+	if stored == "" {
+		return "", ErrNoPassword
+	}
+	return stored, nil
+}
+
+// Verifier checks unlock attempts. Available reports whether any password
+// can succeed at all; the desktop refuses to lock when it cannot.
+type Verifier interface {
+	Available() bool
+	Verify(password string) bool
+}
+
+// NewVerifier returns a verifier for the hash at path, or one backed by
+// PAM when usePAM is set and the binary was built with the "pam" tag.
+func NewVerifier(path string, usePAM bool) Verifier {
+	if usePAM {
+		if !pamSupported {
+			log.Printf("screenlock: PAM requested but not compiled in (build with -tags pam); using %s", path)
+		} else if u, err := user.Current(); err == nil {
+			return pamVerifier{user: u.Username}
+		}
+	}
+	return hashVerifier{path: path}
+}
+
+type hashVerifier struct {
+	path string
+}
+
+func (v hashVerifier) Available() bool {
+	_, err := Load(v.path)
+	return err == nil
+}
+
+func (v hashVerifier) Verify(password string) bool {
+	stored, err := Load(v.path)
+	if err != nil {
+		return false
+	}
+	return Verify(stored, password)
+}
+
+type pamVerifier struct {
+	user string
+}
+
+func (v pamVerifier) Available() bool { return true }
+
+func (v pamVerifier) Verify(password string) bool {
+	return pamAuthenticate(v.user, password)
+}
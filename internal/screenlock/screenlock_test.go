@@ -0,0 +1,52 @@
+package screenlock
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	stored, err := hashWithIterations("hunter2", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, "pbkdf2-sha256$1000$") {
+		t.Fatalf("unexpected hash format %q", stored)
+	}
+	if !Verify(stored, "hunter2") {
+		t.Fatal("correct password rejected")
+	}
+	if Verify(stored, "hunter3") || Verify(stored, "") {
+		t.Fatal("wrong password accepted")
+	}
+	again, _ := hashWithIterations("hunter2", 1000)
+	if again == stored {
+		t.Fatal("expected a fresh salt per hash")
+	}
+	for _, bad := range []string{"", "plain", "md5$1$a$b", "pbkdf2-sha256$x$a$b"} {
+		if Verify(bad, "hunter2") {
+			t.Fatalf("malformed hash %q accepted", bad)
+		}
+	}
+}
+
+func TestVerifierReadsSavedHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock.hash")
+	v := NewVerifier(path, false)
+	if v.Available() {
+		t.Fatal("verifier without a password file should be unavailable")
+	}
+	if _, err := Load(path); !errors.Is(err, ErrNoPassword) {
+		t.Fatalf("Load error = %v, want ErrNoPassword", err)
+	}
+
+	stored, _ := hashWithIterations("s3cret", 1000)
+	if err := Save(path, stored); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Available() || !v.Verify("s3cret") || v.Verify("nope") {
+		t.Fatal("verifier did not use the saved hash")
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: protocol/buffer_delta_batch.go
+// Summary: Multi-pane buffer delta batches and delta coalescing.
+// Usage: A server that negotiated CapBatchedDeltas packs runs of queued
+//   BufferDeltas into one MsgBufferDeltaBatch frame; MergeBufferDeltas folds
+//   a newer delta for a pane into an older, not yet sent one.
+// Notes: Each batch entry is a complete EncodeBufferDelta payload, so the
+//   server can batch already-encoded diffs without re-encoding them.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// CapBatchedDeltas: the client understands MsgBufferDeltaBatch and acks
+	// every delta frame, so the server may pace frames by ack round trip.
+	CapBatchedDeltas uint32 = 1 << 1
+)
+
+// MaxBatchDeltas caps the entry count of one batch.
+const MaxBatchDeltas = 0xFFFF
+
+var ErrBatchTooLarge = errors.New("protocol: buffer delta batch exceeds limits")
+
+// EncodeBufferDeltaBatch joins already-encoded BufferDelta payloads into a
+// batch payload: uint16 count, then per entry uint32 length + payload.
+func EncodeBufferDeltaBatch(encoded [][]byte) ([]byte, error) {
+	if len(encoded) > MaxBatchDeltas {
+		return nil, ErrBatchTooLarge
+	}
+	size := 2
+	for _, p := range encoded {
+		size += 4 + len(p)
+	}
+	if size > int(MaxPayloadLen) {
+		return nil, ErrBatchTooLarge
+	}
+	buf := make([]byte, 2, size)
+	binary.LittleEndian.PutUint16(buf, uint16(len(encoded)))
+	for _, p := range encoded {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(p)))
+		buf = append(buf, p...)
+	}
+	return buf, nil
+}
+
+// DecodeBufferDeltaBatch reverses EncodeBufferDeltaBatch. Deltas are
+// returned in wire order, which is the order they must be applied in.
+func DecodeBufferDeltaBatch(b []byte) ([]BufferDelta, error) {
+	if len(b) < 2 {
+		return nil, ErrPayloadShort
+	}
+	count := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	deltas := make([]BufferDelta, 0, count)
+	for i := 0; i < count; i++ {
+		if len(b) < 4 {
+			return nil, ErrPayloadShort
+		}
+		n := binary.LittleEndian.Uint32(b)
+		b = b[4:]
+		if uint64(n) > uint64(len(b)) {
+			return nil, ErrPayloadShort
+		}
+		delta, err := DecodeBufferDelta(b[:n])
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, delta)
+		b = b[n:]
+	}
+	return deltas, nil
+}
+
+// MergeBufferDeltas returns a single delta equivalent to applying older
+// then newer for the same pane. Rows are sent whole, so for a row present
+// in both the newer copy wins; rows only in older are kept. Style tables
+// are rebuilt to hold only the styles the surviving spans use, so repeated
+// merges do not grow them.
+//
+// It reports false when the pair cannot be merged: different panes, an
+// alt-screen switch between them (clients drop the other screen's rows on
+// a switch), or main-screen rows spanning more than a uint16 Row offset.
+func MergeBufferDeltas(older, newer BufferDelta) (BufferDelta, bool) {
+	if older.PaneID != newer.PaneID || older.Flags != newer.Flags {
+		return BufferDelta{}, false
+	}
+
+	// Key content rows by absolute index (RowBase+Row); alt-screen deltas
+	// always carry RowBase 0.
+	type rowRef struct {
+		src  *BufferDelta
+		span []CellSpan
+	}
+	rows := make(map[int64]rowRef, len(older.Rows)+len(newer.Rows))
+	order := make([]int64, 0, len(older.Rows)+len(newer.Rows))
+	addRows := func(d *BufferDelta) {
+		for _, r := range d.Rows {
+			abs := d.RowBase + int64(r.Row)
+			if _, seen := rows[abs]; !seen {
+				order = append(order, abs)
+			}
+			rows[abs] = rowRef{src: d, span: r.Spans}
+		}
+	}
+	addRows(&older)
+	addRows(&newer)
+
+	base := newer.RowBase
+	for _, abs := range order {
+		if abs < base {
+			base = abs
+		}
+	}
+	for _, abs := range order {
+		if abs-base > 0xFFFF {
+			return BufferDelta{}, false
+		}
+	}
+
+	decor := make(map[uint16]rowRef, len(older.DecorRows)+len(newer.DecorRows))
+	decorOrder := make([]uint16, 0, len(older.DecorRows)+len(newer.DecorRows))
+	addDecor := func(d *BufferDelta) {
+		for _, r := range d.DecorRows {
+			if _, seen := decor[r.RowIdx]; !seen {
+				decorOrder = append(decorOrder, r.RowIdx)
+			}
+			decor[r.RowIdx] = rowRef{src: d, span: r.Spans}
+		}
+	}
+	addDecor(&older)
+	addDecor(&newer)
+
+	merged := BufferDelta{
+		PaneID:   newer.PaneID,
+		Revision: max(older.Revision, newer.Revision),
+		Flags:    newer.Flags,
+		RowBase:  base,
+	}
+	type styleRef struct {
+		src *BufferDelta
+		idx uint16
+	}
+	styleIndex := make(map[styleRef]uint16)
+	remap := func(ref rowRef) ([]CellSpan, bool) {
+		spans := make([]CellSpan, len(ref.span))
+		for i, sp := range ref.span {
+			key := styleRef{src: ref.src, idx: sp.StyleIndex}
+			idx, ok := styleIndex[key]
+			if !ok {
+				if int(sp.StyleIndex) >= len(ref.src.Styles) || len(merged.Styles) >= 0xFFFF {
+					return nil, false
+				}
+				idx = uint16(len(merged.Styles))
+				merged.Styles = append(merged.Styles, ref.src.Styles[sp.StyleIndex])
+				styleIndex[key] = idx
+			}
+			sp.StyleIndex = idx
+			spans[i] = sp
+		}
+		return spans, true
+	}
+
+	for _, abs := range order {
+		spans, ok := remap(rows[abs])
+		if !ok {
+			return BufferDelta{}, false
+		}
+		merged.Rows = append(merged.Rows, RowDelta{Row: uint16(abs - base), Spans: spans})
+	}
+	for _, idx := range decorOrder {
+		spans, ok := remap(decor[idx])
+		if !ok {
+			return BufferDelta{}, false
+		}
+		merged.DecorRows = append(merged.DecorRows, DecorRowDelta{RowIdx: idx, Spans: spans})
+	}
+	return merged, true
+}
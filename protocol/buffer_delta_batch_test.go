@@ -0,0 +1,140 @@
+package protocol
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBufferDeltaBatchRoundTrip(t *testing.T) {
+	a := BufferDelta{PaneID: [16]byte{1}, Revision: 3, Styles: []StyleEntry{{}}, Rows: []RowDelta{{Row: 0, Spans: []CellSpan{{Text: "a"}}}}}
+	b := BufferDelta{PaneID: [16]byte{2}, Revision: 9, Flags: BufferDeltaAltScreen, Styles: []StyleEntry{{}}, Rows: []RowDelta{{Row: 4, Spans: []CellSpan{{Text: "b"}}}}}
+	var encoded [][]byte
+	for _, d := range []BufferDelta{a, b} {
+		p, err := EncodeBufferDelta(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, p)
+	}
+	payload, err := EncodeBufferDeltaBatch(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeBufferDeltaBatch(payload)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 || got[0].PaneID != a.PaneID || got[1].Revision != 9 || got[1].Rows[0].Spans[0].Text != "b" {
+		t.Fatalf("unexpected batch: %+v", got)
+	}
+	if _, err := DecodeBufferDeltaBatch(payload[:len(payload)-3]); !errors.Is(err, ErrPayloadShort) {
+		t.Fatalf("truncated batch: got %v", err)
+	}
+}
+
+// applyRows replays deltas the way the client caches key rows: content
+// by absolute index, decoration by rowIdx, newest whole row wins.
+func applyRows(deltas ...BufferDelta) (map[int64]string, map[uint16]string) {
+	content := make(map[int64]string)
+	decor := make(map[uint16]string)
+	render := func(d BufferDelta, spans []CellSpan) string {
+		out := ""
+		for _, sp := range spans {
+			st := d.Styles[sp.StyleIndex]
+			out += sp.Text + "/" + string(rune('0'+st.FgValue)) + ";"
+		}
+		return out
+	}
+	for _, d := range deltas {
+		for _, r := range d.Rows {
+			content[d.RowBase+int64(r.Row)] = render(d, r.Spans)
+		}
+		for _, r := range d.DecorRows {
+			decor[r.RowIdx] = render(d, r.Spans)
+		}
+	}
+	return content, decor
+}
+
+func TestMergeBufferDeltasMatchesSequentialApply(t *testing.T) {
+	red := StyleEntry{FgModel: ColorModelANSI16, FgValue: 1}
+	green := StyleEntry{FgModel: ColorModelANSI16, FgValue: 2}
+	blue := StyleEntry{FgModel: ColorModelANSI16, FgValue: 3}
+	older := BufferDelta{
+		PaneID: [16]byte{7}, Revision: 4, RowBase: 100,
+		Styles: []StyleEntry{red, green},
+		Rows: []RowDelta{
+			{Row: 0, Spans: []CellSpan{{Text: "old top", StyleIndex: 1}}},
+			{Row: 2, Spans: []CellSpan{{Text: "replaced", StyleIndex: 0}}},
+		},
+		DecorRows: []DecorRowDelta{{RowIdx: 0, Spans: []CellSpan{{Text: "border", StyleIndex: 0}}}},
+	}
+	newer := BufferDelta{
+		PaneID: [16]byte{7}, Revision: 6, RowBase: 101,
+		Styles: []StyleEntry{blue, red},
+		Rows: []RowDelta{
+			{Row: 1, Spans: []CellSpan{{Text: "new", StyleIndex: 0}, {Text: "er", StyleIndex: 1}}},
+			{Row: 5, Spans: []CellSpan{{Text: "bottom", StyleIndex: 1}}},
+		},
+		DecorRows: []DecorRowDelta{{RowIdx: 3, Spans: []CellSpan{{Text: "status", StyleIndex: 0}}}},
+	}
+
+	merged, ok := MergeBufferDeltas(older, newer)
+	if !ok {
+		t.Fatalf("expected merge")
+	}
+	if merged.Revision != 6 {
+		t.Fatalf("revision = %d, want 6", merged.Revision)
+	}
+	wantRows, wantDecor := applyRows(older, newer)
+	gotRows, gotDecor := applyRows(merged)
+	if !reflect.DeepEqual(gotRows, wantRows) || !reflect.DeepEqual(gotDecor, wantDecor) {
+		t.Fatalf("merged state differs:\n got %v %v\nwant %v %v", gotRows, gotDecor, wantRows, wantDecor)
+	}
+	// Four (source, index) styles are still referenced; older's red
+	// survives only through the border row.
+	if len(merged.Styles) != 4 {
+		t.Fatalf("unexpected style table size %d", len(merged.Styles))
+	}
+	if _, err := EncodeBufferDelta(merged); err != nil {
+		t.Fatalf("merged delta must encode: %v", err)
+	}
+}
+
+func TestMergeBufferDeltasRejectsIncompatiblePairs(t *testing.T) {
+	base := BufferDelta{PaneID: [16]byte{1}, Styles: []StyleEntry{{}}}
+	other := base
+	other.PaneID = [16]byte{2}
+	if _, ok := MergeBufferDeltas(base, other); ok {
+		t.Fatalf("different panes must not merge")
+	}
+	alt := base
+	alt.Flags = BufferDeltaAltScreen
+	if _, ok := MergeBufferDeltas(base, alt); ok {
+		t.Fatalf("alt-screen switch must not merge")
+	}
+	far := base
+	far.RowBase = 1 << 20
+	far.Rows = []RowDelta{{Row: 0, Spans: []CellSpan{{Text: "x"}}}}
+	near := base
+	near.Rows = []RowDelta{{Row: 0, Spans: []CellSpan{{Text: "y"}}}}
+	if _, ok := MergeBufferDeltas(near, far); ok {
+		t.Fatalf("rows beyond a uint16 offset must not merge")
+	}
+}
+
+func TestMergeBufferDeltasDoesNotGrowStyles(t *testing.T) {
+	d := BufferDelta{PaneID: [16]byte{1}, Styles: []StyleEntry{{FgValue: 1}, {FgValue: 2}}, Rows: []RowDelta{{Row: 0, Spans: []CellSpan{{Text: "x", StyleIndex: 1}}}}}
+	merged := d
+	for i := 0; i < 50; i++ {
+		var ok bool
+		merged, ok = MergeBufferDeltas(merged, d)
+		if !ok {
+			t.Fatalf("merge %d failed", i)
+		}
+	}
+	if len(merged.Styles) != 1 || len(merged.Rows) != 1 {
+		t.Fatalf("styles=%d rows=%d, want 1/1", len(merged.Styles), len(merged.Rows))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: protocol/compression.go
+// Summary: Negotiated per-connection payload compression.
+// Usage: The client advertises CapCompression in MsgHello; a server that
+//   agrees echoes it in MsgWelcome, then runs large server→client payloads
+//   through a Compressor. The client wraps its conn with
+//   NewDecompressingConn so ReadMessage inflates flagged frames.
+// Notes: Frames share one flate stream per connection (sync-flushed per
+//   frame), so later frames back-reference earlier ones. Compressed frames
+//   must therefore be decoded in the order they were written; uncompressed
+//   frames may be interleaved freely.
+
+package protocol
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Capability bits carried in Hello.Capabilities and Welcome.Capabilities.
+const (
+	// CapCompression: the client can inflate FlagCompressed frames (in
+	// Hello) / the server may send them (in Welcome).
+	CapCompression uint32 = 1 << 0
+)
+
+// CompressionThreshold is the smallest payload worth compressing. Below
+// it the flate block overhead eats most of the gain.
+const CompressionThreshold = 512
+
+var ErrCorruptCompressed = errors.New("protocol: corrupt compressed payload")
+
+// Compressible reports whether frames of type t are eligible for
+// compression: the bulky server→client messages.
+func Compressible(t MessageType) bool {
+	switch t {
+	case MsgBufferDelta, MsgBufferDeltaBatch, MsgFetchRangeResponse, MsgTreeSnapshot, MsgImageUpload:
+		return true
+	}
+	return false
+}
+
+// Compressor deflates payloads for one connection direction. Compress
+// must be called in the same order as the frames are written, which
+// callers get for free by compressing under their write lock.
+type Compressor struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	fw  *flate.Writer
+}
+
+// NewCompressor returns a compressor with an empty shared window.
+func NewCompressor() *Compressor {
+	c := &Compressor{}
+	// BestSpeed: redraw bursts are latency sensitive and most of the win
+	// comes from back-references into the shared window anyway.
+	c.fw, _ = flate.NewWriter(&c.buf, flate.BestSpeed)
+	return c
+}
+
+// Compress returns the wire payload for a frame and sets FlagCompressed
+// on hdr when it compressed. Ineligible or small payloads pass through
+// untouched. The compressed form is uvarint(original length) followed by
+// the flate data of this frame, ending in a sync flush.
+func (c *Compressor) Compress(hdr *Header, payload []byte) []byte {
+	if c == nil || !Compressible(hdr.Type) || len(payload) < CompressionThreshold {
+		return payload
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf.Reset()
+	var lenBuf [binary.MaxVarintLen64]byte
+	c.buf.Write(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(payload)))])
+	_, _ = c.fw.Write(payload) // writes to a bytes.Buffer cannot fail
+	_ = c.fw.Flush()
+	hdr.Flags |= FlagCompressed
+	return append([]byte(nil), c.buf.Bytes()...)
+}
+
+// Decompressor inflates FlagCompressed payloads produced by a peer's
+// Compressor. Decompress must see every compressed frame, in order.
+type Decompressor struct {
+	mu  sync.Mutex
+	in  frameFeeder
+	fr  io.ReadCloser
+	err error
+}
+
+// NewDecompressor returns a decompressor with an empty shared window.
+func NewDecompressor() *Decompressor {
+	d := &Decompressor{}
+	d.fr = flate.NewReader(&d.in)
+	return d
+}
+
+// Decompress returns the original payload. Any error is sticky: the
+// shared window is lost, so the connection must be dropped.
+func (d *Decompressor) Decompress(payload []byte) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return nil, d.err
+	}
+	n, k := binary.Uvarint(payload)
+	if k <= 0 || n > uint64(MaxPayloadLen) {
+		d.err = ErrCorruptCompressed
+		return nil, d.err
+	}
+	d.in.add(payload[k:])
+	out := make([]byte, n)
+	if _, err := io.ReadFull(d.fr, out); err != nil {
+		d.err = fmt.Errorf("%w: %v", ErrCorruptCompressed, err)
+		return nil, d.err
+	}
+	return out, nil
+}
+
+// frameFeeder hands compressed bytes to the flate reader one at a time.
+// Implementing io.ByteReader stops flate from reading ahead, and running
+// dry reports io.ErrUnexpectedEOF instead of io.EOF so a truncated frame
+// is an error rather than a clean end of stream. Bytes flate has not
+// consumed yet (the tail of a sync flush) carry over to the next frame.
+type frameFeeder struct {
+	data []byte
+}
+
+func (f *frameFeeder) add(b []byte) {
+	f.data = append(f.data, b...)
+}
+
+func (f *frameFeeder) ReadByte() (byte, error) {
+	if len(f.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := f.data[0]
+	f.data = f.data[1:]
+	return b, nil
+}
+
+func (f *frameFeeder) Read(p []byte) (int, error) {
+	if len(f.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(p, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+// DecompressingConn is a client connection that negotiated compression.
+// ReadMessage recognises it and inflates compressed frames, so existing
+// read loops work unchanged.
+type DecompressingConn struct {
+	net.Conn
+	dec *Decompressor
+}
+
+// NewDecompressingConn wraps conn with a fresh Decompressor.
+func NewDecompressingConn(conn net.Conn) *DecompressingConn {
+	return &DecompressingConn{Conn: conn, dec: NewDecompressor()}
+}
+
+// readMessage reads one frame and inflates it if flagged. The returned
+// header describes the inflated payload.
+func (c *DecompressingConn) readMessage() (Header, []byte, error) {
+	hdr, payload, err := readFrame(c.Conn)
+	if err != nil || hdr.Flags&FlagCompressed == 0 {
+		return hdr, payload, err
+	}
+	payload, err = c.dec.Decompress(payload)
+	if err != nil {
+		return hdr, nil, err
+	}
+	hdr.Flags &^= FlagCompressed
+	hdr.PayloadLen = uint32(len(payload))
+	return hdr, payload, nil
+}
@@ -0,0 +1,257 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func compressibleDelta(rev uint32) []byte {
+	rows := make([]RowDelta, 40)
+	for i := range rows {
+		rows[i] = RowDelta{Row: uint16(i), Spans: []CellSpan{{StartCol: 0, Text: fmt.Sprintf("%-60s", fmt.Sprintf("line %d of output, revision %d", i, rev)), StyleIndex: 0}}}
+	}
+	payload, err := EncodeBufferDelta(BufferDelta{
+		PaneID:   [16]byte{1},
+		Revision: rev,
+		Styles:   []StyleEntry{{FgModel: ColorModelDefault, BgModel: ColorModelDefault}},
+		Rows:     rows,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return payload
+}
+
+func TestCompressorSkipsSmallAndIneligiblePayloads(t *testing.T) {
+	c := NewCompressor()
+	small := Header{Type: MsgBufferDelta}
+	if out := c.Compress(&small, []byte("tiny")); string(out) != "tiny" || small.Flags&FlagCompressed != 0 {
+		t.Fatalf("small payload should pass through")
+	}
+	big := bytes.Repeat([]byte("x"), 4*CompressionThreshold)
+	key := Header{Type: MsgKeyEvent}
+	if out := c.Compress(&key, big); !bytes.Equal(out, big) || key.Flags&FlagCompressed != 0 {
+		t.Fatalf("ineligible type should pass through")
+	}
+	var nilComp *Compressor
+	delta := Header{Type: MsgBufferDelta}
+	if out := nilComp.Compress(&delta, big); !bytes.Equal(out, big) {
+		t.Fatalf("nil compressor should pass through")
+	}
+}
+
+func TestCompressedFramesRoundTripInOrder(t *testing.T) {
+	srv, cli := net.Pipe()
+	defer srv.Close()
+	defer cli.Close()
+	conn := NewDecompressingConn(cli)
+
+	comp := NewCompressor()
+	var frames [][]byte
+	var wireBytes, rawBytes int
+	for i := 0; i < 6; i++ {
+		frames = append(frames, compressibleDelta(uint32(i)))
+		if i == 2 {
+			// An uncompressed frame between compressed ones.
+			frames = append(frames, []byte("pong"))
+		}
+	}
+
+	go func() {
+		for _, f := range frames {
+			hdr := Header{Version: Version, Type: MsgBufferDelta, Flags: FlagChecksum}
+			if string(f) == "pong" {
+				hdr.Type = MsgPong
+			}
+			wire := comp.Compress(&hdr, f)
+			wireBytes += len(wire)
+			rawBytes += len(f)
+			if err := WriteMessage(srv, hdr, wire); err != nil {
+				t.Errorf("write: %v", err)
+				return
+			}
+		}
+	}()
+
+	for i, want := range frames {
+		hdr, got, err := ReadMessage(conn)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if hdr.Flags&FlagCompressed != 0 {
+			t.Fatalf("frame %d: flag should be cleared after inflating", i)
+		}
+		if int(hdr.PayloadLen) != len(want) || !bytes.Equal(got, want) {
+			t.Fatalf("frame %d: payload mismatch (%d vs %d bytes)", i, len(got), len(want))
+		}
+	}
+	if wireBytes*4 > rawBytes {
+		t.Fatalf("expected at least 4x reduction, got %d -> %d bytes", rawBytes, wireBytes)
+	}
+}
+
+func TestDecompressorRejectsCorruptInputAndStaysFailed(t *testing.T) {
+	d := NewDecompressor()
+	if _, err := d.Decompress([]byte{0x80}); !errors.Is(err, ErrCorruptCompressed) {
+		t.Fatalf("truncated length: got %v", err)
+	}
+	if _, err := d.Decompress([]byte{4, 0xff, 0xff}); !errors.Is(err, ErrCorruptCompressed) {
+		t.Fatalf("sticky error expected, got %v", err)
+	}
+
+	d = NewDecompressor()
+	c := NewCompressor()
+	hdr := Header{Type: MsgTreeSnapshot}
+	wire := c.Compress(&hdr, []byte(strings.Repeat("snapshot ", 200)))
+	if _, err := d.Decompress(wire[:len(wire)/2]); !errors.Is(err, ErrCorruptCompressed) {
+		t.Fatalf("truncated frame: got %v", err)
+	}
+}
+
+func TestWelcomeCapabilities(t *testing.T) {
+	payload, err := EncodeWelcome(Welcome{ServerName: "srv", Capabilities: CapCompression})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := DecodeWelcome(payload)
+	if err != nil || w.Capabilities != CapCompression || w.ServerName != "srv" {
+		t.Fatalf("round trip: %+v, %v", w, err)
+	}
+	// A pre-negotiation server omits the capability word.
+	w, err = DecodeWelcome(payload[:len(payload)-4])
+	if err != nil || w.Capabilities != 0 {
+		t.Fatalf("legacy welcome: %+v, %v", w, err)
+	}
+}
+
+// benchWorkload is a stream of frames for the compression benchmarks.
+type benchWorkload struct {
+	name   string
+	frames [][]byte
+}
+
+// benchWorkloads mirrors the client buffercache benchmarks (24 short rows)
+// and BenchmarkEncodeBufferDelta (a full 80x24 redraw), plus scrolling
+// output whose rows change every frame. Each stream has 16 revisions.
+func benchWorkloads(b *testing.B) []benchWorkload {
+	b.Helper()
+	delta := func(rev uint32, rows int, text func(row int) string, style StyleEntry) []byte {
+		d := BufferDelta{PaneID: [16]byte{1, 2, 3, 4}, Revision: rev, Styles: []StyleEntry{style}, Rows: make([]RowDelta, rows)}
+		for i := range d.Rows {
+			d.Rows[i] = RowDelta{Row: uint16(i), Spans: []CellSpan{{StartCol: 0, Text: text(i), StyleIndex: 0}}}
+		}
+		payload, err := EncodeBufferDelta(d)
+		if err != nil {
+			b.Fatalf("encode: %v", err)
+		}
+		return payload
+	}
+	plain := StyleEntry{FgModel: ColorModelDefault, BgModel: ColorModelDefault}
+	bold := StyleEntry{AttrFlags: AttrBold, FgModel: ColorModelRGB, FgValue: 0xFFFFFF, BgModel: ColorModelRGB, BgValue: 0x000000}
+	workloads := []benchWorkload{{name: "SampleRows"}, {name: "FullRedraw"}, {name: "Scrolling"}}
+	for rev := uint32(0); rev < 16; rev++ {
+		workloads[0].frames = append(workloads[0].frames, delta(rev, 24, func(int) string { return "sample text" }, plain))
+		workloads[1].frames = append(workloads[1].frames, delta(rev, 24, func(int) string { return strings.Repeat("A", 80) }, bold))
+		workloads[2].frames = append(workloads[2].frames, compressibleDelta(rev))
+	}
+	return workloads
+}
+
+// reportWire reports the average payload size before and after
+// compression per frame.
+func reportWire(b *testing.B, raw, wire int) {
+	b.ReportMetric(float64(raw)/float64(b.N), "raw-B/op")
+	b.ReportMetric(float64(wire)/float64(b.N), "wire-B/op")
+}
+
+// BenchmarkCompress runs each workload through one Compressor, so frames
+// back-reference earlier ones through the shared window.
+func BenchmarkCompress(b *testing.B) {
+	for _, w := range benchWorkloads(b) {
+		b.Run(w.name, func(b *testing.B) {
+			c := NewCompressor()
+			raw, wire := 0, 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f := w.frames[i%len(w.frames)]
+				hdr := Header{Type: MsgBufferDelta}
+				raw += len(f)
+				wire += len(c.Compress(&hdr, f))
+			}
+			reportWire(b, raw, wire)
+		})
+	}
+}
+
+// BenchmarkCompressPerFrame compresses every frame with a fresh
+// Compressor: the cost and size without the shared window.
+func BenchmarkCompressPerFrame(b *testing.B) {
+	for _, w := range benchWorkloads(b) {
+		b.Run(w.name, func(b *testing.B) {
+			raw, wire := 0, 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f := w.frames[i%len(w.frames)]
+				hdr := Header{Type: MsgBufferDelta}
+				raw += len(f)
+				wire += len(NewCompressor().Compress(&hdr, f))
+			}
+			reportWire(b, raw, wire)
+		})
+	}
+}
+
+// compressStream compresses frames in order, with one shared Compressor or
+// a fresh one per frame, keeping only the frames that were compressed.
+func compressStream(frames [][]byte, shared bool) (wire [][]byte, raw []int) {
+	c := NewCompressor()
+	for _, f := range frames {
+		if !shared {
+			c = NewCompressor()
+		}
+		hdr := Header{Type: MsgBufferDelta}
+		out := c.Compress(&hdr, f)
+		if hdr.Flags&FlagCompressed != 0 {
+			wire = append(wire, out)
+			raw = append(raw, len(f))
+		}
+	}
+	return wire, raw
+}
+
+func benchmarkDecompress(b *testing.B, shared bool) {
+	for _, w := range benchWorkloads(b) {
+		b.Run(w.name, func(b *testing.B) {
+			wire, raw := compressStream(w.frames, shared)
+			if len(wire) == 0 {
+				b.Skipf("%s frames are below CompressionThreshold", w.name)
+			}
+			d := NewDecompressor()
+			rawTotal, wireTotal := 0, 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := i % len(wire)
+				if !shared || k == 0 {
+					// A shared stream must be replayed from its start.
+					d = NewDecompressor()
+				}
+				if _, err := d.Decompress(wire[k]); err != nil {
+					b.Fatalf("frame %d: %v", k, err)
+				}
+				rawTotal += raw[k]
+				wireTotal += len(wire[k])
+			}
+			reportWire(b, rawTotal, wireTotal)
+		})
+	}
+}
+
+// BenchmarkDecompress inflates a stream written through one Compressor.
+func BenchmarkDecompress(b *testing.B) { benchmarkDecompress(b, true) }
+
+// BenchmarkDecompressPerFrame inflates frames compressed independently.
+func BenchmarkDecompressPerFrame(b *testing.B) { benchmarkDecompress(b, false) }
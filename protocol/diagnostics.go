@@ -0,0 +1,119 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: protocol/diagnostics.go
+// Summary: Periodic server health samples pushed to opted-in clients.
+// Usage: A client that advertises CapDiagnostics in MsgHello receives one
+//   MsgDiagnostics frame per server sampling interval.
+// Notes: Durations travel as microseconds; sub-microsecond precision is not
+//   useful for a live graph and keeps every field a fixed-width integer.
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// CapDiagnostics: the client wants MsgDiagnostics samples.
+	CapDiagnostics uint32 = 1 << 2
+)
+
+// MaxDiagnosticsPanes caps the per-pane entries in one sample.
+const MaxDiagnosticsPanes = 0xFFFF
+
+var ErrDiagnosticsTooLarge = errors.New("protocol: diagnostics sample exceeds limits")
+
+// Diagnostics is one sample of server health. Queue depth and diff counters
+// are summed over live sessions; latencies are the worst seen during the
+// interval.
+type Diagnostics struct {
+	UnixMillis     int64
+	IntervalMicros uint32
+	PublishMicros  uint32
+	FlushMicros    uint32
+	DiffQueueDepth uint32
+	DroppedDiffs   uint64
+	CoalescedDiffs uint64
+	IndexBacklog   uint32
+	Goroutines     uint32
+	Panes          []PaneDiagnostics
+}
+
+// PaneDiagnostics reports one pane's slowest render in the interval.
+type PaneDiagnostics struct {
+	PaneID       [16]byte
+	Title        string
+	RenderMicros uint32
+}
+
+func EncodeDiagnostics(d Diagnostics) ([]byte, error) {
+	if len(d.Panes) > MaxDiagnosticsPanes {
+		return nil, ErrDiagnosticsTooLarge
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 48+len(d.Panes)*32))
+	fixed := []any{
+		d.UnixMillis, d.IntervalMicros, d.PublishMicros, d.FlushMicros,
+		d.DiffQueueDepth, d.DroppedDiffs, d.CoalescedDiffs, d.IndexBacklog,
+		d.Goroutines, uint16(len(d.Panes)),
+	}
+	for _, v := range fixed {
+		if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range d.Panes {
+		buf.Write(p.PaneID[:])
+		if err := encodeString(buf, p.Title); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(buf, binary.LittleEndian, p.RenderMicros); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func DecodeDiagnostics(b []byte) (Diagnostics, error) {
+	var d Diagnostics
+	const fixedLen = 8 + 4*4 + 8*2 + 4*2 + 2
+	if len(b) < fixedLen {
+		return d, ErrPayloadShort
+	}
+	le := binary.LittleEndian
+	d.UnixMillis = int64(le.Uint64(b[0:]))
+	d.IntervalMicros = le.Uint32(b[8:])
+	d.PublishMicros = le.Uint32(b[12:])
+	d.FlushMicros = le.Uint32(b[16:])
+	d.DiffQueueDepth = le.Uint32(b[20:])
+	d.DroppedDiffs = le.Uint64(b[24:])
+	d.CoalescedDiffs = le.Uint64(b[32:])
+	d.IndexBacklog = le.Uint32(b[40:])
+	d.Goroutines = le.Uint32(b[44:])
+	count := int(le.Uint16(b[48:]))
+	b = b[fixedLen:]
+	if count > 0 {
+		d.Panes = make([]PaneDiagnostics, 0, count)
+	}
+	for i := 0; i < count; i++ {
+		var p PaneDiagnostics
+		if len(b) < 16 {
+			return d, ErrPayloadShort
+		}
+		copy(p.PaneID[:], b[:16])
+		title, rest, err := decodeString(b[16:])
+		if err != nil {
+			return d, err
+		}
+		if len(rest) < 4 {
+			return d, ErrPayloadShort
+		}
+		p.Title = title
+		p.RenderMicros = le.Uint32(rest)
+		d.Panes = append(d.Panes, p)
+		b = rest[4:]
+	}
+	return d, nil
+}
@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsRoundTrip(t *testing.T) {
+	in := Diagnostics{
+		UnixMillis:     1_700_000_000_123,
+		IntervalMicros: 1_000_000,
+		PublishMicros:  4200,
+		FlushMicros:    15000,
+		DiffQueueDepth: 7,
+		DroppedDiffs:   3,
+		CoalescedDiffs: 90,
+		IndexBacklog:   512,
+		Goroutines:     41,
+		Panes: []PaneDiagnostics{
+			{PaneID: [16]byte{1}, Title: "htop", RenderMicros: 900},
+			{PaneID: [16]byte{2}, Title: "", RenderMicros: 12},
+		},
+	}
+	payload, err := EncodeDiagnostics(in)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out, err := DecodeDiagnostics(payload)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", out, in)
+	}
+	if _, err := DecodeDiagnostics(payload[:len(payload)-2]); !errors.Is(err, ErrPayloadShort) {
+		t.Fatalf("truncated sample: got %v", err)
+	}
+}
@@ -30,8 +30,9 @@ type Hello struct {
 
 // Welcome is returned by the server acknowledging the handshake.
 type Welcome struct {
-	SessionID  [16]byte
-	ServerName string
+	SessionID    [16]byte
+	ServerName   string
+	Capabilities uint32 // subset of Hello.Capabilities the server enabled
 }
 
 // ConnectRequest attaches or creates a session on the server.
@@ -167,6 +168,8 @@ const (
 	PaneStateActive PaneStateFlags = 1 << iota
 	PaneStateResizing
 	PaneStateSelectionDelegated
+	PaneStateActivity // monitored pane printed while out of view
+	PaneStateSilence  // monitored pane has been quiet while out of view
 )
 
 // PaneState reports transient pane flags (active, resizing, etc.).
@@ -212,6 +215,8 @@ const (
 	SplitNone SplitKind = iota
 	SplitHorizontal
 	SplitVertical
+	// SplitTabbed children share the node's rect; only ActiveTab is shown.
+	SplitTabbed
 )
 
 // TreeNodeSnapshot captures either a leaf pane index or an internal split node.
@@ -220,6 +225,10 @@ type TreeNodeSnapshot struct {
 	Split       SplitKind
 	SplitRatios []float32
 	Children    []TreeNodeSnapshot
+	ActiveTab   uint16 // only encoded for SplitTabbed nodes
+	// UntabbedSplit is the split a SplitTabbed node returns to when
+	// toggled; only encoded for SplitTabbed nodes.
+	UntabbedSplit SplitKind
 }
 
 // TreeSnapshot aggregates pane snapshots along with the layout tree so peers can rebuild geometry.
@@ -292,6 +301,9 @@ func EncodeWelcome(w Welcome) ([]byte, error) {
 	if err := encodeString(buf, w.ServerName); err != nil {
 		return nil, err
 	}
+	if err := binary.Write(buf, binary.LittleEndian, w.Capabilities); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
@@ -301,11 +313,15 @@ func DecodeWelcome(b []byte) (Welcome, error) {
 		return w, ErrPayloadShort
 	}
 	copy(w.SessionID[:], b[:16])
-	name, _, err := decodeString(b[16:])
+	name, rest, err := decodeString(b[16:])
 	if err != nil {
 		return w, err
 	}
 	w.ServerName = name
+	// Servers predating capability negotiation end the payload here.
+	if len(rest) >= 4 {
+		w.Capabilities = binary.LittleEndian.Uint32(rest[:4])
+	}
 	return w, nil
 }
 
@@ -1113,6 +1129,12 @@ func encodeTreeNode(buf *bytes.Buffer, node TreeNodeSnapshot) error {
 			}
 		}
 	}
+	if node.Split == SplitTabbed {
+		if err := binary.Write(buf, binary.LittleEndian, node.ActiveTab); err != nil {
+			return err
+		}
+		return buf.WriteByte(byte(node.UntabbedSplit))
+	}
 	return nil
 }
 
@@ -1145,6 +1167,14 @@ func decodeTreeNode(b []byte) (TreeNodeSnapshot, []byte, error) {
 			b = rest
 		}
 	}
+	if node.Split == SplitTabbed {
+		if len(b) < 3 {
+			return node, nil, ErrPayloadShort
+		}
+		node.ActiveTab = binary.LittleEndian.Uint16(b[:2])
+		node.UntabbedSplit = SplitKind(b[2])
+		b = b[3:]
+	}
 	return node, b, nil
 }
 
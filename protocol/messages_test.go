@@ -239,6 +239,37 @@ func TestTreeSnapshotRoundTrip(t *testing.T) {
 	}
 }
 
+func TestTreeSnapshotTabbedRoundTrip(t *testing.T) {
+	snapshot := TreeSnapshot{
+		Root: TreeNodeSnapshot{
+			PaneIndex:   -1,
+			Split:       SplitTabbed,
+			SplitRatios: []float32{0.5, 0.5},
+			ActiveTab:   1,
+			// Not the zero value, so losing it shows.
+			UntabbedSplit: SplitHorizontal,
+			Children: []TreeNodeSnapshot{
+				{PaneIndex: 0, Split: SplitNone},
+				{PaneIndex: 1, Split: SplitNone},
+			},
+		},
+	}
+	payload, err := EncodeTreeSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	decoded, err := DecodeTreeSnapshot(payload)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if decoded.Root.Split != SplitTabbed || decoded.Root.ActiveTab != 1 || decoded.Root.UntabbedSplit != SplitHorizontal || len(decoded.Root.Children) != 2 {
+		t.Fatalf("unexpected root node: %#v", decoded.Root)
+	}
+	if decoded.Root.Children[1].PaneIndex != 1 {
+		t.Fatalf("unexpected children: %#v", decoded.Root.Children)
+	}
+}
+
 func TestStateUpdateRoundTrip(t *testing.T) {
 	update := StateUpdate{
 		WorkspaceID:   7,
@@ -22,7 +22,8 @@ const (
 
 // Flag bits for the header Flags byte.
 const (
-	FlagChecksum uint8 = 0x01
+	FlagChecksum   uint8 = 0x01
+	FlagCompressed uint8 = 0x02 // payload deflated; see compression.go
 )
 
 // Version is the negotiated protocol version implemented by this package.
@@ -32,7 +33,11 @@ const (
 // PaneViewportState records). Bumping the version lets pre-Plan-B clients
 // receive an explicit handshake rejection instead of a mysterious
 // ErrPayloadShort on the first resume attempt.
-const Version uint8 = 3
+//
+// v4: SplitTabbed tree nodes carry their active tab and the split they
+// return to when untabbed, so a v3 peer would misread every tree holding
+// tabs.
+const Version uint8 = 4
 
 // MessageType enumerates the canonical message categories exchanged between
 // client and server.
@@ -74,6 +79,9 @@ const (
 	MsgViewportUpdate
 	MsgFetchRange
 	MsgFetchRangeResponse
+	MsgBufferDeltaBatch
+	MsgDiagnostics
+	MsgLockRequest // client asks the server to lock the screen (no payload)
 )
 
 // Header describes the fixed portion of every frame exchanged over the wire.
@@ -140,8 +148,17 @@ func WriteMessage(w io.Writer, hdr Header, payload []byte) error {
 }
 
 // ReadMessage reads a header and payload from r. The returned payload points to
-// a freshly allocated slice sized to the declared payload length.
+// a freshly allocated slice sized to the declared payload length. When r is a
+// DecompressingConn, compressed payloads are returned inflated.
 func ReadMessage(r io.Reader) (Header, []byte, error) {
+	if dc, ok := r.(*DecompressingConn); ok {
+		return dc.readMessage()
+	}
+	return readFrame(r)
+}
+
+// readFrame reads one frame as it appears on the wire.
+func readFrame(r io.Reader) (Header, []byte, error) {
 	var hdr Header
 	buf := make([]byte, headerSize)
 	if _, err := io.ReadFull(r, buf); err != nil {
@@ -0,0 +1,120 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/clipboard_history.go
+// Summary: Ring of recent clipboard entries with their source panes.
+// Usage: Every SetClipboard lands here; control mode "p" opens the picker
+//   (apps/cliphist) which pastes the chosen entry into the active pane.
+//   The ring size comes from clipboard.history_size in texelation.json.
+
+package texel
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/framegrace/texelation/config"
+)
+
+const defaultClipboardHistory = 50
+
+// ClipboardEntry is one remembered clipboard value.
+type ClipboardEntry struct {
+	ID   uint64
+	Mime string
+	Data []byte
+	// Source is the title of the pane the value was copied from, or empty
+	// when it arrived from a client.
+	Source   string
+	SourceID [16]byte
+	CopiedAt time.Time
+}
+
+// ClipboardHistorySetter is implemented by apps that list clipboard history.
+type ClipboardHistorySetter interface {
+	SetClipboardHistory(entries []ClipboardEntry)
+}
+
+func clipboardHistoryLimit() int {
+	if cfg := config.System(); cfg != nil {
+		if n := cfg.GetInt("clipboard", "history_size", defaultClipboardHistory); n > 0 {
+			return n
+		}
+	}
+	return defaultClipboardHistory
+}
+
+// recordClipboardLocked pushes a value onto the history ring. A value that
+// is already in the ring moves to the front instead of repeating. Caller
+// holds clipboardMu.
+func (d *DesktopEngine) recordClipboardLocked(mime string, data []byte, sourceID [16]byte) {
+	if len(data) == 0 {
+		return
+	}
+	for i, e := range d.clipboardHistory {
+		if e.Mime == mime && bytes.Equal(e.Data, data) {
+			d.clipboardHistory = append(d.clipboardHistory[:i], d.clipboardHistory[i+1:]...)
+			break
+		}
+	}
+	d.clipboardSeq++
+	d.clipboardHistory = append(d.clipboardHistory, ClipboardEntry{
+		ID:       d.clipboardSeq,
+		Mime:     mime,
+		Data:     append([]byte(nil), data...),
+		SourceID: sourceID,
+		CopiedAt: time.Now(),
+	})
+	if limit := clipboardHistoryLimit(); len(d.clipboardHistory) > limit {
+		d.clipboardHistory = append([]ClipboardEntry(nil), d.clipboardHistory[len(d.clipboardHistory)-limit:]...)
+	}
+}
+
+// ClipboardHistory returns the ring, newest first. Source titles are
+// resolved here, on the desktop loop, because copies can arrive from app
+// goroutines that must not call back into their own app.
+func (d *DesktopEngine) ClipboardHistory() []ClipboardEntry {
+	titles := make(map[[16]byte]string)
+	d.forEachPane(func(p *pane) {
+		titles[p.ID()] = p.getTitle()
+	})
+	for _, cp := range d.closedPanes {
+		titles[cp.ID] = cp.Title
+	}
+
+	d.clipboardMu.Lock()
+	defer d.clipboardMu.Unlock()
+	out := make([]ClipboardEntry, 0, len(d.clipboardHistory))
+	for i := len(d.clipboardHistory) - 1; i >= 0; i-- {
+		e := &d.clipboardHistory[i]
+		if e.Source == "" && e.SourceID != ([16]byte{}) {
+			e.Source = titles[e.SourceID]
+		}
+		out = append(out, *e)
+	}
+	return out
+}
+
+// PasteClipboardEntry makes a history entry the current clipboard and
+// pastes it into the active pane.
+func (d *DesktopEngine) PasteClipboardEntry(id uint64) bool {
+	d.clipboardMu.Lock()
+	var entry *ClipboardEntry
+	for i := range d.clipboardHistory {
+		if d.clipboardHistory[i].ID == id {
+			e := d.clipboardHistory[i]
+			entry = &e
+			break
+		}
+	}
+	d.clipboardMu.Unlock()
+	if entry == nil {
+		return false
+	}
+	d.setClipboard(entry.Mime, entry.Data, entry.SourceID)
+	if strings.HasPrefix(entry.Mime, "text/") {
+		d.handlePasteInternal(entry.Data)
+	}
+	return true
+}
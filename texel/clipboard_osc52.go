@@ -0,0 +1,252 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/clipboard_osc52.go
+// Summary: Per-pane clipboard service and the OSC 52 read/write policy.
+// Usage: Panes hand apps a paneClipboard instead of the desktop so copies
+//   carry their source, and terminal clipboard escapes (OSC 52) go through
+//   osc52Policy: "allow", "deny" or "ask". Asked requests wait for control
+//   mode y/n (once) or Y/N (for the rest of the pane's life).
+// Notes: Policy is looked up per pane override, then the pane app's config
+//   ("clipboard" section), then texelation.json; reads default to "ask" so
+//   a remote host inside ssh cannot silently read the local clipboard.
+
+package texel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/framegrace/texelation/config"
+	"github.com/framegrace/texelation/internal/debuglog"
+	"github.com/framegrace/texelation/internal/keybind"
+)
+
+// OSC52Policy decides what happens to a terminal clipboard request.
+type OSC52Policy string
+
+const (
+	OSC52Allow OSC52Policy = "allow"
+	OSC52Deny  OSC52Policy = "deny"
+	OSC52Ask   OSC52Policy = "ask"
+)
+
+// OSC52Clipboard is implemented by the clipboard service panes give their
+// apps. Terminals route OSC 52 through it instead of Set/GetClipboard so
+// the desktop can apply the policy. reply may run later, on the desktop
+// loop, and never runs for a denied read; if the desktop is too busy to
+// take the request it runs at once with an empty payload.
+type OSC52Clipboard interface {
+	OSC52Write(data []byte)
+	OSC52Read(reply func(data []byte))
+}
+
+const (
+	// osc52MaxPending bounds the queue of unanswered requests.
+	osc52MaxPending = 8
+	// osc52AskTimeout is how long an unanswered request stays answerable.
+	osc52AskTimeout = 30 * time.Second
+)
+
+type osc52Request struct {
+	pane  *pane
+	read  bool
+	data  []byte
+	reply func([]byte)
+	at    time.Time
+}
+
+func parseOSC52Policy(s string) OSC52Policy {
+	switch p := OSC52Policy(s); p {
+	case OSC52Allow, OSC52Deny, OSC52Ask:
+		return p
+	}
+	return ""
+}
+
+// paneClipboard is the ClipboardService handed to a pane's app.
+type paneClipboard struct {
+	d *DesktopEngine
+	p *pane
+}
+
+var _ ClipboardService = paneClipboard{}
+var _ OSC52Clipboard = paneClipboard{}
+
+func (c paneClipboard) SetClipboard(mime string, data []byte) {
+	c.d.setClipboard(mime, data, c.p.ID())
+}
+
+func (c paneClipboard) GetClipboard() (string, []byte, bool) {
+	return c.d.GetClipboard()
+}
+
+// OSC52Write and OSC52Read are called from the app's output goroutine,
+// often while the app holds its own lock, so they only queue work for the
+// desktop loop and never block. When the loop's queue is full a write is
+// posted from its own goroutine rather than dropped, and a read is answered
+// with an empty clipboard at once so the program asking is not left
+// waiting for a reply that never comes.
+func (c paneClipboard) OSC52Write(data []byte) {
+	data = append([]byte(nil), data...)
+	fn := func() { c.d.handleOSC52(c.p, false, data, nil) }
+	if !c.d.trySend(fn) {
+		go c.d.post(fn)
+	}
+}
+
+func (c paneClipboard) OSC52Read(reply func(data []byte)) {
+	if !c.d.trySend(func() { c.d.handleOSC52(c.p, true, nil, reply) }) {
+		debuglog.Printf("OSC 52 read from pane %x: desktop busy, replying empty", c.p.ID())
+		reply(nil)
+	}
+}
+
+// trySend queues fn for the desktop loop without blocking and reports
+// whether it was queued.
+func (d *DesktopEngine) trySend(fn func()) bool {
+	select {
+	case d.eventCh <- desktopEvent{kind: funcEventKind, fn: fn}:
+		return true
+	default:
+		return false
+	}
+}
+
+// osc52Policy resolves the policy for p.
+func (d *DesktopEngine) osc52Policy(p *pane, read bool) OSC52Policy {
+	key, policy, fallback := "osc52_write", p.osc52Write, OSC52Allow
+	if read {
+		key, policy, fallback = "osc52_read", p.osc52Read, OSC52Ask
+	}
+	if policy != "" {
+		return policy
+	}
+	if sp, ok := p.app.(SnapshotProvider); ok {
+		appType, _ := sp.SnapshotMetadata()
+		if cfg := config.App(appType); cfg != nil {
+			if policy = parseOSC52Policy(cfg.GetString("clipboard", key, "")); policy != "" {
+				return policy
+			}
+		}
+	}
+	if cfg := config.System(); cfg != nil {
+		if policy = parseOSC52Policy(cfg.GetString("clipboard", key, "")); policy != "" {
+			return policy
+		}
+	}
+	return fallback
+}
+
+func (d *DesktopEngine) handleOSC52(p *pane, read bool, data []byte, reply func([]byte)) {
+	policy := d.osc52Policy(p, read)
+	if d.locked.Load() && policy == OSC52Ask {
+		policy = OSC52Deny // nobody can answer while locked
+	}
+	switch policy {
+	case OSC52Allow:
+		d.performOSC52(osc52Request{pane: p, read: read, data: data, reply: reply})
+	case OSC52Ask:
+		d.osc52Pending = append(d.osc52Pending, osc52Request{pane: p, read: read, data: data, reply: reply, at: time.Now()})
+		if len(d.osc52Pending) > osc52MaxPending {
+			d.osc52Pending = d.osc52Pending[1:]
+		}
+		verb := "write to"
+		if read {
+			verb = "read"
+		}
+		d.BroadcastToast(fmt.Sprintf("%s wants to %s the clipboard. %s then %s allow, %s always, %s deny, %s never",
+			p.getTitle(), verb, d.keyLabel(keybind.ControlToggle, "Ctrl+A"),
+			d.keyLabel(keybind.ControlOSC52Allow, "y"), d.keyLabel(keybind.ControlOSC52AllowPane, "Y"),
+			d.keyLabel(keybind.ControlOSC52Deny, "n"), d.keyLabel(keybind.ControlOSC52DenyPane, "N")),
+			ToastWarning, 15*time.Second)
+	default:
+		debuglog.Printf("OSC 52 %s from pane %x denied by policy", osc52Direction(read), p.ID())
+	}
+}
+
+func (d *DesktopEngine) performOSC52(req osc52Request) {
+	if !req.read {
+		d.setClipboard("text/plain", req.data, req.pane.ID())
+		return
+	}
+	if _, data, ok := d.GetClipboard(); ok && req.reply != nil {
+		req.reply(data)
+	}
+}
+
+// answerOSC52 settles the oldest pending request. With remember set the
+// answer becomes the pane's policy for that direction and settles its
+// other queued requests of the same kind too.
+func (d *DesktopEngine) answerOSC52(allow, remember bool) {
+	now := time.Now()
+	var head *osc52Request
+	for len(d.osc52Pending) > 0 && head == nil {
+		req := d.osc52Pending[0]
+		d.osc52Pending = d.osc52Pending[1:]
+		if now.Sub(req.at) <= osc52AskTimeout && d.paneAttached(req.pane) {
+			head = &req
+		}
+	}
+	if head == nil {
+		d.BroadcastToast("No clipboard request is waiting", ToastInfo, 2*time.Second)
+		return
+	}
+
+	settle := []osc52Request{*head}
+	if remember {
+		policy := OSC52Deny
+		if allow {
+			policy = OSC52Allow
+		}
+		if head.read {
+			head.pane.osc52Read = policy
+		} else {
+			head.pane.osc52Write = policy
+		}
+		rest := d.osc52Pending[:0]
+		for _, req := range d.osc52Pending {
+			if req.pane == head.pane && req.read == head.read {
+				settle = append(settle, req)
+			} else {
+				rest = append(rest, req)
+			}
+		}
+		d.osc52Pending = rest
+	}
+	for _, req := range settle {
+		if allow {
+			d.performOSC52(req)
+		} else {
+			debuglog.Printf("OSC 52 %s from pane %x denied by user", osc52Direction(req.read), req.pane.ID())
+		}
+	}
+}
+
+func osc52Direction(read bool) string {
+	if read {
+		return "read"
+	}
+	return "write"
+}
+
+// paneAttached reports whether p is still in some workspace tree.
+func (d *DesktopEngine) paneAttached(p *pane) bool {
+	found := false
+	d.forEachPane(func(q *pane) {
+		if q == p {
+			found = true
+		}
+	})
+	return found
+}
+
+// keyLabel formats the first key bound to action.
+func (d *DesktopEngine) keyLabel(action keybind.Action, fallback string) string {
+	if d.keybindings != nil {
+		if keys := d.keybindings.KeysForAction(action); len(keys) > 0 {
+			return keybind.FormatKeyCombo(keys[0])
+		}
+	}
+	return fallback
+}
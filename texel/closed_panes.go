@@ -0,0 +1,224 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/closed_panes.go
+// Summary: Undo-close stack that rebuilds recently closed panes in place.
+// Usage: CloseActivePane records each closed pane once its removal (and
+//   close animation) completes; control mode "u" reopens the newest and
+//   "U" opens the reopen picker listing the rest.
+// Notes: A reopened pane keeps its old pane ID, so apps keyed by it (the
+//   terminal's WAL, page store, search index, env and cwd files) pick up
+//   where they left off. Only app types with a snapshot factory are kept.
+
+package texel
+
+import (
+	"slices"
+	"time"
+
+	"github.com/framegrace/texelation/internal/debuglog"
+)
+
+// closedPaneLimit caps how many closed panes can be reopened.
+const closedPaneLimit = 20
+
+// ClosedPaneInfo describes one entry of the undo-close stack.
+type ClosedPaneInfo struct {
+	ID          [16]byte
+	Title       string
+	AppType     string
+	WorkspaceID int
+	ClosedAt    time.Time
+}
+
+// ClosedPaneListSetter is implemented by the reopen picker, which receives
+// the stack newest first and reports its choice on the "reopen.select"
+// control with the pane ID as payload.
+type ClosedPaneListSetter interface {
+	SetClosedPanes(entries []ClosedPaneInfo)
+}
+
+type closedPane struct {
+	ClosedPaneInfo
+	appConfig map[string]interface{}
+	path      []int     // child indices from the workspace root to the pane
+	split     SplitType // split of the parent it was removed from
+	ratio     float64   // its share of that parent
+}
+
+// closedPaneEntry describes node's pane for the undo-close stack. It must
+// be taken while the node is still in the tree; ok is false for panes that
+// cannot be rebuilt.
+func (d *DesktopEngine) closedPaneEntry(ws *Workspace, node *Node) (cp closedPane, ok bool) {
+	if node == nil || node.Pane == nil {
+		return cp, false
+	}
+	provider, ok := node.Pane.app.(SnapshotProvider)
+	if !ok {
+		return cp, false
+	}
+	appType, cfg := provider.SnapshotMetadata()
+	if _, ok := d.snapshotFactories[appType]; !ok {
+		return cp, false
+	}
+	cp = closedPane{
+		ClosedPaneInfo: ClosedPaneInfo{
+			ID:          node.Pane.ID(),
+			Title:       node.Pane.getTitle(),
+			AppType:     appType,
+			WorkspaceID: ws.id,
+			ClosedAt:    time.Now(),
+		},
+		appConfig: cloneAppConfig(cfg),
+		split:     Vertical,
+		ratio:     0.5,
+	}
+	for child := node; child.Parent != nil; child = child.Parent {
+		index := slices.Index(child.Parent.Children, child)
+		cp.path = append([]int{index}, cp.path...)
+		if child == node {
+			cp.split = child.Parent.Split
+			if index >= 0 && index < len(child.Parent.SplitRatios) {
+				cp.ratio = child.Parent.SplitRatios[index]
+			}
+		}
+	}
+	return cp, true
+}
+
+// pushClosedPane puts cp on the undo-close stack. Callers push only once
+// the pane has left its tree (or will before the next event), so a reopen
+// never finds its ID still in use.
+func (d *DesktopEngine) pushClosedPane(cp closedPane) {
+	d.closedPanes = append(d.closedPanes, cp)
+	if len(d.closedPanes) > closedPaneLimit {
+		d.closedPanes = d.closedPanes[len(d.closedPanes)-closedPaneLimit:]
+	}
+}
+
+// ClosedPanes returns the undo-close stack, newest first.
+func (d *DesktopEngine) ClosedPanes() []ClosedPaneInfo {
+	out := make([]ClosedPaneInfo, 0, len(d.closedPanes))
+	for i := len(d.closedPanes) - 1; i >= 0; i-- {
+		out = append(out, d.closedPanes[i].ClosedPaneInfo)
+	}
+	return out
+}
+
+// UndoClosePane reopens the most recently closed pane.
+func (d *DesktopEngine) UndoClosePane() {
+	if len(d.closedPanes) == 0 {
+		d.BroadcastToast("No closed panes to reopen", ToastInfo, 2*time.Second)
+		return
+	}
+	d.ReopenClosedPane(d.closedPanes[len(d.closedPanes)-1].ID)
+}
+
+// ReopenClosedPane rebuilds the closed pane with the given ID where it used
+// to be and removes it from the stack. It reports false when the pane is
+// not on the stack.
+func (d *DesktopEngine) ReopenClosedPane(id [16]byte) bool {
+	i := slices.IndexFunc(d.closedPanes, func(cp closedPane) bool { return cp.ID == id })
+	if i < 0 {
+		return false
+	}
+	cp := d.closedPanes[i]
+	d.closedPanes = slices.Delete(d.closedPanes, i, i+1)
+
+	ws, ok := d.workspaces[cp.WorkspaceID]
+	if !ok {
+		ws = d.activeWorkspace
+	}
+	if ws == nil {
+		return false
+	}
+	if ws != d.activeWorkspace {
+		d.SwitchToWorkspace(ws.id)
+	}
+	if d.zoomedPane != nil {
+		d.toggleZoom()
+	}
+
+	p := newPane(ws)
+	p.setID(cp.ID)
+	leaf := &Node{Pane: p}
+	ws.insertAtPath(leaf, cp.path, cp.split, cp.ratio)
+	ws.recalculateLayout()
+	p.AttachApp(d.appFromSnapshot(PaneSnapshot{
+		ID:        cp.ID,
+		Title:     cp.Title,
+		AppType:   cp.AppType,
+		AppConfig: cp.appConfig,
+	}), ws.refreshChan)
+	debuglog.Printf("ReopenClosedPane: reopened '%s' in workspace %d at %v", cp.Title, ws.id, cp.path)
+
+	if cur := ws.tree.ActiveLeaf; cur != nil && cur.Pane != nil {
+		cur.Pane.SetActive(false)
+	}
+	ws.tree.ActiveLeaf = leaf
+	p.SetActive(true)
+	ws.recalculateLayout()
+	ws.notifyFocus()
+	d.broadcastTreeChanged()
+	d.broadcastActivePaneChanged()
+	return true
+}
+
+// insertAtPath puts leaf back at path. When the split it left still exists
+// the leaf rejoins it at its old index; otherwise it is paired with whatever
+// now occupies that spot, on the side it used to be.
+func (w *Workspace) insertAtPath(leaf *Node, path []int, split SplitType, ratio float64) {
+	if w.tree.Root == nil {
+		leaf.Parent = nil
+		w.tree.Root = leaf
+		return
+	}
+	index := 1
+	if len(path) > 0 {
+		index = path[len(path)-1]
+	}
+	ratio = min(max(ratio, 0.1), 0.9)
+
+	node := w.tree.Root
+	depth := 0
+	for ; depth < len(path)-1; depth++ {
+		if node.Pane != nil || path[depth] >= len(node.Children) {
+			break
+		}
+		node = node.Children[path[depth]]
+	}
+
+	if depth == len(path)-1 && node.Pane == nil && node.Split == split {
+		index = min(index, len(node.Children))
+		ratios := make([]float64, 0, len(node.Children)+1)
+		for _, r := range node.SplitRatios {
+			ratios = append(ratios, r*(1-ratio))
+		}
+		if len(ratios) != len(node.Children) {
+			ratios = equalRatios(len(node.Children))
+			for i := range ratios {
+				ratios[i] *= 1 - ratio
+			}
+		}
+		leaf.Parent = node
+		node.Children = slices.Insert(node.Children, index, leaf)
+		node.SplitRatios = slices.Insert(ratios, index, ratio)
+		return
+	}
+
+	wrapper := &Node{Parent: node.Parent, Split: split}
+	if parent := node.Parent; parent == nil {
+		w.tree.Root = wrapper
+	} else {
+		parent.Children[slices.Index(parent.Children, node)] = wrapper
+	}
+	node.Parent = wrapper
+	leaf.Parent = wrapper
+	if index == 0 {
+		wrapper.Children = []*Node{leaf, node}
+		wrapper.SplitRatios = []float64{ratio, 1 - ratio}
+	} else {
+		wrapper.Children = []*Node{node, leaf}
+		wrapper.SplitRatios = []float64{1 - ratio, ratio}
+	}
+}
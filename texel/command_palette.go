@@ -0,0 +1,204 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/command_palette.go
+// Summary: Builds and runs the entries of the command palette.
+// Usage: Control mode ":" opens the palette (apps/palette) with the list
+//   from paletteEntries: desktop and control actions, the focused app's
+//   actions, launchable apps, workspaces and panes. The app hands the chosen
+//   entry back and runPaletteEntry executes it.
+// Notes: Actions run through runAction, the same code the key bindings
+//   use. App actions are the focused app's control bus
+//   capabilities whose IDs name a keybind action (texelterm registers its
+//   actions that way); other controls on the bus are internal plumbing.
+
+package texel
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/framegrace/texelation/internal/keybind"
+)
+
+// PaletteEntry is one command palette row.
+type PaletteEntry struct {
+	Title string
+	// Category groups the entry ("Control", "Terminal", "App", "Workspace",
+	// "Pane"); the palette shows it dimmed and matches on it too.
+	Category string
+	// Keys is the current binding, empty when there is none.
+	Keys string
+
+	run func()
+}
+
+// PaletteEntriesSetter is implemented by apps that show the command palette.
+type PaletteEntriesSetter interface {
+	SetPaletteEntries(entries []PaletteEntry)
+}
+
+// paletteHidden lists actions the palette leaves out: toggling control
+// mode or reopening the palette from it makes no sense, and the client
+// handles screenshots and the screensaver itself.
+var paletteHidden = map[keybind.Action]bool{
+	keybind.ControlToggle:  true,
+	keybind.ControlPalette: true,
+	keybind.Screenshot:     true,
+	keybind.Screensaver:    true,
+}
+
+// paletteCategoryOrder orders the action groups in the unfiltered list.
+var paletteCategoryOrder = map[string]int{"Desktop": 0, "Control": 1, "Pane": 2, "Workspace": 3, "Terminal": 4}
+
+// paletteEntries lists everything the palette can run, in display order.
+func (d *DesktopEngine) paletteEntries() []PaletteEntry {
+	var entries []PaletteEntry
+
+	var actions []keybind.Action
+	for action, info := range keybind.ActionDescriptions {
+		if _, ok := paletteCategoryOrder[info.Category]; ok && info.Category != "Terminal" && !paletteHidden[action] {
+			actions = append(actions, action)
+		}
+	}
+	sortPaletteActions(actions)
+	for _, action := range actions {
+		info := keybind.ActionDescriptions[action]
+		entries = append(entries, PaletteEntry{
+			Title:    info.Description,
+			Category: info.Category,
+			Keys:     d.paletteKeys(action),
+			run:      func() { d.runAction(action) },
+		})
+	}
+
+	var active *pane
+	if d.activeWorkspace != nil {
+		active = d.activeWorkspace.ActivePane()
+	}
+	if active != nil {
+		if provider, ok := active.app.(interface{ ControlBus() ControlBus }); ok {
+			bus := provider.ControlBus()
+			var appActions []keybind.Action
+			for _, c := range bus.Capabilities() {
+				if _, known := keybind.ActionDescriptions[keybind.Action(c.ID)]; known {
+					appActions = append(appActions, keybind.Action(c.ID))
+				}
+			}
+			sortPaletteActions(appActions)
+			for _, action := range appActions {
+				info := keybind.ActionDescriptions[action]
+				entries = append(entries, PaletteEntry{
+					Title:    info.Description,
+					Category: info.Category,
+					Keys:     d.paletteKeys(action),
+					run:      func() { d.runAction(action) },
+				})
+			}
+		}
+	}
+
+	if d.registry != nil {
+		for _, app := range d.registry.List() {
+			name := app.Manifest.Name
+			entries = append(entries, PaletteEntry{
+				Title:    "Launch " + app.Manifest.DisplayName,
+				Category: "App",
+				run: func() {
+					if ws := d.ActiveWorkspace(); ws != nil {
+						if p := ws.ActivePane(); p != nil {
+							p.ReplaceWithApp(name, nil)
+						}
+					}
+				},
+			})
+		}
+	}
+
+	for _, info := range d.WorkspacesInfo() {
+		id := info.ID
+		entries = append(entries, PaletteEntry{
+			Title:    "Go to " + workspaceLabel(info),
+			Category: "Workspace",
+			run:      func() { d.SwitchToWorkspace(id) },
+		})
+	}
+
+	wsIDs := make([]int, 0, len(d.workspaces))
+	for id := range d.workspaces {
+		wsIDs = append(wsIDs, id)
+	}
+	sort.Ints(wsIDs)
+	for _, id := range wsIDs {
+		ws := d.workspaces[id]
+		if ws == nil || ws.tree == nil {
+			continue
+		}
+		ws.tree.Traverse(func(n *Node) {
+			if n.Pane == nil {
+				return
+			}
+			p := n.Pane
+			entries = append(entries, PaletteEntry{
+				Title:    p.getTitle(),
+				Category: fmt.Sprintf("Pane · %s", workspaceLabel(WorkspaceInfo{ID: ws.id, Name: ws.Name})),
+				run:      func() { d.focusPaneInWorkspace(ws, p) },
+			})
+		})
+	}
+	return entries
+}
+
+func sortPaletteActions(actions []keybind.Action) {
+	sort.Slice(actions, func(i, j int) bool {
+		ci := paletteCategoryOrder[keybind.ActionDescriptions[actions[i]].Category]
+		cj := paletteCategoryOrder[keybind.ActionDescriptions[actions[j]].Category]
+		if ci != cj {
+			return ci < cj
+		}
+		return actions[i] < actions[j]
+	})
+}
+
+// paletteKeys formats the binding for action; control actions are shown
+// behind the control prefix.
+func (d *DesktopEngine) paletteKeys(action keybind.Action) string {
+	if d.keybindings == nil {
+		return ""
+	}
+	keys := d.keybindings.KeysForAction(action)
+	if len(keys) == 0 {
+		return ""
+	}
+	label := keybind.FormatKeyCombo(keys[0])
+	if keybind.ActionDescriptions[action].Category == "Control" {
+		label = d.keyLabel(keybind.ControlToggle, "Ctrl+A") + " " + label
+	}
+	return label
+}
+
+func workspaceLabel(info WorkspaceInfo) string {
+	if info.Name != "" && info.Name != fmt.Sprint(info.ID) {
+		return fmt.Sprintf("%d: %s", info.ID, info.Name)
+	}
+	return fmt.Sprintf("workspace %d", info.ID)
+}
+
+// runPaletteEntry executes an entry returned by paletteEntries.
+func (d *DesktopEngine) runPaletteEntry(e PaletteEntry) {
+	if e.run != nil {
+		e.run()
+	}
+}
+
+// focusPaneInWorkspace switches to ws and focuses p there.
+func (d *DesktopEngine) focusPaneInWorkspace(ws *Workspace, p *pane) {
+	if !d.paneAttached(p) {
+		return
+	}
+	d.SwitchToWorkspace(ws.id)
+	if d.zoomedPane != nil && d.zoomedPane.Pane != p {
+		d.toggleZoom()
+	}
+	ws.FocusByID(p.ID())
+}
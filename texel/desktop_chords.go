@@ -0,0 +1,405 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/desktop_chords.go
+// Summary: Multi-key chords, named key tables and the which-key hint.
+// Usage: handleEvent feeds every key to handleChordKey first; control mode
+//   feeds its keys to startControlChord. Chords and tables come from the
+//   "chords" and "tables" sections of keybindings.json (see keybind.Config).
+// Notes: A chord outside control mode buffers its keys; if they lead
+//   nowhere, or time out, they are replayed through the normal key path so
+//   a chord prefix never swallows a key for good. Chords behind the control
+//   prefix run inside control mode instead, so the prefix still enters
+//   control mode at once. While a chord is partial or a key table is
+//   active, a hint panel lists what can follow.
+
+package texel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelation/internal/keybind"
+	"github.com/framegrace/texelui/theme"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// chordContext is where a partial chord started.
+type chordContext int
+
+const (
+	chordRoot    chordContext = iota // outside any mode; keys replay on failure
+	chordControl                     // inside control mode, after the prefix
+	chordInTable                     // inside a named key table
+)
+
+// handleChordKey consumes ev when it continues a partial chord, belongs to
+// the active key table, or starts a chord. It reports whether ev was used.
+func (d *DesktopEngine) handleChordKey(ev *tcell.EventKey) bool {
+	if d.keybindings == nil || d.chordReplaying {
+		return false
+	}
+	if d.chordTable != nil {
+		if ev.Key() == tcell.KeyEsc {
+			d.abandonChord(false)
+			return true
+		}
+		d.feedChord(ev)
+		return true
+	}
+	if d.keyTable != nil {
+		if ev.Key() == tcell.KeyEsc {
+			d.exitKeyTable()
+			return true
+		}
+		d.chordCtx = chordInTable
+		d.chordTable = d.keyTable
+		d.feedChord(ev)
+		return true
+	}
+	if d.inControlMode || d.inTabMode || d.topModalPanel() != nil {
+		return false
+	}
+	root := d.keybindings.Chords()
+	if _, more := root.Lookup([]*tcell.EventKey{ev}); !more {
+		return false
+	}
+	d.chordCtx = chordRoot
+	d.chordTable = root
+	d.feedChord(ev)
+	return true
+}
+
+// startControlChord is handleControlMode's hook: it starts a chord when ev
+// is the first key of one behind the control prefix.
+func (d *DesktopEngine) startControlChord(ev *tcell.EventKey) bool {
+	if d.keybindings == nil {
+		return false
+	}
+	table := d.keybindings.ControlChords()
+	if bound, more := table.Lookup([]*tcell.EventKey{ev}); bound == nil && !more {
+		return false
+	}
+	d.chordCtx = chordControl
+	d.chordTable = table
+	d.feedChord(ev)
+	return true
+}
+
+// feedChord adds ev to the partial chord and acts on the result.
+func (d *DesktopEngine) feedChord(ev *tcell.EventKey) {
+	events := append(d.chordEvents, ev)
+	bound, more := d.chordTable.Lookup(events)
+	switch {
+	case more:
+		d.chordEvents = events
+		d.armChordTimer(d.keybindings.ChordTimeout())
+		d.showChordHint()
+	case bound != nil:
+		ctx := d.chordCtx
+		d.clearChord()
+		d.runBinding(*bound, ctx)
+	default:
+		d.chordEvents = events
+		d.abandonChord(true)
+	}
+}
+
+// abandonChord drops a partial chord that cannot complete. Outside control
+// mode the buffered keys are replayed when replay is set; in control mode
+// the chord ends control mode like any unbound key; in a key table an
+// unbound first key leaves the table.
+func (d *DesktopEngine) abandonChord(replay bool) {
+	ctx, events := d.chordCtx, d.chordEvents
+	d.clearChord()
+	switch ctx {
+	case chordRoot:
+		if replay {
+			d.chordReplaying = true
+			for _, ev := range events {
+				d.handleEvent(ev)
+			}
+			d.chordReplaying = false
+		}
+	case chordControl:
+		if d.inControlMode {
+			d.toggleControlMode()
+		}
+	case chordInTable:
+		if len(events) <= 1 {
+			d.exitKeyTable()
+			return
+		}
+		d.armTableTimer()
+		d.showChordHint()
+	}
+}
+
+// chordTimedOut runs when a partial chord waits too long: a sequence that
+// is complete but also the start of a longer one fires, anything else is
+// abandoned (and, outside control mode, replayed).
+func (d *DesktopEngine) chordTimedOut(gen uint64) {
+	if gen != d.chordGen {
+		return
+	}
+	if d.chordTable == nil {
+		if d.keyTable != nil {
+			d.exitKeyTable()
+		}
+		return
+	}
+	if bound, _ := d.chordTable.Lookup(d.chordEvents); bound != nil {
+		ctx := d.chordCtx
+		d.clearChord()
+		d.runBinding(*bound, ctx)
+		return
+	}
+	d.abandonChord(true)
+}
+
+// clearChord forgets the partial chord and hides its hint.
+func (d *DesktopEngine) clearChord() {
+	d.chordTable = nil
+	d.chordEvents = nil
+	d.chordGen++
+	if d.keyTable != nil {
+		d.showChordHint()
+	} else {
+		d.hideChordHint()
+	}
+}
+
+func (d *DesktopEngine) armChordTimer(after time.Duration) {
+	d.chordGen++
+	gen := d.chordGen
+	if after <= 0 {
+		return
+	}
+	time.AfterFunc(after, func() {
+		d.post(func() { d.chordTimedOut(gen) })
+	})
+}
+
+// armTableTimer restarts the active key table's idle timeout.
+func (d *DesktopEngine) armTableTimer() {
+	if d.keyTable != nil {
+		d.armChordTimer(d.keyTable.Timeout)
+	}
+}
+
+// runBinding performs a chord's binding. Control-mode chords that do not
+// run a control action end control mode, as the action's own key would.
+func (d *DesktopEngine) runBinding(b keybind.Binding, ctx chordContext) {
+	switch {
+	case b.Table != "":
+		table := d.keybindings.Table(b.Table)
+		if table == nil {
+			d.BroadcastToast(fmt.Sprintf("Unknown key table %q", b.Table), ToastWarning, 3*time.Second)
+			break
+		}
+		if d.inControlMode {
+			d.toggleControlMode()
+		}
+		d.keyTable = table
+		d.armTableTimer()
+		d.showChordHint()
+		return
+	case b.Text != "":
+		if p := d.activePane(); p != nil {
+			p.sendText([]byte(b.Text))
+		}
+	default:
+		if keybind.ActionDescriptions[b.Action].Category == "Control" {
+			d.runAction(b.Action)
+			if ctx == chordInTable {
+				d.armTableTimer()
+			}
+			return
+		}
+		d.runAction(b.Action)
+	}
+	switch ctx {
+	case chordControl:
+		if d.inControlMode {
+			d.toggleControlMode()
+		}
+	case chordInTable:
+		d.armTableTimer()
+	}
+}
+
+// exitKeyTable leaves the active key table.
+func (d *DesktopEngine) exitKeyTable() {
+	d.keyTable = nil
+	d.chordCtx = chordRoot
+	d.clearChord()
+}
+
+// resetChords drops any partial chord and key table without replaying.
+func (d *DesktopEngine) resetChords() {
+	d.keyTable = nil
+	d.clearChord()
+}
+
+// activePane returns the focused pane, honouring zoom.
+func (d *DesktopEngine) activePane() *pane {
+	if d.zoomedPane != nil && d.zoomedPane.Pane != nil {
+		return d.zoomedPane.Pane
+	}
+	if d.activeWorkspace == nil {
+		return nil
+	}
+	return d.activeWorkspace.ActivePane()
+}
+
+// showChordHint lists the continuations of the partial chord, or the
+// active key table's keys.
+func (d *DesktopEngine) showChordHint() {
+	var title string
+	var conts []keybind.Continuation
+	switch {
+	case d.chordTable != nil:
+		conts = d.chordTable.Continuations(d.chordEvents)
+		var typed []string
+		if d.chordCtx == chordControl {
+			typed = append(typed, d.keyLabel(keybind.ControlToggle, "Ctrl+A"))
+		}
+		for _, ev := range d.chordEvents {
+			typed = append(typed, formatEventKey(ev))
+		}
+		title = strings.Join(typed, " ") + " …"
+	case d.keyTable != nil:
+		conts = d.keyTable.Continuations(nil)
+		title = d.keyTable.Name + " mode (Esc leaves)"
+	default:
+		d.hideChordHint()
+		return
+	}
+
+	lines := make([]chordHintLine, 0, len(conts))
+	keysW := 0
+	for _, c := range conts {
+		lines = append(lines, chordHintLine{keys: c.Keys, desc: c.Binding.Describe()})
+		keysW = max(keysW, runewidth.StringWidth(c.Keys))
+	}
+	w := runewidth.StringWidth(title) + 2
+	for _, l := range lines {
+		w = max(w, keysW+runewidth.StringWidth(l.desc)+4)
+	}
+
+	d.hideChordHint()
+	vw, vh := d.viewportSize()
+	w = max(min(w, vw-2), 1)
+	h := max(min(len(lines)+1, vh-2), 1)
+	d.chordHint = &chordHint{title: title, lines: lines, keysW: keysW}
+	d.showFloatingPanel(d.chordHint, vw-w-1, vh-h-1, w, h, false)
+}
+
+func (d *DesktopEngine) hideChordHint() {
+	if d.chordHint != nil {
+		d.closeFloatingPanelByApp(d.chordHint)
+		d.chordHint = nil
+	}
+}
+
+// formatEventKey formats a key event the way bindings are shown.
+func formatEventKey(ev *tcell.EventKey) string {
+	kc := keybind.KeyCombo{Key: ev.Key(), Rune: ev.Rune(), Modifiers: ev.Modifiers()}
+	if ev.Key() != tcell.KeyRune {
+		kc.Rune = 0
+	}
+	return keybind.FormatKeyCombo(kc)
+}
+
+type chordHintLine struct {
+	keys, desc string
+}
+
+// chordHint is the which-key panel. Like the lock prompt it is built in so
+// it works without any registered app.
+type chordHint struct {
+	mu            sync.Mutex
+	width, height int
+	title         string
+	lines         []chordHintLine
+	keysW         int
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+func (c *chordHint) Run() error {
+	c.mu.Lock()
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+	}
+	stop := c.stop
+	c.mu.Unlock()
+	<-stop
+	return nil
+}
+
+func (c *chordHint) Stop() {
+	c.stopOnce.Do(func() {
+		c.mu.Lock()
+		if c.stop == nil {
+			c.stop = make(chan struct{})
+		}
+		close(c.stop)
+		c.mu.Unlock()
+	})
+}
+
+func (c *chordHint) Resize(cols, rows int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.width, c.height = cols, rows
+}
+
+func (c *chordHint) GetTitle() string                  { return "Keys" }
+func (c *chordHint) HandleKey(ev *tcell.EventKey)      {}
+func (c *chordHint) SetRefreshNotifier(ch chan<- bool) {}
+
+func (c *chordHint) Render() [][]Cell {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.width <= 0 || c.height <= 0 {
+		return [][]Cell{}
+	}
+
+	tm := theme.Get()
+	base := tcell.StyleDefault.Background(tm.GetSemanticColor("bg.surface").TrueColor()).Foreground(tm.GetSemanticColor("text.primary").TrueColor())
+	dim := base.Foreground(tm.GetSemanticColor("text.secondary").TrueColor())
+	accent := base.Foreground(tm.GetSemanticColor("accent").TrueColor()).Bold(true)
+
+	buf := make([][]Cell, c.height)
+	for y := range buf {
+		buf[y] = make([]Cell, c.width)
+		for x := range buf[y] {
+			buf[y][x] = Cell{Ch: ' ', Style: base}
+		}
+	}
+	put := func(x, y int, s string, st tcell.Style) {
+		for _, r := range s {
+			w := runewidth.RuneWidth(r)
+			if x+w > c.width {
+				return
+			}
+			buf[y][x] = Cell{Ch: r, Style: st}
+			x += w
+		}
+	}
+
+	put(1, 0, c.title, dim)
+	for i, l := range c.lines {
+		y := i + 1
+		if y >= c.height {
+			break
+		}
+		put(1, y, l.keys, accent)
+		put(c.keysW+3, y, l.desc, base)
+	}
+	return buf
+}
@@ -11,6 +11,12 @@ import "github.com/framegrace/texelation/internal/debuglog"
 // SetClipboard implements ClipboardService for apps running in the desktop.
 // Also marks the clipboard as pending for broadcast to clients.
 func (d *DesktopEngine) SetClipboard(mime string, data []byte) {
+	d.setClipboard(mime, data, [16]byte{})
+}
+
+// setClipboard stores a value and records it in the history ring with the
+// pane it came from (zero ID when it did not come from a pane).
+func (d *DesktopEngine) setClipboard(mime string, data []byte, sourceID [16]byte) {
 	d.clipboardMu.Lock()
 	defer d.clipboardMu.Unlock()
 	if d.clipboard == nil {
@@ -19,6 +25,7 @@ func (d *DesktopEngine) SetClipboard(mime string, data []byte) {
 	d.clipboardMime = mime
 	d.clipboard[mime] = append([]byte(nil), data...)
 	d.clipboardPending = true
+	d.recordClipboardLocked(mime, data, sourceID)
 	debuglog.Printf("CLIPBOARD DEBUG: Desktop.SetClipboard called: mime=%s, len=%d, pending=%v", mime, len(data), d.clipboardPending)
 }
 
@@ -45,7 +52,7 @@ func (d *DesktopEngine) HandleClipboardSet(mime string, data []byte) {
 func (d *DesktopEngine) HandleClipboardGet(mime string) []byte {
 	d.clipboardMu.Lock()
 	defer d.clipboardMu.Unlock()
-	if d.clipboard == nil {
+	if d.clipboard == nil || d.locked.Load() {
 		return nil
 	}
 	return append([]byte(nil), d.clipboard[mime]...)
@@ -82,7 +89,7 @@ func (d *DesktopEngine) HandlePaste(data []byte) {
 
 // handlePasteInternal routes paste data to the active pane (called from event loop).
 func (d *DesktopEngine) handlePasteInternal(data []byte) {
-	if len(data) == 0 || d.inControlMode {
+	if len(data) == 0 || d.inControlMode || d.locked.Load() {
 		return
 	}
 	if d.zoomedPane != nil && d.zoomedPane.Pane != nil {
@@ -223,6 +223,14 @@ func (d *DesktopEngine) handleControlMode(ev *tcell.EventKey) {
 		switch d.subControlMode {
 		case 'w':
 			d.activeWorkspace.SwapActivePane(keyToDirection(ev))
+		case 'm':
+			if id, ok := keyToWorkspaceID(ev); ok {
+				d.SendActivePaneToWorkspace(id)
+			}
+		case 'j':
+			if id, ok := keyToWorkspaceID(ev); ok {
+				d.JoinPaneFromWorkspace(id)
+			}
 		}
 		d.toggleControlMode()
 		return
@@ -233,8 +241,20 @@ func (d *DesktopEngine) handleControlMode(ev *tcell.EventKey) {
 		return
 	}
 
-	action := d.keybindings.Match(ev)
-	exitControlMode := true
+	if d.startControlChord(ev) {
+		d.closeControlHelpOverlay()
+		return
+	}
+	if d.runControlAction(d.keybindings.Match(ev)) {
+		d.toggleControlMode()
+	}
+}
+
+// runControlAction performs a control-mode action and reports whether
+// control mode should end afterwards. The command palette runs control
+// actions through it too.
+func (d *DesktopEngine) runControlAction(action keybind.Action) (exitControlMode bool) {
+	exitControlMode = true
 	switch action {
 	case keybind.ControlClose:
 		if d.zoomedPane != nil {
@@ -253,6 +273,59 @@ func (d *DesktopEngine) handleControlMode(ev *tcell.EventKey) {
 		exitControlMode = false
 	case keybind.ControlZoom:
 		d.toggleZoom()
+	case keybind.ControlStack:
+		d.activeWorkspace.ToggleTabbed()
+	case keybind.ControlStackNext:
+		d.activeWorkspace.CycleTab(1)
+	case keybind.ControlStackPrev:
+		d.activeWorkspace.CycleTab(-1)
+	case keybind.ControlLayout:
+		if d.zoomedPane != nil {
+			d.toggleZoom()
+		}
+		d.activeWorkspace.CycleLayout()
+	case keybind.ControlEqualize:
+		d.activeWorkspace.EqualizeRatios()
+	case keybind.ControlSendPane:
+		d.subControlMode = 'm'
+		d.broadcastModeChanged()
+		exitControlMode = false
+	case keybind.ControlJoinPane:
+		d.subControlMode = 'j'
+		d.broadcastModeChanged()
+		exitControlMode = false
+	case keybind.ControlBreakPane:
+		d.BreakOutActivePane()
+	case keybind.ControlUndoClose:
+		d.UndoClosePane()
+	case keybind.ControlReopen:
+		d.closeControlHelpOverlay()
+		d.launchReopenOverlay()
+		exitControlMode = false // stay in control mode while modal is open
+	case keybind.ControlSnapshots:
+		d.closeControlHelpOverlay()
+		d.launchSnapshotsOverlay()
+		exitControlMode = false // stay in control mode while modal is open
+	case keybind.ControlClipboard:
+		d.closeControlHelpOverlay()
+		d.launchClipboardOverlay()
+		exitControlMode = false // stay in control mode while modal is open
+	case keybind.ControlPalette:
+		d.closeControlHelpOverlay()
+		d.launchCommandPalette()
+		exitControlMode = false // stay in control mode while modal is open
+	case keybind.ControlOSC52Allow:
+		d.answerOSC52(true, false)
+	case keybind.ControlOSC52AllowPane:
+		d.answerOSC52(true, true)
+	case keybind.ControlOSC52Deny:
+		d.answerOSC52(false, false)
+	case keybind.ControlOSC52DenyPane:
+		d.answerOSC52(false, true)
+	case keybind.ControlLock:
+		if d.Lock() {
+			exitControlMode = false // Lock already left control mode
+		}
 	case keybind.ControlLauncher:
 		d.closeControlHelpOverlay()
 		d.launchLauncherOverlay()
@@ -274,8 +347,5 @@ func (d *DesktopEngine) handleControlMode(ev *tcell.EventKey) {
 	default:
 		// Unknown key — still exit control mode
 	}
-
-	if exitControlMode {
-		d.toggleControlMode()
-	}
+	return exitControlMode
 }
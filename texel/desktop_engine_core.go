@@ -60,6 +60,7 @@ const (
 	pasteEventKind
 	resizeEventKind
 	syncEventKind
+	funcEventKind
 )
 
 // desktopEvent is a tagged union for all events processed by the desktop event loop.
@@ -74,6 +75,7 @@ type desktopEvent struct {
 	width   int
 	height  int
 	done    chan struct{} // used by syncEventKind
+	fn      func()        // used by funcEventKind
 }
 
 // animationFrame carries interpolated ratios from the animation ticker to the event loop.
@@ -123,12 +125,30 @@ type DesktopEngine struct {
 	clipboardMu        sync.Mutex
 	clipboard          map[string][]byte
 	clipboardMime      string
-	clipboardPending   bool // True when clipboard has changed and needs to be sent to client
+	clipboardPending   bool             // True when clipboard has changed and needs to be sent to client
+	clipboardHistory   []ClipboardEntry // ring of recent values, oldest first
+	clipboardSeq       uint64
+	osc52Pending       []osc52Request // OSC 52 requests waiting for an answer, oldest first
 	focusMu            sync.RWMutex
 	focusListeners     []DesktopFocusListener
 	paneStateMu        sync.RWMutex
 	paneStateListeners []PaneStateListener
 	snapshotFactories  map[string]SnapshotFactory
+	closedPanes        []closedPane // undo-close stack, oldest first
+	snapshotHistory    SnapshotHistory
+	monitorTimer       *time.Timer  // wakes the loop at the next silence deadline
+	monitorDeadline    time.Time
+	locked             atomic.Bool // screen lock; read from connection goroutines
+	lockVerifier       LockVerifier
+	lockPrompt         *lockPrompt
+	chordTable         *keybind.KeyTable // table of the partial chord, nil when none
+	chordEvents        []*tcell.EventKey // keys of the partial chord so far
+	chordCtx           chordContext
+	chordGen           uint64            // invalidates stale chord timeouts
+	chordReplaying     bool              // replaying keys of an abandoned chord
+	keyTable           *keybind.KeyTable // active named key table, nil when none
+	chordHint          *chordHint
+	lastShellStatus    ShellStatus // focused pane's status as last broadcast
 	viewportMu         sync.RWMutex
 	viewportWidth      int
 	viewportHeight     int
@@ -147,6 +167,7 @@ type DesktopEngine struct {
 	refreshHandler func()
 
 	lastPublishNanos atomic.Int64
+	renderStats      renderStats
 
 	graphicsFactory func(paneID [16]byte) GraphicsProvider
 
@@ -189,6 +210,7 @@ type PaneStateSnapshot struct {
 	Resizing     bool
 	ZOrder       int
 	HandlesMouse bool
+	Alert        PaneAlert
 }
 
 // NewDesktopEngine creates and initializes a new desktop engine.
@@ -490,6 +512,7 @@ func (d *DesktopEngine) SetRefreshHandler(handler func()) {
 // SetLastPublishDuration records the duration of the most recent publish cycle.
 func (d *DesktopEngine) SetLastPublishDuration(dur time.Duration) {
 	d.lastPublishNanos.Store(dur.Nanoseconds())
+	d.renderStats.recordPublish(dur)
 }
 
 // SetGraphicsProviderFactory sets the factory used to create per-pane graphics providers.
@@ -542,6 +565,7 @@ func (d *DesktopEngine) workspacesChangedPayload() WorkspacesChangedPayload {
 			ID:    ws.id,
 			Name:  ws.Name,
 			Color: ws.Color,
+			Alert: ws.alert,
 		})
 	}
 	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
@@ -575,7 +599,8 @@ func (d *DesktopEngine) broadcastActivePaneChanged() {
 	} else if d.activeWorkspace != nil {
 		title = d.activeWorkspace.tree.ActiveTitle()
 	}
-	d.dispatcher.Broadcast(Event{Type: EventActivePaneChanged, Payload: ActivePaneChangedPayload{ActiveTitle: title}})
+	d.lastShellStatus = d.activeShellStatus()
+	d.dispatcher.Broadcast(Event{Type: EventActivePaneChanged, Payload: ActivePaneChangedPayload{ActiveTitle: title, Shell: d.lastShellStatus}})
 }
 
 func (d *DesktopEngine) broadcastPerformanceUpdate() {
@@ -851,6 +876,19 @@ func (d *DesktopEngine) AppByID(id [16]byte) App {
 	return result
 }
 
+// PaneApps returns the app attached to every tiled pane across all
+// workspaces, keyed by pane ID. Status panes and floating panels are not
+// included. Same concurrency caveats as AppByID.
+func (d *DesktopEngine) PaneApps() map[[16]byte]App {
+	apps := make(map[[16]byte]App)
+	d.forEachPane(func(p *pane) {
+		if p.app != nil {
+			apps[p.ID()] = p.app
+		}
+	})
+	return apps
+}
+
 // RestorePaneViewport looks up the pane with id and, if its app implements
 // ViewportRestorer, forwards the restore call. Returns true on success,
 // false if the pane is unknown or its app does not implement the interface.
@@ -883,6 +921,7 @@ func (d *DesktopEngine) PaneStates() []PaneStateSnapshot {
 			Resizing:     p.IsResizing,
 			ZOrder:       p.ZOrder,
 			HandlesMouse: p.handlesMouseEvents(),
+			Alert:        p.alert,
 		})
 	})
 	for _, fp := range d.floatingPanels {
@@ -1150,6 +1189,8 @@ func (d *DesktopEngine) Run() {
 		// Input events have priority over refresh signals so keystrokes
 		// are never delayed by a flood of app refreshes.
 		d.drainPending()
+		d.checkPaneMonitors(time.Now())
+		d.checkShellStatus()
 
 		// Publish at most once per frame interval (~60fps).
 		now := time.Now()
@@ -1217,6 +1258,8 @@ func (d *DesktopEngine) processDesktopEvent(ev desktopEvent) bool {
 		d.publishIfDirty()
 		close(ev.done)
 		return true
+	case funcEventKind:
+		ev.fn()
 	}
 	return false
 }
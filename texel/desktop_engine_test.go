@@ -9,8 +9,12 @@ package texel
 
 import (
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/framegrace/texelation/internal/keybind"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -369,3 +373,1032 @@ func TestMouseBorderResizeAdjustsRatios(t *testing.T) {
 		t.Fatalf("expected left ratio to grow after drag (before=%.3f, after=%.3f)", initialLeftRatio, root.SplitRatios[0])
 	}
 }
+
+func TestTabbedContainerToggleCycleAndClick(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	var shellCount int
+	shellFactory := func() App {
+		title := fmt.Sprintf("shell-%d", shellCount)
+		shellCount++
+		return newFakeApp(title)
+	}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, shellFactory, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("initial"))
+	ws := desktop.activeWorkspace
+	ws.PerformSplit(Vertical)
+
+	root := ws.tree.Root
+	if root == nil || len(root.Children) != 2 {
+		t.Fatalf("expected two children after split")
+	}
+	first, second := root.Children[0], root.Children[1]
+	if ws.tree.ActiveLeaf != second {
+		t.Fatalf("expected new pane to be active after split")
+	}
+
+	ws.ToggleTabbed()
+	if root.Split != Tabbed || root.ActiveTab != 1 {
+		t.Fatalf("expected tabbed root showing active pane, got split=%v tab=%d", root.Split, root.ActiveTab)
+	}
+	if first.Pane.Width() != second.Pane.Width() || first.Pane.absX0 != second.Pane.absX0 {
+		t.Fatalf("expected tabs to share one rect")
+	}
+	if !first.hiddenInTab() || second.hiddenInTab() {
+		t.Fatalf("expected only the active tab to be visible")
+	}
+	if got := len(desktop.SnapshotBuffers()); got != 1 {
+		t.Fatalf("expected 1 visible pane buffer, got %d", got)
+	}
+
+	ws.CycleTab(1)
+	if root.ActiveTab != 0 || ws.tree.ActiveLeaf != first {
+		t.Fatalf("expected cycling to wrap to first tab and focus it")
+	}
+
+	labels, active := second.Pane.tabStrip()
+	if labels != nil {
+		t.Fatalf("hidden pane should not carry a tab strip, got %v", labels)
+	}
+	labels, active = first.Pane.tabStrip()
+	if len(labels) != 2 || active != 0 {
+		t.Fatalf("expected two tab labels with first active, got %v/%d", labels, active)
+	}
+
+	spans := layoutTabStrip(labels, active, first.Pane.Width())
+	if len(spans) != 2 {
+		t.Fatalf("expected both tabs to fit, got %d", len(spans))
+	}
+	if !ws.selectTabAt(first.Pane.absX0+spans[1].x0, first.Pane.absY0) {
+		t.Fatalf("expected click on second tab to be handled")
+	}
+	if root.ActiveTab != 1 || ws.tree.ActiveLeaf != second {
+		t.Fatalf("expected click to select and focus second tab")
+	}
+	if ws.selectTabAt(second.Pane.absX0+spans[1].x0, second.Pane.absY0+2) {
+		t.Fatalf("click below the strip should not select a tab")
+	}
+
+	ws.ToggleTabbed()
+	if root.Split != Vertical {
+		t.Fatalf("expected toggle to restore vertical split, got %v", root.Split)
+	}
+	if first.Pane.absX0 == second.Pane.absX0 {
+		t.Fatalf("expected panes side by side after unstacking")
+	}
+}
+
+func TestLayoutTabStripKeepsActiveVisible(t *testing.T) {
+	labels := []string{"alpha", "beta", "gamma", "delta"}
+	spans := layoutTabStrip(labels, 3, 20)
+	if len(spans) == 0 || spans[len(spans)-1].index != 3 {
+		t.Fatalf("expected active tab to replace the last fitting tab, got %+v", spans)
+	}
+	for _, s := range spans {
+		if s.x0 < 1 || s.x1 > 19 {
+			t.Fatalf("span %+v overlaps the border corners", s)
+		}
+	}
+}
+
+func TestPresetLayoutsRebuildTree(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	var shellCount int
+	shellFactory := func() App {
+		title := fmt.Sprintf("shell-%d", shellCount)
+		shellCount++
+		return newFakeApp(title)
+	}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, shellFactory, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("initial"))
+	ws := desktop.activeWorkspace
+	ws.PerformSplit(Vertical)
+	ws.PerformSplit(Horizontal)
+	ws.PerformSplit(Vertical)
+	active := ws.tree.ActiveLeaf
+
+	countLeaves := func() int {
+		n := 0
+		ws.tree.Traverse(func(node *Node) {
+			if node.Pane != nil {
+				n++
+			}
+		})
+		return n
+	}
+
+	ws.ApplyLayout(LayoutEvenVertical)
+	root := ws.tree.Root
+	if root.Split != Horizontal || len(root.Children) != 4 || !ratiosAreEqual(root.SplitRatios) {
+		t.Fatalf("expected four equal rows, got split=%v children=%d ratios=%v", root.Split, len(root.Children), root.SplitRatios)
+	}
+
+	ws.ApplyLayout(LayoutMainVertical)
+	root = ws.tree.Root
+	if root.Split != Vertical || len(root.Children) != 2 || root.Children[0].Pane == nil {
+		t.Fatalf("expected main pane on the left")
+	}
+	if stack := root.Children[1]; stack.Split != Horizontal || len(stack.Children) != 3 {
+		t.Fatalf("expected three stacked panes on the right")
+	}
+	if root.Children[0].Pane.Width() <= root.Children[1].Children[0].Pane.Width() {
+		t.Fatalf("expected main pane to be wider than stacked panes")
+	}
+
+	ws.ApplyLayout(LayoutTiled)
+	root = ws.tree.Root
+	if root.Split != Horizontal || len(root.Children) != 2 {
+		t.Fatalf("expected two grid rows, got %d", len(root.Children))
+	}
+	for _, row := range root.Children {
+		if row.Split != Vertical || len(row.Children) != 2 || row.Parent != root {
+			t.Fatalf("expected two panes per grid row")
+		}
+	}
+
+	root.Children[0].SplitRatios = []float64{0.8, 0.2}
+	ws.EqualizeRatios()
+	if !ratiosAreEqual(root.Children[0].SplitRatios) {
+		t.Fatalf("expected equalised ratios, got %v", root.Children[0].SplitRatios)
+	}
+
+	if countLeaves() != 4 || ws.tree.ActiveLeaf != active {
+		t.Fatalf("expected layouts to keep all panes and focus")
+	}
+
+	ws.nextLayout = LayoutTiled
+	ws.CycleLayout()
+	if ws.nextLayout != LayoutEvenHorizontal || ws.tree.Root.Split != Horizontal {
+		t.Fatalf("expected cycling past tiled to wrap around")
+	}
+}
+
+func TestMovePanesBetweenWorkspaces(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	var shellCount int
+	shellFactory := func() App {
+		title := fmt.Sprintf("shell-%d", shellCount)
+		shellCount++
+		return newFakeApp(title)
+	}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, shellFactory, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("initial"))
+	ws1 := desktop.activeWorkspace
+	ws1.PerformSplit(Vertical)
+	moved := ws1.tree.ActiveLeaf.Pane
+	movedID := moved.ID()
+
+	desktop.SendActivePaneToWorkspace(2)
+	ws2 := desktop.workspaces[2]
+	if ws2 == nil || ws2.tree.Root == nil || ws2.tree.Root.Pane != moved {
+		t.Fatalf("expected pane to become workspace 2's root")
+	}
+	if moved.screen != ws2 || moved.ID() != movedID || moved.app == nil {
+		t.Fatalf("expected pane to keep its ID and app and report to workspace 2")
+	}
+	if ws1.tree.Root == nil || ws1.tree.Root.Pane == nil || desktop.activeWorkspace != ws1 {
+		t.Fatalf("expected workspace 1 to keep its remaining pane and focus")
+	}
+	if moved.IsActive {
+		t.Fatalf("pane in a background workspace should not be active")
+	}
+	for _, app := range lifecycle.stopped {
+		if app == moved.app {
+			t.Fatalf("moving a pane must not stop its app")
+		}
+	}
+
+	desktop.JoinPaneFromWorkspace(2)
+	if _, ok := desktop.workspaces[2]; ok {
+		t.Fatalf("expected emptied workspace 2 to be closed")
+	}
+	if ws1.tree.ActiveLeaf.Pane != moved || moved.screen != ws1 {
+		t.Fatalf("expected joined pane to be active in workspace 1")
+	}
+
+	desktop.BreakOutActivePane()
+	if desktop.activeWorkspace == ws1 || desktop.activeWorkspace.tree.Root.Pane != moved {
+		t.Fatalf("expected break-out to switch to a new workspace holding the pane")
+	}
+	if !moved.IsActive {
+		t.Fatalf("expected broken-out pane to be active")
+	}
+
+	capture := desktop.CaptureTree()
+	found := 0
+	for _, p := range capture.Panes {
+		if p.ID == movedID {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Fatalf("expected moved pane captured exactly once, got %d", found)
+	}
+}
+
+// snapshotFakeApp is a fakeApp that can be rebuilt through a snapshot factory.
+type snapshotFakeApp struct {
+	*fakeApp
+	cwd string
+}
+
+func (s *snapshotFakeApp) SnapshotMetadata() (string, map[string]interface{}) {
+	return "fake", map[string]interface{}{"cwd": s.cwd}
+}
+
+func TestUndoCloseRestoresPaneInPlace(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	var rebuilt []map[string]interface{}
+	desktop.RegisterSnapshotFactory("fake", func(title string, cfg map[string]interface{}) App {
+		rebuilt = append(rebuilt, cfg)
+		return &snapshotFakeApp{fakeApp: newFakeApp(title), cwd: cfg["cwd"].(string)}
+	})
+
+	desktop.SwitchToWorkspace(1)
+	ws := desktop.activeWorkspace
+	ws.AddApp(&snapshotFakeApp{fakeApp: newFakeApp("left"), cwd: "/left"})
+	ws.ShellAppFactory = func() App { return &snapshotFakeApp{fakeApp: newFakeApp("right"), cwd: "/right"} }
+	ws.PerformSplit(Vertical)
+	ws.ShellAppFactory = func() App { return &snapshotFakeApp{fakeApp: newFakeApp("third"), cwd: "/third"} }
+	ws.PerformSplit(Horizontal)
+
+	// Tree: root Vertical [left, Horizontal [right, third]]; close "right".
+	right := ws.tree.Root.Children[1].Children[0]
+	rightID := right.Pane.ID()
+	ws.tree.ActiveLeaf = right
+	ws.CloseActivePane()
+	if got := desktop.ClosedPanes(); len(got) != 1 || got[0].ID != rightID || got[0].Title != "right" {
+		t.Fatalf("expected closed pane on stack, got %+v", got)
+	}
+
+	desktop.UndoClosePane()
+	if len(desktop.ClosedPanes()) != 0 {
+		t.Fatalf("expected stack to be empty after undo")
+	}
+	// "third" was promoted into the root when "right" closed, so the
+	// reopened pane is paired with it again on the same side.
+	split := ws.tree.Root.Children[1]
+	if split.Split != Horizontal || len(split.Children) != 2 || split.Children[0].Pane == nil {
+		t.Fatalf("expected reopened pane back above its sibling")
+	}
+	p := split.Children[0].Pane
+	if p.ID() != rightID || ws.tree.ActiveLeaf != split.Children[0] || !p.IsActive {
+		t.Fatalf("expected reopened pane to keep its ID and take focus")
+	}
+	if len(rebuilt) != 1 || rebuilt[0]["cwd"] != "/right" {
+		t.Fatalf("expected app rebuilt from its closed config, got %v", rebuilt)
+	}
+
+	desktop.UndoClosePane()
+	if len(rebuilt) != 1 {
+		t.Fatalf("undo with an empty stack should not rebuild anything")
+	}
+}
+
+func TestUndoCloseWaitsForCloseAnimation(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.RegisterSnapshotFactory("fake", func(title string, cfg map[string]interface{}) App {
+		return &snapshotFakeApp{fakeApp: newFakeApp(title), cwd: cfg["cwd"].(string)}
+	})
+	// A transition manager past its grace period and without a ticker:
+	// the close animation runs until the test completes it.
+	if old := desktop.layoutTransitions; old != nil && old.ticker != nil {
+		old.Stop()
+	}
+	transitions := &LayoutTransitionManager{
+		enabled:   true,
+		duration:  time.Hour,
+		easing:    "linear",
+		animating: make(map[*Node]*transitionState),
+		desktop:   desktop,
+	}
+	desktop.layoutTransitions = transitions
+
+	desktop.SwitchToWorkspace(1)
+	ws := desktop.activeWorkspace
+	ws.AddApp(&snapshotFakeApp{fakeApp: newFakeApp("left"), cwd: "/left"})
+	ws.ShellAppFactory = func() App { return &snapshotFakeApp{fakeApp: newFakeApp("right"), cwd: "/right"} }
+	ws.PerformSplit(Vertical)
+	for _, state := range transitions.animating {
+		state.node.SplitRatios = state.targetRatios
+	}
+	clear(transitions.animating)
+
+	right := ws.tree.ActiveLeaf
+	rightID := right.Pane.ID()
+	ws.CloseActivePane()
+	state := transitions.animating[ws.tree.Root]
+	if state == nil || state.onComplete == nil {
+		t.Fatalf("expected the close to be animated")
+	}
+
+	// Mid-animation the pane is still in the tree: nothing to undo yet.
+	if len(desktop.ClosedPanes()) != 0 {
+		t.Fatalf("pane stacked before its removal completed")
+	}
+	desktop.UndoClosePane()
+	count := 0
+	ws.tree.Traverse(func(n *Node) {
+		if n.Pane != nil && n.Pane.ID() == rightID {
+			count++
+		}
+	})
+	if count != 1 {
+		t.Fatalf("expected the closing pane once in the tree, found %d", count)
+	}
+
+	desktop.applyAnimationFrame(animationFrame{node: state.node, ratios: state.targetRatios, done: true, onComplete: state.onComplete})
+	if got := desktop.ClosedPanes(); len(got) != 1 || got[0].ID != rightID {
+		t.Fatalf("expected closed pane on stack after the animation, got %+v", got)
+	}
+	if ws.tree.Root.Pane == nil || ws.tree.Root.Pane.ID() == rightID {
+		t.Fatalf("expected only the left pane to remain")
+	}
+	if !desktop.ReopenClosedPane(rightID) || len(ws.tree.Root.Children) != 2 {
+		t.Fatalf("expected the pane to reopen beside its sibling")
+	}
+}
+
+type alertRecorder struct {
+	alerts map[[16]byte]PaneAlert
+}
+
+func (r *alertRecorder) PaneStateChanged([16]byte, bool, bool, int, bool) {}
+func (r *alertRecorder) PaneAlertChanged(id [16]byte, alert PaneAlert) {
+	r.alerts[id] = alert
+}
+
+func TestPaneMonitorsRaiseAndClearAlerts(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	rec := &alertRecorder{alerts: make(map[[16]byte]PaneAlert)}
+	desktop.RegisterPaneStateListener(rec)
+
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("editor"))
+	desktop.SwitchToWorkspace(2)
+	ws2 := desktop.activeWorkspace
+	ws2.AddApp(newFakeApp("build"))
+	build := ws2.tree.Root.Pane
+	build.setMonitor(true, 10*time.Second)
+	desktop.SwitchToWorkspace(1)
+
+	start := time.Now()
+	desktop.checkPaneMonitors(start)
+	if build.alert != 0 {
+		t.Fatalf("no alert expected before output, got %v", build.alert)
+	}
+
+	// Output right after leaving view is ignored; later output is activity.
+	build.lastOutput.Store(start.Add(100 * time.Millisecond).UnixNano())
+	desktop.checkPaneMonitors(start.Add(200 * time.Millisecond))
+	if build.alert != 0 {
+		t.Fatalf("output within the grace period should not alert")
+	}
+	build.lastOutput.Store(start.Add(2 * time.Second).UnixNano())
+	desktop.checkPaneMonitors(start.Add(2 * time.Second))
+	if build.alert != AlertActivity || rec.alerts[build.ID()] != AlertActivity {
+		t.Fatalf("expected activity alert, got %v", build.alert)
+	}
+	if info := desktop.workspacesChangedPayload().Workspaces[1]; info.ID != 2 || info.Alert != AlertActivity {
+		t.Fatalf("expected workspace 2 badge, got %+v", info)
+	}
+
+	desktop.checkPaneMonitors(start.Add(13 * time.Second))
+	if build.alert != AlertActivity|AlertSilence {
+		t.Fatalf("expected silence alert after 10s quiet, got %v", build.alert)
+	}
+
+	desktop.SwitchToWorkspace(2)
+	desktop.checkPaneMonitors(start.Add(14 * time.Second))
+	if build.alert != 0 || rec.alerts[build.ID()] != 0 || ws2.alert != 0 {
+		t.Fatalf("expected alerts cleared once the pane is shown")
+	}
+	if desktop.monitorTimer != nil {
+		desktop.monitorTimer.Stop()
+	}
+}
+
+// outputFakeApp reports output through the pane's notifier on demand.
+type outputFakeApp struct {
+	*fakeApp
+	notify func()
+}
+
+func (o *outputFakeApp) SetOutputNotifier(notify func()) { o.notify = notify }
+
+func TestPaneMonitorIgnoresRefreshesWithoutOutput(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	defer func() {
+		if desktop.monitorTimer != nil {
+			desktop.monitorTimer.Stop()
+		}
+	}()
+
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("editor"))
+	desktop.SwitchToWorkspace(2)
+	ws2 := desktop.activeWorkspace
+	app := &outputFakeApp{fakeApp: newFakeApp("build")}
+	ws2.AddApp(app)
+	build := ws2.tree.Root.Pane
+	if app.notify == nil {
+		t.Fatal("expected the pane to hand the app an output notifier")
+	}
+	build.setMonitor(true, 0)
+	desktop.SwitchToWorkspace(1)
+	build.monitorSince = time.Now().Add(-time.Minute)
+
+	// A focus change redraws the pane but is not output. Wait for the
+	// forwarder to pass the refresh on to the desktop.
+	gen := atomic.LoadInt32(&build.renderGen)
+	build.SetActive(!build.IsActive)
+	app.notifier <- true
+	deadline := time.After(time.Second)
+	for atomic.LoadInt32(&build.renderGen) == gen {
+		select {
+		case <-deadline:
+			t.Fatal("refresh was not forwarded")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	select {
+	case <-desktop.refreshCh:
+	case <-deadline:
+		t.Fatal("refresh did not reach the desktop")
+	}
+	desktop.checkPaneMonitors(time.Now())
+	if build.lastOutput.Load() != 0 || build.alert != 0 {
+		t.Fatalf("refresh counted as activity: lastOutput %d, alert %v", build.lastOutput.Load(), build.alert)
+	}
+
+	app.notify()
+	desktop.checkPaneMonitors(time.Now())
+	if build.alert != AlertActivity {
+		t.Fatalf("expected activity alert after output, got %v", build.alert)
+	}
+}
+
+type fakeLockVerifier struct {
+	password string
+}
+
+func (v fakeLockVerifier) Available() bool             { return v.password != "" }
+func (v fakeLockVerifier) Verify(password string) bool { return password == v.password }
+
+type keyCountingApp struct {
+	*fakeApp
+	keys int
+}
+
+func (k *keyCountingApp) HandleKey(ev *tcell.EventKey) { k.keys++ }
+
+func TestScreenLockBlanksPanesAndSwallowsInput(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.SwitchToWorkspace(1)
+	app := &keyCountingApp{fakeApp: newFakeApp("secret")}
+	desktop.activeWorkspace.AddApp(app)
+	desktop.SetClipboard("text/plain", []byte("token"))
+
+	if desktop.Lock() || desktop.IsLocked() {
+		t.Fatalf("lock without a password should be refused")
+	}
+	desktop.SetLockVerifier(fakeLockVerifier{password: "pw"})
+	if !desktop.Lock() || !desktop.IsLocked() {
+		t.Fatalf("expected desktop to lock")
+	}
+
+	paneID := desktop.activeWorkspace.tree.Root.Pane.ID()
+	sawPrompt := false
+	for _, snap := range desktop.SnapshotBuffers() {
+		if snap.ID == paneID {
+			if snap.Title != "" || !snap.AltScreen {
+				t.Fatalf("locked pane leaked metadata: %+v", snap)
+			}
+			for _, row := range snap.Buffer {
+				for _, c := range row {
+					if c.Ch != ' ' {
+						t.Fatalf("locked pane buffer is not blank")
+					}
+				}
+			}
+		}
+		if snap.Title == "Locked" {
+			sawPrompt = true
+		}
+	}
+	if !sawPrompt {
+		t.Fatalf("expected the unlock prompt in the snapshot")
+	}
+	if data := desktop.HandleClipboardGet("text/plain"); len(data) != 0 {
+		t.Fatalf("clipboard should be unreadable while locked, got %q", data)
+	}
+
+	unlock := func(password string) {
+		for _, r := range password {
+			desktop.handleEvent(tcell.NewEventKey(tcell.KeyRune, r, 0))
+		}
+		desktop.handleEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+		select {
+		case ev := <-desktop.eventCh:
+			desktop.processDesktopEvent(ev)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("unlock attempt never completed")
+		}
+	}
+
+	unlock("nope")
+	if !desktop.IsLocked() {
+		t.Fatalf("wrong password unlocked the screen")
+	}
+	desktop.handleEvent(tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	if !desktop.IsLocked() || len(desktop.floatingPanels) != 1 {
+		t.Fatalf("Esc must not dismiss the unlock prompt")
+	}
+
+	unlock("pw")
+	if desktop.IsLocked() || len(desktop.floatingPanels) != 0 {
+		t.Fatalf("expected correct password to unlock and close the prompt")
+	}
+	if app.keys != 0 {
+		t.Fatalf("pane received %d keys while locked", app.keys)
+	}
+	if string(desktop.HandleClipboardGet("text/plain")) != "token" {
+		t.Fatalf("clipboard should be readable again after unlock")
+	}
+}
+
+type clipboardApp struct {
+	*fakeApp
+	clipboard ClipboardService
+	pasted    []byte
+}
+
+func (c *clipboardApp) SetClipboardService(s ClipboardService) { c.clipboard = s }
+func (c *clipboardApp) HandlePaste(data []byte)                { c.pasted = append(c.pasted, data...) }
+
+func TestClipboardHistoryAndOSC52Policy(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.SwitchToWorkspace(1)
+	app := &clipboardApp{fakeApp: newFakeApp("editor")}
+	desktop.activeWorkspace.AddApp(app)
+	if app.clipboard == nil {
+		t.Fatalf("expected a clipboard service to be injected")
+	}
+
+	app.clipboard.SetClipboard("text/plain", []byte("first"))
+	desktop.SetClipboard("text/plain", []byte("second"))
+	app.clipboard.SetClipboard("text/plain", []byte("first"))
+	history := desktop.ClipboardHistory()
+	if len(history) != 2 || string(history[0].Data) != "first" || string(history[1].Data) != "second" {
+		t.Fatalf("unexpected history order: %+v", history)
+	}
+	if history[0].Source != "editor" || history[1].Source != "" {
+		t.Fatalf("unexpected sources %q, %q", history[0].Source, history[1].Source)
+	}
+
+	if !desktop.PasteClipboardEntry(history[1].ID) || string(app.pasted) != "second" {
+		t.Fatalf("expected older entry to be pasted, got %q", app.pasted)
+	}
+	if _, data, _ := desktop.GetClipboard(); string(data) != "second" {
+		t.Fatalf("pasted entry should become the clipboard, got %q", data)
+	}
+
+	osc := app.clipboard.(OSC52Clipboard)
+	drain := func() {
+		for {
+			select {
+			case ev := <-desktop.eventCh:
+				desktop.processDesktopEvent(ev)
+			default:
+				return
+			}
+		}
+	}
+
+	// Writes default to allow.
+	osc.OSC52Write([]byte("from-remote"))
+	drain()
+	if _, data, _ := desktop.GetClipboard(); string(data) != "from-remote" {
+		t.Fatalf("expected OSC 52 write to land, got %q", data)
+	}
+
+	// Reads default to ask: nothing happens until answered.
+	var replies []string
+	osc.OSC52Read(func(data []byte) { replies = append(replies, string(data)) })
+	drain()
+	if len(replies) != 0 || len(desktop.osc52Pending) != 1 {
+		t.Fatalf("expected read to wait for an answer, replies=%v pending=%d", replies, len(desktop.osc52Pending))
+	}
+	desktop.answerOSC52(false, false)
+	if len(replies) != 0 {
+		t.Fatalf("denied read should not reply")
+	}
+
+	osc.OSC52Read(func(data []byte) { replies = append(replies, string(data)) })
+	osc.OSC52Read(func(data []byte) { replies = append(replies, string(data)) })
+	drain()
+	desktop.answerOSC52(true, true)
+	if len(replies) != 2 || replies[0] != "from-remote" || len(desktop.osc52Pending) != 0 {
+		t.Fatalf("expected both queued reads answered, replies=%v", replies)
+	}
+
+	// Remembered answer applies without asking.
+	osc.OSC52Read(func(data []byte) { replies = append(replies, string(data)) })
+	drain()
+	if len(replies) != 3 {
+		t.Fatalf("expected remembered allow to reply immediately")
+	}
+
+	desktop.activeWorkspace.tree.Root.Pane.osc52Write = OSC52Deny
+	osc.OSC52Write([]byte("blocked"))
+	drain()
+	if _, data, _ := desktop.GetClipboard(); string(data) != "from-remote" {
+		t.Fatalf("denied write changed the clipboard to %q", data)
+	}
+
+	// With the event queue full a read is answered empty at once and a
+	// write still lands once the loop catches up.
+	desktop.activeWorkspace.tree.Root.Pane.osc52Write = OSC52Allow
+	for len(desktop.eventCh) < cap(desktop.eventCh) {
+		desktop.eventCh <- desktopEvent{kind: funcEventKind, fn: func() {}}
+	}
+	osc.OSC52Read(func(data []byte) { replies = append(replies, string(data)) })
+	if len(replies) != 4 || replies[3] != "" {
+		t.Fatalf("expected an empty reply while the desktop is busy, replies=%v", replies)
+	}
+	osc.OSC52Write([]byte("late"))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		drain()
+		if _, data, _ := desktop.GetClipboard(); string(data) == "late" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("OSC 52 write was dropped while the desktop was busy")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type busApp struct {
+	*fakeApp
+	bus ControlBus
+}
+
+func (b *busApp) ControlBus() ControlBus { return b.bus }
+
+func TestCommandPaletteListsAndRunsEntries(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.SetKeybindings(keybind.NewRegistry("linux", "", nil))
+	desktop.SwitchToWorkspace(1)
+	desktop.activeWorkspace.AddApp(newFakeApp("logs"))
+	desktop.SwitchToWorkspace(2)
+	term := &busApp{fakeApp: newFakeApp("term"), bus: NewControlBus()}
+	searched := 0
+	term.bus.Register(string(keybind.TermSearch), "", func(interface{}) error {
+		searched++
+		return nil
+	})
+	term.bus.Register("decorator.add", "", func(interface{}) error { return nil })
+	desktop.activeWorkspace.AddApp(term)
+
+	find := func(title, category string) PaletteEntry {
+		t.Helper()
+		for _, e := range desktop.paletteEntries() {
+			if e.Title == title && (category == "" || e.Category == category) {
+				return e
+			}
+		}
+		t.Fatalf("palette has no %q entry", title)
+		return PaletteEntry{}
+	}
+	for _, e := range desktop.paletteEntries() {
+		if e.Title == "Toggle control mode" || e.Title == "Add decorator action" {
+			t.Fatalf("palette lists hidden entry %q", e.Title)
+		}
+	}
+
+	if zoom := find("Toggle pane zoom", "Control"); zoom.Keys != "Ctrl+A Z" {
+		t.Fatalf("zoom keys = %q, want Ctrl+A Z", zoom.Keys)
+	}
+	desktop.runPaletteEntry(find("Toggle pane zoom", "Control"))
+	if desktop.zoomedPane == nil || desktop.inControlMode {
+		t.Fatalf("expected zoom without lingering in control mode")
+	}
+	desktop.runPaletteEntry(find("Toggle pane zoom", "Control"))
+
+	search := find("Toggle history search", "Terminal")
+	if search.Keys != "F3" {
+		t.Fatalf("search keys = %q, want F3", search.Keys)
+	}
+	desktop.runPaletteEntry(search)
+	if searched != 1 {
+		t.Fatalf("expected the app action to run once, ran %d", searched)
+	}
+
+	desktop.runPaletteEntry(find("logs", "Pane · 1: default"))
+	if desktop.activeWorkspace.id != 1 || desktop.activeWorkspace.ActivePane().getTitle() != "logs" {
+		t.Fatalf("expected focus on the logs pane in workspace 1")
+	}
+
+	desktop.runPaletteEntry(find("Go to workspace 2", "Workspace"))
+	if desktop.activeWorkspace.id != 2 {
+		t.Fatalf("expected workspace 2, got %d", desktop.activeWorkspace.id)
+	}
+
+	desktop.runPaletteEntry(find("Enter pane swap mode", "Control"))
+	if !desktop.inControlMode || desktop.subControlMode != 'w' {
+		t.Fatalf("swap should wait in control mode for a direction")
+	}
+}
+
+func TestChordsKeyTablesAndReplay(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.SetKeybindings(keybind.NewRegistryFromConfig(keybind.Config{
+		Preset:         "linux",
+		ChordTimeoutMs: 60000, // timeouts are driven by hand below
+		Chords: map[string]string{
+			"ctrl+a g s": "send:ls\r",
+			"ctrl+a n":   "table:nav",
+			"q w":        "control.zoom",
+		},
+		Tables: map[string]keybind.TableConfig{
+			"nav": {Keys: map[string]string{"z": "control.zoom", "t": "send:t"}},
+		},
+	}))
+	desktop.SwitchToWorkspace(1)
+	app := &keyRecordingApp{title: "term"}
+	desktop.activeWorkspace.AddApp(app)
+
+	press := func(keys ...*tcell.EventKey) {
+		for _, ev := range keys {
+			desktop.handleEvent(ev)
+		}
+	}
+	ctrlA := tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl)
+	key := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, 0) }
+	typed := func() string {
+		var sb strings.Builder
+		for _, ev := range app.getKeys() {
+			if ev.Key() == tcell.KeyEnter {
+				sb.WriteString("⏎")
+			} else {
+				sb.WriteRune(ev.Rune())
+			}
+		}
+		app.mu.Lock()
+		app.keys = nil
+		app.mu.Unlock()
+		return sb.String()
+	}
+
+	// A control chord keeps control mode until it completes.
+	press(ctrlA, key('g'))
+	if !desktop.inControlMode || desktop.chordHint == nil {
+		t.Fatalf("expected a pending chord with its hint in control mode")
+	}
+	press(key('s'))
+	if got := typed(); got != "ls⏎" {
+		t.Fatalf("send binding typed %q, want ls⏎", got)
+	}
+	if desktop.inControlMode || desktop.chordHint != nil {
+		t.Fatalf("expected control mode and hint gone after the chord")
+	}
+
+	// A root chord that goes nowhere hands its keys to the pane.
+	press(key('q'))
+	if got := typed(); got != "" {
+		t.Fatalf("pending chord leaked %q", got)
+	}
+	press(key('x'))
+	if got := typed(); got != "qx" {
+		t.Fatalf("abandoned chord replayed %q, want qx", got)
+	}
+	press(key('q'))
+	desktop.chordTimedOut(desktop.chordGen)
+	if got := typed(); got != "q" {
+		t.Fatalf("timed-out chord replayed %q, want q", got)
+	}
+	press(key('q'), key('w'))
+	if desktop.zoomedPane == nil {
+		t.Fatalf("expected q w to zoom")
+	}
+
+	// A key table stays active until an unbound key, which it swallows.
+	press(ctrlA, key('n'))
+	if desktop.keyTable == nil || desktop.inControlMode || desktop.chordHint == nil {
+		t.Fatalf("expected the nav table with its hint outside control mode")
+	}
+	press(key('z'), key('t'))
+	if desktop.zoomedPane != nil {
+		t.Fatalf("expected z in the table to unzoom")
+	}
+	if got := typed(); got != "t" {
+		t.Fatalf("table send typed %q, want t", got)
+	}
+	press(key('k'))
+	if desktop.keyTable != nil || desktop.chordHint != nil {
+		t.Fatalf("expected an unbound key to leave the table")
+	}
+	if got := typed(); got != "" {
+		t.Fatalf("key leaving the table leaked %q", got)
+	}
+}
+
+type shellApp struct {
+	*fakeApp
+	status ShellStatus
+}
+
+func (s *shellApp) ShellStatus() ShellStatus { return s.status }
+
+type paneEventRecorder struct {
+	events []ActivePaneChangedPayload
+}
+
+func (r *paneEventRecorder) OnEvent(e Event) {
+	if p, ok := e.Payload.(ActivePaneChangedPayload); ok {
+		r.events = append(r.events, p)
+	}
+}
+
+func TestShellStatusChangesRebroadcastActivePane(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.SwitchToWorkspace(1)
+	term := &shellApp{fakeApp: newFakeApp("term"), status: ShellStatus{Cwd: "/src"}}
+	desktop.activeWorkspace.AddApp(term)
+
+	rec := &paneEventRecorder{}
+	desktop.Subscribe(rec)
+	desktop.checkShellStatus()
+	if len(rec.events) != 0 {
+		t.Fatalf("unchanged status should not rebroadcast, got %d events", len(rec.events))
+	}
+
+	term.status = ShellStatus{Cwd: "/src", Command: "make", Finished: true, ExitCode: 2}
+	desktop.checkShellStatus()
+	desktop.checkShellStatus()
+	if len(rec.events) != 1 {
+		t.Fatalf("expected one rebroadcast, got %d", len(rec.events))
+	}
+	if got := rec.events[0].Shell; got.ExitCode != 2 || got.Cwd != "/src" {
+		t.Fatalf("payload shell = %+v", got)
+	}
+}
+
+type fakeSnapshotHistory map[string]TreeCapture
+
+func (h fakeSnapshotHistory) Generations() ([]SnapshotGeneration, error) { return nil, nil }
+func (h fakeSnapshotHistory) GenerationCapture(id string) (TreeCapture, error) {
+	return h[id], nil
+}
+
+func TestRestoreSnapshotRebuildsEarlierLayout(t *testing.T) {
+	driver := &stubScreenDriver{}
+	lifecycle := &trackingLifecycle{}
+
+	desktop, err := NewDesktopEngineWithDriver(driver, func() App { return newFakeApp("shell") }, "", lifecycle)
+	if err != nil {
+		t.Fatalf("expected desktop, got error %v", err)
+	}
+	desktop.RegisterSnapshotFactory("fake", func(title string, cfg map[string]interface{}) App {
+		return &snapshotFakeApp{fakeApp: newFakeApp(title), cwd: cfg["cwd"].(string)}
+	})
+	desktop.SetViewportSize(80, 24)
+
+	desktop.SwitchToWorkspace(1)
+	ws1 := desktop.activeWorkspace
+	ws1.AddApp(&snapshotFakeApp{fakeApp: newFakeApp("left"), cwd: "/left"})
+	ws1.ShellAppFactory = func() App { return &snapshotFakeApp{fakeApp: newFakeApp("right"), cwd: "/right"} }
+	ws1.PerformSplit(Vertical)
+	desktop.SwitchToWorkspace(2)
+	desktop.activeWorkspace.AddApp(&snapshotFakeApp{fakeApp: newFakeApp("logs"), cwd: "/logs"})
+	desktop.SwitchToWorkspace(1)
+	leftID := ws1.tree.Root.Children[0].Pane.ID()
+	leftApp := ws1.tree.Root.Children[0].Pane.app
+	rightApp := ws1.tree.Root.Children[1].Pane.app
+	history := fakeSnapshotHistory{"old": desktop.CaptureTree()}
+	desktop.SetSnapshotHistory(history)
+
+	// Later: workspace 2 is closed and a third pane is added.
+	desktop.CloseWorkspace(2)
+	ws1.ShellAppFactory = func() App { return &snapshotFakeApp{fakeApp: newFakeApp("third"), cwd: "/third"} }
+	ws1.PerformSplit(Horizontal)
+	thirdApp := ws1.tree.ActiveLeaf.Pane.app
+	lifecycle.started, lifecycle.stopped = nil, nil
+
+	if err := desktop.RestoreSnapshot("old"); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if len(desktop.workspaces) != 2 || desktop.workspaces[2] == nil {
+		t.Fatalf("expected workspaces 1 and 2 back, got %d", len(desktop.workspaces))
+	}
+	var titles []string
+	desktop.workspaces[1].tree.Traverse(func(n *Node) {
+		if n.Pane != nil {
+			titles = append(titles, n.Pane.getTitle())
+		}
+	})
+	if strings.Join(titles, ",") != "left,right" {
+		t.Fatalf("workspace 1 panes = %v, want left,right", titles)
+	}
+	if got := desktop.workspaces[1].tree.Root.Children[0].Pane.ID(); got != leftID {
+		t.Fatalf("restored pane should keep its ID")
+	}
+	// Open panes are moved, not relaunched; only the closed workspace's
+	// pane starts again, and only the pane the snapshot lacks stops.
+	ws1Panes := desktop.workspaces[1].tree.Root.Children
+	if ws1Panes[0].Pane.app != leftApp || ws1Panes[1].Pane.app != rightApp {
+		t.Fatalf("open panes should keep their running apps")
+	}
+	if len(lifecycle.stopped) != 1 || lifecycle.stopped[0] != thirdApp {
+		t.Fatalf("stopped %v, want only the newer pane's app", lifecycle.stopped)
+	}
+	if len(lifecycle.started) != 1 || lifecycle.started[0].GetTitle() != "logs" {
+		t.Fatalf("started %v, want only the closed workspace's pane", lifecycle.started)
+	}
+	if closed := desktop.ClosedPanes(); len(closed) != 1 || closed[0].Title != "third" {
+		t.Fatalf("expected the newer pane on the undo-close stack, got %+v", closed)
+	}
+
+	if err := (&DesktopEngine{}).RestoreSnapshot("old"); err == nil {
+		t.Fatalf("expected an error without snapshot history")
+	}
+}
@@ -7,6 +7,7 @@
 package texel
 
 import (
+	"github.com/framegrace/texelation/internal/debuglog"
 	"github.com/framegrace/texelation/internal/keybind"
 	"github.com/gdamore/tcell/v2"
 )
@@ -44,51 +45,16 @@ func (d *DesktopEngine) handleEvent(ev tcell.Event) {
 	if !ok {
 		return
 	}
+	if d.locked.Load() {
+		d.handleLockedKey(key)
+		return
+	}
+	if d.handleChordKey(key) {
+		return
+	}
 	// Keybinding-driven shortcuts
-	if d.keybindings != nil {
-		action := d.keybindings.Match(key)
-		switch action {
-		case keybind.Help:
-			d.launchHelpOverlay()
-			return
-		case keybind.WorkspaceSwitchPrev:
-			d.switchWorkspaceRelative(-1)
-			return
-		case keybind.WorkspaceSwitchNext:
-			d.switchWorkspaceRelative(1)
-			return
-		case keybind.ConfigEditor:
-			d.launchConfigEditorOverlay(d.activeAppTarget())
-			return
-		case keybind.ControlToggle:
-			d.toggleControlMode()
-			return
-		}
-
-		// Pane resize (skip in tab mode)
-		if !d.inTabMode {
-			switch action {
-			case keybind.PaneResizeUp, keybind.PaneResizeDown, keybind.PaneResizeLeft, keybind.PaneResizeRight:
-				if d.activeWorkspace != nil {
-					dir := actionToDirection(action)
-					searchDir := dir
-					if dir == DirLeft {
-						searchDir = DirRight
-					} else if dir == DirUp {
-						searchDir = DirDown
-					}
-					border := d.activeWorkspace.findBorderToResize(searchDir)
-					if border == nil {
-						border = d.activeWorkspace.findBorderToResize(dir)
-					}
-					if border != nil {
-						d.activeWorkspace.adjustBorder(border, dir)
-						d.activeWorkspace.clearResizeSelection(border)
-					}
-				}
-				return
-			}
-		}
+	if d.keybindings != nil && d.runDesktopAction(d.keybindings.Match(key)) {
+		return
 	}
 
 	// If in control mode, route keys to control handler (which also manages
@@ -149,6 +115,9 @@ func (d *DesktopEngine) handleMouseEvent(ev *tcell.EventMouse) {
 }
 
 func (d *DesktopEngine) processMouseEvent(x, y int, buttons tcell.ButtonMask, modifiers tcell.ModMask) {
+	if d.locked.Load() {
+		return
+	}
 	d.mouseMu.Lock()
 	prevButtons := d.lastMouseButtons
 	d.lastMouseX = x
@@ -179,6 +148,14 @@ func (d *DesktopEngine) processMouseEvent(x, y int, buttons tcell.ButtonMask, mo
 		return
 	}
 
+	// A press on a tab strip selects that tab instead of reaching the pane.
+	buttonPressed := buttons&tcell.Button1 != 0 && prevButtons&tcell.Button1 == 0
+	if buttonPressed && d.zoomedPane == nil && d.activeWorkspace != nil {
+		if d.activeWorkspace.selectTabAt(x, y) {
+			return
+		}
+	}
+
 	// Forward mouse events to the pane under cursor
 	pane := d.paneAtCoordinates(x, y)
 	if pane != nil && pane.handlesMouseEvents() {
@@ -186,7 +163,6 @@ func (d *DesktopEngine) processMouseEvent(x, y int, buttons tcell.ButtonMask, mo
 	}
 
 	// Activate pane on button press
-	buttonPressed := buttons&tcell.Button1 != 0 && prevButtons&tcell.Button1 == 0
 	if buttonPressed {
 		d.activatePaneAt(x, y)
 	}
@@ -292,6 +268,96 @@ func (d *DesktopEngine) activatePaneAt(x, y int) {
 	}
 }
 
+// runDesktopAction performs a desktop-wide keybinding action and reports
+// whether action was one. The command palette runs actions through it too.
+func (d *DesktopEngine) runDesktopAction(action keybind.Action) bool {
+	switch action {
+	case keybind.Help:
+		d.launchHelpOverlay()
+		return true
+	case keybind.WorkspaceSwitchPrev:
+		d.switchWorkspaceRelative(-1)
+		return true
+	case keybind.WorkspaceSwitchNext:
+		d.switchWorkspaceRelative(1)
+		return true
+	case keybind.ConfigEditor:
+		d.launchConfigEditorOverlay(d.activeAppTarget())
+		return true
+	case keybind.ControlToggle:
+		d.toggleControlMode()
+		return true
+	}
+
+	// Pane resize (skip in tab mode)
+	if !d.inTabMode {
+		switch action {
+		case keybind.PaneResizeUp, keybind.PaneResizeDown, keybind.PaneResizeLeft, keybind.PaneResizeRight:
+			if d.activeWorkspace != nil {
+				dir := actionToDirection(action)
+				searchDir := dir
+				if dir == DirLeft {
+					searchDir = DirRight
+				} else if dir == DirUp {
+					searchDir = DirDown
+				}
+				border := d.activeWorkspace.findBorderToResize(searchDir)
+				if border == nil {
+					border = d.activeWorkspace.findBorderToResize(dir)
+				}
+				if border != nil {
+					d.activeWorkspace.adjustBorder(border, dir)
+					d.activeWorkspace.clearResizeSelection(border)
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// runAction runs any keybind action as if its key had been pressed, for
+// callers that are not key presses: the command palette and chords.
+// Terminal actions go to the focused app's control bus.
+func (d *DesktopEngine) runAction(action keybind.Action) {
+	switch keybind.ActionDescriptions[action].Category {
+	case "Control":
+		// Actions that wait for a follow-up key (direction or workspace
+		// digit) need control mode to receive it.
+		switch action {
+		case keybind.ControlSwap, keybind.ControlSendPane, keybind.ControlJoinPane:
+			if !d.inControlMode {
+				d.toggleControlMode()
+			}
+		}
+		if d.runControlAction(action) && d.inControlMode {
+			d.toggleControlMode()
+		}
+	case "Pane":
+		if d.runDesktopAction(action) || d.activeWorkspace == nil {
+			return
+		}
+		d.activeWorkspace.moveActivePane(actionToDirection(action))
+		d.activeWorkspace.Refresh()
+	case "Terminal":
+		if d.activeWorkspace == nil {
+			return
+		}
+		p := d.activeWorkspace.ActivePane()
+		if p == nil {
+			return
+		}
+		if provider, ok := p.app.(interface{ ControlBus() ControlBus }); ok {
+			if err := provider.ControlBus().Trigger(string(action), nil); err != nil {
+				debuglog.Printf("runAction: %s: %v", action, err)
+			}
+			p.markDirty()
+		}
+	default:
+		d.runDesktopAction(action)
+	}
+}
+
 // actionToDirection maps a pane resize or navigate action to a Direction.
 func actionToDirection(a keybind.Action) Direction {
 	switch a {
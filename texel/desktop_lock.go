@@ -0,0 +1,283 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/desktop_lock.go
+// Summary: Screen lock with password re-authentication.
+// Usage: Locked from control mode "L" or by a client's idle watcher
+//   (RequestLock); unlocked by typing the password into the prompt overlay.
+//   texel-server installs the verifier with SetLockVerifier.
+// Notes: The lock lives in the server, so it survives detach/reattach.
+//   While locked every pane is published as a blank buffer, scrollback
+//   fetches and clipboard reads are refused, and all input except keys for
+//   the prompt is dropped. Persistence snapshots are unaffected.
+
+package texel
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/framegrace/texelui/theme"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// LockVerifier checks unlock attempts. Available reports whether any
+// password could succeed; without one the desktop refuses to lock.
+type LockVerifier interface {
+	Available() bool
+	Verify(password string) bool
+}
+
+// SetLockVerifier installs the password check used by the screen lock.
+func (d *DesktopEngine) SetLockVerifier(v LockVerifier) {
+	d.lockVerifier = v
+}
+
+// IsLocked reports whether the screen is locked. Safe from any goroutine.
+func (d *DesktopEngine) IsLocked() bool {
+	return d.locked.Load()
+}
+
+// RequestLock locks the screen from outside the desktop loop.
+func (d *DesktopEngine) RequestLock() {
+	d.post(func() { d.Lock() })
+}
+
+// post runs fn on the desktop loop. Unlike SendEvent it waits for room
+// rather than dropping, since callers rely on fn running.
+func (d *DesktopEngine) post(fn func()) {
+	select {
+	case d.eventCh <- desktopEvent{kind: funcEventKind, fn: fn}:
+	case <-d.quit:
+	}
+}
+
+// Lock blanks the desktop behind the unlock prompt and reports whether the
+// screen is now locked.
+func (d *DesktopEngine) Lock() bool {
+	if d.locked.Load() {
+		return true
+	}
+	if d.lockVerifier == nil || !d.lockVerifier.Available() {
+		d.BroadcastToast("Screen lock unavailable: set a password with texelation --set-lock-password", ToastWarning, 4*time.Second)
+		return false
+	}
+	d.resetChords()
+	if d.inControlMode {
+		d.toggleControlMode()
+	}
+	for len(d.floatingPanels) > 0 {
+		d.CloseFloatingPanel(d.floatingPanels[len(d.floatingPanels)-1])
+	}
+
+	d.locked.Store(true)
+	d.lockPrompt = newLockPrompt(d.attemptUnlock)
+	vw, vh := d.viewportSize()
+	w := max(min(40, vw-2), 1)
+	h := max(min(lockPromptHeight, vh-2), 1)
+	d.ShowFloatingPanel(d.lockPrompt, (vw-w)/2, (vh-h)/2, w, h)
+	return true
+}
+
+// attemptUnlock checks password off the loop; hashing is deliberately slow.
+func (d *DesktopEngine) attemptUnlock(password string) {
+	verifier := d.lockVerifier
+	go func() {
+		ok := verifier != nil && verifier.Verify(password)
+		d.post(func() { d.finishUnlock(ok) })
+	}()
+}
+
+func (d *DesktopEngine) finishUnlock(ok bool) {
+	prompt := d.lockPrompt
+	if !d.locked.Load() || prompt == nil {
+		return
+	}
+	if !ok {
+		prompt.fail("Wrong password")
+		return
+	}
+	d.locked.Store(false)
+	d.lockPrompt = nil
+	d.closeFloatingPanelByApp(prompt)
+}
+
+// handleLockedKey is the only key path while locked.
+func (d *DesktopEngine) handleLockedKey(ev *tcell.EventKey) {
+	if d.lockPrompt != nil {
+		d.lockPrompt.HandleKey(ev)
+	}
+}
+
+// lockedPaneSnapshot stands in for a pane's content while locked.
+func lockedPaneSnapshot(id [16]byte, rect Rectangle) PaneSnapshot {
+	tm := theme.Get()
+	style := tcell.StyleDefault.Background(tm.GetSemanticColor("bg.base").TrueColor())
+	buf := make([][]Cell, max(rect.Height, 0))
+	for y := range buf {
+		buf[y] = make([]Cell, max(rect.Width, 0))
+		for x := range buf[y] {
+			buf[y][x] = Cell{Ch: ' ', Style: style}
+		}
+	}
+	return PaneSnapshot{
+		ID:           id,
+		Buffer:       buf,
+		RowGlobalIdx: allMinusOne(len(buf)),
+		AltScreen:    true,
+		Rect:         rect,
+	}
+}
+
+// blankIfLocked swaps snaps for blank stand-ins while locked.
+func blankIfLocked(snaps []PaneSnapshot, locked bool) []PaneSnapshot {
+	if !locked {
+		return snaps
+	}
+	for i, s := range snaps {
+		snaps[i] = lockedPaneSnapshot(s.ID, s.Rect)
+	}
+	return snaps
+}
+
+func lockedPaneRect(p *pane) Rectangle {
+	return Rectangle{X: p.absX0, Y: p.absY0, Width: p.Width(), Height: p.Height()}
+}
+
+const lockPromptHeight = 5
+
+// lockPrompt is the unlock overlay. It is built in rather than registered
+// so the lock never depends on an app being installed.
+type lockPrompt struct {
+	mu            sync.Mutex
+	width, height int
+	input         []rune
+	message       string
+	checking      bool
+	submit        func(string)
+	refreshChan   chan<- bool
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+func newLockPrompt(submit func(string)) *lockPrompt {
+	return &lockPrompt{submit: submit, stop: make(chan struct{})}
+}
+
+func (l *lockPrompt) Run() error {
+	<-l.stop
+	return nil
+}
+
+func (l *lockPrompt) Stop() {
+	l.stopOnce.Do(func() { close(l.stop) })
+}
+
+func (l *lockPrompt) Resize(cols, rows int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.width, l.height = cols, rows
+}
+
+func (l *lockPrompt) GetTitle() string {
+	return "Locked"
+}
+
+func (l *lockPrompt) SetRefreshNotifier(refreshChan chan<- bool) {
+	l.refreshChan = refreshChan
+}
+
+func (l *lockPrompt) HandleKey(ev *tcell.EventKey) {
+	l.mu.Lock()
+	if l.checking {
+		l.mu.Unlock()
+		return
+	}
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		password := string(l.input)
+		l.input = l.input[:0]
+		l.checking = true
+		l.message = "Checking…"
+		l.mu.Unlock()
+		l.requestRefresh()
+		l.submit(password)
+		return
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(l.input) > 0 {
+			l.input = l.input[:len(l.input)-1]
+		}
+	case tcell.KeyEsc, tcell.KeyCtrlU:
+		l.input = l.input[:0]
+	case tcell.KeyRune:
+		l.input = append(l.input, ev.Rune())
+		l.message = ""
+	}
+	l.mu.Unlock()
+	l.requestRefresh()
+}
+
+// fail re-arms the prompt after a rejected password.
+func (l *lockPrompt) fail(message string) {
+	l.mu.Lock()
+	l.checking = false
+	l.message = message
+	l.mu.Unlock()
+	l.requestRefresh()
+}
+
+func (l *lockPrompt) requestRefresh() {
+	if l.refreshChan == nil {
+		return
+	}
+	select {
+	case l.refreshChan <- true:
+	default:
+	}
+}
+
+func (l *lockPrompt) Render() [][]Cell {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.width <= 0 || l.height <= 0 {
+		return [][]Cell{}
+	}
+
+	tm := theme.Get()
+	base := tcell.StyleDefault.Background(tm.GetSemanticColor("bg.surface").TrueColor()).Foreground(tm.GetSemanticColor("text.primary").TrueColor())
+	dim := base.Foreground(tm.GetSemanticColor("text.secondary").TrueColor())
+
+	buf := make([][]Cell, l.height)
+	for y := range buf {
+		buf[y] = make([]Cell, l.width)
+		for x := range buf[y] {
+			buf[y][x] = Cell{Ch: ' ', Style: base}
+		}
+	}
+	put := func(y int, s string, st tcell.Style) {
+		if y >= l.height {
+			return
+		}
+		x := max((l.width-runewidth.StringWidth(s))/2, 0)
+		for _, r := range s {
+			w := runewidth.RuneWidth(r)
+			if x+w > l.width {
+				return
+			}
+			buf[y][x] = Cell{Ch: r, Style: st}
+			x += w
+		}
+	}
+
+	put(0, "Screen locked", base.Bold(true))
+	field := strings.Repeat("•", min(len(l.input), max(l.width-4, 0)))
+	put(2, "Password: "+field, base)
+	if l.message != "" {
+		put(4, l.message, dim)
+	} else {
+		put(4, "Type your password and press Enter", dim)
+	}
+	return buf
+}
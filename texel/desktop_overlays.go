@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/framegrace/texelation/apps/help"
 	"github.com/framegrace/texelation/internal/keybind"
@@ -243,6 +244,166 @@ func (d *DesktopEngine) launchHelpOverlay() {
 	d.ShowFloatingPanel(app, x, y, w, h)
 }
 
+// pickerOverlay describes a list picker shown as a floating panel. The
+// picker triggers "<control>.select" with the chosen entry and
+// "<control>.close" on Esc; both close the panel.
+type pickerOverlay struct {
+	app      string                    // registry name
+	title    string                    // picker title; launching it again closes it
+	control  string                    // control bus prefix
+	populate func(app App)             // hands the picker its entries
+	selected func(payload interface{}) // runs once the panel is closed
+	w, h     int                       // preferred size, clamped to the viewport
+	upper    bool                      // sit in the upper third instead of the middle
+}
+
+// launchPickerOverlay opens p centered on the viewport, or closes it if it
+// is already open.
+func (d *DesktopEngine) launchPickerOverlay(p pickerOverlay) {
+	for _, fp := range d.floatingPanels {
+		if fp.app.GetTitle() == p.title {
+			d.CloseFloatingPanel(fp)
+			return
+		}
+	}
+
+	appInstance := d.registry.CreateApp(p.app, nil)
+	app, ok := appInstance.(App)
+	if !ok {
+		return
+	}
+	p.populate(app)
+
+	if provider, ok := app.(ControlBusProvider); ok {
+		provider.RegisterControl(p.control+".select", "Pick the selected "+p.title+" entry", func(payload interface{}) error {
+			d.closeFloatingPanelByApp(app)
+			p.selected(payload)
+			return nil
+		})
+		provider.RegisterControl(p.control+".close", "Close "+p.title, func(payload interface{}) error {
+			d.closeFloatingPanelByApp(app)
+			return nil
+		})
+	}
+
+	vw, vh := d.viewportSize()
+	w := min(p.w, vw-2)
+	h := min(p.h, vh-2)
+	y := (vh - h) / 2
+	if p.upper {
+		y = (vh - h) / 3
+	}
+	d.ShowFloatingPanel(app, (vw-w)/2, y, w, h)
+}
+
+func (d *DesktopEngine) launchReopenOverlay() {
+	d.launchPickerOverlay(pickerOverlay{
+		app:     "reopen",
+		title:   "Reopen",
+		control: "reopen",
+		populate: func(app App) {
+			if setter, ok := app.(ClosedPaneListSetter); ok {
+				setter.SetClosedPanes(d.ClosedPanes())
+			}
+		},
+		selected: func(payload interface{}) {
+			if id, ok := payload.([16]byte); ok {
+				d.ReopenClosedPane(id)
+			}
+		},
+		w: 60,
+		h: closedPaneLimit + 2,
+	})
+}
+
+func (d *DesktopEngine) launchSnapshotsOverlay() {
+	// Close an open picker before the checks below can toast.
+	for _, fp := range d.floatingPanels {
+		if fp.app.GetTitle() == "Snapshots" {
+			d.CloseFloatingPanel(fp)
+			return
+		}
+	}
+	if d.snapshotHistory == nil {
+		d.BroadcastToast("Session snapshots are disabled", ToastInfo, 2*time.Second)
+		return
+	}
+	generations, err := d.SnapshotGenerations()
+	if err != nil {
+		d.BroadcastToast("Cannot list snapshots: "+err.Error(), ToastError, 3*time.Second)
+		return
+	}
+
+	d.launchPickerOverlay(pickerOverlay{
+		app:     "snapshots",
+		title:   "Snapshots",
+		control: "snapshots",
+		populate: func(app App) {
+			if setter, ok := app.(SnapshotListSetter); ok {
+				setter.SetSnapshots(generations)
+			}
+		},
+		selected: func(payload interface{}) {
+			id, ok := payload.(string)
+			if !ok {
+				return
+			}
+			if err := d.RestoreSnapshot(id); err != nil {
+				d.BroadcastToast("Restore failed: "+err.Error(), ToastError, 3*time.Second)
+				return
+			}
+			for _, g := range generations {
+				if g.ID == id {
+					d.BroadcastToast("Restored the layout of "+g.SavedAt.Local().Format("Jan 2 15:04"), ToastSuccess, 3*time.Second)
+				}
+			}
+		},
+		w: 72,
+		h: 24,
+	})
+}
+
+func (d *DesktopEngine) launchClipboardOverlay() {
+	d.launchPickerOverlay(pickerOverlay{
+		app:     "clipboard-history",
+		title:   "Clipboard",
+		control: "clipboard",
+		populate: func(app App) {
+			if setter, ok := app.(ClipboardHistorySetter); ok {
+				setter.SetClipboardHistory(d.ClipboardHistory())
+			}
+		},
+		selected: func(payload interface{}) {
+			if id, ok := payload.(uint64); ok {
+				d.PasteClipboardEntry(id)
+			}
+		},
+		w: 72,
+		h: min(clipboardHistoryLimit(), 20) + 2,
+	})
+}
+
+func (d *DesktopEngine) launchCommandPalette() {
+	d.launchPickerOverlay(pickerOverlay{
+		app:     "palette",
+		title:   "Commands",
+		control: "palette",
+		populate: func(app App) {
+			if setter, ok := app.(PaletteEntriesSetter); ok {
+				setter.SetPaletteEntries(d.paletteEntries())
+			}
+		},
+		selected: func(payload interface{}) {
+			if entry, ok := payload.(PaletteEntry); ok {
+				d.runPaletteEntry(entry)
+			}
+		},
+		w:     72,
+		h:     22,
+		upper: true,
+	})
+}
+
 const controlHelpTitle = "Control Mode"
 
 func (d *DesktopEngine) launchControlHelpOverlay() {
@@ -416,26 +577,19 @@ func loadKeybindingsFromDisk() *keybind.Registry {
 	if err != nil {
 		return nil
 	}
-	preset := "auto"
-	var extraPreset string
-	var overrides map[string][]string
+	cfg := keybind.Config{Preset: "auto"}
 
 	data, err := os.ReadFile(filepath.Join(home, ".config", "texelation", "keybindings.json"))
 	if err == nil {
-		var cfg struct {
-			Preset      string              `json:"preset"`
-			ExtraPreset string              `json:"extraPreset"`
-			Actions     map[string][]string `json:"actions"`
-		}
-		if json.Unmarshal(data, &cfg) == nil {
-			if cfg.Preset != "" {
-				preset = cfg.Preset
+		var fileCfg keybind.Config
+		if json.Unmarshal(data, &fileCfg) == nil {
+			cfg = fileCfg
+			if cfg.Preset == "" {
+				cfg.Preset = "auto"
 			}
-			extraPreset = cfg.ExtraPreset
-			overrides = cfg.Actions
 		}
 	}
-	return keybind.NewRegistry(preset, extraPreset, overrides)
+	return keybind.NewRegistryFromConfig(cfg)
 }
 
 // notifyAppConfigChanged iterates all panes across all workspaces and calls
@@ -0,0 +1,179 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/desktop_pane_move.go
+// Summary: Moves live panes between workspaces (send-to, break-out, join).
+// Usage: Control mode "m" + digit sends the active pane to that workspace,
+//   "!" breaks it out into a new workspace, and "J" + digit joins the active
+//   pane of that workspace into the current one.
+// Notes: The pane object itself moves, so its ID, running app, WAL and
+//   search index are untouched; only the workspace it reports to changes.
+//   A workspace left without panes is closed.
+
+package texel
+
+import (
+	"log"
+	"time"
+
+	"github.com/framegrace/texelation/internal/debuglog"
+	"github.com/gdamore/tcell/v2"
+)
+
+// SendActivePaneToWorkspace moves the active pane into workspace id,
+// creating it if needed. Focus stays on the current workspace unless it
+// was emptied by the move.
+func (d *DesktopEngine) SendActivePaneToWorkspace(id int) {
+	src := d.activeWorkspace
+	if src == nil || src.tree == nil || src.tree.ActiveLeaf == nil || src.id == id || id <= 0 {
+		return
+	}
+	d.movePane(src, d.ensureWorkspace(id), src.tree.ActiveLeaf)
+}
+
+// BreakOutActivePane moves the active pane into a new workspace of its own
+// and switches to it.
+func (d *DesktopEngine) BreakOutActivePane() {
+	src := d.activeWorkspace
+	if src == nil || src.tree == nil || src.tree.ActiveLeaf == nil {
+		return
+	}
+	if src.tree.ActiveLeaf == src.tree.Root {
+		d.BroadcastToast("Pane is already alone in its workspace", ToastInfo, 2*time.Second)
+		return
+	}
+	maxID := 0
+	for id := range d.workspaces {
+		maxID = max(maxID, id)
+	}
+	dst := d.ensureWorkspace(maxID + 1)
+	d.movePane(src, dst, src.tree.ActiveLeaf)
+	d.SwitchToWorkspace(dst.id)
+}
+
+// JoinPaneFromWorkspace moves the active pane of workspace id into the
+// active workspace, splitting the current pane.
+func (d *DesktopEngine) JoinPaneFromWorkspace(id int) {
+	dst := d.activeWorkspace
+	src, ok := d.workspaces[id]
+	if dst == nil || !ok || src == dst || src.tree == nil || src.tree.ActiveLeaf == nil {
+		return
+	}
+	d.movePane(src, dst, src.tree.ActiveLeaf)
+}
+
+// ensureWorkspace returns workspace id, creating it empty (without the
+// init app SwitchToWorkspace would start) when it does not exist.
+func (d *DesktopEngine) ensureWorkspace(id int) *Workspace {
+	if ws, ok := d.workspaces[id]; ok {
+		return ws
+	}
+	ws, err := newWorkspace(id, d.ShellAppFactory, d.appLifecycle, d)
+	if err != nil {
+		log.Printf("ensureWorkspace: %v", err)
+		return nil
+	}
+	d.workspaces[id] = ws
+	return ws
+}
+
+func (d *DesktopEngine) movePane(src, dst *Workspace, node *Node) {
+	if dst == nil || node == nil || node.Pane == nil {
+		return
+	}
+	if d.zoomedPane != nil {
+		d.toggleZoom()
+	}
+	p := src.takePane(node)
+	dst.adoptPane(p)
+	debuglog.Printf("movePane: moved '%s' from workspace %d to %d", p.getTitle(), src.id, dst.id)
+
+	// Only panes of the visible workspace may hold focus.
+	if dst != d.activeWorkspace {
+		p.SetActive(false)
+	}
+	if src.tree.Root == nil {
+		if src == d.activeWorkspace {
+			d.SwitchToWorkspace(dst.id)
+		}
+		d.CloseWorkspace(src.id)
+	}
+
+	d.recalculateLayout()
+	d.broadcastWorkspacesChanged()
+	// After the tab list is rebuilt, so the status bar can flash the new tab.
+	d.dispatcher.Broadcast(Event{Type: EventPaneMoved, Payload: PaneMovedPayload{PaneID: p.ID(), From: src.id, To: dst.id}})
+	d.broadcastActivePaneChanged()
+	d.notifyFocusActive()
+	d.broadcastTreeChanged()
+}
+
+// takePane unlinks node from w without closing its pane and returns the
+// pane, still running.
+func (w *Workspace) takePane(node *Node) *pane {
+	if w.mouseResizeBorder != nil {
+		w.finishMouseResize()
+	}
+	p := node.Pane
+	wasActive := w.tree.ActiveLeaf == node
+	if node.Parent == nil {
+		w.tree.Root = nil
+		w.tree.ActiveLeaf = nil
+	} else {
+		parent := node.Parent
+		index := -1
+		for i, child := range parent.Children {
+			if child == node {
+				index = i
+				break
+			}
+		}
+		next := w.unlinkNode(parent, index)
+		if wasActive {
+			w.tree.ActiveLeaf = next
+			if next != nil && next.Pane != nil {
+				next.Pane.SetActive(true)
+			}
+		}
+	}
+	if listener, ok := p.app.(Listener); ok {
+		w.Unsubscribe(listener)
+	}
+	w.recalculateLayout()
+	return p
+}
+
+// adoptPane inserts a running pane into w, splitting the active pane along
+// its longer side, and makes it w's active pane.
+func (w *Workspace) adoptPane(p *pane) {
+	p.screen = w
+	if listener, ok := p.app.(Listener); ok {
+		w.Subscribe(listener)
+	}
+	if w.tree.Root != nil && w.tree.ActiveLeaf == nil {
+		w.tree.ActiveLeaf = findFirstLeaf(w.tree.Root)
+	}
+	if w.tree.Root == nil {
+		w.tree.SetRoot(p)
+	} else {
+		split := Horizontal
+		if cur := w.tree.ActiveLeaf.Pane; cur != nil && cur.Width() >= 2*cur.Height() {
+			split = Vertical
+		}
+		if cur := w.tree.ActiveLeaf.Pane; cur != nil {
+			cur.SetActive(false)
+		}
+		w.tree.SplitActive(split, p)
+	}
+	p.SetActive(true)
+	p.markDirty()
+	w.recalculateLayout()
+}
+
+// keyToWorkspaceID maps the digits 1-9 to workspace IDs.
+func keyToWorkspaceID(ev *tcell.EventKey) (int, bool) {
+	if ev.Key() != tcell.KeyRune || ev.Rune() < '1' || ev.Rune() > '9' {
+		return 0, false
+	}
+	return int(ev.Rune() - '0'), true
+}
@@ -39,6 +39,8 @@ const (
 	EventActivePaneChanged
 	EventPerformanceUpdate
 	EventToast
+	EventDiagnostics
+	EventPaneMoved
 )
 
 // Event represents a message passed through the system.
@@ -99,6 +101,7 @@ type WorkspaceInfo struct {
 	ID    int
 	Name  string
 	Color tcell.Color
+	Alert PaneAlert // monitor alerts raised by any of its panes
 }
 
 // WorkspacesChangedPayload is sent when the set of workspaces or their metadata changes.
@@ -121,6 +124,7 @@ type ModeChangedPayload struct {
 // ActivePaneChangedPayload is sent when the focused pane changes.
 type ActivePaneChangedPayload struct {
 	ActiveTitle string
+	Shell       ShellStatus // cwd and command state of the focused pane
 }
 
 // PerformanceUpdatePayload carries render pipeline timing data.
@@ -128,6 +132,37 @@ type PerformanceUpdatePayload struct {
 	LastPublishDuration time.Duration
 }
 
+// DiagnosticsPayload is a periodic sample of server health, broadcast for
+// the monitor app and forwarded to clients that asked for diagnostics.
+// Latencies are maxima over the sample interval, so a single slow frame
+// shows up even when the interval was otherwise quiet.
+type DiagnosticsPayload struct {
+	At             time.Time
+	Interval       time.Duration
+	PublishLatency time.Duration
+	DiffQueueDepth int    // queued diffs summed over sessions
+	DroppedDiffs   uint64 // cumulative
+	CoalescedDiffs uint64 // cumulative
+	FlushLatency   time.Duration
+	IndexBacklog   int
+	Goroutines     int
+	Panes          []PaneDiagnostics
+}
+
+// PaneDiagnostics reports one pane's slowest render in the interval.
+type PaneDiagnostics struct {
+	ID         [16]byte
+	Title      string
+	RenderTime time.Duration
+}
+
+// PaneMovedPayload is sent after a pane moves to another workspace.
+type PaneMovedPayload struct {
+	PaneID [16]byte
+	From   int // source workspace ID
+	To     int // destination workspace ID
+}
+
 // ToastSeverity indicates the importance level of a toast notification.
 type ToastSeverity int
 
@@ -13,6 +13,8 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 
 	"github.com/framegrace/texelation/config"
 	"github.com/framegrace/texelation/internal/debuglog"
@@ -60,6 +62,21 @@ type pane struct {
 	refreshStop   chan struct{}  // stop signal for refresh forwarder goroutine
 	prevTitle     string         // title when prevBuf was last rendered
 
+	// Activity/silence monitoring (see pane_monitor.go). lastOutput is
+	// written by the app's output notifier; the rest belongs to the
+	// desktop loop.
+	lastOutput      atomic.Int64 // unix nanos of the app's last output
+	seenOutput      int64        // lastOutput already accounted for
+	monitorSince    time.Time    // last time the pane was seen or monitoring changed
+	monitorActivity bool
+	monitorSilence  time.Duration
+	alert           PaneAlert
+
+	// OSC 52 policy set by answering a clipboard request with "always" or
+	// "never" (see clipboard_osc52.go); empty defers to config.
+	osc52Read  OSC52Policy
+	osc52Write OSC52Policy
+
 	// Public state fields
 	IsActive       bool
 	IsResizing     bool
@@ -105,6 +122,7 @@ func newPane(s *Workspace) *pane {
 			}
 		},
 	})
+	p.addMonitorActions()
 
 	return p
 }
@@ -220,6 +238,11 @@ func (p *pane) AttachApp(app App, refreshChan chan<- bool) {
 		idSetter.SetPaneID(p.id)
 	}
 
+	// Let apps report their output for activity monitoring
+	if setter, ok := app.(OutputNotifierSetter); ok {
+		setter.SetOutputNotifier(p.noteOutput)
+	}
+
 	// Inject storage for apps that need it (interfaces go to app, not pipeline)
 	if p.screen != nil && p.screen.desktop != nil && p.screen.desktop.Storage() != nil {
 		appType := "unknown"
@@ -240,11 +263,11 @@ func (p *pane) AttachApp(app App, refreshChan chan<- bool) {
 		p.screen.Subscribe(listener)
 	}
 
-	// Inject clipboard service for apps that need it
-	// Desktop implements ClipboardService, so we pass it directly
+	// Inject clipboard service for apps that need it. The per-pane wrapper
+	// tags copies with this pane and applies the OSC 52 policy.
 	if p.screen != nil && p.screen.desktop != nil {
 		if aware, ok := app.(ClipboardAware); ok {
-			aware.SetClipboardService(p.screen.desktop)
+			aware.SetClipboardService(paneClipboard{d: p.screen.desktop, p: p})
 		}
 	}
 
@@ -373,6 +396,11 @@ func (p *pane) PrepareAppForRestore(app App, refreshChan chan<- bool) {
 		idSetter.SetPaneID(p.id)
 	}
 
+	// Let apps report their output for activity monitoring
+	if setter, ok := app.(OutputNotifierSetter); ok {
+		setter.SetOutputNotifier(p.noteOutput)
+	}
+
 	// Inject storage for apps that need it (interfaces go to app, not pipeline)
 	if p.screen != nil && p.screen.desktop != nil && p.screen.desktop.Storage() != nil {
 		appType := "unknown"
@@ -393,11 +421,11 @@ func (p *pane) PrepareAppForRestore(app App, refreshChan chan<- bool) {
 		p.screen.Subscribe(listener)
 	}
 
-	// Inject clipboard service for apps that need it
-	// Desktop implements ClipboardService, so we pass it directly
+	// Inject clipboard service for apps that need it. The per-pane wrapper
+	// tags copies with this pane and applies the OSC 52 policy.
 	if p.screen != nil && p.screen.desktop != nil {
 		if aware, ok := app.(ClipboardAware); ok {
-			aware.SetClipboardService(p.screen.desktop)
+			aware.SetClipboardService(paneClipboard{d: p.screen.desktop, p: p})
 		}
 	}
 
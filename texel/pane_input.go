@@ -35,6 +35,11 @@ func (p *pane) handlePaste(data []byte) {
 		}
 	}
 	// No paste handler - convert to key events
+	p.sendText(data)
+}
+
+// sendText types data into the pane as key events.
+func (p *pane) sendText(data []byte) {
 	for len(data) > 0 {
 		r, size := utf8.DecodeRune(data)
 		if r == utf8.RuneError && size == 1 {
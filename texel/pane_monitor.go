@@ -0,0 +1,251 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/pane_monitor.go
+// Summary: Activity and silence monitoring for panes the user cannot see.
+// Usage: Toggled per pane from the decorator pill; defaults come from the
+//   "monitor" section of texelation.json. The desktop loop calls
+//   checkPaneMonitors after draining events.
+// Notes: Alerts only arise in panes outside the visible layout (background
+//   workspaces or hidden tabs) and clear as soon as the pane is shown. They
+//   reach clients as pane state flags, which drive the "pane.activity" and
+//   "pane.silence" effect triggers, and the status bar as workspace badges.
+//   Output is what the app reports through OutputNotifierSetter; redraws
+//   alone are never activity.
+
+package texel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/framegrace/texelation/config"
+)
+
+// PaneAlert is a set of monitor alerts raised on a pane or workspace.
+type PaneAlert uint8
+
+const (
+	// AlertActivity means the pane printed while out of view.
+	AlertActivity PaneAlert = 1 << iota
+	// AlertSilence means the pane has printed nothing for its silence
+	// interval while out of view.
+	AlertSilence
+)
+
+// PaneAlertListener is an optional extension of PaneStateListener for
+// remotes that mirror monitor alerts.
+type PaneAlertListener interface {
+	PaneAlertChanged(id [16]byte, alert PaneAlert)
+}
+
+const (
+	// defaultSilenceInterval is used when silence monitoring is switched on
+	// without a configured interval.
+	defaultSilenceInterval = 30 * time.Second
+	// monitorGrace ignores output right after a pane leaves view, which is
+	// usually the app redrawing itself on blur.
+	monitorGrace = 500 * time.Millisecond
+)
+
+// monitorDefaults reads the "monitor" config section.
+func monitorDefaults() (activity bool, silence time.Duration, toasts bool) {
+	silence = defaultSilenceInterval
+	cfg := config.System()
+	if cfg == nil {
+		return false, 0, false
+	}
+	if secs := cfg.GetInt("monitor", "silence_seconds", 0); secs > 0 {
+		silence = time.Duration(secs) * time.Second
+	}
+	if !cfg.GetBool("monitor", "silence", false) {
+		silence = 0
+	}
+	return cfg.GetBool("monitor", "activity", false), silence, cfg.GetBool("monitor", "toasts", false)
+}
+
+// silenceInterval is the interval the decorator toggle switches on.
+func silenceInterval() time.Duration {
+	if cfg := config.System(); cfg != nil {
+		if secs := cfg.GetInt("monitor", "silence_seconds", 0); secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return defaultSilenceInterval
+}
+
+// addMonitorActions puts the activity and silence toggles on the pane's
+// decorator pill.
+func (p *pane) addMonitorActions() {
+	p.monitorActivity, p.monitorSilence, _ = monitorDefaults()
+	p.decorator.AddWMAction(DecoratorAction{
+		ID:     "monitor.activity",
+		Icon:   '󰂚', // nf-md-bell
+		Active: p.monitorActivity,
+		HelpFunc: func() string {
+			if p.monitorActivity {
+				return "Stop monitoring activity"
+			}
+			return "Monitor activity"
+		},
+		OnClick: func() { p.setMonitor(!p.monitorActivity, p.monitorSilence) },
+	})
+	p.decorator.AddWMAction(DecoratorAction{
+		ID:     "monitor.silence",
+		Icon:   '󰔟', // nf-md-timer_sand
+		Active: p.monitorSilence > 0,
+		HelpFunc: func() string {
+			if p.monitorSilence > 0 {
+				return fmt.Sprintf("Stop monitoring silence (%s)", p.monitorSilence)
+			}
+			return fmt.Sprintf("Monitor silence (%s)", silenceInterval())
+		},
+		OnClick: func() {
+			silence := time.Duration(0)
+			if p.monitorSilence == 0 {
+				silence = silenceInterval()
+			}
+			p.setMonitor(p.monitorActivity, silence)
+		},
+	})
+}
+
+// setMonitor changes the pane's monitor settings. Monitoring starts fresh:
+// earlier output does not count as activity and the silence interval starts
+// now.
+func (p *pane) setMonitor(activity bool, silence time.Duration) {
+	p.monitorActivity = activity
+	p.monitorSilence = silence
+	p.seenOutput = p.lastOutput.Load()
+	p.monitorSince = time.Now()
+	p.decorator.UpdateWMAction(DecoratorAction{ID: "monitor.activity", Active: activity})
+	p.decorator.UpdateWMAction(DecoratorAction{ID: "monitor.silence", Active: silence > 0})
+	p.markDirty()
+	if p.screen != nil && p.screen.desktop != nil {
+		p.screen.desktop.checkPaneMonitors(time.Now())
+	}
+}
+
+// OutputNotifierSetter is implemented by apps that can tell their own
+// output from other redraws, such as a terminal reading its PTY. The pane
+// passes a callback to run on each output; only that counts as activity,
+// not refreshes caused by focus changes, resizes or theme reloads.
+type OutputNotifierSetter interface {
+	SetOutputNotifier(notify func())
+}
+
+// noteOutput records that the pane's app produced output. Safe to call from
+// any goroutine.
+func (p *pane) noteOutput() {
+	p.lastOutput.Store(time.Now().UnixNano())
+}
+
+// checkPaneMonitors raises and clears alerts, publishes changes and arms
+// a wakeup for the next silence deadline. Runs on the desktop loop.
+func (d *DesktopEngine) checkPaneMonitors(now time.Time) {
+	var next time.Time
+	changed := false
+	for _, ws := range d.workspaces {
+		if ws.tree == nil {
+			continue
+		}
+		var agg PaneAlert
+		ws.tree.Traverse(func(n *Node) {
+			if n.Pane == nil {
+				return
+			}
+			visible := ws == d.activeWorkspace && !n.hiddenInTab()
+			deadline := d.updatePaneAlert(ws, n.Pane, visible, now)
+			if !deadline.IsZero() && (next.IsZero() || deadline.Before(next)) {
+				next = deadline
+			}
+			agg |= n.Pane.alert
+		})
+		if agg != ws.alert {
+			ws.alert = agg
+			changed = true
+		}
+	}
+	if changed {
+		d.broadcastWorkspacesChanged()
+	}
+	d.armMonitorTimer(next, now)
+}
+
+// updatePaneAlert recomputes p's alert and returns when its silence alert
+// would next fire, or the zero time.
+func (d *DesktopEngine) updatePaneAlert(ws *Workspace, p *pane, visible bool, now time.Time) time.Time {
+	last := p.lastOutput.Load()
+	alert := p.alert
+	var deadline time.Time
+	if visible || (!p.monitorActivity && p.monitorSilence == 0) {
+		p.seenOutput = last
+		p.monitorSince = now
+		alert = 0
+	} else {
+		if p.monitorActivity && last > p.seenOutput && time.Unix(0, last).Sub(p.monitorSince) > monitorGrace {
+			alert |= AlertActivity
+		}
+		p.seenOutput = last
+		alert &^= AlertSilence
+		if p.monitorSilence > 0 {
+			quietSince := p.monitorSince
+			if t := time.Unix(0, last); t.After(quietSince) {
+				quietSince = t
+			}
+			if now.Sub(quietSince) >= p.monitorSilence {
+				alert |= AlertSilence
+			} else {
+				deadline = quietSince.Add(p.monitorSilence)
+			}
+		}
+	}
+	if alert == p.alert {
+		return deadline
+	}
+	raised := alert &^ p.alert
+	p.alert = alert
+	d.notifyPaneAlert(p.ID(), alert)
+	if raised != 0 && monitorToasts() {
+		title := p.getTitle()
+		if raised&AlertActivity != 0 {
+			d.BroadcastToast(fmt.Sprintf("Activity in %s (workspace %s)", title, ws.Name), ToastInfo, 3*time.Second)
+		}
+		if raised&AlertSilence != 0 {
+			d.BroadcastToast(fmt.Sprintf("%s silent for %s (workspace %s)", title, p.monitorSilence, ws.Name), ToastWarning, 3*time.Second)
+		}
+	}
+	return deadline
+}
+
+func monitorToasts() bool {
+	_, _, toasts := monitorDefaults()
+	return toasts
+}
+
+// armMonitorTimer wakes the desktop loop at the next silence deadline.
+func (d *DesktopEngine) armMonitorTimer(next, now time.Time) {
+	if next.Equal(d.monitorDeadline) {
+		return
+	}
+	if d.monitorTimer != nil {
+		d.monitorTimer.Stop()
+		d.monitorTimer = nil
+	}
+	d.monitorDeadline = next
+	if next.IsZero() {
+		return
+	}
+	d.monitorTimer = time.AfterFunc(next.Sub(now), d.SendRefresh)
+}
+
+func (d *DesktopEngine) notifyPaneAlert(id [16]byte, alert PaneAlert) {
+	d.paneStateMu.RLock()
+	listeners := append([]PaneStateListener(nil), d.paneStateListeners...)
+	d.paneStateMu.RUnlock()
+	for _, l := range listeners {
+		if al, ok := l.(PaneAlertListener); ok {
+			al.PaneAlertChanged(id, alert)
+		}
+	}
+}
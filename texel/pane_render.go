@@ -9,6 +9,8 @@ package texel
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/framegrace/texelation/internal/debuglog"
@@ -16,6 +18,7 @@ import (
 	texelcore "github.com/framegrace/texelui/core"
 	"github.com/framegrace/texelui/theme"
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 // renderDebug is gated by env var TEXELATION_DEBUG=1. When enabled, each
@@ -82,6 +85,11 @@ func (p *pane) renderBuffer(applyEffects bool) [][]Cell {
 	// TOCTOU race where a binary flag set during rendering gets clobbered
 	// by the post-render clear.
 	currentTitle := p.getTitle()
+	tabs, activeTab := p.tabStrip()
+	if tabs != nil {
+		// Sibling tab titles and the active tab are part of what we draw.
+		currentTitle += "\x00" + strings.Join(tabs, "\x00") + "\x00" + strconv.Itoa(activeTab)
+	}
 	gen := atomic.LoadInt32(&p.renderGen)
 	if gen == p.lastRendered && p.prevBuf != nil &&
 		len(p.prevBuf) == h && (h == 0 || len(p.prevBuf[0]) == w) &&
@@ -136,6 +144,9 @@ func (p *pane) renderBuffer(applyEffects bool) [][]Cell {
 	// Update persistent border widget state.
 	p.border.Resize(w, h)
 	p.border.Title = p.getTitle()
+	if tabs != nil {
+		p.border.Title = ""
+	}
 	p.border.IsResizing = p.IsResizing
 	if p.RoundedCorners {
 		p.border.SetRoundedCorners()
@@ -179,6 +190,9 @@ func (p *pane) renderBuffer(applyEffects bool) [][]Cell {
 	// Draw through the persistent widget tree.
 	painter := texelcore.NewPainter(buffer, texelcore.Rect{X: 0, Y: 0, W: w, H: h})
 	p.border.Draw(painter)
+	if tabs != nil {
+		p.drawTabStrip(buffer, tabs, activeTab)
+	}
 
 	// Update zoom decorator state and draw pill on border.
 	if p.decorator != nil {
@@ -269,6 +283,26 @@ func (p *pane) setDimensions(x0, y0, x1, y1 int) {
 	}
 }
 
+// drawTabStrip draws the tab labels of the Tabbed container over the top
+// border; the visible tab is drawn in reverse video.
+func (p *pane) drawTabStrip(buffer [][]Cell, labels []string, active int) {
+	style := p.currentBorderStyle()
+	for _, tab := range layoutTabStrip(labels, active, len(buffer[0])) {
+		st := style
+		if tab.index == active {
+			st = style.Reverse(true).Bold(true)
+		}
+		x := tab.x0
+		for _, r := range tab.text {
+			if x >= tab.x1 {
+				break
+			}
+			buffer[0][x] = Cell{Ch: r, Style: st}
+			x += runewidth.RuneWidth(r)
+		}
+	}
+}
+
 // currentBorderStyle returns the tcell.Style the border is using based on pane state.
 func (p *pane) currentBorderStyle() tcell.Style {
 	var ds color.DynamicStyle
@@ -0,0 +1,77 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/render_stats.go
+// Summary: Per-interval render and publish timing for diagnostics.
+// Usage: SnapshotBuffers and SetLastPublishDuration feed it; the server's
+//   diagnostics sampler drains it with TakeRenderStats and republishes the
+//   sample with BroadcastDiagnostics.
+
+package texel
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RenderStats holds the slowest publish and per-pane render since the
+// previous TakeRenderStats call. Panes are sorted slowest first.
+type RenderStats struct {
+	PublishMax time.Duration
+	Panes      []PaneDiagnostics
+}
+
+type renderStats struct {
+	mu         sync.Mutex
+	publishMax time.Duration
+	panes      map[[16]byte]PaneDiagnostics
+}
+
+func (r *renderStats) recordPublish(dur time.Duration) {
+	r.mu.Lock()
+	r.publishMax = max(r.publishMax, dur)
+	r.mu.Unlock()
+}
+
+func (r *renderStats) recordPanes(snaps []PaneSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.panes == nil {
+		r.panes = make(map[[16]byte]PaneDiagnostics)
+	}
+	for _, snap := range snaps {
+		entry := r.panes[snap.ID]
+		entry.ID = snap.ID
+		entry.Title = snap.Title
+		entry.RenderTime = max(entry.RenderTime, snap.RenderTime)
+		r.panes[snap.ID] = entry
+	}
+}
+
+// TakeRenderStats returns the timings gathered since the last call and
+// starts a new interval. Panes that did not render in the interval (closed,
+// or on another workspace) are absent.
+func (d *DesktopEngine) TakeRenderStats() RenderStats {
+	r := &d.renderStats
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := RenderStats{PublishMax: r.publishMax, Panes: make([]PaneDiagnostics, 0, len(r.panes))}
+	for _, p := range r.panes {
+		out.Panes = append(out.Panes, p)
+	}
+	sort.Slice(out.Panes, func(i, j int) bool {
+		if out.Panes[i].RenderTime != out.Panes[j].RenderTime {
+			return out.Panes[i].RenderTime > out.Panes[j].RenderTime
+		}
+		return out.Panes[i].Title < out.Panes[j].Title
+	})
+	r.publishMax = 0
+	clear(r.panes)
+	return out
+}
+
+// BroadcastDiagnostics delivers a diagnostics sample to all subscribers.
+func (d *DesktopEngine) BroadcastDiagnostics(sample DiagnosticsPayload) {
+	d.dispatcher.Broadcast(Event{Type: EventDiagnostics, Payload: sample})
+}
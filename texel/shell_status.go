@@ -0,0 +1,56 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/shell_status.go
+// Summary: Shell state of the focused pane (cwd, running and last command).
+// Usage: Apps with shell integration implement ShellStatusProvider; the
+//   desktop loop calls checkShellStatus after draining events and
+//   rebroadcasts EventActivePaneChanged when the focused pane's status
+//   changes, so status bar segments follow the shell.
+
+package texel
+
+import "time"
+
+// ShellStatus is what an app knows about the shell it hosts: the working
+// directory from OSC 7 and command boundaries from OSC 133.
+type ShellStatus struct {
+	Cwd string
+	// Command is the running command, or the last one when Running is false.
+	Command string
+	Running bool
+	// Started is when the running or last command began; zero until the
+	// shell reports one.
+	Started time.Time
+	// Finished is set once a command has ended; ExitCode and Duration
+	// describe the last one that did.
+	Finished bool
+	ExitCode int
+	Duration time.Duration
+}
+
+// ShellStatusProvider is implemented by apps that track their shell.
+type ShellStatusProvider interface {
+	ShellStatus() ShellStatus
+}
+
+// activeShellStatus returns the focused pane's shell status, or the zero
+// value when its app does not report one.
+func (d *DesktopEngine) activeShellStatus() ShellStatus {
+	p := d.activePane()
+	if p == nil {
+		return ShellStatus{}
+	}
+	if provider, ok := p.app.(ShellStatusProvider); ok {
+		return provider.ShellStatus()
+	}
+	return ShellStatus{}
+}
+
+// checkShellStatus rebroadcasts the active pane when its shell status
+// changed since the last broadcast. Runs on the desktop loop.
+func (d *DesktopEngine) checkShellStatus() {
+	if d.activeShellStatus() != d.lastShellStatus {
+		d.broadcastActivePaneChanged()
+	}
+}
@@ -9,6 +9,7 @@ package texel
 
 import (
 	"log"
+	"time"
 
 	"github.com/framegrace/texelui/theme"
 	"github.com/gdamore/tcell/v2"
@@ -43,6 +44,8 @@ type PaneSnapshot struct {
 	// positionally regardless.
 	ContentTopRow  uint16
 	NumContentRows uint16
+	// RenderTime is how long the pane's app took to render this buffer.
+	RenderTime time.Duration
 }
 
 // Rectangle stores pane position and size in screen coordinates.
@@ -70,16 +73,21 @@ type TreeCapture struct {
 }
 
 // TreeNodeCapture stores split metadata or references a leaf pane by index.
+// Client captures leave hidden tabs' leaves at PaneIndex -1.
 type TreeNodeCapture struct {
 	PaneIndex   int
 	Split       SplitType
 	SplitRatios []float64
 	Children    []*TreeNodeCapture
+	ActiveTab   int
+	// UntabbedSplit is the split a Tabbed node returns to when toggled.
+	UntabbedSplit SplitType
 }
 
 // SnapshotBuffers collects the current buffers for all panes in the active workspace.
 func (d *DesktopEngine) SnapshotBuffers() []PaneSnapshot {
 	var panes []PaneSnapshot
+	locked := d.locked.Load()
 
 	// Only capture active workspace for rendering
 	if d.activeWorkspace != nil && d.activeWorkspace.tree != nil {
@@ -90,11 +98,14 @@ func (d *DesktopEngine) SnapshotBuffers() []PaneSnapshot {
 				return
 			}
 			if len(n.Children) == 0 {
-				if n.Pane != nil {
+				if n.Pane != nil && locked {
+					panes = append(panes, lockedPaneSnapshot(n.Pane.ID(), lockedPaneRect(n.Pane)))
+				} else if n.Pane != nil {
 					panes = append(panes, capturePaneSnapshot(n.Pane))
 				}
 			}
-			for _, child := range n.Children {
+			// Hidden tabs are not on screen, so clients never see them.
+			for _, child := range n.visibleChildren() {
 				collect(child)
 			}
 		}
@@ -105,11 +116,12 @@ func (d *DesktopEngine) SnapshotBuffers() []PaneSnapshot {
 
 	// Always include status/floating
 	if status := d.captureStatusPaneSnapshots(); len(status) > 0 {
-		panes = append(panes, status...)
+		panes = append(panes, blankIfLocked(status, locked)...)
 	}
 	if floating := d.captureFloatingPanelSnapshots(); len(floating) > 0 {
 		panes = append(panes, floating...)
 	}
+	d.renderStats.recordPanes(panes)
 	return panes
 }
 
@@ -129,6 +141,7 @@ func (d *DesktopEngine) SnapshotForClient() TreeCapture {
 	paneIndex := make(map[*pane]int)
 	capture.Panes = make([]PaneSnapshot, 0)
 	capture.ActiveWorkspaceID = d.activeWorkspace.id
+	locked := d.locked.Load()
 
 	var collect func(*Node)
 	collect = func(n *Node) {
@@ -139,13 +152,18 @@ func (d *DesktopEngine) SnapshotForClient() TreeCapture {
 			if n.Pane != nil {
 				// Check if already captured
 				if _, exists := paneIndex[n.Pane]; !exists {
-					paneSnap := capturePaneSnapshot(n.Pane)
+					var paneSnap PaneSnapshot
+					if locked {
+						paneSnap = lockedPaneSnapshot(n.Pane.ID(), lockedPaneRect(n.Pane))
+					} else {
+						paneSnap = capturePaneSnapshot(n.Pane)
+					}
 					paneIndex[n.Pane] = len(capture.Panes)
 					capture.Panes = append(capture.Panes, paneSnap)
 				}
 			}
 		}
-		for _, child := range n.Children {
+		for _, child := range n.visibleChildren() {
 			collect(child)
 		}
 	}
@@ -158,7 +176,7 @@ func (d *DesktopEngine) SnapshotForClient() TreeCapture {
 	}
 
 	if status := d.captureStatusPaneSnapshots(); len(status) > 0 {
-		capture.Panes = append(capture.Panes, status...)
+		capture.Panes = append(capture.Panes, blankIfLocked(status, locked)...)
 	}
 	if floating := d.captureFloatingPanelSnapshots(); len(floating) > 0 {
 		capture.Panes = append(capture.Panes, floating...)
@@ -214,7 +232,7 @@ func (d *DesktopEngine) GeometryForClient() TreeCapture {
 				}
 			}
 		}
-		for _, child := range n.Children {
+		for _, child := range n.visibleChildren() {
 			collect(child)
 		}
 	}
@@ -359,10 +377,12 @@ func (d *DesktopEngine) CaptureTree() TreeCapture {
 }
 
 func capturePaneSnapshot(p *pane) PaneSnapshot {
+	start := time.Now()
 	buf := p.renderBuffer(false)
 	id := p.ID()
 	snap := PaneSnapshot{
 		ID:           id,
+		RenderTime:   time.Since(start),
 		Title:        p.getTitle(),
 		Buffer:       buf,
 		RowGlobalIdx: allMinusOne(len(buf)),
@@ -573,6 +593,9 @@ func (d *DesktopEngine) captureFloatingPanelSnapshots() []PaneSnapshot {
 		if fp == nil || fp.app == nil {
 			continue
 		}
+		if d.locked.Load() && fp.app != App(d.lockPrompt) {
+			continue
+		}
 		// Render from pipeline (or app as fallback)
 		var buf [][]Cell
 		if fp.pipeline != nil {
@@ -719,13 +742,15 @@ func buildTreeCapture(n *Node, paneIndex map[*pane]int) *TreeNodeCapture {
 	if len(n.Children) == 0 {
 		if idx, ok := paneIndex[n.Pane]; ok {
 			node.PaneIndex = idx
-		} else if n.Pane != nil {
+		} else if n.Pane != nil && !n.hiddenInTab() {
 			// This means the pane exists in the tree but wasn't captured (likely app == nil)
 			log.Printf("WARNING: buildTreeCapture - leaf pane '%s' not in paneIndex map, setting PaneIndex=-1 (CORRUPTED TREE)", n.Pane.getTitle())
 		}
 		return node
 	}
 	node.Split = n.Split
+	node.ActiveTab = n.activeTabIndex()
+	node.UntabbedSplit = n.untabbedSplit
 	node.SplitRatios = make([]float64, len(n.SplitRatios))
 	copy(node.SplitRatios, n.SplitRatios)
 	node.Children = make([]*TreeNodeCapture, len(n.Children))
@@ -0,0 +1,161 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/snapshot_history.go
+// Summary: Point-in-time restore of earlier session layouts.
+// Usage: The host hands the desktop its snapshot store with
+//   SetSnapshotHistory; control mode "S" opens the snapshot picker, which
+//   lists the generations and reports its choice on "snapshots.select"
+//   with the generation ID as payload.
+// Notes: Restoring rebuilds the generation's layout. Panes still open keep
+//   running and only move; panes closed since then are relaunched with
+//   their old IDs, so terminals reopen their current history, not the
+//   history as it was then. Panes the generation lacks go on the
+//   undo-close stack.
+
+package texel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotWorkspace summarises one workspace of a snapshot generation.
+type SnapshotWorkspace struct {
+	ID         int
+	Name       string
+	PaneTitles []string // in layout order
+}
+
+// SnapshotGeneration describes one saved generation of the session.
+type SnapshotGeneration struct {
+	ID         string
+	SavedAt    time.Time
+	Workspaces []SnapshotWorkspace // by workspace ID
+}
+
+// SnapshotHistory gives access to the saved generations of the session;
+// the server's snapshot store implements it.
+type SnapshotHistory interface {
+	// Generations lists the saved generations, newest first.
+	Generations() ([]SnapshotGeneration, error)
+	// GenerationCapture loads the generation with the given ID.
+	GenerationCapture(id string) (TreeCapture, error)
+}
+
+// SnapshotListSetter is implemented by the snapshot picker.
+type SnapshotListSetter interface {
+	SetSnapshots(generations []SnapshotGeneration)
+}
+
+// SetSnapshotHistory enables restoring earlier generations of the session.
+func (d *DesktopEngine) SetSnapshotHistory(h SnapshotHistory) {
+	d.snapshotHistory = h
+}
+
+// SnapshotGenerations lists the saved generations, newest first. It
+// returns nothing when no snapshot history is set.
+func (d *DesktopEngine) SnapshotGenerations() ([]SnapshotGeneration, error) {
+	if d.snapshotHistory == nil {
+		return nil, nil
+	}
+	return d.snapshotHistory.Generations()
+}
+
+// RestoreSnapshot replaces the current workspaces with those of the
+// generation with the given ID. Open panes the generation has are reused
+// as they are; workspaces the generation does not have are closed.
+func (d *DesktopEngine) RestoreSnapshot(id string) error {
+	if d.snapshotHistory == nil {
+		return errors.New("session snapshots are disabled")
+	}
+	capture, err := d.snapshotHistory.GenerationCapture(id)
+	if err != nil {
+		return err
+	}
+	if len(capture.Panes) == 0 || len(capture.WorkspaceRoots) == 0 {
+		return fmt.Errorf("snapshot %s has no panes", id)
+	}
+	if d.zoomedPane != nil {
+		d.toggleZoom()
+	}
+
+	restored := make(map[[16]byte]bool, len(capture.Panes))
+	for _, p := range capture.Panes {
+		restored[p.ID] = true
+	}
+	live := make(map[[16]byte]*pane)
+	ids := make([]int, 0, len(d.workspaces))
+	for wsID := range d.workspaces {
+		ids = append(ids, wsID)
+	}
+	sort.Ints(ids)
+	for _, wsID := range ids {
+		ws := d.workspaces[wsID]
+		if ws.tree == nil {
+			continue
+		}
+		var leaves []*Node
+		ws.tree.Traverse(func(n *Node) {
+			if n.Pane == nil {
+				return
+			}
+			if restored[n.Pane.ID()] {
+				live[n.Pane.ID()] = n.Pane
+			} else {
+				leaves = append(leaves, n)
+			}
+		})
+		// The restore drops these leaves below, before the next event.
+		for _, n := range leaves {
+			if cp, ok := d.closedPaneEntry(ws, n); ok {
+				d.pushClosedPane(cp)
+			}
+		}
+		if _, ok := capture.WorkspaceRoots[wsID]; !ok {
+			for _, n := range leaves {
+				if n.Pane.app != nil {
+					d.appLifecycle.StopApp(n.Pane.app)
+				}
+			}
+			delete(d.workspaces, wsID)
+		}
+	}
+
+	// applyTreeCapture rebuilds existing workspaces; create the missing
+	// ones empty so no default app is started in them.
+	for wsID := range capture.WorkspaceRoots {
+		if _, ok := d.workspaces[wsID]; ok {
+			continue
+		}
+		ws, err := newWorkspace(wsID, d.ShellAppFactory, d.appLifecycle, d)
+		if err != nil {
+			return err
+		}
+		d.workspaces[wsID] = ws
+	}
+	if d.activeWorkspace == nil || d.workspaces[d.activeWorkspace.id] != d.activeWorkspace {
+		d.activeWorkspace = d.workspaces[capture.ActiveWorkspaceID]
+		if d.activeWorkspace == nil {
+			for wsID := range capture.WorkspaceRoots {
+				d.activeWorkspace = d.workspaces[wsID]
+				break
+			}
+		}
+	}
+
+	if err := d.applyTreeCapture(capture, live); err != nil {
+		return err
+	}
+	d.recalculateLayout()
+	d.viewportMu.RLock()
+	hasViewport := d.hasViewport
+	d.viewportMu.RUnlock()
+	if hasViewport {
+		d.startPendingApps()
+	}
+	d.broadcastTreeChanged()
+	return nil
+}
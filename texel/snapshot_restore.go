@@ -14,6 +14,13 @@ import (
 )
 
 func (d *DesktopEngine) ApplyTreeCapture(capture TreeCapture) error {
+	return d.applyTreeCapture(capture, nil)
+}
+
+// applyTreeCapture rebuilds the workspaces in capture. Panes found in live
+// by ID are moved into the new layout with their apps still running;
+// every other pane gets a new app from its snapshot.
+func (d *DesktopEngine) applyTreeCapture(capture TreeCapture, live map[[16]byte]*pane) error {
 	if len(capture.Panes) == 0 {
 		return nil
 	}
@@ -34,7 +41,14 @@ func (d *DesktopEngine) ApplyTreeCapture(capture TreeCapture) error {
 	}
 	dummyScreen := d.activeWorkspace
 
+	reused := make(map[*pane]bool, len(live))
 	for i, snap := range capture.Panes {
+		if p := live[snap.ID]; p != nil {
+			p.SetActive(false)
+			reused[p] = true
+			panes[i] = p
+			continue
+		}
 		p := newPane(dummyScreen)
 		p.setID(snap.ID)
 		app := d.appFromSnapshot(snap)
@@ -68,9 +82,14 @@ func (d *DesktopEngine) ApplyTreeCapture(capture TreeCapture) error {
 		
 		screen := d.workspaces[id]
 		
-		// Stop existing apps in this workspace
+		// Stop existing apps in this workspace, except those moving
+		// into the new layout
 		if screen.tree != nil {
-			stopApps(screen.tree.Root, screen.appLifecycle)
+			screen.tree.Traverse(func(n *Node) {
+				if n.Pane != nil && n.Pane.app != nil && !reused[n.Pane] {
+					screen.appLifecycle.StopApp(n.Pane.app)
+				}
+			})
 		}
 
 		// Rebuild tree
@@ -166,8 +185,11 @@ func (d *DesktopEngine) ApplyTreeCapture(capture TreeCapture) error {
 		}
 		return false
 	}
-	startable := panes[:0]
+	startable := make([]*pane, 0, len(panes))
 	for _, p := range panes {
+		if reused[p] {
+			continue // already running
+		}
 		if isStatusOrphan(p) {
 			// Stop the orphan's app and detach so it doesn't dangle.
 			// The real status pane (from AddStatusPane) is unaffected.
@@ -277,6 +299,8 @@ func buildNodesFromCapture(screen *Workspace, capture *TreeNodeCapture, panes []
 	}
 
 	node.Split = capture.Split
+	node.ActiveTab = capture.ActiveTab
+	node.untabbedSplit = capture.UntabbedSplit
 	node.SplitRatios = make([]float64, len(capture.SplitRatios))
 	copy(node.SplitRatios, capture.SplitRatios)
 	node.Children = make([]*Node, len(capture.Children))
@@ -308,6 +332,13 @@ func buildNodesFromCapture(screen *Workspace, capture *TreeNodeCapture, panes []
 			firstLeaf = findFirstLeaf(childNode)
 		}
 	}
+	if node.Split == Tabbed {
+		// Focus must land on the visible tab, or revealActive would flip
+		// the restored container to whichever tab held the first leaf.
+		if leaf := findFirstLeaf(node.Children[node.activeTabIndex()]); leaf != nil {
+			firstLeaf = leaf
+		}
+	}
 	return node, firstLeaf, nil
 }
 
@@ -321,7 +352,7 @@ func findFirstLeaf(node *Node) *Node {
 		}
 		return nil
 	}
-	for _, child := range node.Children {
+	for _, child := range node.visibleChildren() {
 		if leaf := findFirstLeaf(child); leaf != nil {
 			return leaf
 		}
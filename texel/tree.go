@@ -24,6 +24,10 @@ type SplitType int
 const (
 	Horizontal SplitType = iota
 	Vertical
+	// Tabbed stacks its children in one rect and shows only Children[ActiveTab];
+	// the visible pane's top border carries a tab strip. SplitRatios are kept
+	// (equal-length with Children) so the split can be restored unchanged.
+	Tabbed
 )
 
 // Node represents a node in the pane layout tree. It can be an internal
@@ -34,6 +38,63 @@ type Node struct {
 	Pane        *pane // A pane is only present in leaf nodes
 	SplitRatios []float64
 	Children    []*Node
+	// ActiveTab is the visible child of a Tabbed node.
+	ActiveTab int
+	// untabbedSplit remembers the split a Tabbed node had before it was
+	// converted, so toggling back restores it.
+	untabbedSplit SplitType
+}
+
+// visibleChildren returns the children that occupy screen space: all of
+// them for a split, only the active tab for a Tabbed node.
+func (n *Node) visibleChildren() []*Node {
+	if n.Split == Tabbed && len(n.Children) > 0 {
+		return n.Children[n.activeTabIndex() : n.activeTabIndex()+1]
+	}
+	return n.Children
+}
+
+// activeTabIndex returns ActiveTab clamped to the children range.
+func (n *Node) activeTabIndex() int {
+	return min(max(n.ActiveTab, 0), max(len(n.Children)-1, 0))
+}
+
+// childRemoved keeps ActiveTab pointing at the same child after
+// Children[index] was removed, or at its successor if it was the one removed.
+func (n *Node) childRemoved(index int) {
+	if index < n.ActiveTab {
+		n.ActiveTab--
+	}
+	n.ActiveTab = n.activeTabIndex()
+}
+
+// hiddenInTab reports whether n sits in a non-visible tab of some ancestor.
+func (n *Node) hiddenInTab() bool {
+	for child, parent := n, n.Parent; parent != nil; child, parent = parent, parent.Parent {
+		if parent.Split == Tabbed && parent.Children[parent.activeTabIndex()] != child {
+			return true
+		}
+	}
+	return false
+}
+
+// revealActive switches every Tabbed ancestor of the active leaf to the tab
+// containing it, so focus never rests on a hidden pane.
+func (t *Tree) revealActive() {
+	if t.ActiveLeaf == nil {
+		return
+	}
+	for child, parent := t.ActiveLeaf, t.ActiveLeaf.Parent; parent != nil; child, parent = parent, parent.Parent {
+		if parent.Split != Tabbed {
+			continue
+		}
+		for i, c := range parent.Children {
+			if c == child {
+				parent.ActiveTab = i
+				break
+			}
+		}
+	}
 }
 
 // Tree manages the node hierarchy of panes.
@@ -193,6 +254,7 @@ func (t *Tree) CloseActiveLeaf() *Node {
 
 	// Remove the child from the parent's slice
 	parent.Children = append(parent.Children[:childIndex], parent.Children[childIndex+1:]...)
+	parent.childRemoved(childIndex)
 
 	// If the parent has only one child left, the split is no longer needed.
 	// Promote the remaining child to replace its parent.
@@ -321,9 +383,9 @@ func (t *Tree) findFirstLeaf(node *Node) *Node {
 		return nil
 	}
 	curr := node
-	// While the current node is not a leaf, descend to the first child.
+	// While the current node is not a leaf, descend to the first visible child.
 	for len(curr.Children) > 0 {
-		curr = curr.Children[0]
+		curr = curr.visibleChildren()[0]
 	}
 	return curr
 }
@@ -419,6 +481,7 @@ func (t *Tree) Resize(x, y, w, h int) {
 	debuglog.Printf("Tree.Resize: Setting root to (%d,%d) size %dx%d", x, y, w, h)
 
 	if t.Root != nil {
+		t.revealActive()
 		t.resizeNode(t.Root, x, y, w, h)
 	} else {
 		debuglog.Printf("Tree.Resize: Root is nil!")
@@ -444,6 +507,14 @@ func (t *Tree) resizeNode(n *Node, x, y, w, h int) {
 		return
 	}
 
+	if n.Split == Tabbed {
+		// Every tab gets the full rect so switching tabs needs no resize.
+		for _, child := range n.Children {
+			t.resizeNode(child, x, y, w, h)
+		}
+		return
+	}
+
 	numChildren := len(n.Children)
 	if numChildren == 0 || len(n.SplitRatios) != numChildren {
 		log.Printf("resizeNode: Invalid internal node - numChildren=%d, numRatios=%d",
@@ -523,6 +594,8 @@ func (t *Tree) debugPrintTree(node *Node, depth int) {
 		splitStr := "Vertical"
 		if node.Split == Horizontal {
 			splitStr = "Horizontal"
+		} else if node.Split == Tabbed {
+			splitStr = "Tabbed"
 		}
 		debuglog.Printf("%sInternal: %s split, %d children, ratios=%v",
 			indent, splitStr, len(node.Children), node.SplitRatios)
@@ -548,7 +621,7 @@ func findLeafAt(node *Node, x, y int) *Node {
 		}
 		return nil
 	}
-	for _, child := range node.Children {
+	for _, child := range node.visibleChildren() {
 		if hit := findLeafAt(child, x, y); hit != nil {
 			return hit
 		}
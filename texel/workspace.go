@@ -87,6 +87,8 @@ type Workspace struct {
 	resizeSelection   *selectedBorder
 	mouseResizeBorder *selectedBorder
 	debugFramesToDump int
+	nextLayout        LayoutPreset // preset CycleLayout applies next
+	alert             PaneAlert    // union of its panes' monitor alerts
 }
 
 // newWorkspace creates a new workspace with its own tiling pane tree.
@@ -192,6 +194,8 @@ func (w *Workspace) OnEvent(event Event) {
 		forEachLeafPane(w.tree.Root, func(p *pane) {
 			p.markDirty()
 		})
+	case EventDiagnostics:
+		w.dispatcher.Broadcast(event)
 	default:
 		// Other Desktop events can be handled here if needed
 	}
@@ -19,6 +19,13 @@ func (w *Workspace) setArea(x, y, width, height int) {
 }
 
 func (w *Workspace) removeNode(target *Node, allowRoot bool) {
+	w.removeNodeThen(target, allowRoot, nil)
+}
+
+// removeNodeThen is removeNode with a callback run once the pane is out of
+// the tree, after its close animation if it has one. done is not called
+// when the removal is refused.
+func (w *Workspace) removeNodeThen(target *Node, allowRoot bool, done func()) {
 	if w == nil || w.tree == nil || target == nil {
 		return
 	}
@@ -53,6 +60,9 @@ func (w *Workspace) removeNode(target *Node, allowRoot bool) {
 		if w.desktop != nil {
 			w.desktop.broadcastTreeChanged()
 		}
+		if done != nil {
+			done()
+		}
 		w.ensureWelcomePane()
 		return
 	}
@@ -70,12 +80,16 @@ func (w *Workspace) removeNode(target *Node, allowRoot bool) {
 		return
 	}
 
-	// Try to animate the removal if enabled and we have siblings
-	if w.desktop != nil && w.desktop.layoutTransitions != nil && len(parent.Children) > 1 {
+	// Try to animate the removal if enabled and we have siblings. Tabs share
+	// one rect, so there is nothing to animate for them.
+	if w.desktop != nil && w.desktop.layoutTransitions != nil && len(parent.Children) > 1 && parent.Split != Tabbed {
 		debuglog.Printf("removeNode: Starting animated removal of pane '%s' at index %d", pane.getTitle(), closingIndex)
 		w.desktop.layoutTransitions.AnimateRemoval(parent, closingIndex, func() {
 			debuglog.Printf("removeNode: Animation complete, performing actual removal of '%s'", pane.getTitle())
 			w.doRemoveNode(target, parent, closingIndex, wasActive)
+			if done != nil {
+				done()
+			}
 		})
 		return // The callback will finish the job
 	}
@@ -83,6 +97,9 @@ func (w *Workspace) removeNode(target *Node, allowRoot bool) {
 	// No animation, do immediate removal
 	debuglog.Printf("removeNode: Performing immediate removal of pane '%s'", pane.getTitle())
 	w.doRemoveNode(target, parent, closingIndex, wasActive)
+	if done != nil {
+		done()
+	}
 }
 
 // doRemoveNode performs the actual removal of a pane from the tree.
@@ -93,12 +110,44 @@ func (w *Workspace) doRemoveNode(target *Node, parent *Node, closingIndex int, w
 		return
 	}
 
+	nextActive := w.unlinkNode(parent, closingIndex)
+
+	pane.IsActive = false
+	pane.Close()
+
+	if wasActive {
+		w.tree.ActiveLeaf = nextActive
+		if w.tree.ActiveLeaf != nil && w.tree.ActiveLeaf.Pane != nil {
+			w.tree.ActiveLeaf.Pane.SetActive(true)
+		}
+	} else if w.tree.ActiveLeaf == nil {
+		w.tree.ActiveLeaf = nextActive
+		if w.tree.ActiveLeaf != nil && w.tree.ActiveLeaf.Pane != nil {
+			w.tree.ActiveLeaf.Pane.SetActive(true)
+		}
+	}
+
+	w.recalculateLayout()
+	w.Broadcast(Event{Type: EventPaneClosed, Payload: target})
+	w.notifyFocus()
+	if w.desktop != nil {
+		w.desktop.broadcastTreeChanged()
+		w.desktop.broadcastActivePaneChanged()
+	}
+	w.ensureWelcomePane()
+}
+
+// unlinkNode removes parent's child at closingIndex, collapsing a split
+// left with one child and dropping one left empty. It returns the leaf that
+// should take focus if the removed child held it.
+func (w *Workspace) unlinkNode(parent *Node, closingIndex int) *Node {
 	if len(parent.Children) > 0 {
 		parent.Children = append(parent.Children[:closingIndex], parent.Children[closingIndex+1:]...)
 	}
 	if closingIndex < len(parent.SplitRatios) {
 		parent.SplitRatios = append(parent.SplitRatios[:closingIndex], parent.SplitRatios[closingIndex+1:]...)
 	}
+	parent.childRemoved(closingIndex)
 
 	var nextActive *Node
 	switch len(parent.Children) {
@@ -119,6 +168,7 @@ func (w *Workspace) doRemoveNode(target *Node, parent *Node, closingIndex int, w
 				if parentIndex < len(grandparent.SplitRatios) {
 					grandparent.SplitRatios = append(grandparent.SplitRatios[:parentIndex], grandparent.SplitRatios[parentIndex+1:]...)
 				}
+				grandparent.childRemoved(parentIndex)
 				if len(grandparent.Children) > 0 {
 					idx := parentIndex
 					if idx >= len(grandparent.Children) {
@@ -161,30 +211,7 @@ func (w *Workspace) doRemoveNode(target *Node, parent *Node, closingIndex int, w
 			nextActive = w.tree.findFirstLeaf(parent.Children[newIndex])
 		}
 	}
-
-	pane.IsActive = false
-	pane.Close()
-
-	if wasActive {
-		w.tree.ActiveLeaf = nextActive
-		if w.tree.ActiveLeaf != nil && w.tree.ActiveLeaf.Pane != nil {
-			w.tree.ActiveLeaf.Pane.SetActive(true)
-		}
-	} else if w.tree.ActiveLeaf == nil {
-		w.tree.ActiveLeaf = nextActive
-		if w.tree.ActiveLeaf != nil && w.tree.ActiveLeaf.Pane != nil {
-			w.tree.ActiveLeaf.Pane.SetActive(true)
-		}
-	}
-
-	w.recalculateLayout()
-	w.Broadcast(Event{Type: EventPaneClosed, Payload: target})
-	w.notifyFocus()
-	if w.desktop != nil {
-		w.desktop.broadcastTreeChanged()
-		w.desktop.broadcastActivePaneChanged()
-	}
-	w.ensureWelcomePane()
+	return nextActive
 }
 
 func (w *Workspace) CloseActivePane() {
@@ -203,7 +230,15 @@ func (w *Workspace) CloseActivePane() {
 		}
 	}
 
-	w.removeNode(w.tree.ActiveLeaf, true)
+	// Stack the pane only once it is gone: while the close animation runs
+	// it is still in the tree, and reopening it then would duplicate its ID.
+	var done func()
+	if d := w.desktop; d != nil {
+		if cp, ok := d.closedPaneEntry(w, w.tree.ActiveLeaf); ok {
+			done = func() { d.pushClosedPane(cp) }
+		}
+	}
+	w.removeNodeThen(w.tree.ActiveLeaf, true, done)
 }
 
 func (w *Workspace) adjustBorderToX(border *selectedBorder, x int) {
@@ -125,6 +125,9 @@ func (w *Workspace) activateLeaf(node *Node) bool {
 
 	w.tree.ActiveLeaf = node
 	node.Pane.SetActive(true)
+	if node.hiddenInTab() {
+		w.tabsChanged()
+	}
 
 	w.Broadcast(Event{Type: EventPaneActiveChanged, Payload: node})
 	w.notifyFocus()
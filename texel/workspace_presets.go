@@ -0,0 +1,234 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/workspace_presets.go
+// Summary: Preset layouts that rebuild a workspace tree from its panes.
+// Usage: Control mode cycles through the presets and equalises split ratios;
+//   both animate through the LayoutTransitionManager.
+// Notes: Rebuilding flattens Tabbed containers; every pane becomes visible.
+
+package texel
+
+import (
+	"math"
+
+	"github.com/framegrace/texelation/internal/debuglog"
+)
+
+// LayoutPreset names a layout algorithm, in the spirit of tmux select-layout.
+type LayoutPreset int
+
+const (
+	// LayoutEvenHorizontal puts every pane side by side with equal widths.
+	LayoutEvenHorizontal LayoutPreset = iota
+	// LayoutEvenVertical stacks every pane top to bottom with equal heights.
+	LayoutEvenVertical
+	// LayoutMainVertical gives the first pane the left side and stacks the
+	// rest on the right.
+	LayoutMainVertical
+	// LayoutMainHorizontal gives the first pane the top and lays the rest
+	// side by side below it.
+	LayoutMainHorizontal
+	// LayoutTiled arranges panes in a near-square grid of rows.
+	LayoutTiled
+
+	layoutPresetCount
+)
+
+// mainPaneRatio is the share of the workspace given to the main pane.
+const mainPaneRatio = 0.6
+
+var layoutPresetNames = [...]string{
+	LayoutEvenHorizontal: "even-horizontal",
+	LayoutEvenVertical:   "even-vertical",
+	LayoutMainVertical:   "main-vertical",
+	LayoutMainHorizontal: "main-horizontal",
+	LayoutTiled:          "tiled",
+}
+
+func (p LayoutPreset) String() string {
+	if p < 0 || p >= layoutPresetCount {
+		return "unknown"
+	}
+	return layoutPresetNames[p]
+}
+
+// CycleLayout applies the next preset in order, starting with
+// LayoutEvenHorizontal the first time it is used on a workspace.
+func (w *Workspace) CycleLayout() {
+	if w == nil {
+		return
+	}
+	preset := w.nextLayout
+	w.nextLayout = (preset + 1) % layoutPresetCount
+	w.ApplyLayout(preset)
+}
+
+// ApplyLayout rebuilds the tree from its current panes, in tree order, using
+// preset. Panes slide from their old sizes to the new ones.
+func (w *Workspace) ApplyLayout(preset LayoutPreset) {
+	if w == nil || w.tree == nil || w.tree.Root == nil {
+		return
+	}
+	var leaves []*Node
+	w.tree.Traverse(func(n *Node) {
+		if n.Pane != nil {
+			leaves = append(leaves, n)
+		}
+	})
+	if len(leaves) < 2 {
+		return
+	}
+	root := buildPresetTree(leaves, preset)
+	debuglog.Printf("ApplyLayout: %s over %d panes", preset, len(leaves))
+
+	// Panes still hold their old geometry, so each new split can start
+	// from the sizes its children currently occupy.
+	w.animateToRatios(collectSplits(root), func(n *Node) []float64 {
+		target := n.SplitRatios
+		n.SplitRatios = w.tree.currentRatios(n)
+		return target
+	})
+	root.Parent = nil
+	w.tree.Root = root
+	w.layoutChanged()
+}
+
+// EqualizeRatios gives every child of every split an equal share.
+func (w *Workspace) EqualizeRatios() {
+	if w == nil || w.tree == nil || w.tree.Root == nil {
+		return
+	}
+	var nodes []*Node
+	for _, n := range collectSplits(w.tree.Root) {
+		if !ratiosAreEqual(n.SplitRatios) || len(n.SplitRatios) != len(n.Children) {
+			nodes = append(nodes, n)
+		}
+	}
+	w.animateToRatios(nodes, func(n *Node) []float64 {
+		return equalRatios(len(n.Children))
+	})
+	w.layoutChanged()
+}
+
+// animateToRatios starts one transition per node towards the ratios target
+// returns; target may also rewrite the node's starting ratios.
+func (w *Workspace) animateToRatios(nodes []*Node, target func(n *Node) []float64) {
+	for _, n := range nodes {
+		ratios := target(n)
+		if w.desktop != nil && w.desktop.layoutTransitions != nil {
+			w.desktop.layoutTransitions.AnimateSplit(n, ratios)
+		} else {
+			n.SplitRatios = ratios
+		}
+	}
+}
+
+// layoutChanged publishes the new layout unless an animation is already
+// doing so on every frame.
+func (w *Workspace) layoutChanged() {
+	if w.desktop != nil && w.desktop.layoutTransitions != nil && w.desktop.layoutTransitions.IsAnimating() {
+		return
+	}
+	w.recalculateLayout()
+	forEachLeafPane(w.tree.Root, func(p *pane) { p.markDirty() })
+	w.Refresh()
+	if w.desktop != nil {
+		w.desktop.broadcastTreeChanged()
+	}
+}
+
+// buildPresetTree arranges leaves according to preset and returns the root.
+func buildPresetTree(leaves []*Node, preset LayoutPreset) *Node {
+	switch preset {
+	case LayoutEvenVertical:
+		return groupNodes(Horizontal, leaves)
+	case LayoutMainVertical, LayoutMainHorizontal:
+		outer, inner := Vertical, Horizontal
+		if preset == LayoutMainHorizontal {
+			outer, inner = Horizontal, Vertical
+		}
+		root := groupNodes(outer, []*Node{leaves[0], groupNodes(inner, leaves[1:])})
+		root.SplitRatios = []float64{mainPaneRatio, 1 - mainPaneRatio}
+		return root
+	case LayoutTiled:
+		cols := int(math.Ceil(math.Sqrt(float64(len(leaves)))))
+		var rows []*Node
+		for i := 0; i < len(leaves); i += cols {
+			rows = append(rows, groupNodes(Vertical, leaves[i:min(i+cols, len(leaves))]))
+		}
+		return groupNodes(Horizontal, rows)
+	default:
+		return groupNodes(Vertical, leaves)
+	}
+}
+
+// groupNodes returns a split of children with equal ratios, or the only
+// child itself.
+func groupNodes(split SplitType, children []*Node) *Node {
+	if len(children) == 1 {
+		return children[0]
+	}
+	node := &Node{
+		Split:       split,
+		SplitRatios: equalRatios(len(children)),
+		Children:    append([]*Node(nil), children...),
+	}
+	for _, child := range node.Children {
+		child.Parent = node
+	}
+	return node
+}
+
+func equalRatios(n int) []float64 {
+	ratios := make([]float64, n)
+	for i := range ratios {
+		ratios[i] = 1.0 / float64(n)
+	}
+	return ratios
+}
+
+// collectSplits returns the Horizontal and Vertical nodes under root.
+func collectSplits(root *Node) []*Node {
+	var nodes []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if len(n.Children) > 1 && n.Split != Tabbed {
+			nodes = append(nodes, n)
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return nodes
+}
+
+// currentRatios measures how much of n's axis each child covers right now,
+// falling back to n's own ratios when the children have no usable size.
+func (t *Tree) currentRatios(n *Node) []float64 {
+	ratios := make([]float64, len(n.Children))
+	total := 0.0
+	for i, child := range n.Children {
+		x0, y0, x1, y1, ok := t.NodeBounds(child)
+		if !ok {
+			return n.SplitRatios
+		}
+		extent := float64(x1 - x0)
+		if n.Split == Horizontal {
+			extent = float64(y1 - y0)
+		}
+		if extent <= 0 {
+			return n.SplitRatios
+		}
+		ratios[i] = extent
+		total += extent
+	}
+	for i := range ratios {
+		ratios[i] /= total
+	}
+	return ratios
+}
@@ -0,0 +1,193 @@
+// Copyright © 2026 Texelation contributors
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// File: texel/workspace_tabs.go
+// Summary: Tabbed (stacked) containers: conversion, tab cycling, tab strip.
+// Usage: Control mode toggles the active pane's parent split into a Tabbed
+//   node and back, and cycles its tabs; clicking a tab in the strip drawn on
+//   the visible pane's top border selects it.
+
+package texel
+
+import (
+	"github.com/framegrace/texelation/internal/debuglog"
+	"github.com/mattn/go-runewidth"
+)
+
+// maxTabLabelWidth caps one tab's title so a long title cannot push every
+// other tab off the strip.
+const maxTabLabelWidth = 20
+
+// ToggleTabbed converts the split holding the active pane into a Tabbed
+// container, or a Tabbed container back into the split it was.
+func (w *Workspace) ToggleTabbed() {
+	if w == nil || w.tree == nil || w.tree.ActiveLeaf == nil {
+		return
+	}
+	parent := w.tree.ActiveLeaf.Parent
+	if parent == nil {
+		return
+	}
+	if parent.Split == Tabbed {
+		parent.Split = parent.untabbedSplit
+		debuglog.Printf("ToggleTabbed: restored %d tabs to split %v", len(parent.Children), parent.Split)
+	} else {
+		parent.untabbedSplit = parent.Split
+		parent.Split = Tabbed
+		debuglog.Printf("ToggleTabbed: stacked %d panes into tabs", len(parent.Children))
+	}
+	w.tabsChanged()
+}
+
+// CycleTab shows the next (delta > 0) or previous tab of the innermost
+// Tabbed container holding the active pane and focuses it.
+func (w *Workspace) CycleTab(delta int) {
+	if w == nil || w.tree == nil || w.tree.ActiveLeaf == nil {
+		return
+	}
+	container := w.tree.ActiveLeaf.Parent
+	for container != nil && container.Split != Tabbed {
+		container = container.Parent
+	}
+	if container == nil || len(container.Children) < 2 {
+		return
+	}
+	n := len(container.Children)
+	w.selectTab(container, ((container.activeTabIndex()+delta)%n+n)%n)
+}
+
+// selectTab shows container's tab index and focuses its first leaf.
+func (w *Workspace) selectTab(container *Node, index int) {
+	container.ActiveTab = index
+	if leaf := w.tree.findFirstLeaf(container.Children[index]); leaf != nil {
+		w.activateLeaf(leaf)
+	}
+	w.tabsChanged()
+}
+
+func (w *Workspace) tabsChanged() {
+	w.recalculateLayout()
+	forEachLeafPane(w.tree.Root, func(p *pane) { p.markDirty() })
+	w.Refresh()
+	if w.desktop != nil {
+		w.desktop.broadcastTreeChanged()
+	}
+}
+
+// selectTabAt handles a click at (x, y). It reports true when the point
+// was on a tab in some pane's tab strip.
+func (w *Workspace) selectTabAt(x, y int) bool {
+	if w == nil || w.tree == nil {
+		return false
+	}
+	node := w.tree.FindLeafAt(x, y)
+	if node == nil || node.Pane == nil || y != node.Pane.absY0 {
+		return false
+	}
+	container := tabStripContainer(node)
+	if container == nil {
+		return false
+	}
+	local := x - node.Pane.absX0
+	for _, tab := range layoutTabStrip(tabLabels(container), container.activeTabIndex(), node.Pane.Width()) {
+		if local >= tab.x0 && local < tab.x1 {
+			w.selectTab(container, tab.index)
+			return true
+		}
+	}
+	return false
+}
+
+// tabStripContainer returns the Tabbed container whose strip is drawn on
+// leaf's top border: leaf must be the top-left leaf of the visible tab.
+func tabStripContainer(leaf *Node) *Node {
+	for child, parent := leaf, leaf.Parent; parent != nil; child, parent = parent, parent.Parent {
+		if parent.Split == Tabbed {
+			if parent.Children[parent.activeTabIndex()] == child {
+				return parent
+			}
+			return nil
+		}
+		if parent.Children[0] != child {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tabLabels names each tab after the title of the pane it would focus.
+func tabLabels(container *Node) []string {
+	labels := make([]string, len(container.Children))
+	for i, child := range container.Children {
+		leaf := child
+		for len(leaf.Children) > 0 {
+			leaf = leaf.visibleChildren()[0]
+		}
+		if leaf.Pane != nil {
+			labels[i] = runewidth.Truncate(leaf.Pane.getTitle(), maxTabLabelWidth, "…")
+		}
+	}
+	return labels
+}
+
+type tabSpan struct {
+	index  int
+	x0, x1 int // columns within the pane, [x0, x1)
+	text   string
+}
+
+// layoutTabStrip places " label " cells along a top border of the given
+// width, leaving the corners alone. Tabs that do not fit are dropped, but
+// the active tab is always shown, replacing the last visible one if needed.
+func layoutTabStrip(labels []string, active, width int) []tabSpan {
+	limit := width - 1
+	var spans []tabSpan
+	x := 1
+	place := func(i int) bool {
+		text := " " + labels[i] + " "
+		w := runewidth.StringWidth(text)
+		if x+w > limit {
+			return false
+		}
+		spans = append(spans, tabSpan{index: i, x0: x, x1: x + w, text: text})
+		x += w
+		return true
+	}
+	for i := range labels {
+		if !place(i) {
+			break
+		}
+	}
+	shown := false
+	for _, s := range spans {
+		shown = shown || s.index == active
+	}
+	if !shown && active >= 0 && active < len(labels) {
+		for len(spans) > 0 {
+			last := spans[len(spans)-1]
+			spans = spans[:len(spans)-1]
+			x = last.x0
+			if place(active) {
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// tabStrip returns the tab labels and active index to draw on p's top
+// border, or nil when p does not carry a tab strip.
+func (p *pane) tabStrip() ([]string, int) {
+	if p == nil || p.screen == nil || p.screen.tree == nil {
+		return nil, 0
+	}
+	node := p.screen.tree.FindNodeWithPane(p)
+	if node == nil {
+		return nil, 0
+	}
+	container := tabStripContainer(node)
+	if container == nil {
+		return nil, 0
+	}
+	return tabLabels(container), container.activeTabIndex()
+}